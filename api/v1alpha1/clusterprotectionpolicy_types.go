@@ -0,0 +1,56 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterProtectionPolicySpec defines the resources that must never be granted by generated ClusterRoles
+type ClusterProtectionPolicySpec struct {
+
+	// Rules are denied on every DynamicClusterRole, whatever their spec says.
+	// They are expressed and expanded the same way as deny rules. As ClusterRoles are not bound
+	// to namespaces, protected resources are removed from all of them
+	Rules []rbacv1.PolicyRule `json:"rules"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// ClusterProtectionPolicy is the Schema for the clusterprotectionpolicies API
+type ClusterProtectionPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterProtectionPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterProtectionPolicyList contains a list of ClusterProtectionPolicy
+type ClusterProtectionPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterProtectionPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterProtectionPolicy{}, &ClusterProtectionPolicyList{})
+}
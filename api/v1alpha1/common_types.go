@@ -16,7 +16,51 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DeletionPolicyDelete deletes the generated resources when their owner is deleted
+	DeletionPolicyDelete = "Delete"
+
+	// DeletionPolicyOrphan keeps the generated resources in the cluster when their owner is deleted,
+	// removing the references to it
+	DeletionPolicyOrphan = "Orphan"
+)
+
 // SynchronizationT defines the spec of the synchronization section of a DynamicClusterRole
 type SynchronizationT struct {
-	Time string `json:"time"`
+
+	// Time between synchronizations, as a Go duration such as '30s' or '5m'.
+	// When not set, the default time of the controller is used
+	Time string `json:"time,omitempty"`
+}
+
+// SyncChangeT summarizes the changes applied to the generated resources on a synchronization.
+// Lists are truncated to keep the status small, but counts are always complete
+type SyncChangeT struct {
+	Time metav1.Time `json:"time"`
+
+	AddedRulesCount   int      `json:"addedRulesCount,omitempty"`
+	RemovedRulesCount int      `json:"removedRulesCount,omitempty"`
+	AddedRules        []string `json:"addedRules,omitempty"`
+	RemovedRules      []string `json:"removedRules,omitempty"`
+
+	AddedSubjectsCount   int      `json:"addedSubjectsCount,omitempty"`
+	RemovedSubjectsCount int      `json:"removedSubjectsCount,omitempty"`
+	AddedSubjects        []string `json:"addedSubjects,omitempty"`
+	RemovedSubjects      []string `json:"removedSubjects,omitempty"`
+}
+
+// MemberClusterStatusT is the result of propagating the generated resources onto a member cluster of the inventory
+type MemberClusterStatusT struct {
+	Name   string `json:"name"`
+	Synced bool   `json:"synced"`
+
+	// Message carries the error of the last propagation when it failed
+	Message string `json:"message,omitempty"`
+
+	// LastSyncTime is the time of the last successful propagation onto the member cluster
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 }
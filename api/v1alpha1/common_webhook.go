@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Defaulter sets sensible defaults on the resources when they are created or updated, so common mistakes
+// are fixed on admission instead of failing on synchronization. Resources of every version are defaulted by it,
+// as the API server converts them to this one before calling the webhook
+// +kubebuilder:object:generate=false
+type Defaulter struct {
+
+	// SyncTime is set on the resources not setting spec.synchronization.time.
+	// When zero, it is not set, and the default time of the controller is used
+	SyncTime time.Duration
+}
+
+// Default sets the defaults of a DynamicClusterRole, a DynamicRoleBinding or a DynamicServiceAccount
+func (d *Defaulter) Default(ctx context.Context, obj runtime.Object) error {
+
+	switch resource := obj.(type) {
+	case *DynamicClusterRole:
+		resource.setDefaults(d.SyncTime)
+	case *DynamicRoleBinding:
+		resource.setDefaults(d.SyncTime)
+	case *DynamicServiceAccount:
+		resource.setDefaults(d.SyncTime)
+	default:
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+
+	return nil
+}
+
+// defaultSynchronization sets the given time on a synchronization not setting it,
+// so the time a resource is synchronized with is visible on it
+func defaultSynchronization(synchronization *SynchronizationT, syncTime time.Duration) {
+	if synchronization.Time == "" && syncTime > 0 {
+		synchronization.Time = syncTime.String()
+	}
+}
+
+// normalizeVerbs lowercases the verbs and trims their spaces, as Kubernetes compares them case-sensitively,
+// so 'Get' or 'LIST' would never grant nor deny anything
+func normalizeVerbs(verbs []string) {
+	for index, verb := range verbs {
+		verbs[index] = strings.ToLower(strings.TrimSpace(verb))
+	}
+}
+
+// defaultSubjectAPIGroup sets the API group of a subject depending on its kind. It is always empty for ServiceAccounts,
+// and 'rbac.authorization.k8s.io' for Users and Groups not setting it
+func defaultSubjectAPIGroup(kind string, apiGroup *string) {
+	switch kind {
+	case rbacv1.ServiceAccountKind:
+		*apiGroup = ""
+	case rbacv1.UserKind, rbacv1.GroupKind:
+		if *apiGroup == "" {
+			*apiGroup = rbacv1.GroupName
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Defaulting webhook", func() {
+
+	ctx := context.Background()
+	defaulter := &Defaulter{SyncTime: 5 * time.Minute}
+
+	DescribeTable("When defaulting the synchronization time",
+		func(defaulter *Defaulter, synchronization SynchronizationT, expected string) {
+			dynamicClusterRole := &DynamicClusterRole{Spec: DynamicClusterRoleSpec{Synchronization: synchronization}}
+			dynamicRoleBinding := &DynamicRoleBinding{Spec: DynamicRoleBindingSpec{Synchronization: synchronization}}
+			dynamicServiceAccount := &DynamicServiceAccount{Spec: DynamicServiceAccountSpec{Synchronization: synchronization}}
+
+			Expect(defaulter.Default(ctx, dynamicClusterRole)).To(Succeed())
+			Expect(defaulter.Default(ctx, dynamicRoleBinding)).To(Succeed())
+			Expect(defaulter.Default(ctx, dynamicServiceAccount)).To(Succeed())
+
+			Expect(dynamicClusterRole.Spec.Synchronization.Time).To(Equal(expected))
+			Expect(dynamicRoleBinding.Spec.Synchronization.Time).To(Equal(expected))
+			Expect(dynamicServiceAccount.Spec.Synchronization.Time).To(Equal(expected))
+		},
+		Entry("should set the time of the controller when it is not set",
+			defaulter, SynchronizationT{}, "5m0s"),
+		Entry("should keep the time set on the resource",
+			defaulter, SynchronizationT{Time: "30s"}, "30s"),
+		Entry("should not set any time when the controller has none",
+			&Defaulter{}, SynchronizationT{}, ""),
+	)
+
+	It("should lowercase and trim the verbs of a DynamicClusterRole", func() {
+		resource := &DynamicClusterRole{Spec: DynamicClusterRoleSpec{
+			Allow: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"Pods"}, Verbs: []string{"Get", " LIST "}}},
+			Deny: []DenyPolicyRuleT{{PolicyRule: rbacv1.PolicyRule{
+				APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"DELETE"}}}},
+			DefaultDeniedVerbs: []string{"DeleteCollection"},
+		}}
+		Expect(defaulter.Default(ctx, resource)).To(Succeed())
+
+		Expect(resource.Spec.Allow[0].Verbs).To(Equal([]string{"get", "list"}))
+		Expect(resource.Spec.Deny[0].Verbs).To(Equal([]string{"delete"}))
+		Expect(resource.Spec.DefaultDeniedVerbs).To(Equal([]string{"deletecollection"}))
+
+		By("keeping the rest of the rule as it is")
+		Expect(resource.Spec.Allow[0].Resources).To(Equal([]string{"Pods"}))
+	})
+
+	DescribeTable("When defaulting the API group of the subjects of a DynamicRoleBinding",
+		func(kind, apiGroup, expected string) {
+			resource := &DynamicRoleBinding{Spec: DynamicRoleBindingSpec{Source: DynamicRoleBindingSource{
+				Subject:        &DynamicRoleBindingSourceSubject{Kind: kind, ApiGroup: apiGroup},
+				StaticSubjects: []rbacv1.Subject{{Kind: kind, APIGroup: apiGroup, Name: "static"}},
+			}}}
+			Expect(defaulter.Default(ctx, resource)).To(Succeed())
+
+			Expect(resource.Spec.Source.Subject.ApiGroup).To(Equal(expected))
+			Expect(resource.Spec.Source.StaticSubjects[0].APIGroup).To(Equal(expected))
+		},
+		Entry("should fill it on Groups", rbacv1.GroupKind, "", rbacv1.GroupName),
+		Entry("should fill it on Users", rbacv1.UserKind, "", rbacv1.GroupName),
+		Entry("should keep the one set on Users", rbacv1.UserKind, "example.com", "example.com"),
+		Entry("should clear it on ServiceAccounts", rbacv1.ServiceAccountKind, rbacv1.GroupName, ""),
+		Entry("should keep it on unknown kinds, so they are rejected as they are", "Robot", "", ""),
+	)
+
+	It("should default a DynamicRoleBinding without subject selector", func() {
+		resource := &DynamicRoleBinding{Spec: DynamicRoleBindingSpec{Source: DynamicRoleBindingSource{
+			StaticSubjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "developers"}},
+		}}}
+		Expect(defaulter.Default(ctx, resource)).To(Succeed())
+
+		Expect(resource.Spec.Source.Subject).To(BeNil())
+		Expect(resource.Spec.Source.StaticSubjects[0].APIGroup).To(Equal(rbacv1.GroupName))
+	})
+
+	It("should reject objects of other kinds", func() {
+		Expect(defaulter.Default(ctx, &corev1.ConfigMap{})).To(MatchError(ContainSubstring("unexpected object")))
+	})
+
+	It("should be called for every version of the resources, converted to this one", func() {
+		manifests, err := os.ReadFile("../../config/webhook/manifests.yaml")
+		Expect(err).NotTo(HaveOccurred())
+
+		configuration := admissionregistrationv1.MutatingWebhookConfiguration{}
+		Expect(yaml.Unmarshal(manifests, &configuration)).To(Succeed())
+		Expect(configuration.Webhooks).To(HaveLen(3))
+
+		for _, webhook := range configuration.Webhooks {
+			Expect(webhook.MatchPolicy).NotTo(BeNil(), "webhook %s", webhook.Name)
+			Expect(*webhook.MatchPolicy).To(Equal(admissionregistrationv1.Equivalent), "webhook %s", webhook.Name)
+		}
+	})
+})
@@ -0,0 +1,131 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DynamicAccessSource defines the subjects granted the access of a DynamicAccess
+type DynamicAccessSource struct {
+	Subject DynamicRoleBindingSourceSubject `json:"subject"`
+}
+
+// DynamicAccessTargets defines the spec of the targets section of a DynamicAccess.
+// A Role and a RoleBinding, both called as name, are generated on each targeted namespace
+type DynamicAccessTargets struct {
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+
+	NamespaceSelector NamespaceSelectorT `json:"namespaceSelector,omitempty"`
+
+	// ExcludeSystemNamespaces skips kube-system, kube-public and kube-node-lease when selecting target namespaces.
+	// When not set, the default of the controller is used, which excludes them
+	ExcludeSystemNamespaces *bool `json:"excludeSystemNamespaces,omitempty"`
+}
+
+// DynamicAccessSpec defines the desired state of DynamicAccess
+type DynamicAccessSpec struct {
+
+	// SynchronizationSpec defines the behavior of synchronization
+	Synchronization SynchronizationT `json:"synchronization,omitempty"`
+
+	// DeletionPolicy defines what happens to the generated resources when this one is deleted:
+	// 'Delete' removes them, while 'Orphan' keeps them in the cluster untracked. Defaults to 'Delete'
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// Allow and Deny are evaluated as in DynamicClusterRoles. Roles can only grant access to namespaced resources,
+	// so the rules about cluster-scoped resources and non-resource URLs are left out of the generated Roles
+	Allow []rbacv1.PolicyRule `json:"allow,omitempty"`
+	Deny  []DenyPolicyRuleT   `json:"deny,omitempty"`
+
+	// DefaultDeniedVerbs are removed from every allow rule, including those granted through '*', unless the rule
+	// names them explicitly in its verbs. Aliases of groups of verbs, such as 'write', are accepted
+	DefaultDeniedVerbs []string `json:"defaultDeniedVerbs,omitempty"`
+
+	//
+	Source  DynamicAccessSource  `json:"source"`
+	Targets DynamicAccessTargets `json:"targets"`
+}
+
+// DynamicAccessStatus defines the observed state of DynamicAccess
+type DynamicAccessStatus struct {
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions"`
+
+	// RulesCount is the number of rules of the generated Roles
+	RulesCount int `json:"rulesCount,omitempty"`
+
+	// SubjectsCount is the number of subjects bound on each targeted namespace
+	SubjectsCount int `json:"subjectsCount,omitempty"`
+
+	// TargetNamespacesCount is the number of namespaces where a Role and a RoleBinding were generated
+	TargetNamespacesCount int `json:"targetNamespacesCount,omitempty"`
+
+	// ObservedGeneration is the generation of the spec synchronized on the last successful synchronization
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// NextSyncTime is the time when the next periodical synchronization is scheduled.
+	// It is not set while failures are retried with backoff, or while the resource waits for changes
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// LastSyncDuration is the time spent on the last synchronization, successful or not
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+
+	// MemberClusters contains the result of propagating the generated resources onto each member cluster,
+	// when the operator is configured with a cluster inventory
+	MemberClusters []MemberClusterStatusT `json:"memberClusters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].reason",description=""
+// +kubebuilder:printcolumn:name="Rules",type="integer",JSONPath=".status.rulesCount",description=""
+// +kubebuilder:printcolumn:name="Subjects",type="integer",JSONPath=".status.subjectsCount",description=""
+// +kubebuilder:printcolumn:name="Namespaces",type="integer",JSONPath=".status.targetNamespacesCount",description=""
+// +kubebuilder:printcolumn:name="Next Sync",type="string",JSONPath=".status.nextSyncTime",description=""
+// +kubebuilder:printcolumn:name="Sync Duration",type="string",JSONPath=".status.lastSyncDuration",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// DynamicAccess is the Schema for the dynamicaccesses API.
+// It renders the Roles and the RoleBindings binding them on each targeted namespace in one go,
+// so a DynamicClusterRole and a DynamicRoleBinding do not need to be kept in lockstep
+type DynamicAccess struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DynamicAccessSpec   `json:"spec,omitempty"`
+	Status DynamicAccessStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DynamicAccessList contains a list of DynamicAccess
+type DynamicAccessList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DynamicAccess `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DynamicAccess{}, &DynamicAccessList{})
+}
@@ -29,18 +29,143 @@ type TargetT struct {
 	Labels      map[string]string `json:"labels,omitempty"`
 
 	SeparateScopes bool `json:"separateScopes,omitempty"`
+
+	// DryRun renders the ClusterRoles into the status, but never creates or updates them
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// EmitAdmissionPolicy generates a ValidatingAdmissionPolicy, and its binding, rejecting the requests matching
+	// the deny rules when they are made by the subjects bound to the generated ClusterRoles. This way, denials are
+	// enforced even when other roles allow them. Only write operations reach admission, so reading verbs are ignored
+	EmitAdmissionPolicy bool `json:"emitAdmissionPolicy,omitempty"`
+
+	// AdmissionFailurePolicy defines what happens to the requests when the ValidatingAdmissionPolicy can not be evaluated:
+	// 'Ignore' admits them, while 'Fail' rejects them. Every write request on the mirrored resources is evaluated,
+	// including those of subjects not bound to the ClusterRoles, so it defaults to 'Ignore'
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	AdmissionFailurePolicy string `json:"admissionFailurePolicy,omitempty"`
+
+	// CompactRules merges the generated rules sharing the same verbs into fewer ones. Stretching wildcards
+	// produces a rule for each resource, so broad policies can exceed the object size limits of etcd otherwise
+	CompactRules bool `json:"compactRules,omitempty"`
+
+	// MaxRulesPerClusterRole shards the rules into several ClusterRoles, named '<name>-shard-<index>',
+	// which are aggregated into the ClusterRole of the target. Disabled when zero
+	// +kubebuilder:validation:Minimum=0
+	MaxRulesPerClusterRole int `json:"maxRulesPerClusterRole,omitempty"`
+
+	// ExportConfigMap writes the rules generated for the target as YAML into a ConfigMap, so external tools
+	// can consume the effective policy without being allowed to read ClusterRoles
+	ExportConfigMap *ExportConfigMapT `json:"exportConfigMap,omitempty"`
+}
+
+// ExportConfigMapT defines the ConfigMap where the rules generated for a target are exported
+type ExportConfigMapT struct {
+	// Name defaults to the name of the target
+	Name string `json:"name,omitempty"`
+
+	// Namespace defaults to the namespace of the DynamicClusterRole
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RenderedClusterRoleT represents a ClusterRole rendered in dry-run mode
+type RenderedClusterRoleT struct {
+	Name  string              `json:"name"`
+	Rules []rbacv1.PolicyRule `json:"rules,omitempty"`
+}
+
+// DenyPolicyRuleT represents a PolicyRule to be denied. It can be narrowed to the objects matching a label selector.
+// In that case, it is translated into a rule with the names of the matching objects on each synchronization
+type DenyPolicyRuleT struct {
+	rbacv1.PolicyRule `json:",inline"`
+
+	// ObjectSelector restricts the rule to the objects whose labels match it
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+}
+
+// ClusterRoleSourceT references existing ClusterRoles whose rules are imported into the allow list.
+// They are selected by name, or by a label selector
+type ClusterRoleSourceT struct {
+	Name     string                `json:"name,omitempty"`
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ValuesReferenceT references an object holding values, living in the same namespace as the DynamicClusterRole
+type ValuesReferenceT struct {
+	Name string `json:"name"`
+
+	// Optional ignores the reference when the object does not exist
+	Optional bool `json:"optional,omitempty"`
+}
+
+// ValuesSourceT defines where the values injected into the templates are read from.
+// Exactly one of configMapRef or secretRef must be set. All the keys of the object are read
+type ValuesSourceT struct {
+	ConfigMapRef *ValuesReferenceT `json:"configMapRef,omitempty"`
+	SecretRef    *ValuesReferenceT `json:"secretRef,omitempty"`
+}
+
+// ImpersonateT defines the identities the subjects bound to the generated ClusterRoles can impersonate
+type ImpersonateT struct {
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+
+	// ServiceAccounts are selected by name, or by a label selector, inside a namespace or in all of them
+	ServiceAccounts []ImpersonateServiceAccountT `json:"serviceAccounts,omitempty"`
+}
+
+// ImpersonateServiceAccountT selects the ServiceAccounts that can be impersonated. Exactly one of name or selector
+// must be set. RBAC authorizes their impersonation by name, so ServiceAccounts with the same name in other namespaces
+// can be impersonated too when the ClusterRole is bound cluster-wide
+type ImpersonateServiceAccountT struct {
+	Name      string                `json:"name,omitempty"`
+	Namespace string                `json:"namespace,omitempty"`
+	Selector  *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 // DynamicClusterRoleSpec defines the desired state of DynamicClusterRole
 type DynamicClusterRoleSpec struct {
 
 	// SynchronizationSpec defines the behavior of synchronization
-	Synchronization SynchronizationT `json:"synchronization"`
-
-	//
-	Target TargetT             `json:"target"`
-	Allow  []rbacv1.PolicyRule `json:"allow"`
-	Deny   []rbacv1.PolicyRule `json:"deny"`
+	Synchronization SynchronizationT `json:"synchronization,omitempty"`
+
+	// DeletionPolicy defines what happens to the generated resources when this one is deleted:
+	// 'Delete' removes them, while 'Orphan' keeps them in the cluster untracked. Defaults to 'Delete'
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// Target defines the ClusterRoles to generate. Several of them can be defined using Targets,
+	// rendering the same policy under different names, labels or scope-splitting options
+	Target  TargetT             `json:"target,omitempty"`
+	Targets []TargetT           `json:"targets,omitempty"`
+	Allow   []rbacv1.PolicyRule `json:"allow,omitempty"`
+	Deny    []DenyPolicyRuleT   `json:"deny"`
+
+	// AllowImpersonate generates the rules allowing to impersonate some identities by name. Wildcards are never
+	// expanded to the 'impersonate' verb, and impersonated users and groups are not resources served by the cluster,
+	// so they can not be granted through allow rules. Deny rules on the 'impersonate' verb exclude identities from them
+	AllowImpersonate *ImpersonateT `json:"allowImpersonate,omitempty"`
+
+	// DefaultDeniedVerbs are removed from every allow rule, including those granted through '*', unless the rule
+	// names them explicitly in its verbs. Useful for read-mostly roles, e.g. ['delete', 'deletecollection'].
+	// Aliases of groups of verbs, such as 'write', are accepted
+	DefaultDeniedVerbs []string `json:"defaultDeniedVerbs,omitempty"`
+
+	// From imports the rules of existing ClusterRoles into the allow list before evaluating deny rules,
+	// so well-known roles can be narrowed without copying their rules
+	From []ClusterRoleSourceT `json:"from,omitempty"`
+
+	// ValuesFrom reads the values injected, as '{{ .Values.key }}', into the templates of the names of the targets,
+	// and of the resourceNames and nonResourceURLs of the rules. Later sources override the keys of previous ones
+	ValuesFrom []ValuesSourceT `json:"valuesFrom,omitempty"`
+
+	// Explain writes a ConfigMap named '<name>-explanation', in the namespace of the resource, mapping each generated rule
+	// to the allow rules granting it and the deny rules removing some of its verbs. Useful to trace long policies
+	Explain bool `json:"explain,omitempty"`
+
+	// NamespaceSelector restricts the objects of namespaced resources read when deny rules are expanded into
+	// the names of the objects, by name or by objectSelector, to the namespaces matching it. This way, names of
+	// unrelated namespaces are neither excluded by mistake nor granted, and the generated rules are smaller
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 }
 
 // DynamicClusterRoleStatus defines the observed state of DynamicClusterRole
@@ -48,12 +173,46 @@ type DynamicClusterRoleStatus struct {
 
 	// Conditions represent the latest available observations of an object's state
 	Conditions []metav1.Condition `json:"conditions"`
+
+	// RenderedClusterRoles contains the ClusterRoles that would be generated when dry-run is enabled
+	RenderedClusterRoles []RenderedClusterRoleT `json:"renderedClusterRoles,omitempty"`
+
+	// GeneratedClusterRoles contains the names of the ClusterRoles generated on the last synchronization
+	GeneratedClusterRoles []string `json:"generatedClusterRoles,omitempty"`
+
+	// RulesCount is the number of PolicyRules generated on the last synchronization
+	RulesCount int `json:"rulesCount,omitempty"`
+
+	// LastSyncTime is the time of the last successful synchronization
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// NextSyncTime is the time when the next periodical synchronization is scheduled.
+	// It is not set while failures are retried with backoff, or while the resource waits for changes
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// LastSyncDuration is the time spent on the last synchronization, successful or not
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+
+	// ObservedGeneration is the generation of the spec synchronized on the last successful synchronization
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastChange summarizes the last synchronization that changed the generated ClusterRoles
+	LastChange *SyncChangeT `json:"lastChange,omitempty"`
+
+	// MemberClusters contains the result of propagating the generated resources onto each member cluster,
+	// when the operator is configured with a cluster inventory
+	MemberClusters []MemberClusterStatusT `json:"memberClusters,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].reason",description=""
+// +kubebuilder:printcolumn:name="Rules",type="integer",JSONPath=".status.rulesCount",description=""
+// +kubebuilder:printcolumn:name="Last Sync",type="date",JSONPath=".status.lastSyncTime",description=""
+// +kubebuilder:printcolumn:name="Next Sync",type="string",JSONPath=".status.nextSyncTime",description=""
+// +kubebuilder:printcolumn:name="Sync Duration",type="string",JSONPath=".status.lastSyncDuration",description=""
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
 // DynamicClusterRole is the Schema for the dynamicclusterroles API
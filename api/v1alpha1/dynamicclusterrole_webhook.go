@@ -0,0 +1,47 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// +kubebuilder:webhook:path=/mutate-kuberbac-prosimcorp-com-v1alpha1-dynamicclusterrole,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,sideEffects=None,groups=kuberbac.prosimcorp.com,resources=dynamicclusterroles,verbs=create;update,versions=v1alpha1,name=mdynamicclusterrole.kb.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the defaulting webhook for DynamicClusterRole in the manager
+func (r *DynamicClusterRole) SetupWebhookWithManager(mgr ctrl.Manager, defaulter *Defaulter) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(defaulter).
+		Complete()
+}
+
+// setDefaults sets the synchronization time when missing, and normalizes the verbs of the rules
+func (r *DynamicClusterRole) setDefaults(syncTime time.Duration) {
+
+	defaultSynchronization(&r.Spec.Synchronization, syncTime)
+
+	for index := range r.Spec.Allow {
+		normalizeVerbs(r.Spec.Allow[index].Verbs)
+	}
+	for index := range r.Spec.Deny {
+		normalizeVerbs(r.Spec.Deny[index].Verbs)
+	}
+	normalizeVerbs(r.Spec.DefaultDeniedVerbs)
+}
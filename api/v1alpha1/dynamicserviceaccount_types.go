@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DynamicServiceAccountTargets defines the spec of the targets section of a DynamicServiceAccount.
+// Name, annotations and labels values are Golang templates rendered for each targeted namespace
+type DynamicServiceAccountTargets struct {
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+
+	NamespaceSelector NamespaceSelectorT `json:"namespaceSelector,omitempty"`
+
+	// ExcludeSystemNamespaces skips kube-system, kube-public and kube-node-lease when selecting target namespaces.
+	// When not set, the default of the controller is used, which excludes them
+	ExcludeSystemNamespaces *bool `json:"excludeSystemNamespaces,omitempty"`
+}
+
+// DynamicServiceAccountSpec defines the desired state of DynamicServiceAccount
+type DynamicServiceAccountSpec struct {
+
+	// SynchronizationSpec defines the behavior of synchronization
+	Synchronization SynchronizationT `json:"synchronization,omitempty"`
+
+	// DeletionPolicy defines what happens to the generated resources when this one is deleted:
+	// 'Delete' removes them, while 'Orphan' keeps them in the cluster untracked. Defaults to 'Delete'
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	//
+	Targets DynamicServiceAccountTargets `json:"targets"`
+}
+
+// DynamicServiceAccountStatus defines the observed state of DynamicServiceAccount
+type DynamicServiceAccountStatus struct {
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions"`
+
+	// ObservedGeneration is the generation of the spec synchronized on the last successful synchronization
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// NextSyncTime is the time when the next periodical synchronization is scheduled.
+	// It is not set while failures are retried with backoff, or while the resource waits for changes
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// LastSyncDuration is the time spent on the last synchronization, successful or not
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].reason",description=""
+// +kubebuilder:printcolumn:name="Next Sync",type="string",JSONPath=".status.nextSyncTime",description=""
+// +kubebuilder:printcolumn:name="Sync Duration",type="string",JSONPath=".status.lastSyncDuration",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// DynamicServiceAccount is the Schema for the dynamicserviceaccounts API
+type DynamicServiceAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DynamicServiceAccountSpec   `json:"spec,omitempty"`
+	Status DynamicServiceAccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DynamicServiceAccountList contains a list of DynamicServiceAccount
+type DynamicServiceAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DynamicServiceAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DynamicServiceAccount{}, &DynamicServiceAccountList{})
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// +kubebuilder:webhook:path=/mutate-kuberbac-prosimcorp-com-v1alpha1-dynamicserviceaccount,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,sideEffects=None,groups=kuberbac.prosimcorp.com,resources=dynamicserviceaccounts,verbs=create;update,versions=v1alpha1,name=mdynamicserviceaccount.kb.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the defaulting webhook for DynamicServiceAccount in the manager
+func (r *DynamicServiceAccount) SetupWebhookWithManager(mgr ctrl.Manager, defaulter *Defaulter) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(defaulter).
+		Complete()
+}
+
+// setDefaults sets the synchronization time when missing
+func (r *DynamicServiceAccount) setDefaults(syncTime time.Duration) {
+	defaultSynchronization(&r.Spec.Synchronization, syncTime)
+}
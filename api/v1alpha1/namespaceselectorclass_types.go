@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceSelectorClassSpec defines a set of namespaces shared by several resources
+type NamespaceSelectorClassSpec struct {
+
+	// NamespaceSelector selects the namespaces of the class, the same way as the namespaceSelector of the targets.
+	// Resources referencing the class by name always use its latest definition, so sets of namespaces,
+	// such as all the tenant namespaces, are defined in a single place
+	NamespaceSelector NamespaceSelectorT `json:"namespaceSelector"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// NamespaceSelectorClass is the Schema for the namespaceselectorclasses API
+type NamespaceSelectorClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NamespaceSelectorClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespaceSelectorClassList contains a list of NamespaceSelectorClass
+type NamespaceSelectorClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceSelectorClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceSelectorClass{}, &NamespaceSelectorClassList{})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RBACReportSubjectT defines the subjects whose effective access is reported.
+// Selectors are matched against the subjects found on the bindings of the cluster,
+// so the subjects do not need to exist
+type RBACReportSubjectT struct {
+	// +kubebuilder:validation:Enum=ServiceAccount;User;Group
+	Kind string `json:"kind"`
+
+	NameSelector NameSelectorT `json:"nameSelector,omitempty"`
+
+	// NamespaceSelector narrows ServiceAccount subjects to some namespaces
+	NamespaceSelector NamespaceSelectorT `json:"namespaceSelector,omitempty"`
+}
+
+// RBACReportSpec defines the desired state of RBACReport
+type RBACReportSpec struct {
+
+	// SynchronizationSpec defines the behavior of synchronization
+	Synchronization SynchronizationT `json:"synchronization,omitempty"`
+
+	//
+	Subject RBACReportSubjectT `json:"subject"`
+
+	// IncludeUnmanaged evaluates every binding of the cluster, and not only those generated by Kuberbac
+	IncludeUnmanaged bool `json:"includeUnmanaged,omitempty"`
+}
+
+// NamespacedRulesT defines the rules granted inside a namespace
+type NamespacedRulesT struct {
+	Namespace string              `json:"namespace"`
+	Rules     []rbacv1.PolicyRule `json:"rules,omitempty"`
+}
+
+// SubjectAccessT defines the effective access of a subject: the union of the rules granted by its bindings
+type SubjectAccessT struct {
+	Subject rbacv1.Subject `json:"subject"`
+
+	// Bindings granting access to the subject, as 'Kind:namespace/name'. Those granting it through a group
+	// Kubernetes places the subject in implicitly, like 'system:authenticated', are followed by the group in parentheses
+	Bindings []string `json:"bindings,omitempty"`
+
+	// ClusterRules are granted cluster-wide by ClusterRoleBindings
+	ClusterRules []rbacv1.PolicyRule `json:"clusterRules,omitempty"`
+
+	// NamespacedRules are granted inside some namespaces by RoleBindings
+	NamespacedRules []NamespacedRulesT `json:"namespacedRules,omitempty"`
+}
+
+// RBACReportStatus defines the observed state of RBACReport
+type RBACReportStatus struct {
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions"`
+
+	// Subjects contains the effective access of each selected subject
+	Subjects []SubjectAccessT `json:"subjects,omitempty"`
+
+	// SubjectsCount is the number of subjects reported on the last synchronization
+	SubjectsCount int `json:"subjectsCount,omitempty"`
+
+	// LastSyncTime is the time of the last successful synchronization
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// NextSyncTime is the time when the next periodical synchronization is scheduled.
+	// It is not set while failures are retried with backoff, or while the resource waits for changes
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// LastSyncDuration is the time spent on the last synchronization, successful or not
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].reason",description=""
+// +kubebuilder:printcolumn:name="Subjects",type="integer",JSONPath=".status.subjectsCount",description=""
+// +kubebuilder:printcolumn:name="Next Sync",type="string",JSONPath=".status.nextSyncTime",description=""
+// +kubebuilder:printcolumn:name="Sync Duration",type="string",JSONPath=".status.lastSyncDuration",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// RBACReport is the Schema for the rbacreports API.
+// It is read-only: the effective access of the selected subjects is computed into its status
+type RBACReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RBACReportSpec   `json:"spec,omitempty"`
+	Status RBACReportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RBACReportList contains a list of RBACReport
+type RBACReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RBACReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RBACReport{}, &RBACReportList{})
+}
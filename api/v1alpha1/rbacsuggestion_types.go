@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RBACSuggestionSpec defines the desired state of RBACSuggestion
+type RBACSuggestionSpec struct {
+
+	// SynchronizationSpec defines the behavior of synchronization
+	Synchronization SynchronizationT `json:"synchronization,omitempty"`
+
+	// DynamicRoleBinding is the name of the DynamicRoleBinding, in the same namespace, whose bound subjects are observed
+	DynamicRoleBinding string `json:"dynamicRoleBinding"`
+}
+
+// RBACSuggestionStatus defines the observed state of RBACSuggestion
+type RBACSuggestionStatus struct {
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions"`
+
+	// SuggestedRules is the minimal allow list covering every request made by the bound subjects since the
+	// suggestion was created. It only grows, so recreate the RBACSuggestion to start observing again
+	SuggestedRules []rbacv1.PolicyRule `json:"suggestedRules,omitempty"`
+
+	// ObservedNamespaces contains the namespaces where the bound subjects made namespaced requests
+	ObservedNamespaces []string `json:"observedNamespaces,omitempty"`
+
+	// SubjectsCount is the number of subjects observed on the last synchronization
+	SubjectsCount int `json:"subjectsCount,omitempty"`
+
+	// RulesCount is the number of suggested rules
+	RulesCount int `json:"rulesCount,omitempty"`
+
+	// ObservedSince is the time when the requests started to be observed
+	ObservedSince *metav1.Time `json:"observedSince,omitempty"`
+
+	// LastSyncTime is the time of the last successful synchronization
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// NextSyncTime is the time when the next periodical synchronization is scheduled.
+	// It is not set while failures are retried with backoff, or while the resource waits for changes
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// LastSyncDuration is the time spent on the last synchronization, successful or not
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].reason",description=""
+// +kubebuilder:printcolumn:name="Binding",type="string",JSONPath=".spec.dynamicRoleBinding",description=""
+// +kubebuilder:printcolumn:name="Rules",type="integer",JSONPath=".status.rulesCount",description=""
+// +kubebuilder:printcolumn:name="Next Sync",type="string",JSONPath=".status.nextSyncTime",description=""
+// +kubebuilder:printcolumn:name="Sync Duration",type="string",JSONPath=".status.lastSyncDuration",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// RBACSuggestion is the Schema for the rbacsuggestions API.
+// It is read-only: the minimal rules used by the subjects of a DynamicRoleBinding, read from the audit events
+// of the cluster, are suggested into its status
+type RBACSuggestion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RBACSuggestionSpec   `json:"spec,omitempty"`
+	Status RBACSuggestionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RBACSuggestionList contains a list of RBACSuggestion
+type RBACSuggestionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RBACSuggestion `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RBACSuggestion{}, &RBACSuggestionList{})
+}
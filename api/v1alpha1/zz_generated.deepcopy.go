@@ -26,6 +26,327 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapT) DeepCopyInto(out *BootstrapT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapT.
+func (in *BootstrapT) DeepCopy() *BootstrapT {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProtectionPolicy) DeepCopyInto(out *ClusterProtectionPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProtectionPolicy.
+func (in *ClusterProtectionPolicy) DeepCopy() *ClusterProtectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProtectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProtectionPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProtectionPolicyList) DeepCopyInto(out *ClusterProtectionPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterProtectionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProtectionPolicyList.
+func (in *ClusterProtectionPolicyList) DeepCopy() *ClusterProtectionPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProtectionPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProtectionPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProtectionPolicySpec) DeepCopyInto(out *ClusterProtectionPolicySpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProtectionPolicySpec.
+func (in *ClusterProtectionPolicySpec) DeepCopy() *ClusterProtectionPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProtectionPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRoleSourceT) DeepCopyInto(out *ClusterRoleSourceT) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRoleSourceT.
+func (in *ClusterRoleSourceT) DeepCopy() *ClusterRoleSourceT {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRoleSourceT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DenyPolicyRuleT) DeepCopyInto(out *DenyPolicyRuleT) {
+	*out = *in
+	in.PolicyRule.DeepCopyInto(&out.PolicyRule)
+	if in.ObjectSelector != nil {
+		in, out := &in.ObjectSelector, &out.ObjectSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DenyPolicyRuleT.
+func (in *DenyPolicyRuleT) DeepCopy() *DenyPolicyRuleT {
+	if in == nil {
+		return nil
+	}
+	out := new(DenyPolicyRuleT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicAccess) DeepCopyInto(out *DynamicAccess) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicAccess.
+func (in *DynamicAccess) DeepCopy() *DynamicAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicAccess) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicAccessList) DeepCopyInto(out *DynamicAccessList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynamicAccess, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicAccessList.
+func (in *DynamicAccessList) DeepCopy() *DynamicAccessList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicAccessList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicAccessList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicAccessSource) DeepCopyInto(out *DynamicAccessSource) {
+	*out = *in
+	in.Subject.DeepCopyInto(&out.Subject)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicAccessSource.
+func (in *DynamicAccessSource) DeepCopy() *DynamicAccessSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicAccessSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicAccessSpec) DeepCopyInto(out *DynamicAccessSpec) {
+	*out = *in
+	out.Synchronization = in.Synchronization
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]DenyPolicyRuleT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultDeniedVerbs != nil {
+		in, out := &in.DefaultDeniedVerbs, &out.DefaultDeniedVerbs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Source.DeepCopyInto(&out.Source)
+	in.Targets.DeepCopyInto(&out.Targets)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicAccessSpec.
+func (in *DynamicAccessSpec) DeepCopy() *DynamicAccessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicAccessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicAccessStatus) DeepCopyInto(out *DynamicAccessStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextSyncTime != nil {
+		in, out := &in.NextSyncTime, &out.NextSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncDuration != nil {
+		in, out := &in.LastSyncDuration, &out.LastSyncDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MemberClusters != nil {
+		in, out := &in.MemberClusters, &out.MemberClusters
+		*out = make([]MemberClusterStatusT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicAccessStatus.
+func (in *DynamicAccessStatus) DeepCopy() *DynamicAccessStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicAccessStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicAccessTargets) DeepCopyInto(out *DynamicAccessTargets) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.ExcludeSystemNamespaces != nil {
+		in, out := &in.ExcludeSystemNamespaces, &out.ExcludeSystemNamespaces
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicAccessTargets.
+func (in *DynamicAccessTargets) DeepCopy() *DynamicAccessTargets {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicAccessTargets)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DynamicClusterRole) DeepCopyInto(out *DynamicClusterRole) {
 	*out = *in
@@ -35,18 +356,905 @@ func (in *DynamicClusterRole) DeepCopyInto(out *DynamicClusterRole) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRole.
-func (in *DynamicClusterRole) DeepCopy() *DynamicClusterRole {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRole.
+func (in *DynamicClusterRole) DeepCopy() *DynamicClusterRole {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicClusterRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicClusterRole) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicClusterRoleList) DeepCopyInto(out *DynamicClusterRoleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynamicClusterRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRoleList.
+func (in *DynamicClusterRoleList) DeepCopy() *DynamicClusterRoleList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicClusterRoleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicClusterRoleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicClusterRoleSpec) DeepCopyInto(out *DynamicClusterRoleSpec) {
+	*out = *in
+	out.Synchronization = in.Synchronization
+	in.Target.DeepCopyInto(&out.Target)
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]TargetT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]DenyPolicyRuleT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowImpersonate != nil {
+		in, out := &in.AllowImpersonate, &out.AllowImpersonate
+		*out = new(ImpersonateT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultDeniedVerbs != nil {
+		in, out := &in.DefaultDeniedVerbs, &out.DefaultDeniedVerbs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]ClusterRoleSourceT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValuesSourceT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRoleSpec.
+func (in *DynamicClusterRoleSpec) DeepCopy() *DynamicClusterRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicClusterRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicClusterRoleStatus) DeepCopyInto(out *DynamicClusterRoleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RenderedClusterRoles != nil {
+		in, out := &in.RenderedClusterRoles, &out.RenderedClusterRoles
+		*out = make([]RenderedClusterRoleT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GeneratedClusterRoles != nil {
+		in, out := &in.GeneratedClusterRoles, &out.GeneratedClusterRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextSyncTime != nil {
+		in, out := &in.NextSyncTime, &out.NextSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncDuration != nil {
+		in, out := &in.LastSyncDuration, &out.LastSyncDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LastChange != nil {
+		in, out := &in.LastChange, &out.LastChange
+		*out = new(SyncChangeT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemberClusters != nil {
+		in, out := &in.MemberClusters, &out.MemberClusters
+		*out = make([]MemberClusterStatusT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRoleStatus.
+func (in *DynamicClusterRoleStatus) DeepCopy() *DynamicClusterRoleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicClusterRoleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicRoleBinding) DeepCopyInto(out *DynamicRoleBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBinding.
+func (in *DynamicRoleBinding) DeepCopy() *DynamicRoleBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicRoleBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicRoleBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicRoleBindingList) DeepCopyInto(out *DynamicRoleBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynamicRoleBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingList.
+func (in *DynamicRoleBindingList) DeepCopy() *DynamicRoleBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicRoleBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicRoleBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicRoleBindingSource) DeepCopyInto(out *DynamicRoleBindingSource) {
+	*out = *in
+	if in.ClusterRoles != nil {
+		in, out := &in.ClusterRoles, &out.ClusterRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterRoleSelector != nil {
+		in, out := &in.ClusterRoleSelector, &out.ClusterRoleSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Subject != nil {
+		in, out := &in.Subject, &out.Subject
+		*out = new(DynamicRoleBindingSourceSubject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StaticSubjects != nil {
+		in, out := &in.StaticSubjects, &out.StaticSubjects
+		*out = make([]v1.Subject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingSource.
+func (in *DynamicRoleBindingSource) DeepCopy() *DynamicRoleBindingSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicRoleBindingSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicRoleBindingSourceSubject) DeepCopyInto(out *DynamicRoleBindingSourceSubject) {
+	*out = *in
+	in.MetaSelector.DeepCopyInto(&out.MetaSelector)
+	in.NameSelector.DeepCopyInto(&out.NameSelector)
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingSourceSubject.
+func (in *DynamicRoleBindingSourceSubject) DeepCopy() *DynamicRoleBindingSourceSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicRoleBindingSourceSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicRoleBindingSpec) DeepCopyInto(out *DynamicRoleBindingSpec) {
+	*out = *in
+	out.Synchronization = in.Synchronization
+	in.Source.DeepCopyInto(&out.Source)
+	in.Targets.DeepCopyInto(&out.Targets)
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleT)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingSpec.
+func (in *DynamicRoleBindingSpec) DeepCopy() *DynamicRoleBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicRoleBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicRoleBindingStatus) DeepCopyInto(out *DynamicRoleBindingStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RenderedSubjects != nil {
+		in, out := &in.RenderedSubjects, &out.RenderedSubjects
+		*out = make([]v1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.RenderedNamespaces != nil {
+		in, out := &in.RenderedNamespaces, &out.RenderedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelectionExplanation != nil {
+		in, out := &in.SelectionExplanation, &out.SelectionExplanation
+		*out = make([]SelectionDecisionT, len(*in))
+		copy(*out, *in)
+	}
+	if in.GeneratedBindings != nil {
+		in, out := &in.GeneratedBindings, &out.GeneratedBindings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BindingsCreationTime != nil {
+		in, out := &in.BindingsCreationTime, &out.BindingsCreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleChangeTime != nil {
+		in, out := &in.NextScheduleChangeTime, &out.NextScheduleChangeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextSyncTime != nil {
+		in, out := &in.NextSyncTime, &out.NextSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncDuration != nil {
+		in, out := &in.LastSyncDuration, &out.LastSyncDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LastChange != nil {
+		in, out := &in.LastChange, &out.LastChange
+		*out = new(SyncChangeT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemberClusters != nil {
+		in, out := &in.MemberClusters, &out.MemberClusters
+		*out = make([]MemberClusterStatusT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingStatus.
+func (in *DynamicRoleBindingStatus) DeepCopy() *DynamicRoleBindingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicRoleBindingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicRoleBindingTargets) DeepCopyInto(out *DynamicRoleBindingTargets) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.ExcludeSystemNamespaces != nil {
+		in, out := &in.ExcludeSystemNamespaces, &out.ExcludeSystemNamespaces
+		*out = new(bool)
+		**out = **in
+	}
+	out.Bootstrap = in.Bootstrap
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingTargets.
+func (in *DynamicRoleBindingTargets) DeepCopy() *DynamicRoleBindingTargets {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicRoleBindingTargets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicServiceAccount) DeepCopyInto(out *DynamicServiceAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicServiceAccount.
+func (in *DynamicServiceAccount) DeepCopy() *DynamicServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicServiceAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicServiceAccountList) DeepCopyInto(out *DynamicServiceAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynamicServiceAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicServiceAccountList.
+func (in *DynamicServiceAccountList) DeepCopy() *DynamicServiceAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicServiceAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicServiceAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicServiceAccountSpec) DeepCopyInto(out *DynamicServiceAccountSpec) {
+	*out = *in
+	out.Synchronization = in.Synchronization
+	in.Targets.DeepCopyInto(&out.Targets)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicServiceAccountSpec.
+func (in *DynamicServiceAccountSpec) DeepCopy() *DynamicServiceAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicServiceAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicServiceAccountStatus) DeepCopyInto(out *DynamicServiceAccountStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextSyncTime != nil {
+		in, out := &in.NextSyncTime, &out.NextSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncDuration != nil {
+		in, out := &in.LastSyncDuration, &out.LastSyncDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicServiceAccountStatus.
+func (in *DynamicServiceAccountStatus) DeepCopy() *DynamicServiceAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicServiceAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicServiceAccountTargets) DeepCopyInto(out *DynamicServiceAccountTargets) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.ExcludeSystemNamespaces != nil {
+		in, out := &in.ExcludeSystemNamespaces, &out.ExcludeSystemNamespaces
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicServiceAccountTargets.
+func (in *DynamicServiceAccountTargets) DeepCopy() *DynamicServiceAccountTargets {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicServiceAccountTargets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportConfigMapT) DeepCopyInto(out *ExportConfigMapT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportConfigMapT.
+func (in *ExportConfigMapT) DeepCopy() *ExportConfigMapT {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportConfigMapT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImpersonateServiceAccountT) DeepCopyInto(out *ImpersonateServiceAccountT) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpersonateServiceAccountT.
+func (in *ImpersonateServiceAccountT) DeepCopy() *ImpersonateServiceAccountT {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpersonateServiceAccountT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImpersonateT) DeepCopyInto(out *ImpersonateT) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]ImpersonateServiceAccountT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpersonateT.
+func (in *ImpersonateT) DeepCopy() *ImpersonateT {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpersonateT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchRegexT) DeepCopyInto(out *MatchRegexT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchRegexT.
+func (in *MatchRegexT) DeepCopy() *MatchRegexT {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchRegexT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemberClusterStatusT) DeepCopyInto(out *MemberClusterStatusT) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemberClusterStatusT.
+func (in *MemberClusterStatusT) DeepCopy() *MemberClusterStatusT {
+	if in == nil {
+		return nil
+	}
+	out := new(MemberClusterStatusT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetaSelectorT) DeepCopyInto(out *MetaSelectorT) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchAnnotations != nil {
+		in, out := &in.MatchAnnotations, &out.MatchAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchAnnotationsRegex != nil {
+		in, out := &in.MatchAnnotationsRegex, &out.MatchAnnotationsRegex
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchExpressions != nil {
+		in, out := &in.MatchExpressions, &out.MatchExpressions
+		*out = make([]metav1.LabelSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaSelectorT.
+func (in *MetaSelectorT) DeepCopy() *MetaSelectorT {
+	if in == nil {
+		return nil
+	}
+	out := new(MetaSelectorT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NameSelectorT) DeepCopyInto(out *NameSelectorT) {
+	*out = *in
+	if in.MatchList != nil {
+		in, out := &in.MatchList, &out.MatchList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.MatchRegex = in.MatchRegex
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameSelectorT.
+func (in *NameSelectorT) DeepCopy() *NameSelectorT {
+	if in == nil {
+		return nil
+	}
+	out := new(NameSelectorT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSelectorClass) DeepCopyInto(out *NamespaceSelectorClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelectorClass.
+func (in *NamespaceSelectorClass) DeepCopy() *NamespaceSelectorClass {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSelectorClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceSelectorClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSelectorClassList) DeepCopyInto(out *NamespaceSelectorClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceSelectorClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelectorClassList.
+func (in *NamespaceSelectorClassList) DeepCopy() *NamespaceSelectorClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSelectorClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceSelectorClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSelectorClassSpec) DeepCopyInto(out *NamespaceSelectorClassSpec) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelectorClassSpec.
+func (in *NamespaceSelectorClassSpec) DeepCopy() *NamespaceSelectorClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSelectorClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSelectorT) DeepCopyInto(out *NamespaceSelectorT) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchAnnotations != nil {
+		in, out := &in.MatchAnnotations, &out.MatchAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchList != nil {
+		in, out := &in.MatchList, &out.MatchList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.MatchRegex = in.MatchRegex
+	if in.MatchAnnotationsRegex != nil {
+		in, out := &in.MatchAnnotationsRegex, &out.MatchAnnotationsRegex
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchExpressions != nil {
+		in, out := &in.MatchExpressions, &out.MatchExpressions
+		*out = make([]metav1.LabelSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelectorT.
+func (in *NamespaceSelectorT) DeepCopy() *NamespaceSelectorT {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSelectorT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedRulesT) DeepCopyInto(out *NamespacedRulesT) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedRulesT.
+func (in *NamespacedRulesT) DeepCopy() *NamespacedRulesT {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedRulesT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACReport) DeepCopyInto(out *RBACReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACReport.
+func (in *RBACReport) DeepCopy() *RBACReport {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicClusterRole)
+	out := new(RBACReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DynamicClusterRole) DeepCopyObject() runtime.Object {
+func (in *RBACReport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -54,31 +1262,31 @@ func (in *DynamicClusterRole) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicClusterRoleList) DeepCopyInto(out *DynamicClusterRoleList) {
+func (in *RBACReportList) DeepCopyInto(out *RBACReportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DynamicClusterRole, len(*in))
+		*out = make([]RBACReport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRoleList.
-func (in *DynamicClusterRoleList) DeepCopy() *DynamicClusterRoleList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACReportList.
+func (in *RBACReportList) DeepCopy() *RBACReportList {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicClusterRoleList)
+	out := new(RBACReportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DynamicClusterRoleList) DeepCopyObject() runtime.Object {
+func (in *RBACReportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -86,79 +1294,102 @@ func (in *DynamicClusterRoleList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicClusterRoleSpec) DeepCopyInto(out *DynamicClusterRoleSpec) {
+func (in *RBACReportSpec) DeepCopyInto(out *RBACReportSpec) {
 	*out = *in
 	out.Synchronization = in.Synchronization
-	in.Target.DeepCopyInto(&out.Target)
-	if in.Allow != nil {
-		in, out := &in.Allow, &out.Allow
-		*out = make([]v1.PolicyRule, len(*in))
+	in.Subject.DeepCopyInto(&out.Subject)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACReportSpec.
+func (in *RBACReportSpec) DeepCopy() *RBACReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RBACReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACReportStatus) DeepCopyInto(out *RBACReportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Deny != nil {
-		in, out := &in.Deny, &out.Deny
-		*out = make([]v1.PolicyRule, len(*in))
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]SubjectAccessT, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextSyncTime != nil {
+		in, out := &in.NextSyncTime, &out.NextSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncDuration != nil {
+		in, out := &in.LastSyncDuration, &out.LastSyncDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRoleSpec.
-func (in *DynamicClusterRoleSpec) DeepCopy() *DynamicClusterRoleSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACReportStatus.
+func (in *RBACReportStatus) DeepCopy() *RBACReportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicClusterRoleSpec)
+	out := new(RBACReportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicClusterRoleStatus) DeepCopyInto(out *DynamicClusterRoleStatus) {
+func (in *RBACReportSubjectT) DeepCopyInto(out *RBACReportSubjectT) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.NameSelector.DeepCopyInto(&out.NameSelector)
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicClusterRoleStatus.
-func (in *DynamicClusterRoleStatus) DeepCopy() *DynamicClusterRoleStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACReportSubjectT.
+func (in *RBACReportSubjectT) DeepCopy() *RBACReportSubjectT {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicClusterRoleStatus)
+	out := new(RBACReportSubjectT)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicRoleBinding) DeepCopyInto(out *DynamicRoleBinding) {
+func (in *RBACSuggestion) DeepCopyInto(out *RBACSuggestion) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBinding.
-func (in *DynamicRoleBinding) DeepCopy() *DynamicRoleBinding {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACSuggestion.
+func (in *RBACSuggestion) DeepCopy() *RBACSuggestion {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicRoleBinding)
+	out := new(RBACSuggestion)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DynamicRoleBinding) DeepCopyObject() runtime.Object {
+func (in *RBACSuggestion) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -166,31 +1397,31 @@ func (in *DynamicRoleBinding) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicRoleBindingList) DeepCopyInto(out *DynamicRoleBindingList) {
+func (in *RBACSuggestionList) DeepCopyInto(out *RBACSuggestionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DynamicRoleBinding, len(*in))
+		*out = make([]RBACSuggestion, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingList.
-func (in *DynamicRoleBindingList) DeepCopy() *DynamicRoleBindingList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACSuggestionList.
+func (in *RBACSuggestionList) DeepCopy() *RBACSuggestionList {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicRoleBindingList)
+	out := new(RBACSuggestionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DynamicRoleBindingList) DeepCopyObject() runtime.Object {
+func (in *RBACSuggestionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -198,198 +1429,226 @@ func (in *DynamicRoleBindingList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicRoleBindingSource) DeepCopyInto(out *DynamicRoleBindingSource) {
+func (in *RBACSuggestionSpec) DeepCopyInto(out *RBACSuggestionSpec) {
 	*out = *in
-	in.Subject.DeepCopyInto(&out.Subject)
+	out.Synchronization = in.Synchronization
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingSource.
-func (in *DynamicRoleBindingSource) DeepCopy() *DynamicRoleBindingSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACSuggestionSpec.
+func (in *RBACSuggestionSpec) DeepCopy() *RBACSuggestionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicRoleBindingSource)
+	out := new(RBACSuggestionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicRoleBindingSourceSubject) DeepCopyInto(out *DynamicRoleBindingSourceSubject) {
+func (in *RBACSuggestionStatus) DeepCopyInto(out *RBACSuggestionStatus) {
 	*out = *in
-	in.MetaSelector.DeepCopyInto(&out.MetaSelector)
-	in.NameSelector.DeepCopyInto(&out.NameSelector)
-	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SuggestedRules != nil {
+		in, out := &in.SuggestedRules, &out.SuggestedRules
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ObservedNamespaces != nil {
+		in, out := &in.ObservedNamespaces, &out.ObservedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ObservedSince != nil {
+		in, out := &in.ObservedSince, &out.ObservedSince
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextSyncTime != nil {
+		in, out := &in.NextSyncTime, &out.NextSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncDuration != nil {
+		in, out := &in.LastSyncDuration, &out.LastSyncDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingSourceSubject.
-func (in *DynamicRoleBindingSourceSubject) DeepCopy() *DynamicRoleBindingSourceSubject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACSuggestionStatus.
+func (in *RBACSuggestionStatus) DeepCopy() *RBACSuggestionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicRoleBindingSourceSubject)
+	out := new(RBACSuggestionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicRoleBindingSpec) DeepCopyInto(out *DynamicRoleBindingSpec) {
+func (in *RenderedClusterRoleT) DeepCopyInto(out *RenderedClusterRoleT) {
 	*out = *in
-	out.Synchronization = in.Synchronization
-	in.Source.DeepCopyInto(&out.Source)
-	in.Targets.DeepCopyInto(&out.Targets)
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingSpec.
-func (in *DynamicRoleBindingSpec) DeepCopy() *DynamicRoleBindingSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenderedClusterRoleT.
+func (in *RenderedClusterRoleT) DeepCopy() *RenderedClusterRoleT {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicRoleBindingSpec)
+	out := new(RenderedClusterRoleT)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicRoleBindingStatus) DeepCopyInto(out *DynamicRoleBindingStatus) {
+func (in *ScheduleT) DeepCopyInto(out *ScheduleT) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+	if in.ActiveFrom != nil {
+		in, out := &in.ActiveFrom, &out.ActiveFrom
+		*out = (*in).DeepCopy()
+	}
+	if in.ActiveTo != nil {
+		in, out := &in.ActiveTo, &out.ActiveTo
+		*out = (*in).DeepCopy()
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]ScheduleWindowT, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingStatus.
-func (in *DynamicRoleBindingStatus) DeepCopy() *DynamicRoleBindingStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleT.
+func (in *ScheduleT) DeepCopy() *ScheduleT {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicRoleBindingStatus)
+	out := new(ScheduleT)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicRoleBindingTargets) DeepCopyInto(out *DynamicRoleBindingTargets) {
+func (in *ScheduleWindowT) DeepCopyInto(out *ScheduleWindowT) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]ScheduleDayT, len(*in))
+		copy(*out, *in)
 	}
-	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicRoleBindingTargets.
-func (in *DynamicRoleBindingTargets) DeepCopy() *DynamicRoleBindingTargets {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindowT.
+func (in *ScheduleWindowT) DeepCopy() *ScheduleWindowT {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicRoleBindingTargets)
+	out := new(ScheduleWindowT)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MatchRegexT) DeepCopyInto(out *MatchRegexT) {
+func (in *SelectionDecisionT) DeepCopyInto(out *SelectionDecisionT) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchRegexT.
-func (in *MatchRegexT) DeepCopy() *MatchRegexT {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectionDecisionT.
+func (in *SelectionDecisionT) DeepCopy() *SelectionDecisionT {
 	if in == nil {
 		return nil
 	}
-	out := new(MatchRegexT)
+	out := new(SelectionDecisionT)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetaSelectorT) DeepCopyInto(out *MetaSelectorT) {
+func (in *SubjectAccessT) DeepCopyInto(out *SubjectAccessT) {
 	*out = *in
-	if in.MatchLabels != nil {
-		in, out := &in.MatchLabels, &out.MatchLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	out.Subject = in.Subject
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterRules != nil {
+		in, out := &in.ClusterRules, &out.ClusterRules
+		*out = make([]v1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.MatchAnnotations != nil {
-		in, out := &in.MatchAnnotations, &out.MatchAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.NamespacedRules != nil {
+		in, out := &in.NamespacedRules, &out.NamespacedRules
+		*out = make([]NamespacedRulesT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaSelectorT.
-func (in *MetaSelectorT) DeepCopy() *MetaSelectorT {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubjectAccessT.
+func (in *SubjectAccessT) DeepCopy() *SubjectAccessT {
 	if in == nil {
 		return nil
 	}
-	out := new(MetaSelectorT)
+	out := new(SubjectAccessT)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NameSelectorT) DeepCopyInto(out *NameSelectorT) {
+func (in *SyncChangeT) DeepCopyInto(out *SyncChangeT) {
 	*out = *in
-	if in.MatchList != nil {
-		in, out := &in.MatchList, &out.MatchList
+	in.Time.DeepCopyInto(&out.Time)
+	if in.AddedRules != nil {
+		in, out := &in.AddedRules, &out.AddedRules
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	out.MatchRegex = in.MatchRegex
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameSelectorT.
-func (in *NameSelectorT) DeepCopy() *NameSelectorT {
-	if in == nil {
-		return nil
+	if in.RemovedRules != nil {
+		in, out := &in.RemovedRules, &out.RemovedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	out := new(NameSelectorT)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamespaceSelectorT) DeepCopyInto(out *NamespaceSelectorT) {
-	*out = *in
-	if in.MatchLabels != nil {
-		in, out := &in.MatchLabels, &out.MatchLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.AddedSubjects != nil {
+		in, out := &in.AddedSubjects, &out.AddedSubjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.MatchList != nil {
-		in, out := &in.MatchList, &out.MatchList
+	if in.RemovedSubjects != nil {
+		in, out := &in.RemovedSubjects, &out.RemovedSubjects
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	out.MatchRegex = in.MatchRegex
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelectorT.
-func (in *NamespaceSelectorT) DeepCopy() *NamespaceSelectorT {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncChangeT.
+func (in *SyncChangeT) DeepCopy() *SyncChangeT {
 	if in == nil {
 		return nil
 	}
-	out := new(NamespaceSelectorT)
+	out := new(SyncChangeT)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -426,6 +1685,11 @@ func (in *TargetT) DeepCopyInto(out *TargetT) {
 			(*out)[key] = val
 		}
 	}
+	if in.ExportConfigMap != nil {
+		in, out := &in.ExportConfigMap, &out.ExportConfigMap
+		*out = new(ExportConfigMapT)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetT.
@@ -437,3 +1701,43 @@ func (in *TargetT) DeepCopy() *TargetT {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesReferenceT) DeepCopyInto(out *ValuesReferenceT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesReferenceT.
+func (in *ValuesReferenceT) DeepCopy() *ValuesReferenceT {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesReferenceT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesSourceT) DeepCopyInto(out *ValuesSourceT) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ValuesReferenceT)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(ValuesReferenceT)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesSourceT.
+func (in *ValuesSourceT) DeepCopy() *ValuesSourceT {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesSourceT)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// convertSelectorToHub converts a selector into the namespaceSelector of the hub version
+func convertSelectorToHub(src SelectorT) v1alpha1.NamespaceSelectorT {
+	return v1alpha1.NamespaceSelectorT{
+		MatchLabels:           src.MatchLabels,
+		MatchAnnotations:      src.MatchAnnotations,
+		MatchAnnotationsRegex: src.MatchAnnotationsRegex,
+		MatchList:             src.MatchList,
+		MatchRegex:            v1alpha1.MatchRegexT(src.MatchRegex),
+		MatchExpressions:      src.MatchExpressions,
+	}
+}
+
+// convertSelectorFromHub converts a namespaceSelector of the hub version into a selector
+func convertSelectorFromHub(src v1alpha1.NamespaceSelectorT) SelectorT {
+	return SelectorT{
+		MatchList:             src.MatchList,
+		MatchRegex:            MatchRegexT(src.MatchRegex),
+		MatchLabels:           src.MatchLabels,
+		MatchAnnotations:      src.MatchAnnotations,
+		MatchAnnotationsRegex: src.MatchAnnotationsRegex,
+		MatchExpressions:      src.MatchExpressions,
+	}
+}
+
+// convertSyncChangeToHub converts a summary of changes into the one of the hub version
+func convertSyncChangeToHub(src *SyncChangeT) *v1alpha1.SyncChangeT {
+	if src == nil {
+		return nil
+	}
+	dst := v1alpha1.SyncChangeT(*src)
+	return &dst
+}
+
+// convertSyncChangeFromHub converts a summary of changes of the hub version
+func convertSyncChangeFromHub(src *v1alpha1.SyncChangeT) *SyncChangeT {
+	if src == nil {
+		return nil
+	}
+	dst := SyncChangeT(*src)
+	return &dst
+}
+
+// convertMemberClustersToHub converts the status of the member clusters into the one of the hub version
+func convertMemberClustersToHub(src []MemberClusterStatusT) (dst []v1alpha1.MemberClusterStatusT) {
+	for _, memberCluster := range src {
+		dst = append(dst, v1alpha1.MemberClusterStatusT(memberCluster))
+	}
+	return dst
+}
+
+// convertMemberClustersFromHub converts the status of the member clusters of the hub version
+func convertMemberClustersFromHub(src []v1alpha1.MemberClusterStatusT) (dst []MemberClusterStatusT) {
+	for _, memberCluster := range src {
+		dst = append(dst, MemberClusterStatusT(memberCluster))
+	}
+	return dst
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SynchronizationT defines the spec of the synchronization section of the resources
+type SynchronizationT struct {
+
+	// Time between synchronizations, as a Go duration such as '30s' or '5m'.
+	// When not set, the default time of the controller is used
+	Time string `json:"time,omitempty"`
+}
+
+// MatchRegexT selects objects whose name matches a regular expression, or does not match it when negative.
+// The expression is checked on admission, as matching any string against it fails when it does not compile
+// +kubebuilder:validation:XValidation:rule="!has(self.expression) || '-'.matches(self.expression) == '-'.matches(self.expression)",message="expression must be a valid regular expression"
+type MatchRegexT struct {
+	Negative   bool   `json:"negative,omitempty"`
+	Expression string `json:"expression,omitempty"`
+
+	// RequireFullMatch anchors the expression to the whole name, so 'dev' does not match 'devops-prod'.
+	// Otherwise, the expression matches any part of the name
+	RequireFullMatch bool `json:"requireFullMatch,omitempty"`
+}
+
+// SelectorT selects objects by name or by metadata. Only one of its fields can be set,
+// except matchExpressions, which can be combined with matchLabels, and matchAnnotationsRegex,
+// which can be combined with matchAnnotations
+type SelectorT struct {
+	MatchList        []string          `json:"matchList,omitempty"`
+	MatchRegex       MatchRegexT       `json:"matchRegex,omitempty"`
+	MatchLabels      map[string]string `json:"matchLabels,omitempty"`
+	MatchAnnotations map[string]string `json:"matchAnnotations,omitempty"`
+
+	// MatchAnnotationsRegex selects by annotations whose values match a regular expression, keyed by annotation.
+	// It can be combined with matchAnnotations, and both of them must match
+	MatchAnnotationsRegex map[string]string `json:"matchAnnotationsRegex,omitempty"`
+
+	// MatchExpressions selects by labels using LabelSelector operators: In, NotIn, Exists and DoesNotExist.
+	// It can be combined with matchLabels, and both of them must match
+	MatchExpressions []metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// SyncChangeT summarizes the changes applied to the generated resources on a synchronization.
+// Lists are truncated to keep the status small, but counts are always complete
+type SyncChangeT struct {
+	Time metav1.Time `json:"time"`
+
+	AddedRulesCount   int      `json:"addedRulesCount,omitempty"`
+	RemovedRulesCount int      `json:"removedRulesCount,omitempty"`
+	AddedRules        []string `json:"addedRules,omitempty"`
+	RemovedRules      []string `json:"removedRules,omitempty"`
+
+	AddedSubjectsCount   int      `json:"addedSubjectsCount,omitempty"`
+	RemovedSubjectsCount int      `json:"removedSubjectsCount,omitempty"`
+	AddedSubjects        []string `json:"addedSubjects,omitempty"`
+	RemovedSubjects      []string `json:"removedSubjects,omitempty"`
+}
+
+// MemberClusterStatusT is the result of propagating the generated resources onto a member cluster of the inventory
+type MemberClusterStatusT struct {
+	Name   string `json:"name"`
+	Synced bool   `json:"synced"`
+
+	// Message carries the error of the last propagation when it failed
+	Message string `json:"message,omitempty"`
+
+	// LastSyncTime is the time of the last successful propagation onto the member cluster
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
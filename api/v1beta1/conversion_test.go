@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// newConversionFuzzer returns a fuzzer filling every field of the hub objects.
+// Times are truncated to seconds, as it is the precision kept by the API
+func newConversionFuzzer() *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(GinkgoRandomSeed()).NilChance(0.2).NumElements(0, 3).Funcs(
+		func(t *metav1.Time, c fuzz.Continue) {
+			*t = metav1.Unix(c.Int63n(1<<32), 0)
+		},
+		func(d *metav1.Duration, c fuzz.Continue) {
+			d.Duration = time.Duration(c.Int63n(1<<32)) * time.Second
+		},
+	)
+}
+
+// expectRoundTrip converts a hub object into the spoke version and back, expecting to get the same object.
+// Kinds are set by the scheme instead of the conversions, and empty lists and maps are the same as missing ones
+func expectRoundTrip(hub conversion.Hub, spoke conversion.Convertible, roundTripped conversion.Hub) {
+	Expect(spoke.ConvertFrom(hub)).To(Succeed())
+	Expect(spoke.ConvertTo(roundTripped)).To(Succeed())
+
+	Expect(cmp.Diff(hub, roundTripped, cmpopts.EquateEmpty(), cmpopts.IgnoreTypes(metav1.TypeMeta{}))).To(BeEmpty(),
+		"fields lost converting through v1beta1 (-hub +round-tripped)")
+}
+
+var _ = Describe("Conversion between versions", func() {
+	fuzzer := newConversionFuzzer()
+
+	Context("When converting a DynamicClusterRole from the hub and back", func() {
+		It("should keep every field", func() {
+			for range 200 {
+				hub := &v1alpha1.DynamicClusterRole{}
+				fuzzer.Fuzz(hub)
+
+				// Targets are merged into a single list, so only a named 'target' is kept apart from 'targets'
+				for hub.Spec.Target.Name == "" && len(hub.Spec.Targets) > 0 {
+					hub.Spec.Target, hub.Spec.Targets = hub.Spec.Targets[0], hub.Spec.Targets[1:]
+				}
+				if hub.Spec.Target.Name == "" {
+					hub.Spec.Target = v1alpha1.TargetT{}
+				}
+
+				expectRoundTrip(hub, &DynamicClusterRole{}, &v1alpha1.DynamicClusterRole{})
+			}
+		})
+	})
+
+	Context("When converting a DynamicRoleBinding from the hub and back", func() {
+		It("should keep every field", func() {
+			for range 200 {
+				hub := &v1alpha1.DynamicRoleBinding{}
+				fuzzer.Fuzz(hub)
+
+				expectRoundTrip(hub, &DynamicRoleBinding{}, &v1alpha1.DynamicRoleBinding{})
+			}
+		})
+	})
+
+	Context("When converting a DynamicServiceAccount from the hub and back", func() {
+		It("should keep every field", func() {
+			for range 200 {
+				hub := &v1alpha1.DynamicServiceAccount{}
+				fuzzer.Fuzz(hub)
+
+				expectRoundTrip(hub, &DynamicServiceAccount{}, &v1alpha1.DynamicServiceAccount{})
+			}
+		})
+	})
+})
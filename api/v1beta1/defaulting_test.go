@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// defaultThroughHub defaults a spoke object the way the API server does with matchPolicy 'Equivalent':
+// it is converted to the hub, defaulted there, and converted back
+func defaultThroughHub(spoke conversion.Convertible, hub conversion.Hub) {
+	defaulter := &v1alpha1.Defaulter{SyncTime: 5 * time.Minute}
+
+	Expect(spoke.ConvertTo(hub)).To(Succeed())
+	Expect(defaulter.Default(context.Background(), hub)).To(Succeed())
+	Expect(spoke.ConvertFrom(hub)).To(Succeed())
+}
+
+var _ = Describe("Defaulting through the hub", func() {
+
+	It("should default a DynamicClusterRole", func() {
+		resource := &DynamicClusterRole{Spec: DynamicClusterRoleSpec{
+			Targets: []TargetT{{Name: "developers"}},
+			Allow:   []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"Get", " LIST "}}},
+		}}
+		defaultThroughHub(resource, &v1alpha1.DynamicClusterRole{})
+
+		Expect(resource.Spec.Allow[0].Verbs).To(Equal([]string{"get", "list"}))
+		Expect(resource.Spec.Synchronization.Time).To(Equal("5m0s"))
+		Expect(resource.Spec.Targets).To(Equal([]TargetT{{Name: "developers"}}))
+	})
+
+	It("should default a DynamicRoleBinding", func() {
+		resource := &DynamicRoleBinding{Spec: DynamicRoleBindingSpec{
+			Synchronization: SynchronizationT{Time: "30s"},
+			Source: SourceT{
+				ClusterRole:    "view",
+				Subject:        &SubjectT{Kind: rbacv1.GroupKind},
+				StaticSubjects: []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, APIGroup: rbacv1.GroupName, Name: "deployer"}},
+			},
+			Target: RoleBindingTargetT{Name: "developers"},
+		}}
+		defaultThroughHub(resource, &v1alpha1.DynamicRoleBinding{})
+
+		Expect(resource.Spec.Source.Subject.APIGroup).To(Equal(rbacv1.GroupName))
+		Expect(resource.Spec.Source.StaticSubjects[0].APIGroup).To(BeEmpty())
+		Expect(resource.Spec.Synchronization.Time).To(Equal("30s"))
+	})
+
+	It("should default a DynamicServiceAccount", func() {
+		resource := &DynamicServiceAccount{}
+		defaultThroughHub(resource, &v1alpha1.DynamicServiceAccount{})
+
+		Expect(resource.Spec.Synchronization.Time).To(Equal("5m0s"))
+	})
+})
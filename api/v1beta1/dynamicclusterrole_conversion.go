@@ -0,0 +1,213 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// ConvertTo converts this DynamicClusterRole to the hub version (v1alpha1).
+// The first target is kept as 'target' and the rest of them as 'targets'
+func (src *DynamicClusterRole) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.DynamicClusterRole)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Synchronization = v1alpha1.SynchronizationT(src.Spec.Synchronization)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+	dst.Spec.Allow = src.Spec.Allow
+
+	dst.Spec.Target = v1alpha1.TargetT{}
+	dst.Spec.Targets = nil
+	for index, target := range src.Spec.Targets {
+		if index == 0 {
+			dst.Spec.Target = convertTargetToHub(target)
+			continue
+		}
+		dst.Spec.Targets = append(dst.Spec.Targets, convertTargetToHub(target))
+	}
+
+	dst.Spec.Deny = nil
+	for _, rule := range src.Spec.Deny {
+		dst.Spec.Deny = append(dst.Spec.Deny, v1alpha1.DenyPolicyRuleT(rule))
+	}
+	dst.Spec.DefaultDeniedVerbs = src.Spec.DefaultDeniedVerbs
+
+	dst.Spec.From = nil
+	for _, source := range src.Spec.From {
+		dst.Spec.From = append(dst.Spec.From, v1alpha1.ClusterRoleSourceT(source))
+	}
+
+	dst.Spec.ValuesFrom = nil
+	for _, source := range src.Spec.ValuesFrom {
+		dst.Spec.ValuesFrom = append(dst.Spec.ValuesFrom, v1alpha1.ValuesSourceT{
+			ConfigMapRef: (*v1alpha1.ValuesReferenceT)(source.ConfigMapRef),
+			SecretRef:    (*v1alpha1.ValuesReferenceT)(source.SecretRef),
+		})
+	}
+	dst.Spec.Explain = src.Spec.Explain
+	dst.Spec.NamespaceSelector = src.Spec.NamespaceSelector
+	dst.Spec.AllowImpersonate = convertImpersonateToHub(src.Spec.AllowImpersonate)
+
+	// Status
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.GeneratedClusterRoles = src.Status.GeneratedClusterRoles
+	dst.Status.RulesCount = src.Status.RulesCount
+	dst.Status.LastSyncTime = src.Status.LastSyncTime
+	dst.Status.NextSyncTime = src.Status.NextSyncTime
+	dst.Status.LastSyncDuration = src.Status.LastSyncDuration
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.LastChange = convertSyncChangeToHub(src.Status.LastChange)
+	dst.Status.MemberClusters = convertMemberClustersToHub(src.Status.MemberClusters)
+
+	dst.Status.RenderedClusterRoles = nil
+	for _, clusterRole := range src.Status.RenderedClusterRoles {
+		dst.Status.RenderedClusterRoles = append(dst.Status.RenderedClusterRoles, v1alpha1.RenderedClusterRoleT(clusterRole))
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+// Both 'target' and 'targets' are merged into 'targets', ignoring an unnamed 'target'
+func (dst *DynamicClusterRole) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.DynamicClusterRole)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Synchronization = SynchronizationT(src.Spec.Synchronization)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+	dst.Spec.Allow = src.Spec.Allow
+
+	dst.Spec.Targets = nil
+	if src.Spec.Target.Name != "" {
+		dst.Spec.Targets = append(dst.Spec.Targets, convertTargetFromHub(src.Spec.Target))
+	}
+	for _, target := range src.Spec.Targets {
+		dst.Spec.Targets = append(dst.Spec.Targets, convertTargetFromHub(target))
+	}
+
+	dst.Spec.Deny = nil
+	for _, rule := range src.Spec.Deny {
+		dst.Spec.Deny = append(dst.Spec.Deny, DenyPolicyRuleT(rule))
+	}
+	dst.Spec.DefaultDeniedVerbs = src.Spec.DefaultDeniedVerbs
+
+	dst.Spec.From = nil
+	for _, source := range src.Spec.From {
+		dst.Spec.From = append(dst.Spec.From, ClusterRoleSourceT(source))
+	}
+
+	dst.Spec.ValuesFrom = nil
+	for _, source := range src.Spec.ValuesFrom {
+		dst.Spec.ValuesFrom = append(dst.Spec.ValuesFrom, ValuesSourceT{
+			ConfigMapRef: (*ValuesReferenceT)(source.ConfigMapRef),
+			SecretRef:    (*ValuesReferenceT)(source.SecretRef),
+		})
+	}
+	dst.Spec.Explain = src.Spec.Explain
+	dst.Spec.NamespaceSelector = src.Spec.NamespaceSelector
+	dst.Spec.AllowImpersonate = convertImpersonateFromHub(src.Spec.AllowImpersonate)
+
+	// Status
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.GeneratedClusterRoles = src.Status.GeneratedClusterRoles
+	dst.Status.RulesCount = src.Status.RulesCount
+	dst.Status.LastSyncTime = src.Status.LastSyncTime
+	dst.Status.NextSyncTime = src.Status.NextSyncTime
+	dst.Status.LastSyncDuration = src.Status.LastSyncDuration
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.LastChange = convertSyncChangeFromHub(src.Status.LastChange)
+	dst.Status.MemberClusters = convertMemberClustersFromHub(src.Status.MemberClusters)
+
+	dst.Status.RenderedClusterRoles = nil
+	for _, clusterRole := range src.Status.RenderedClusterRoles {
+		dst.Status.RenderedClusterRoles = append(dst.Status.RenderedClusterRoles, RenderedClusterRoleT(clusterRole))
+	}
+
+	return nil
+}
+
+// convertTargetToHub converts a target into the one of the hub version
+func convertTargetToHub(src TargetT) v1alpha1.TargetT {
+	return v1alpha1.TargetT{
+		Name:                   src.Name,
+		Annotations:            src.Annotations,
+		Labels:                 src.Labels,
+		SeparateScopes:         src.SeparateScopes,
+		DryRun:                 src.DryRun,
+		EmitAdmissionPolicy:    src.EmitAdmissionPolicy,
+		AdmissionFailurePolicy: src.AdmissionFailurePolicy,
+		CompactRules:           src.CompactRules,
+		MaxRulesPerClusterRole: src.MaxRulesPerClusterRole,
+		ExportConfigMap:        (*v1alpha1.ExportConfigMapT)(src.ExportConfigMap),
+	}
+}
+
+// convertTargetFromHub converts a target of the hub version
+func convertTargetFromHub(src v1alpha1.TargetT) TargetT {
+	return TargetT{
+		Name:                   src.Name,
+		Annotations:            src.Annotations,
+		Labels:                 src.Labels,
+		SeparateScopes:         src.SeparateScopes,
+		DryRun:                 src.DryRun,
+		EmitAdmissionPolicy:    src.EmitAdmissionPolicy,
+		AdmissionFailurePolicy: src.AdmissionFailurePolicy,
+		CompactRules:           src.CompactRules,
+		MaxRulesPerClusterRole: src.MaxRulesPerClusterRole,
+		ExportConfigMap:        (*ExportConfigMapT)(src.ExportConfigMap),
+	}
+}
+
+// convertImpersonateToHub converts the impersonated identities into the ones of the hub version
+func convertImpersonateToHub(src *ImpersonateT) *v1alpha1.ImpersonateT {
+	if src == nil {
+		return nil
+	}
+
+	dst := &v1alpha1.ImpersonateT{
+		Users:  src.Users,
+		Groups: src.Groups,
+	}
+	for _, serviceAccount := range src.ServiceAccounts {
+		dst.ServiceAccounts = append(dst.ServiceAccounts, v1alpha1.ImpersonateServiceAccountT(serviceAccount))
+	}
+
+	return dst
+}
+
+// convertImpersonateFromHub converts the impersonated identities of the hub version
+func convertImpersonateFromHub(src *v1alpha1.ImpersonateT) *ImpersonateT {
+	if src == nil {
+		return nil
+	}
+
+	dst := &ImpersonateT{
+		Users:  src.Users,
+		Groups: src.Groups,
+	}
+	for _, serviceAccount := range src.ServiceAccounts {
+		dst.ServiceAccounts = append(dst.ServiceAccounts, ImpersonateServiceAccountT(serviceAccount))
+	}
+
+	return dst
+}
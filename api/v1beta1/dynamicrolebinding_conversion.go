@@ -0,0 +1,263 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// ConvertTo converts this DynamicRoleBinding to the hub version (v1alpha1).
+// The subject selector is split into the 'nameSelector' and 'metaSelector' of the hub
+func (src *DynamicRoleBinding) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.DynamicRoleBinding)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Synchronization = v1alpha1.SynchronizationT(src.Spec.Synchronization)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+
+	dst.Spec.Source = v1alpha1.DynamicRoleBindingSource{
+		ClusterRole:               src.Spec.Source.ClusterRole,
+		Role:                      src.Spec.Source.Role,
+		DynamicClusterRole:        src.Spec.Source.DynamicClusterRole,
+		ClusterRoles:              src.Spec.Source.ClusterRoles,
+		ClusterRoleSelector:       src.Spec.Source.ClusterRoleSelector,
+		ClusterRoleSelectorPolicy: src.Spec.Source.ClusterRoleSelectorPolicy,
+		WaitForRole:               src.Spec.Source.WaitForRole,
+		Subject:                   convertSubjectToHub(src.Spec.Source.Subject),
+		StaticSubjects:            src.Spec.Source.StaticSubjects,
+	}
+
+	dst.Spec.Targets = v1alpha1.DynamicRoleBindingTargets{
+		Name:              src.Spec.Target.Name,
+		Annotations:       src.Spec.Target.Annotations,
+		Labels:            src.Spec.Target.Labels,
+		ClusterScoped:     src.Spec.Target.ClusterScoped,
+		Mode:              src.Spec.Target.Mode,
+		DryRun:            src.Spec.Target.DryRun,
+		NamespaceSelector: convertSelectorToHub(src.Spec.Target.NamespaceSelector),
+
+		NamespaceSelectorClassName: src.Spec.Target.NamespaceSelectorClassName,
+		ExcludeSystemNamespaces:    src.Spec.Target.ExcludeSystemNamespaces,
+		ExpiresAfter:               src.Spec.Target.ExpiresAfter,
+		Bootstrap:                  v1alpha1.BootstrapT(src.Spec.Target.Bootstrap),
+		RoleNameTemplate:           src.Spec.Target.RoleNameTemplate,
+		MaxSubjectsPerBinding:      src.Spec.Target.MaxSubjectsPerBinding,
+		OnlyWhereSubjectsExist:     src.Spec.Target.OnlyWhereSubjectsExist,
+	}
+
+	dst.Spec.Schedule = convertScheduleToHub(src.Spec.Schedule)
+
+	// Status
+	dst.Status = v1alpha1.DynamicRoleBindingStatus{
+		Conditions:             src.Status.Conditions,
+		RenderedSubjects:       src.Status.RenderedSubjects,
+		RenderedNamespaces:     src.Status.RenderedNamespaces,
+		SelectionExplanation:   convertSelectionExplanationToHub(src.Status.SelectionExplanation),
+		GeneratedBindings:      src.Status.GeneratedBindings,
+		SubjectsCount:          src.Status.SubjectsCount,
+		TargetNamespacesCount:  src.Status.TargetNamespacesCount,
+		GeneratedBindingsCount: src.Status.GeneratedBindingsCount,
+		ObservedGeneration:     src.Status.ObservedGeneration,
+		BindingsCreationTime:   src.Status.BindingsCreationTime,
+		ExpirationTime:         src.Status.ExpirationTime,
+		NextScheduleChangeTime: src.Status.NextScheduleChangeTime,
+		LastSyncTime:           src.Status.LastSyncTime,
+		NextSyncTime:           src.Status.NextSyncTime,
+		LastSyncDuration:       src.Status.LastSyncDuration,
+		LastChange:             convertSyncChangeToHub(src.Status.LastChange),
+		MemberClusters:         convertMemberClustersToHub(src.Status.MemberClusters),
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+// The 'nameSelector' and 'metaSelector' of the hub are merged into the subject selector
+func (dst *DynamicRoleBinding) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.DynamicRoleBinding)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Synchronization = SynchronizationT(src.Spec.Synchronization)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+
+	dst.Spec.Source = SourceT{
+		ClusterRole:               src.Spec.Source.ClusterRole,
+		Role:                      src.Spec.Source.Role,
+		DynamicClusterRole:        src.Spec.Source.DynamicClusterRole,
+		ClusterRoles:              src.Spec.Source.ClusterRoles,
+		ClusterRoleSelector:       src.Spec.Source.ClusterRoleSelector,
+		ClusterRoleSelectorPolicy: src.Spec.Source.ClusterRoleSelectorPolicy,
+		WaitForRole:               src.Spec.Source.WaitForRole,
+		Subject:                   convertSubjectFromHub(src.Spec.Source.Subject),
+		StaticSubjects:            src.Spec.Source.StaticSubjects,
+	}
+
+	dst.Spec.Target = RoleBindingTargetT{
+		Name:              src.Spec.Targets.Name,
+		Annotations:       src.Spec.Targets.Annotations,
+		Labels:            src.Spec.Targets.Labels,
+		ClusterScoped:     src.Spec.Targets.ClusterScoped,
+		Mode:              src.Spec.Targets.Mode,
+		DryRun:            src.Spec.Targets.DryRun,
+		NamespaceSelector: convertSelectorFromHub(src.Spec.Targets.NamespaceSelector),
+
+		NamespaceSelectorClassName: src.Spec.Targets.NamespaceSelectorClassName,
+		ExcludeSystemNamespaces:    src.Spec.Targets.ExcludeSystemNamespaces,
+		ExpiresAfter:               src.Spec.Targets.ExpiresAfter,
+		Bootstrap:                  BootstrapT(src.Spec.Targets.Bootstrap),
+		RoleNameTemplate:           src.Spec.Targets.RoleNameTemplate,
+		MaxSubjectsPerBinding:      src.Spec.Targets.MaxSubjectsPerBinding,
+		OnlyWhereSubjectsExist:     src.Spec.Targets.OnlyWhereSubjectsExist,
+	}
+
+	dst.Spec.Schedule = convertScheduleFromHub(src.Spec.Schedule)
+
+	// Status
+	dst.Status = DynamicRoleBindingStatus{
+		Conditions:             src.Status.Conditions,
+		RenderedSubjects:       src.Status.RenderedSubjects,
+		RenderedNamespaces:     src.Status.RenderedNamespaces,
+		SelectionExplanation:   convertSelectionExplanationFromHub(src.Status.SelectionExplanation),
+		GeneratedBindings:      src.Status.GeneratedBindings,
+		SubjectsCount:          src.Status.SubjectsCount,
+		TargetNamespacesCount:  src.Status.TargetNamespacesCount,
+		GeneratedBindingsCount: src.Status.GeneratedBindingsCount,
+		ObservedGeneration:     src.Status.ObservedGeneration,
+		BindingsCreationTime:   src.Status.BindingsCreationTime,
+		ExpirationTime:         src.Status.ExpirationTime,
+		NextScheduleChangeTime: src.Status.NextScheduleChangeTime,
+		LastSyncTime:           src.Status.LastSyncTime,
+		NextSyncTime:           src.Status.NextSyncTime,
+		LastSyncDuration:       src.Status.LastSyncDuration,
+		LastChange:             convertSyncChangeFromHub(src.Status.LastChange),
+		MemberClusters:         convertMemberClustersFromHub(src.Status.MemberClusters),
+	}
+
+	return nil
+}
+
+// convertSelectionExplanationToHub converts the decisions of a selection explanation into the ones of the hub version
+func convertSelectionExplanationToHub(src []SelectionDecisionT) (dst []v1alpha1.SelectionDecisionT) {
+	for _, decision := range src {
+		dst = append(dst, v1alpha1.SelectionDecisionT(decision))
+	}
+	return dst
+}
+
+// convertSelectionExplanationFromHub converts the decisions of a selection explanation of the hub version
+func convertSelectionExplanationFromHub(src []v1alpha1.SelectionDecisionT) (dst []SelectionDecisionT) {
+	for _, decision := range src {
+		dst = append(dst, SelectionDecisionT(decision))
+	}
+	return dst
+}
+
+// convertScheduleToHub converts a schedule into the one of the hub version
+func convertScheduleToHub(src *ScheduleT) *v1alpha1.ScheduleT {
+	if src == nil {
+		return nil
+	}
+
+	dst := &v1alpha1.ScheduleT{
+		ActiveFrom: src.ActiveFrom,
+		ActiveTo:   src.ActiveTo,
+		TimeZone:   src.TimeZone,
+	}
+	for _, window := range src.Windows {
+		dstWindow := v1alpha1.ScheduleWindowT{Start: window.Start, End: window.End}
+		for _, day := range window.Days {
+			dstWindow.Days = append(dstWindow.Days, v1alpha1.ScheduleDayT(day))
+		}
+		dst.Windows = append(dst.Windows, dstWindow)
+	}
+	return dst
+}
+
+// convertScheduleFromHub converts a schedule of the hub version
+func convertScheduleFromHub(src *v1alpha1.ScheduleT) *ScheduleT {
+	if src == nil {
+		return nil
+	}
+
+	dst := &ScheduleT{
+		ActiveFrom: src.ActiveFrom,
+		ActiveTo:   src.ActiveTo,
+		TimeZone:   src.TimeZone,
+	}
+	for _, window := range src.Windows {
+		dstWindow := ScheduleWindowT{Start: window.Start, End: window.End}
+		for _, day := range window.Days {
+			dstWindow.Days = append(dstWindow.Days, ScheduleDayT(day))
+		}
+		dst.Windows = append(dst.Windows, dstWindow)
+	}
+	return dst
+}
+
+// convertSubjectToHub converts the subject selected by a source into the one of the hub version
+func convertSubjectToHub(src *SubjectT) *v1alpha1.DynamicRoleBindingSourceSubject {
+	if src == nil {
+		return nil
+	}
+
+	return &v1alpha1.DynamicRoleBindingSourceSubject{
+		ApiGroup: src.APIGroup,
+		Kind:     src.Kind,
+		NameSelector: v1alpha1.NameSelectorT{
+			MatchList:  src.Selector.MatchList,
+			MatchRegex: v1alpha1.MatchRegexT(src.Selector.MatchRegex),
+		},
+		MetaSelector: v1alpha1.MetaSelectorT{
+			MatchLabels:           src.Selector.MatchLabels,
+			MatchAnnotations:      src.Selector.MatchAnnotations,
+			MatchAnnotationsRegex: src.Selector.MatchAnnotationsRegex,
+			MatchExpressions:      src.Selector.MatchExpressions,
+		},
+		NamespaceSelector: convertSelectorToHub(src.NamespaceSelector),
+		IdentityProvider:  src.IdentityProvider,
+		NamePrefix:        src.NamePrefix,
+	}
+}
+
+// convertSubjectFromHub converts the subject selected by a source of the hub version
+func convertSubjectFromHub(src *v1alpha1.DynamicRoleBindingSourceSubject) *SubjectT {
+	if src == nil {
+		return nil
+	}
+
+	return &SubjectT{
+		APIGroup: src.ApiGroup,
+		Kind:     src.Kind,
+		Selector: SelectorT{
+			MatchList:             src.NameSelector.MatchList,
+			MatchRegex:            MatchRegexT(src.NameSelector.MatchRegex),
+			MatchLabels:           src.MetaSelector.MatchLabels,
+			MatchAnnotations:      src.MetaSelector.MatchAnnotations,
+			MatchAnnotationsRegex: src.MetaSelector.MatchAnnotationsRegex,
+			MatchExpressions:      src.MetaSelector.MatchExpressions,
+		},
+		NamespaceSelector: convertSelectorFromHub(src.NamespaceSelector),
+		IdentityProvider:  src.IdentityProvider,
+		NamePrefix:        src.NamePrefix,
+	}
+}
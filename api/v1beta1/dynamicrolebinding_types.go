@@ -0,0 +1,310 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SubjectT defines the subjects to bind the role to.
+// Selector picks them by name or metadata, and NamespaceSelector narrows ServiceAccounts to some namespaces
+type SubjectT struct {
+	APIGroup string `json:"apiGroup"`
+
+	// Kind is one of 'ServiceAccount', 'User', 'Group' or 'NamespaceServiceAccounts'. The last one binds all
+	// the ServiceAccounts of each namespace selected by namespaceSelector, through the group 'system:serviceaccounts:<namespace>'
+	Kind string `json:"kind"`
+
+	Selector          SelectorT `json:"selector,omitempty"`
+	NamespaceSelector SelectorT `json:"namespaceSelector,omitempty"`
+
+	// IdentityProvider validates the names of User and Group subjects against the identity conventions of a managed
+	// Kubernetes provider: 'EKS' (IAM ARNs, and names not using the prefixes reserved by AWS), 'GKE' (emails of
+	// Google accounts and groups) or 'AKS' (object IDs of Microsoft Entra ID, or user principal names for users)
+	// +kubebuilder:validation:Enum=EKS;GKE;AKS
+	IdentityProvider string `json:"identityProvider,omitempty"`
+
+	// NamePrefix is prepended to the names of User and Group subjects, such as 'oidc:' for API servers configured
+	// with '--oidc-username-prefix' or '--oidc-groups-prefix'. Names already starting with it are kept as they are
+	NamePrefix string `json:"namePrefix,omitempty"`
+}
+
+// SourceT defines the role to bind and the subjects to bind it to.
+// Only one of ClusterRole, ClusterRoles, ClusterRoleSelector, Role or DynamicClusterRole can be set.
+// Role refers to a Role living in the same namespace as each generated RoleBinding, so it is not allowed
+// for cluster-scoped targets. DynamicClusterRole refers to a DynamicClusterRole in the same namespace
+// as the DynamicRoleBinding, and every ClusterRole generated by it is bound
+type SourceT struct {
+	ClusterRole        string `json:"clusterRole,omitempty"`
+	Role               string `json:"role,omitempty"`
+	DynamicClusterRole string `json:"dynamicClusterRole,omitempty"`
+
+	// ClusterRoles binds a bundle of ClusterRoles at once. There is one binding for each of them
+	// on each target, named '<targets.name>-<clusterRole name>'
+	ClusterRoles []string `json:"clusterRoles,omitempty"`
+
+	// ClusterRoleSelector binds every ClusterRole matching this label selector, the same way as ClusterRoles.
+	// Matching ClusterRoles are looked for on each synchronization
+	ClusterRoleSelector *metav1.LabelSelector `json:"clusterRoleSelector,omitempty"`
+
+	// ClusterRoleSelectorPolicy defines what is bound when several ClusterRoles match clusterRoleSelector:
+	// 'All' binds every one of them (default), 'Single' expects exactly one, and 'Newest' takes the most recently created.
+	// The last two generate a single binding named '<targets.name>', which is kept while no ClusterRole matches,
+	// so it survives the ClusterRole being renamed by other tools
+	// +kubebuilder:validation:Enum=All;Single;Newest
+	ClusterRoleSelectorPolicy string `json:"clusterRoleSelectorPolicy,omitempty"`
+
+	// WaitForRole prevents the bindings from being synced while the referenced ClusterRole does not exist.
+	// Otherwise, they are synced anyway. In both cases, the synchronization is retried until it appears
+	WaitForRole bool `json:"waitForRole,omitempty"`
+
+	// Subject selects the subjects to bind. It can be left out when staticSubjects are set
+	Subject *SubjectT `json:"subject,omitempty"`
+
+	// StaticSubjects are bound as they are, without checking they exist, so bindings can be ready
+	// for identities created later or living in other clusters. Their name and namespace are Golang templates
+	// rendered with the target namespace (empty for ClusterRoleBindings) and the owner
+	StaticSubjects []rbacv1.Subject `json:"staticSubjects,omitempty"`
+}
+
+// BootstrapT defines how freshly created namespaces are prepared, so they are usable instantly
+type BootstrapT struct {
+	// Enabled synchronizes the bindings as soon as a namespace matching the selector is created,
+	// instead of waiting for the next synchronization
+	Enabled bool `json:"enabled,omitempty"`
+
+	// CreateServiceAccounts creates the static ServiceAccount subjects living in the targeted namespaces when they
+	// are missing. They are never deleted, as workloads may be using them
+	CreateServiceAccounts bool `json:"createServiceAccounts,omitempty"`
+}
+
+// RoleBindingTargetT defines the bindings generated by a DynamicRoleBinding
+type RoleBindingTargetT struct {
+	Name          string            `json:"name"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	ClusterScoped bool              `json:"clusterScoped,omitempty"`
+
+	// Mode defines the kind of bindings generated: 'ClusterScoped', 'Namespaced' or 'Both'.
+	// When not set, it is 'ClusterScoped' or 'Namespaced' depending on clusterScoped
+	// +kubebuilder:validation:Enum=ClusterScoped;Namespaced;Both
+	Mode string `json:"mode,omitempty"`
+
+	// DryRun renders the subjects and namespaces into the status, but never creates or updates the bindings
+	DryRun bool `json:"dryRun,omitempty"`
+
+	NamespaceSelector SelectorT `json:"namespaceSelector,omitempty"`
+
+	// NamespaceSelectorClassName selects the namespaces using the selector of a NamespaceSelectorClass,
+	// so the same definition is shared between resources. It is not allowed along with namespaceSelector
+	NamespaceSelectorClassName string `json:"namespaceSelectorClassName,omitempty"`
+
+	// ExcludeSystemNamespaces skips kube-system, kube-public and kube-node-lease when selecting target namespaces.
+	// When not set, the default of the controller is used, which excludes them
+	ExcludeSystemNamespaces *bool `json:"excludeSystemNamespaces,omitempty"`
+
+	// ExpiresAfter deletes the generated bindings once this duration passes since they were created, e.g. '8h'.
+	// Useful to grant temporary access, like breakglass procedures, without manual cleanup
+	ExpiresAfter string `json:"expiresAfter,omitempty"`
+
+	// Bootstrap prepares the namespaces matching the selector as soon as they are created
+	Bootstrap BootstrapT `json:"bootstrap,omitempty"`
+
+	// RoleNameTemplate binds, in each targeted namespace, the Role with the name rendered from this Golang template,
+	// instead of the roles of the source. It is rendered with the target namespace and the owner, e.g. 'tenant-admin'
+	// or '{{ .Namespace.Labels.tenant }}-admin', so Roles provisioned per namespace by other tools can be bound.
+	// It is not allowed for cluster-scoped targets
+	RoleNameTemplate string `json:"roleNameTemplate,omitempty"`
+
+	// MaxSubjectsPerBinding shards the subjects into several bindings, named '<name>-<index>', with at most
+	// this number of subjects each. Subjects keep their binding between synchronizations. Disabled when zero
+	// +kubebuilder:validation:Minimum=0
+	MaxSubjectsPerBinding int `json:"maxSubjectsPerBinding,omitempty"`
+
+	// OnlyWhereSubjectsExist creates the RoleBindings only in the target namespaces containing some of the ServiceAccounts
+	// selected by source.subject, instead of binding them on every target namespace. Only allowed for ServiceAccount subjects
+	OnlyWhereSubjectsExist bool `json:"onlyWhereSubjectsExist,omitempty"`
+}
+
+// ScheduleT defines when the bindings of a DynamicRoleBinding exist. Outside of it, they are deleted,
+// and they are created again once it starts. Both the absolute period and the windows must allow the access
+type ScheduleT struct {
+	// ActiveFrom and ActiveTo limit the access to an absolute period. Any of them can be omitted
+	ActiveFrom *metav1.Time `json:"activeFrom,omitempty"`
+	ActiveTo   *metav1.Time `json:"activeTo,omitempty"`
+
+	// Windows limit the access to recurring periods of the week, such as business hours.
+	// When set, bindings only exist while some of them is open
+	Windows []ScheduleWindowT `json:"windows,omitempty"`
+
+	// TimeZone is the IANA time zone the windows are evaluated in, e.g. 'Europe/Madrid'. Defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ScheduleWindowT is a recurring period of the week, e.g. from '09:00' to '18:00' on weekdays.
+// Windows ending before they start span midnight, so overnight on-call shifts can be expressed
+type ScheduleWindowT struct {
+	// Days of the week the window opens on. When empty, it opens every day
+	Days []ScheduleDayT `json:"days,omitempty"`
+
+	// Start and End are the times of the day when the window opens and closes, as 'HH:MM'
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// ScheduleDayT is a day of the week
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type ScheduleDayT string
+
+// DynamicRoleBindingSpec defines the desired state of DynamicRoleBinding
+type DynamicRoleBindingSpec struct {
+
+	// SynchronizationSpec defines the behavior of synchronization
+	Synchronization SynchronizationT `json:"synchronization,omitempty"`
+
+	// DeletionPolicy defines what happens to the generated resources when this one is deleted:
+	// 'Delete' removes them, while 'Orphan' keeps them in the cluster untracked. Defaults to 'Delete'
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	//
+	Source SourceT            `json:"source"`
+	Target RoleBindingTargetT `json:"target"`
+
+	// Schedule limits the time when the bindings exist, e.g. to business hours or to an on-call shift.
+	// When not set, they always exist
+	Schedule *ScheduleT `json:"schedule,omitempty"`
+}
+
+// SelectionDecisionT explains why a namespace or a ServiceAccount was selected or not by a selector
+type SelectionDecisionT struct {
+	// Selector is the path of the selector taking the decision, e.g. 'targets.namespaceSelector'
+	Selector string `json:"selector"`
+
+	// Kind is the kind of the evaluated object: Namespace or ServiceAccount
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+
+	Selected bool `json:"selected"`
+
+	// Reason is the clause of the selector, or the rule of the controller, that matched or rejected the object
+	Reason string `json:"reason"`
+}
+
+// DynamicRoleBindingStatus defines the observed state of DynamicRoleBinding
+type DynamicRoleBindingStatus struct {
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions"`
+
+	// RenderedSubjects contains the subjects that would be bound when dry-run is enabled, or a preview is requested
+	RenderedSubjects []rbacv1.Subject `json:"renderedSubjects,omitempty"`
+
+	// RenderedNamespaces contains the namespaces where the RoleBindings would be created when dry-run is enabled,
+	// or a preview is requested
+	RenderedNamespaces []string `json:"renderedNamespaces,omitempty"`
+
+	// SelectionExplanation contains the decision taken for each evaluated namespace and ServiceAccount
+	// when an explanation is requested
+	SelectionExplanation []SelectionDecisionT `json:"selectionExplanation,omitempty"`
+
+	// GeneratedBindings contains the names of the bindings generated on the last synchronization.
+	// RoleBindings are expressed as 'namespace/name'
+	GeneratedBindings []string `json:"generatedBindings,omitempty"`
+
+	// SubjectsCount is the number of subjects bound on the last synchronization.
+	// It is always set, so selectors not matching anything are noticed at a glance
+	// +optional
+	SubjectsCount int `json:"subjectsCount"`
+
+	// TargetNamespacesCount is the number of namespaces targeted on the last synchronization
+	// +optional
+	TargetNamespacesCount int `json:"targetNamespacesCount"`
+
+	// GeneratedBindingsCount is the number of bindings generated on the last synchronization
+	// +optional
+	GeneratedBindingsCount int `json:"generatedBindingsCount"`
+
+	// ObservedGeneration is the generation of the spec synchronized on the last successful synchronization.
+	// When it changes, bindings generated for previous generations and not desired anymore are pruned
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BindingsCreationTime is the time when the bindings were created for the first time.
+	// Expiration is computed from it, so it is never updated afterwards
+	BindingsCreationTime *metav1.Time `json:"bindingsCreationTime,omitempty"`
+
+	// ExpirationTime is the time when the bindings expire, when targets set 'expiresAfter'
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
+	// NextScheduleChangeTime is the time when the schedule grants or revokes the access next, when it is set
+	NextScheduleChangeTime *metav1.Time `json:"nextScheduleChangeTime,omitempty"`
+
+	// LastSyncTime is the time of the last successful synchronization
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// NextSyncTime is the time when the next periodical synchronization is scheduled.
+	// It is not set while failures are retried with backoff, or while the resource waits for changes
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// LastSyncDuration is the time spent on the last synchronization, successful or not
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+
+	// LastChange summarizes the last synchronization that changed the generated bindings
+	LastChange *SyncChangeT `json:"lastChange,omitempty"`
+
+	// MemberClusters contains the result of propagating the generated resources onto each member cluster,
+	// when the operator is configured with a cluster inventory
+	MemberClusters []MemberClusterStatusT `json:"memberClusters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].reason",description=""
+// +kubebuilder:printcolumn:name="Subjects",type="integer",JSONPath=".status.subjectsCount",description=""
+// +kubebuilder:printcolumn:name="Namespaces",type="integer",JSONPath=".status.targetNamespacesCount",description=""
+// +kubebuilder:printcolumn:name="Bindings",type="integer",JSONPath=".status.generatedBindingsCount",description=""
+// +kubebuilder:printcolumn:name="Last Sync",type="date",JSONPath=".status.lastSyncTime",description=""
+// +kubebuilder:printcolumn:name="Next Sync",type="string",JSONPath=".status.nextSyncTime",description=""
+// +kubebuilder:printcolumn:name="Sync Duration",type="string",JSONPath=".status.lastSyncDuration",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// DynamicRoleBinding is the Schema for the dynamicrolebindings API
+type DynamicRoleBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DynamicRoleBindingSpec   `json:"spec,omitempty"`
+	Status DynamicRoleBindingStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DynamicRoleBindingList contains a list of DynamicRoleBinding
+type DynamicRoleBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DynamicRoleBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DynamicRoleBinding{}, &DynamicRoleBindingList{})
+}
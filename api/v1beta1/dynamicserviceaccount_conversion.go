@@ -0,0 +1,71 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// ConvertTo converts this DynamicServiceAccount to the hub version (v1alpha1)
+func (src *DynamicServiceAccount) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.DynamicServiceAccount)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Synchronization = v1alpha1.SynchronizationT(src.Spec.Synchronization)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+	dst.Spec.Targets = v1alpha1.DynamicServiceAccountTargets{
+		Name:              src.Spec.Target.Name,
+		Annotations:       src.Spec.Target.Annotations,
+		Labels:            src.Spec.Target.Labels,
+		NamespaceSelector: convertSelectorToHub(src.Spec.Target.NamespaceSelector),
+
+		ExcludeSystemNamespaces: src.Spec.Target.ExcludeSystemNamespaces,
+	}
+
+	// Status
+	dst.Status = v1alpha1.DynamicServiceAccountStatus(src.Status)
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version
+func (dst *DynamicServiceAccount) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.DynamicServiceAccount)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Synchronization = SynchronizationT(src.Spec.Synchronization)
+	dst.Spec.DeletionPolicy = src.Spec.DeletionPolicy
+	dst.Spec.Target = ServiceAccountTargetT{
+		Name:              src.Spec.Targets.Name,
+		Annotations:       src.Spec.Targets.Annotations,
+		Labels:            src.Spec.Targets.Labels,
+		NamespaceSelector: convertSelectorFromHub(src.Spec.Targets.NamespaceSelector),
+
+		ExcludeSystemNamespaces: src.Spec.Targets.ExcludeSystemNamespaces,
+	}
+
+	// Status
+	dst.Status = DynamicServiceAccountStatus(src.Status)
+
+	return nil
+}
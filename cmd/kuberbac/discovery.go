@@ -0,0 +1,24 @@
+package main
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DiscoveryDump serves the resources recorded by 'kuberbac dump-discovery',
+// so DynamicClusterRoles can be rendered without access to the cluster
+type DiscoveryDump struct {
+	apiResourceLists []*metav1.APIResourceList
+}
+
+// NewDiscoveryDump decodes a discovery dump, expressed as a YAML or JSON list of APIResourceList
+func NewDiscoveryDump(content []byte) (dump *DiscoveryDump, err error) {
+	dump = &DiscoveryDump{}
+	err = yaml.Unmarshal(content, &dump.apiResourceLists)
+	return dump, err
+}
+
+// ServerGroupsAndResources returns the recorded resources. Groups are not recorded as they are not needed
+func (d *DiscoveryDump) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	return nil, d.apiResourceLists, nil
+}
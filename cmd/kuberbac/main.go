@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/controller"
+	"prosimcorp.com/kuberbac/pkg/policy"
+)
+
+const (
+	usage = `kuberbac is a companion CLI for the kuberbac operator
+
+Usage:
+  kuberbac render -f <dynamicclusterrole.yaml> [--kubeconfig <path> | --discovery-dump <path>]
+  kuberbac diff --from <previous.yaml> --to <next.yaml> [--kubeconfig <path> | --discovery-dump <path>] [-o text|yaml|json]
+  kuberbac dump-discovery [--kubeconfig <path>] [-o <path>]
+  kuberbac snapshot [--kubeconfig <path>] [--namespaces <ns1,ns2>] [-o <path>]
+  kuberbac restore -f <snapshot.yaml> [--kubeconfig <path>] [--orphan] [--dry-run]
+
+Commands:
+  render          Print the ClusterRoles the operator would generate for a DynamicClusterRole
+  diff            Print the permissions added and removed between two versions of a DynamicClusterRole
+  dump-discovery  Record the resources available in a cluster, to render offline later
+  snapshot        Archive the objects generated by the operator into a single List
+  restore         Apply the objects of a snapshot on a cluster
+`
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "render":
+		err = runRender(os.Args[2:])
+	case "diff":
+		err = runDiff(os.Args[2:])
+	case "dump-discovery":
+		err = runDumpDiscovery(os.Args[2:])
+	case "snapshot":
+		err = runSnapshot(os.Args[2:])
+	case "restore":
+		err = runRestore(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// runRender prints the ClusterRoles generated from a DynamicClusterRole manifest.
+// Resources are discovered from a live cluster, or from a recorded dump when provided
+func runRender(args []string) (err error) {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	manifestPath := flags.String("f", "", "Path to the DynamicClusterRole manifest. Use '-' to read from stdin")
+	kubeconfigPath := flags.String("kubeconfig", "", "Path to the kubeconfig file. Defaults to the usual kubectl rules")
+	discoveryDumpPath := flags.String("discovery-dump", "", "Path to a discovery dump. When set, the cluster is never contacted")
+	wildcardVerbs := flags.String("wildcard-verbs", "", "Comma-separated list of verbs used to expand wildcard verbs for all the resources")
+	extraWildcardVerbs := flags.String("extra-wildcard-verbs", "", "Comma-separated list of verbs always added when expanding wildcard verbs")
+	disableSelfProtection := flags.Bool("disable-self-protection", false, "Keep the rules granting write access to kuberbac resources")
+	_ = flags.Parse(args)
+
+	if *manifestPath == "" {
+		return fmt.Errorf("flag -f is required")
+	}
+
+	resource, err := readDynamicClusterRole(*manifestPath)
+	if err != nil {
+		return err
+	}
+
+	kubeClient, discoverer, err := getDiscoverer(*kubeconfigPath, *discoveryDumpPath)
+	if err != nil {
+		return err
+	}
+
+	// Objects are read with the credentials of the user, so values can be read from any Secret they can read
+	clusterRoles, _, _, _, err := controller.RenderClusterRoles(context.Background(), kubeClient, discoverer, policy.WildcardVerbsT{
+		Override: parseList(*wildcardVerbs),
+		Extra:    parseList(*extraWildcardVerbs),
+	}, controller.ObjectListingT{SecretValues: true}, getSelfProtection(*disableSelfProtection, kubeClient != nil), resource)
+	if err != nil {
+		return err
+	}
+
+	// Print them as a multi-document YAML
+	separator := ""
+	for _, targetClusterRoles := range clusterRoles {
+		for _, clusterRole := range targetClusterRoles.ClusterRoles {
+			output, err := yaml.Marshal(clusterRole)
+			if err != nil {
+				return fmt.Errorf("error encoding ClusterRole: %s", err.Error())
+			}
+
+			fmt.Fprint(os.Stdout, separator+string(output))
+			separator = "---\n"
+		}
+	}
+
+	return err
+}
+
+// runDiff prints the permissions added and removed when a DynamicClusterRole changes from one manifest to another.
+// Both of them are rendered against the same resources, so only the changes on the specs are shown
+func runDiff(args []string) (err error) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	fromPath := flags.String("from", "", "Path to the manifest of the previous DynamicClusterRole. Use '-' to read from stdin")
+	toPath := flags.String("to", "", "Path to the manifest of the next DynamicClusterRole. Use '-' to read from stdin")
+	kubeconfigPath := flags.String("kubeconfig", "", "Path to the kubeconfig file. Defaults to the usual kubectl rules")
+	discoveryDumpPath := flags.String("discovery-dump", "", "Path to a discovery dump. When set, the cluster is never contacted")
+	wildcardVerbs := flags.String("wildcard-verbs", "", "Comma-separated list of verbs used to expand wildcard verbs for all the resources")
+	extraWildcardVerbs := flags.String("extra-wildcard-verbs", "", "Comma-separated list of verbs always added when expanding wildcard verbs")
+	disableSelfProtection := flags.Bool("disable-self-protection", false, "Keep the rules granting write access to kuberbac resources")
+	outputFormat := flags.String("o", "text", "Output format. One of: text, yaml, json")
+	exitCode := flags.Bool("exit-code", false, "Exit with status 1 when the permissions differ, like 'git diff --exit-code'")
+	_ = flags.Parse(args)
+
+	if *fromPath == "" || *toPath == "" {
+		return fmt.Errorf("flags --from and --to are required")
+	}
+
+	if *fromPath == "-" && *toPath == "-" {
+		return fmt.Errorf("only one of the manifests can be read from stdin")
+	}
+
+	if !slices.Contains([]string{"text", "yaml", "json"}, *outputFormat) {
+		return fmt.Errorf("invalid output format: %s", *outputFormat)
+	}
+
+	kubeClient, discoverer, err := getDiscoverer(*kubeconfigPath, *discoveryDumpPath)
+	if err != nil {
+		return err
+	}
+
+	wildcardVerbsConfig := policy.WildcardVerbsT{
+		Override: parseList(*wildcardVerbs),
+		Extra:    parseList(*extraWildcardVerbs),
+	}
+	selfProtection := getSelfProtection(*disableSelfProtection, kubeClient != nil)
+
+	policyRules := [2][]rbacv1.PolicyRule{}
+	for index, manifestPath := range []string{*fromPath, *toPath} {
+
+		resource, err := readDynamicClusterRole(manifestPath)
+		if err != nil {
+			return err
+		}
+
+		clusterRoles, _, _, _, err := controller.RenderClusterRoles(context.Background(), kubeClient, discoverer, wildcardVerbsConfig,
+			controller.ObjectListingT{SecretValues: true}, selfProtection, resource)
+		if err != nil {
+			return fmt.Errorf("error rendering '%s': %s", manifestPath, err.Error())
+		}
+
+		// Permissions are the union of all the generated ClusterRoles, as they are usually bound together
+		for _, targetClusterRoles := range clusterRoles {
+			for _, clusterRole := range targetClusterRoles.ClusterRoles {
+				policyRules[index] = append(policyRules[index], clusterRole.Rules...)
+			}
+		}
+	}
+
+	added, removed := policy.DiffPolicyRules(policyRules[0], policyRules[1])
+
+	switch *outputFormat {
+	case "text":
+		for _, permission := range removed {
+			fmt.Fprintln(os.Stdout, "- "+permission.String())
+		}
+		for _, permission := range added {
+			fmt.Fprintln(os.Stdout, "+ "+permission.String())
+		}
+	default:
+		diff := map[string][]policy.PermissionT{"added": added, "removed": removed}
+
+		var output []byte
+		if *outputFormat == "json" {
+			output, err = json.MarshalIndent(diff, "", "  ")
+			output = append(output, '\n')
+		} else {
+			output, err = yaml.Marshal(diff)
+		}
+		if err != nil {
+			return fmt.Errorf("error encoding diff: %s", err.Error())
+		}
+
+		_, err = os.Stdout.Write(output)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *exitCode && (len(added) > 0 || len(removed) > 0) {
+		os.Exit(1)
+	}
+
+	return err
+}
+
+// runDumpDiscovery records the resources available in a cluster into a file
+func runDumpDiscovery(args []string) (err error) {
+	flags := flag.NewFlagSet("dump-discovery", flag.ExitOnError)
+	kubeconfigPath := flags.String("kubeconfig", "", "Path to the kubeconfig file. Defaults to the usual kubectl rules")
+	outputPath := flags.String("o", "-", "Path to the output file. Use '-' to write to stdout")
+	_ = flags.Parse(args)
+
+	config, err := getRestConfig(*kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return fmt.Errorf("error creating discovery client: %s", err.Error())
+	}
+
+	_, apiResourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		return fmt.Errorf("error retrieving resources: %s", err.Error())
+	}
+
+	output, err := yaml.Marshal(apiResourceLists)
+	if err != nil {
+		return fmt.Errorf("error encoding discovery dump: %s", err.Error())
+	}
+
+	if *outputPath == "-" {
+		_, err = os.Stdout.Write(output)
+		return err
+	}
+
+	return os.WriteFile(*outputPath, output, 0644)
+}
+
+// runSnapshot archives the objects generated by the operator, such as ClusterRoles and bindings, into a single List,
+// for disaster recovery or migrations between clusters
+func runSnapshot(args []string) (err error) {
+	flags := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	kubeconfigPath := flags.String("kubeconfig", "", "Path to the kubeconfig file. Defaults to the usual kubectl rules")
+	namespaces := flags.String("namespaces", "", "Comma-separated list of namespaces where namespaced objects are looked for. Defaults to all of them")
+	outputPath := flags.String("o", "-", "Path to the output file. Use '-' to write to stdout")
+	_ = flags.Parse(args)
+
+	kubeClient, err := getClient(*kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := controller.TakeSnapshot(context.Background(), kubeClient, parseList(*namespaces))
+	if err != nil {
+		return fmt.Errorf("error taking snapshot: %s", err.Error())
+	}
+
+	content, err := snapshot.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("error encoding snapshot: %s", err.Error())
+	}
+
+	output, err := yaml.JSONToYAML(content)
+	if err != nil {
+		return fmt.Errorf("error encoding snapshot: %s", err.Error())
+	}
+
+	fmt.Fprintf(os.Stderr, "%d objects archived\n", len(snapshot.Items))
+
+	if *outputPath == "-" {
+		_, err = os.Stdout.Write(output)
+		return err
+	}
+
+	return os.WriteFile(*outputPath, output, 0600)
+}
+
+// runRestore applies the objects of a snapshot taken by 'kuberbac snapshot' on a cluster
+func runRestore(args []string) (err error) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	snapshotPath := flags.String("f", "", "Path to the snapshot. Use '-' to read from stdin")
+	kubeconfigPath := flags.String("kubeconfig", "", "Path to the kubeconfig file. Defaults to the usual kubectl rules")
+	orphan := flags.Bool("orphan", false, "Remove the reference annotations, keeping the restored objects untracked by their owners")
+	dryRun := flags.Bool("dry-run", false, "Validate the objects on the API server without persisting them")
+	_ = flags.Parse(args)
+
+	if *snapshotPath == "" {
+		return fmt.Errorf("flag -f is required")
+	}
+
+	content, err := readFile(*snapshotPath)
+	if err != nil {
+		return fmt.Errorf("error reading snapshot: %s", err.Error())
+	}
+
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		return fmt.Errorf("error decoding snapshot: %s", err.Error())
+	}
+
+	snapshot := &unstructured.UnstructuredList{}
+	err = snapshot.UnmarshalJSON(content)
+	if err != nil {
+		return fmt.Errorf("error decoding snapshot: %s", err.Error())
+	}
+
+	kubeClient, err := getClient(*kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	restored, err := controller.RestoreSnapshot(context.Background(), kubeClient, snapshot, *orphan, *dryRun)
+	fmt.Fprintf(os.Stdout, "%d of %d objects restored\n", restored, len(snapshot.Items))
+	if err != nil {
+		return fmt.Errorf("error restoring snapshot: %s", err.Error())
+	}
+
+	return err
+}
+
+// readDynamicClusterRole reads and strictly decodes a DynamicClusterRole manifest
+func readDynamicClusterRole(manifestPath string) (resource *kuberbacv1alpha1.DynamicClusterRole, err error) {
+
+	manifest, err := readFile(manifestPath)
+	if err != nil {
+		return resource, fmt.Errorf("error reading manifest: %s", err.Error())
+	}
+
+	resource = &kuberbacv1alpha1.DynamicClusterRole{}
+	err = yaml.UnmarshalStrict(manifest, resource)
+	if err != nil {
+		return resource, fmt.Errorf("error decoding DynamicClusterRole: %s", err.Error())
+	}
+
+	return resource, err
+}
+
+// getSelfProtection returns the self-protection of an operator deployed with the default names,
+// so rendered ClusterRoles match the ones generated in the cluster. Rendering offline, the identity of the operator
+// can not be denied by name, so a warning is printed to tell it apart from the rules generated in the cluster
+func getSelfProtection(disabled bool, online bool) controller.SelfProtectionT {
+	selfProtection := controller.SelfProtectionT{
+		Disabled:           disabled,
+		ServiceAccount:     controller.DefaultOperatorServiceAccount,
+		ClusterRole:        controller.DefaultOperatorClusterRole,
+		ClusterRoleBinding: controller.DefaultOperatorClusterRoleBinding,
+	}
+
+	if unprotected := selfProtection.Unprotected(controller.ObjectListingT{}, online); len(unprotected) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: rendering offline, the self-protection does not deny access to %s\n",
+			strings.Join(unprotected, ", "))
+	}
+
+	return selfProtection
+}
+
+// getDiscoverer chooses where to discover resources from: a recorded dump when provided, or a live cluster otherwise.
+// The client is only returned for live clusters, as protection policies are read from them
+func getDiscoverer(kubeconfigPath, discoveryDumpPath string) (kubeClient client.Client, discoverer policy.ResourceDiscoverer, err error) {
+
+	if discoveryDumpPath != "" {
+		dump, err := readFile(discoveryDumpPath)
+		if err != nil {
+			return kubeClient, discoverer, fmt.Errorf("error reading discovery dump: %s", err.Error())
+		}
+
+		discoverer, err = NewDiscoveryDump(dump)
+		if err != nil {
+			return kubeClient, discoverer, fmt.Errorf("error decoding discovery dump: %s", err.Error())
+		}
+
+		return kubeClient, discoverer, err
+	}
+
+	config, err := getRestConfig(kubeconfigPath)
+	if err != nil {
+		return kubeClient, discoverer, err
+	}
+
+	discoverer, err = discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return kubeClient, discoverer, fmt.Errorf("error creating discovery client: %s", err.Error())
+	}
+
+	// Protection policies are read from the cluster, so their type must be known by the client
+	kubeClient, err = getClient(kubeconfigPath)
+	return kubeClient, discoverer, err
+}
+
+// getClient creates a client for the cluster, knowing the built-in types and the ones of the operator
+func getClient(kubeconfigPath string) (kubeClient client.Client, err error) {
+
+	config, err := getRestConfig(kubeconfigPath)
+	if err != nil {
+		return kubeClient, err
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kuberbacv1alpha1.AddToScheme(scheme))
+
+	kubeClient, err = client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return kubeClient, fmt.Errorf("error creating client: %s", err.Error())
+	}
+
+	return kubeClient, err
+}
+
+// getRestConfig loads the configuration to connect to the cluster following the usual kubectl rules.
+// An explicit kubeconfig path takes precedence over them
+func getRestConfig(kubeconfigPath string) (config *rest.Config, err error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
+
+	config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return config, fmt.Errorf("error loading kubeconfig: %s", err.Error())
+	}
+
+	return config, err
+}
+
+// readFile reads the content of a file, or stdin when the path is '-'
+func readFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// parseList converts a comma-separated list, such as verbs or namespaces, into a slice, ignoring empty items
+func parseList(list string) (result []string) {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
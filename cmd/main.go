@@ -19,7 +19,12 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"k8s.io/client-go/discovery"
 
@@ -27,17 +32,29 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	kuberbacv1beta1 "prosimcorp.com/kuberbac/api/v1beta1"
+	"prosimcorp.com/kuberbac/internal/audit"
 	"prosimcorp.com/kuberbac/internal/controller"
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+	"prosimcorp.com/kuberbac/internal/groupprovider"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+	"prosimcorp.com/kuberbac/internal/mutationhook"
+	"prosimcorp.com/kuberbac/internal/readiness"
+	"prosimcorp.com/kuberbac/pkg/policy"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -50,6 +67,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(kuberbacv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(kuberbacv1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -59,6 +77,57 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var ownershipMode string
+	var standardLabels bool
+	var propagatedAnnotations string
+	var discoveryCacheTTL time.Duration
+	var readinessCheckInterval time.Duration
+	var pruneOrphans bool
+	var pruneOrphansInterval time.Duration
+	var escalationProtection bool
+	var allowedPrivilegedVerbs string
+	var allowedPrivilegedResources string
+	var disableSelfProtection bool
+	var selfProtectionServiceAccount string
+	var selfProtectionClusterRole string
+	var selfProtectionClusterRoleBinding string
+	var selfProtectionAllowedResources string
+	var wildcardVerbs string
+	var extraWildcardVerbs string
+	var disableObjectListing bool
+	var allowSecretValues bool
+	var objectListingGroups string
+	var excludeSystemNamespaces bool
+	var groupProviderType string
+	var groupProviderConfigMap string
+	var certificatesProviderInventory string
+	var groupProviderURL string
+	var groupProviderTokenFile string
+	var groupProviderCacheTTL time.Duration
+	var mutationHookURL string
+	var mutationHookTokenFile string
+	var mutationHookTimeout time.Duration
+	var clusterInventoryNamespace string
+	var clusterInventorySelector string
+	var clusterInventoryKubeconfigKey string
+	var userProviderType string
+	var userProviderConfigMap string
+	var retryBaseDelay time.Duration
+	var retryMaxDelay time.Duration
+	var syncTime time.Duration
+	var syncJitter float64
+	var minSyncTime time.Duration
+	var watchNamespaces string
+	var auditWebhookAddr string
+	var auditWebhookCertDir string
+	var auditWebhookTokenFile string
+	var auditWebhookClientCAFile string
+	var podName string
+	var podNamespace string
+	var previewAPIAddr string
+	var previewAPICertDir string
+	var previewAPITokenFile string
+	var auditLogPath string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metric endpoint binds to. "+
 		"Use the port :8080. If not set, it will be 0 in order to disable the metrics server")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -69,6 +138,138 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&ownershipMode, "ownership-mode", controller.OwnershipModeAnnotations,
+		"How generated resources are tracked. One of: annotations, references. "+
+			"With 'references', OwnerReferences are set on generated resources living in the same namespace as their owner")
+	flag.BoolVar(&standardLabels, "standard-labels", false,
+		"If set, generated ClusterRoles, Roles and bindings are labeled with 'app.kubernetes.io/managed-by', "+
+			"'app.kubernetes.io/part-of' and the hash of their desired state, so they are not written again while nothing changes")
+	flag.StringVar(&propagatedAnnotations, "propagated-annotations", "",
+		"Comma-separated list of annotations copied from each resource onto the resources it generates, "+
+			"e.g. argocd.argoproj.io/tracking-id. Items ending with '*' match every annotation starting with them")
+	flag.BoolVar(&escalationProtection, "escalation-protection", false,
+		"If set, DynamicClusterRoles and DynamicAccesses generating rules with privileged verbs (bind, escalate, impersonate), "+
+			"or writing privileged resources (secrets, serviceaccounts/token, or RBAC objects under wildcard verbs), are rejected")
+	flag.StringVar(&allowedPrivilegedVerbs, "allowed-privileged-verbs", "",
+		"Comma-separated list of privileged verbs allowed when escalation protection is enabled")
+	flag.StringVar(&allowedPrivilegedResources, "allowed-privileged-resources", "",
+		"Comma-separated list of privileged resources, named as '<resource>.<group>', whose writes are allowed "+
+			"when escalation protection is enabled, e.g. secrets,rolebindings.rbac.authorization.k8s.io")
+	flag.BoolVar(&disableSelfProtection, "disable-self-protection", false,
+		"If set, generated rules granting write access to kuberbac resources, or to the ServiceAccount, "+
+			"the ClusterRole and the ClusterRoleBinding of the operator, are kept instead of removed")
+	flag.StringVar(&selfProtectionServiceAccount, "self-protection-service-account", controller.DefaultOperatorServiceAccount,
+		"Name of the ServiceAccount of the operator, protected by the self-protection on every namespace")
+	flag.StringVar(&selfProtectionClusterRole, "self-protection-cluster-role", controller.DefaultOperatorClusterRole,
+		"Name of the ClusterRole of the operator, protected by the self-protection")
+	flag.StringVar(&selfProtectionClusterRoleBinding, "self-protection-cluster-role-binding", controller.DefaultOperatorClusterRoleBinding,
+		"Name of the ClusterRoleBinding granting its ClusterRole to the operator, protected by the self-protection")
+	flag.StringVar(&selfProtectionAllowedResources, "self-protection-allowed-resources", "",
+		"Comma-separated list of DynamicClusterRoles and DynamicAccesses not affected by the self-protection, "+
+			"expressed as 'Kind/namespace/name', e.g. 'DynamicClusterRole/platform/kuberbac-admins'")
+	flag.DurationVar(&discoveryCacheTTL, "discovery-cache-ttl", 5*time.Minute,
+		"How long the resources retrieved from the discovery endpoint are cached. "+
+			"The cache is also invalidated when CustomResourceDefinitions are added, updated or removed")
+	flag.DurationVar(&readinessCheckInterval, "readiness-check-interval", readiness.DefaultInterval,
+		"Time between the checks served on the readiness probe: discovering the resources of the cluster, "+
+			"and reviewing the permissions of the operator")
+	flag.BoolVar(&pruneOrphans, "prune-orphans", false,
+		"Delete periodically the generated objects whose owner does not exist anymore, "+
+			"e.g. when finalizers were removed by hand or the operator was down while owners were deleted")
+	flag.DurationVar(&pruneOrphansInterval, "prune-orphans-interval", controller.DefaultJanitorInterval,
+		"Time between consecutive looks for orphaned objects, when --prune-orphans is set")
+	flag.StringVar(&wildcardVerbs, "wildcard-verbs", "",
+		"Comma-separated list of verbs used to expand wildcard verbs for all the resources. "+
+			"By default, wildcard verbs are expanded to the verbs reported by discovery for each resource")
+	flag.StringVar(&extraWildcardVerbs, "extra-wildcard-verbs", "",
+		"Comma-separated list of verbs always added when expanding wildcard verbs, e.g. bind,escalate,impersonate")
+	flag.BoolVar(&disableObjectListing, "disable-object-listing", false,
+		"If set, objects are never listed to evaluate deny rules with resourceNames or objectSelector, "+
+			"so the operator does not need permissions to read every resource. Those deny rules are rejected")
+	flag.BoolVar(&allowSecretValues, "allow-secret-values", false,
+		"If set, DynamicClusterRoles can read the values of their templates from Secrets of their namespace through valuesFrom. "+
+			"Values end up in the generated ClusterRoles, readable by others, so only ConfigMaps are read by default")
+	flag.StringVar(&objectListingGroups, "object-listing-groups", "",
+		"Comma-separated list of API groups whose objects can be listed to evaluate deny rules with resourceNames "+
+			"or objectSelector, being 'core' the core group. All of them when empty")
+	flag.BoolVar(&excludeSystemNamespaces, "exclude-system-namespaces", true,
+		"If set, RoleBindings and ServiceAccounts are not generated on kube-system, kube-public and kube-node-lease, "+
+			"unless resources set 'excludeSystemNamespaces: false' on their targets")
+	flag.StringVar(&groupProviderType, "group-provider", "",
+		"Directory used to select Group subjects by regular expression. One of: configmap, scim, bindings, certificates. "+
+			"Disabled by default")
+	flag.StringVar(&groupProviderConfigMap, "group-provider-configmap", "",
+		"ConfigMap containing the groups, one per line under the key 'groups', expressed as 'namespace/name'")
+	flag.StringVar(&certificatesProviderInventory, "certificates-provider-inventory", "",
+		"ConfigMap where the certificates provider remembers the issued certificates until they expire, "+
+			"as their CertificateSigningRequests are garbage-collected earlier, expressed as 'namespace/name'. "+
+			"Required by the certificates group and user providers")
+	flag.StringVar(&groupProviderURL, "group-provider-url", "",
+		"Base URL of the SCIM 2.0 server to list the groups from, e.g. https://idp.example.com/scim/v2")
+	flag.StringVar(&groupProviderTokenFile, "group-provider-token-file", "",
+		"Path to a file containing the bearer token used to authenticate against the SCIM server")
+	flag.DurationVar(&groupProviderCacheTTL, "group-provider-cache-ttl", time.Minute,
+		"How long the groups and users listed by the group and user providers are cached")
+	flag.StringVar(&mutationHookURL, "mutation-hook-url", "",
+		"URL of a webhook receiving the generated ClusterRoles and bindings before they are applied, "+
+			"which answers with their labels and annotations mutated, or rejects them. Disabled by default")
+	flag.StringVar(&mutationHookTokenFile, "mutation-hook-token-file", "",
+		"Path to a file containing the bearer token used to authenticate against the mutation hook")
+	flag.DurationVar(&mutationHookTimeout, "mutation-hook-timeout", 10*time.Second,
+		"How long to wait for the mutation hook to answer")
+	flag.StringVar(&clusterInventoryNamespace, "cluster-inventory-namespace", "",
+		"Namespace containing the Secrets with the kubeconfig of the member clusters the generated ClusterRoles "+
+			"and bindings are propagated onto. Disabled by default")
+	flag.StringVar(&clusterInventorySelector, "cluster-inventory-selector", multicluster.DefaultSelector,
+		"Label selector of the Secrets of the cluster inventory")
+	flag.StringVar(&clusterInventoryKubeconfigKey, "cluster-inventory-kubeconfig-key", multicluster.DefaultKubeconfigKey,
+		"Key of the Secrets of the cluster inventory containing the kubeconfig")
+	flag.StringVar(&userProviderType, "user-provider", "",
+		"Directory used to select User subjects by regular expression. One of: configmap, bindings, certificates. "+
+			"Disabled by default")
+	flag.StringVar(&userProviderConfigMap, "user-provider-configmap", "",
+		"ConfigMap containing the users, one per line under the key 'users', expressed as 'namespace/name'")
+	flag.DurationVar(&retryBaseDelay, "retry-base-delay", controller.DefaultRetryBaseDelay,
+		"Delay to requeue a resource after its first failed synchronization. It doubles on each consecutive failure")
+	flag.DurationVar(&retryMaxDelay, "retry-max-delay", controller.DefaultRetryMaxDelay,
+		"Maximum delay to requeue a resource after consecutive failed synchronizations. "+
+			"Resources with an invalid spec are not requeued until they change")
+	flag.DurationVar(&syncTime, "sync-time", controller.DefaultSyncTime,
+		"Time between synchronizations of the resources not setting spec.synchronization.time")
+	flag.Float64Var(&syncJitter, "sync-jitter", controller.DefaultSyncJitter,
+		"Fraction of the synchronization time randomly added or subtracted on each requeue, "+
+			"so resources created at once do not synchronize at the same time")
+	flag.DurationVar(&minSyncTime, "min-sync-time", controller.DefaultMinSyncTime,
+		"Minimum time between synchronizations of a resource, whatever its spec.synchronization.time says")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", os.Getenv("WATCH_NAMESPACE"),
+		"Comma-separated list of namespaces where resources are reconciled from and generated in. "+
+			"All of them are used when empty. Defaults to the value of the WATCH_NAMESPACE environment variable")
+	flag.StringVar(&auditWebhookAddr, "audit-webhook-bind-address", "0",
+		"The address the audit webhook endpoint binds to, receiving the audit events of the API server to suggest rules "+
+			"on RBACSuggestions. If not set, it will be 0 in order to disable it")
+	flag.StringVar(&auditWebhookCertDir, "audit-webhook-cert-dir", "",
+		"Directory containing 'tls.crt' and 'tls.key' to serve the audit webhook endpoint with TLS. Required when the audit webhook is enabled")
+	flag.StringVar(&auditWebhookTokenFile, "audit-webhook-token-file", "",
+		"Path to the file containing the bearer token the API server must send to the audit webhook endpoint. "+
+			"This or '--audit-webhook-client-ca-file' is required when the audit webhook is enabled")
+	flag.StringVar(&auditWebhookClientCAFile, "audit-webhook-client-ca-file", "",
+		"Path to the CA file verifying the client certificates the API server can present to the audit webhook endpoint. "+
+			"This or '--audit-webhook-token-file' is required when the audit webhook is enabled")
+	flag.StringVar(&podName, "pod-name", os.Getenv("POD_NAME"),
+		"Name of the pod of the controller, labeled when it is the leader so the Service of the audit webhook only selects it. "+
+			"Defaults to the value of the POD_NAME environment variable")
+	flag.StringVar(&podNamespace, "pod-namespace", os.Getenv("POD_NAMESPACE"),
+		"Namespace of the pod of the controller. Defaults to the value of the POD_NAMESPACE environment variable")
+	flag.StringVar(&previewAPIAddr, "preview-api-bind-address", "0",
+		"The address the preview API binds to, rendering the manifests posted to '/render' and '/explain' without creating them. "+
+			"If not set, it will be 0 in order to disable it")
+	flag.StringVar(&previewAPICertDir, "preview-api-cert-dir", "",
+		"Directory containing 'tls.crt' and 'tls.key' to serve the preview API with TLS. A self-signed certificate is generated when empty")
+	flag.StringVar(&previewAPITokenFile, "preview-api-token-file", "",
+		"Path to the file containing the bearer token required by the preview API. Required when the preview API is enabled")
+	flag.StringVar(&auditLogPath, "audit-log-path", "",
+		"Path to the audit log file written by the API server, one JSON event per line, "+
+			"read to suggest rules on RBACSuggestions. Disabled by default")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -77,6 +278,21 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if syncJitter < 0 || syncJitter >= 1 {
+		setupLog.Error(fmt.Errorf("invalid value: %v", syncJitter), "unable to parse flag", "flag", "sync-jitter")
+		os.Exit(1)
+	}
+	syncSchedule := controller.SyncScheduleT{
+		DefaultTime: syncTime,
+		Jitter:      syncJitter,
+		MinTime:     minSyncTime,
+	}
+
+	if !slices.Contains([]string{controller.OwnershipModeAnnotations, controller.OwnershipModeReferences}, ownershipMode) {
+		setupLog.Error(fmt.Errorf("invalid value: %s", ownershipMode), "unable to parse flag", "flag", "ownership-mode")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -88,6 +304,22 @@ func main() {
 		c.NextProtos = []string{"http/1.1"}
 	}
 
+	// Restrict the cache to the watched namespaces, so namespaced objects living elsewhere are never read
+	watchNamespaceList := parseList(watchNamespaces)
+	cacheOptions := cache.Options{}
+	if len(watchNamespaceList) > 0 {
+		setupLog.Info("watching only some namespaces", "namespaces", watchNamespaceList)
+		cacheOptions.DefaultNamespaces = map[string]cache.Config{}
+		for _, namespace := range watchNamespaceList {
+			cacheOptions.DefaultNamespaces[namespace] = cache.Config{}
+		}
+	}
+
+	// Secrets are read from the API server when needed, so the content of every Secret is never kept in memory
+	clientOptions := client.Options{
+		Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+	}
+
 	tlsOpts := []func(*tls.Config){}
 	if !enableHTTP2 {
 		tlsOpts = append(tlsOpts, disableHTTP2)
@@ -99,6 +331,8 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOptions,
+		Client: clientOptions,
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
@@ -131,28 +365,408 @@ func main() {
 		setupLog.Error(err, "error creating discovery client")
 		os.Exit(1)
 	}
+	discoveryCache := discoverycache.NewDiscoveryCache(discoveryClient, discoveryCacheTTL)
 
-	if err = (&controller.DynamicClusterRoleReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+	// The certificates provider is shared by groups and users, so both of them write the same inventory
+	var certificatesProvider *groupprovider.CertificatesProvider
+	if groupProviderType == groupprovider.ProviderTypeCertificates || userProviderType == groupprovider.ProviderTypeCertificates {
+		namespace, name, found := strings.Cut(certificatesProviderInventory, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("invalid value: %s", certificatesProviderInventory), "unable to parse flag", "flag", "certificates-provider-inventory")
+			os.Exit(1)
+		}
+		certificatesProvider = &groupprovider.CertificatesProvider{
+			Client:             mgr.GetAPIReader(),
+			Writer:             mgr.GetClient(),
+			InventoryNamespace: namespace,
+			InventoryName:      name,
+		}
+	}
 
-		// TODO
-		DiscoveryClient: *discoveryClient,
-	}).SetupWithManager(mgr); err != nil {
+	// Group provider is optional. It is only needed to select Group subjects by regular expression
+	var groupProvider groupprovider.Provider
+	switch groupProviderType {
+	case "":
+	case groupprovider.ProviderTypeConfigMap:
+		namespace, name, found := strings.Cut(groupProviderConfigMap, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("invalid value: %s", groupProviderConfigMap), "unable to parse flag", "flag", "group-provider-configmap")
+			os.Exit(1)
+		}
+		groupProvider = groupprovider.NewCachedProvider(&groupprovider.ConfigMapProvider{
+			Client:    mgr.GetAPIReader(),
+			Namespace: namespace,
+			Name:      name,
+		}, groupProviderCacheTTL)
+	case groupprovider.ProviderTypeSCIM:
+		if groupProviderURL == "" {
+			setupLog.Error(fmt.Errorf("flag is required for the scim group provider"), "unable to parse flag", "flag", "group-provider-url")
+			os.Exit(1)
+		}
+		groupProvider = groupprovider.NewCachedProvider(&groupprovider.SCIMProvider{
+			URL:        groupProviderURL,
+			TokenFile:  groupProviderTokenFile,
+			HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		}, groupProviderCacheTTL)
+	case groupprovider.ProviderTypeBindings:
+		groupProvider = groupprovider.NewCachedProvider(&groupprovider.BindingsProvider{
+			Client: mgr.GetAPIReader(),
+		}, groupProviderCacheTTL)
+	case groupprovider.ProviderTypeCertificates:
+		groupProvider = groupprovider.NewCachedProvider(certificatesProvider, groupProviderCacheTTL)
+	default:
+		setupLog.Error(fmt.Errorf("invalid value: %s", groupProviderType), "unable to parse flag", "flag", "group-provider")
+		os.Exit(1)
+	}
+
+	// Mutation hook is optional. It lets organizations change the generated objects without forking the controller
+	var mutationHook *mutationhook.Hook
+	if mutationHookURL != "" {
+		mutationHook = &mutationhook.Hook{
+			URL:        mutationHookURL,
+			TokenFile:  mutationHookTokenFile,
+			HTTPClient: &http.Client{Timeout: mutationHookTimeout},
+		}
+	}
+
+	// Cluster inventory is optional. It lets a management cluster propagate the generated resources onto a fleet
+	var clusterInventory *multicluster.Inventory
+	if clusterInventoryNamespace != "" {
+		selector, err := labels.Parse(clusterInventorySelector)
+		if err != nil {
+			setupLog.Error(err, "unable to parse flag", "flag", "cluster-inventory-selector")
+			os.Exit(1)
+		}
+		clusterInventory = &multicluster.Inventory{
+			Reader:            mgr.GetAPIReader(),
+			Namespace:         clusterInventoryNamespace,
+			Selector:          selector,
+			KubeconfigKey:     clusterInventoryKubeconfigKey,
+			Scheme:            mgr.GetScheme(),
+			DiscoveryCacheTTL: discoveryCacheTTL,
+		}
+	}
+
+	// User provider is optional. It is only needed to select User subjects by regular expression
+	var userProvider groupprovider.UserProvider
+	switch userProviderType {
+	case "":
+	case groupprovider.ProviderTypeConfigMap:
+		namespace, name, found := strings.Cut(userProviderConfigMap, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("invalid value: %s", userProviderConfigMap), "unable to parse flag", "flag", "user-provider-configmap")
+			os.Exit(1)
+		}
+		userProvider = groupprovider.NewCachedUserProvider(&groupprovider.ConfigMapProvider{
+			Client:    mgr.GetAPIReader(),
+			Namespace: namespace,
+			Name:      name,
+		}, groupProviderCacheTTL)
+	case groupprovider.ProviderTypeBindings:
+		userProvider = groupprovider.NewCachedUserProvider(&groupprovider.BindingsProvider{
+			Client: mgr.GetAPIReader(),
+		}, groupProviderCacheTTL)
+	case groupprovider.ProviderTypeCertificates:
+		userProvider = groupprovider.NewCachedUserProvider(certificatesProvider, groupProviderCacheTTL)
+	default:
+		setupLog.Error(fmt.Errorf("invalid value: %s", userProviderType), "unable to parse flag", "flag", "user-provider")
+		os.Exit(1)
+	}
+
+	// Annotations set by GitOps tools on resources, such as their tracking id, are copied onto the generated ones
+	propagatedAnnotationList := parseList(propagatedAnnotations)
+
+	// Objects are listed to evaluate some deny rules. Restricting it allows running without permissions to read everything
+	objectListing := controller.ObjectListingT{Disabled: disableObjectListing, SecretValues: allowSecretValues}
+	for _, group := range parseList(objectListingGroups) {
+		if group == "core" {
+			group = ""
+		}
+		objectListing.Groups = append(objectListing.Groups, group)
+	}
+
+	// Tenants can not take the operator over through the rules it generates, unless their resources are allowed to
+	selfProtection := controller.SelfProtectionT{
+		Disabled:           disableSelfProtection,
+		ServiceAccount:     selfProtectionServiceAccount,
+		ClusterRole:        selfProtectionClusterRole,
+		ClusterRoleBinding: selfProtectionClusterRoleBinding,
+		AllowedResources:   parseList(selfProtectionAllowedResources),
+	}
+	if err = selfProtection.Validate(); err != nil {
+		setupLog.Error(err, "unable to parse flag", "flag", "self-protection-allowed-resources")
+		os.Exit(1)
+	}
+
+	// The identity of the operator is denied by name, so it is only protected when its objects can be listed
+	if unprotected := selfProtection.Unprotected(objectListing, true); len(unprotected) > 0 {
+		setupLog.Info("WARNING: the self-protection can not protect some objects of the operator, "+
+			"as listing the objects of their API groups is disabled", "objects", unprotected)
+	}
+
+	dynamicClusterRoleReconciler := &controller.DynamicClusterRoleReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("dynamicclusterrole-controller"),
+
+		StandardLabels:        standardLabels,
+		PropagatedAnnotations: propagatedAnnotationList,
+		MutationHook:          mutationHook,
+		MemberClusters:        clusterInventory,
+
+		DiscoveryCache: discoveryCache,
+
+		EscalationProtection:       escalationProtection,
+		AllowedPrivilegedVerbs:     parseList(allowedPrivilegedVerbs),
+		AllowedPrivilegedResources: parseList(allowedPrivilegedResources),
+
+		WildcardVerbs: policy.WildcardVerbsT{
+			Override: parseList(wildcardVerbs),
+			Extra:    parseList(extraWildcardVerbs),
+		},
+		ObjectListing:  objectListing,
+		SelfProtection: selfProtection,
+
+		RetryBaseDelay: retryBaseDelay,
+		RetryMaxDelay:  retryMaxDelay,
+		SyncSchedule:   syncSchedule,
+	}
+	if err = dynamicClusterRoleReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DynamicClusterRole")
 		os.Exit(1)
 	}
 
-	if err = (&controller.DynamicRoleBindingReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+	dynamicRoleBindingReconciler := &controller.DynamicRoleBindingReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("dynamicrolebinding-controller"),
+		OwnershipMode: ownershipMode,
 
-		// TODO
-		DiscoveryClient: *discoveryClient,
-	}).SetupWithManager(mgr); err != nil {
+		StandardLabels:        standardLabels,
+		PropagatedAnnotations: propagatedAnnotationList,
+		MutationHook:          mutationHook,
+		MemberClusters:        clusterInventory,
+
+		ExcludeSystemNamespaces: excludeSystemNamespaces,
+		WatchNamespaces:         watchNamespaceList,
+
+		DiscoveryCache: discoveryCache,
+		GroupProvider:  groupProvider,
+		UserProvider:   userProvider,
+
+		RetryBaseDelay: retryBaseDelay,
+		RetryMaxDelay:  retryMaxDelay,
+		SyncSchedule:   syncSchedule,
+	}
+	if err = dynamicRoleBindingReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DynamicRoleBinding")
 		os.Exit(1)
 	}
+
+	if err = (&controller.DynamicServiceAccountReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("dynamicserviceaccount-controller"),
+		OwnershipMode: ownershipMode,
+
+		PropagatedAnnotations: propagatedAnnotationList,
+
+		ExcludeSystemNamespaces: excludeSystemNamespaces,
+		WatchNamespaces:         watchNamespaceList,
+
+		RetryBaseDelay: retryBaseDelay,
+		RetryMaxDelay:  retryMaxDelay,
+		SyncSchedule:   syncSchedule,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DynamicServiceAccount")
+		os.Exit(1)
+	}
+
+	if err = (&controller.DynamicAccessReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("dynamicaccess-controller"),
+		OwnershipMode: ownershipMode,
+
+		StandardLabels:        standardLabels,
+		PropagatedAnnotations: propagatedAnnotationList,
+		MutationHook:          mutationHook,
+		MemberClusters:        clusterInventory,
+
+		ExcludeSystemNamespaces: excludeSystemNamespaces,
+		WatchNamespaces:         watchNamespaceList,
+
+		DiscoveryCache: discoveryCache,
+
+		EscalationProtection:       escalationProtection,
+		AllowedPrivilegedVerbs:     parseList(allowedPrivilegedVerbs),
+		AllowedPrivilegedResources: parseList(allowedPrivilegedResources),
+
+		WildcardVerbs: policy.WildcardVerbsT{
+			Override: parseList(wildcardVerbs),
+			Extra:    parseList(extraWildcardVerbs),
+		},
+		ObjectListing:  objectListing,
+		SelfProtection: selfProtection,
+		GroupProvider:  groupProvider,
+		UserProvider:   userProvider,
+
+		RetryBaseDelay: retryBaseDelay,
+		RetryMaxDelay:  retryMaxDelay,
+		SyncSchedule:   syncSchedule,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DynamicAccess")
+		os.Exit(1)
+	}
+
+	// Previews are served with the settings of the reconcilers, so they render exactly what would be synchronized
+	if previewAPIAddr != "0" {
+		previewAPIToken, err := os.ReadFile(previewAPITokenFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read the token of the preview API")
+			os.Exit(1)
+		}
+
+		if err := mgr.Add(&controller.PreviewAPIServer{
+			BindAddress: previewAPIAddr,
+			CertDir:     previewAPICertDir,
+			Token:       strings.TrimSpace(string(previewAPIToken)),
+
+			DynamicClusterRoleReconciler: dynamicClusterRoleReconciler,
+			DynamicRoleBindingReconciler: dynamicRoleBindingReconciler,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up preview API")
+			os.Exit(1)
+		}
+	}
+
+	// Audit events are optional. They are only consumed to suggest the rules used by the subjects on RBACSuggestions
+	var auditStore *audit.Store
+	if auditWebhookAddr != "0" || auditLogPath != "" {
+		auditStore = audit.NewStore()
+	}
+
+	if auditWebhookAddr != "0" {
+		auditWebhookToken := []byte{}
+		if auditWebhookTokenFile != "" {
+			auditWebhookToken, err = os.ReadFile(auditWebhookTokenFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read the token of the audit webhook")
+				os.Exit(1)
+			}
+		}
+
+		if err := mgr.Add(&audit.WebhookReceiver{
+			Store:        auditStore,
+			BindAddress:  auditWebhookAddr,
+			CertDir:      auditWebhookCertDir,
+			Token:        strings.TrimSpace(string(auditWebhookToken)),
+			ClientCAFile: auditWebhookClientCAFile,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up audit webhook")
+			os.Exit(1)
+		}
+
+		// Events are only received by the leader, so the Service must route them to its pod when there are several replicas
+		if enableLeaderElection {
+			if podName == "" || podNamespace == "" {
+				setupLog.Error(nil, "the name and namespace of the pod are required to serve the audit webhook with leader election, "+
+					"set '--pod-name' and '--pod-namespace'")
+				os.Exit(1)
+			}
+
+			if err := mgr.Add(&audit.LeaderPodLabeler{
+				Client:       mgr.GetClient(),
+				APIReader:    mgr.GetAPIReader(),
+				PodName:      podName,
+				PodNamespace: podNamespace,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up audit webhook leader labeler")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if auditLogPath != "" {
+		if err := mgr.Add(&audit.LogFileReader{
+			Store: auditStore,
+			Path:  auditLogPath,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up audit log reader")
+			os.Exit(1)
+		}
+	}
+
+	if err = (&controller.RBACReportReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("rbacreport-controller"),
+
+		RetryBaseDelay: retryBaseDelay,
+		RetryMaxDelay:  retryMaxDelay,
+		SyncSchedule:   syncSchedule,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RBACReport")
+		os.Exit(1)
+	}
+
+	if err = (&controller.RBACSuggestionReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("rbacsuggestion-controller"),
+
+		AuditStore: auditStore,
+
+		RetryBaseDelay: retryBaseDelay,
+		RetryMaxDelay:  retryMaxDelay,
+		SyncSchedule:   syncSchedule,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RBACSuggestion")
+		os.Exit(1)
+	}
+
+	// Orphaned objects are only pruned when asked, as they are expected to be released by the finalizers of their owners
+	if pruneOrphans {
+		if err := mgr.Add(&controller.OrphanJanitor{
+			Client:          mgr.GetClient(),
+			Reader:          mgr.GetAPIReader(),
+			Interval:        pruneOrphansInterval,
+			WatchNamespaces: watchNamespaceList,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up orphan janitor")
+			os.Exit(1)
+		}
+	}
+
+	// Conversion webhooks serve v1beta1 resources from the v1alpha1 storage version, while defaulting webhooks
+	// fix common mistakes on admission. They can be disabled to run the manager locally, where no certificates are available
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		defaulter := &kuberbacv1alpha1.Defaulter{SyncTime: syncTime}
+		if err = (&kuberbacv1alpha1.DynamicClusterRole{}).SetupWebhookWithManager(mgr, defaulter); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DynamicClusterRole")
+			os.Exit(1)
+		}
+		if err = (&kuberbacv1alpha1.DynamicRoleBinding{}).SetupWebhookWithManager(mgr, defaulter); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DynamicRoleBinding")
+			os.Exit(1)
+		}
+		if err = (&kuberbacv1alpha1.DynamicServiceAccount{}).SetupWebhookWithManager(mgr, defaulter); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DynamicServiceAccount")
+			os.Exit(1)
+		}
+		if err = (&kuberbacv1beta1.DynamicClusterRole{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DynamicClusterRole")
+			os.Exit(1)
+		}
+		if err = (&kuberbacv1beta1.DynamicRoleBinding{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DynamicRoleBinding")
+			os.Exit(1)
+		}
+		if err = (&kuberbacv1beta1.DynamicServiceAccount{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DynamicServiceAccount")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -164,9 +778,43 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Readiness fails while the cluster can not be discovered or the permissions of the operator are not enough,
+	// so broken deployments are caught by rollout checks. Liveness is not affected, as restarting would not fix them
+	readinessGate := &readiness.Gate{
+		DiscoveryCache: discoveryCache,
+		Client:         mgr.GetClient(),
+		Interval:       readinessCheckInterval,
+		Namespaces:     watchNamespaceList,
+	}
+	switch {
+	case objectListing.Disabled:
+		readinessGate.Permissions = readiness.RestrictedPermissions(nil)
+	case len(objectListing.Groups) > 0:
+		readinessGate.Permissions = readiness.RestrictedPermissions(objectListing.Groups)
+	}
+	if err := mgr.Add(readinessGate); err != nil {
+		setupLog.Error(err, "unable to set up readiness checks")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("operator", readinessGate.Check); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// parseList converts a comma-separated list, such as verbs or namespaces, into a slice, ignoring empty items
+func parseList(list string) (result []string) {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// stageResponseComplete is the stage of the events sent once the response is written.
+	// Requests are recorded only once, so the rest of stages are ignored
+	stageResponseComplete = "ResponseComplete"
+
+	// serviceAccountUsernamePrefix is the prefix of the usernames of ServiceAccounts, followed by 'namespace:name'
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+
+	// DefaultMaxIdentities and DefaultMaxAccessPerIdentity limit the memory taken by the Store, as events of
+	// any user can be received. Access of new identities, or new access of full ones, is dropped once reached
+	DefaultMaxIdentities        = 10000
+	DefaultMaxAccessPerIdentity = 10000
+)
+
+// EventT is the subset of the fields of an event of the audit.k8s.io/v1 API needed to know the access of a subject
+type EventT struct {
+	Stage          string            `json:"stage"`
+	RequestURI     string            `json:"requestURI"`
+	Verb           string            `json:"verb"`
+	User           UserInfoT         `json:"user"`
+	ObjectRef      *ObjectReferenceT `json:"objectRef,omitempty"`
+	ResponseStatus *ResponseStatusT  `json:"responseStatus,omitempty"`
+}
+
+// EventListT is the list of events sent by the API server to the audit webhook backend
+type EventListT struct {
+	Items []EventT `json:"items"`
+}
+
+// UserInfoT identifies the user making a request
+type UserInfoT struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// ObjectReferenceT identifies the object targeted by a request. It is empty for non-resource URLs
+type ObjectReferenceT struct {
+	Resource    string `json:"resource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	APIGroup    string `json:"apiGroup,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+}
+
+// ResponseStatusT is the status returned by the API server
+type ResponseStatusT struct {
+	Code int `json:"code,omitempty"`
+}
+
+// AccessT is a request allowed to a subject: a verb on a resource of a group, or on a non-resource URL.
+// Subresources are expressed as 'resource/subresource', the same way as on PolicyRules
+type AccessT struct {
+	Namespace      string
+	APIGroup       string
+	Resource       string
+	NonResourceURL string
+	Verb           string
+}
+
+// Store keeps in memory the access observed for each user and group, until the process ends.
+// It holds at most maxIdentities identities, and maxAccessPerIdentity access for each of them
+type Store struct {
+	mutex sync.RWMutex
+
+	// accessByIdentity is keyed by 'User:<name>' or 'Group:<name>'
+	accessByIdentity map[string]map[AccessT]struct{}
+	startTime        time.Time
+
+	maxIdentities        int
+	maxAccessPerIdentity int
+}
+
+// NewStore returns an empty Store, limited to the default number of identities and access
+func NewStore() *Store {
+	return &Store{
+		accessByIdentity:     map[string]map[AccessT]struct{}{},
+		startTime:            time.Now(),
+		maxIdentities:        DefaultMaxIdentities,
+		maxAccessPerIdentity: DefaultMaxAccessPerIdentity,
+	}
+}
+
+// StartTime returns the time since the Store is recording events
+func (s *Store) StartTime() time.Time {
+	return s.startTime
+}
+
+// Record stores the access of an event for its user and each of its groups. Events of stages other than
+// ResponseComplete, and those denied by the API server, are ignored
+func (s *Store) Record(event EventT) {
+
+	if event.Stage != stageResponseComplete || event.User.Username == "" || event.Verb == "" {
+		return
+	}
+
+	// Only what was allowed is recorded. Denied requests must not be suggested to be granted
+	if event.ResponseStatus != nil && (event.ResponseStatus.Code == 401 || event.ResponseStatus.Code == 403) {
+		return
+	}
+
+	access := AccessT{Verb: event.Verb}
+	if event.ObjectRef == nil || event.ObjectRef.Resource == "" {
+		access.NonResourceURL, _, _ = strings.Cut(event.RequestURI, "?")
+	} else {
+		access.Namespace = event.ObjectRef.Namespace
+		access.APIGroup = event.ObjectRef.APIGroup
+		access.Resource = event.ObjectRef.Resource
+		if event.ObjectRef.Subresource != "" {
+			access.Resource += "/" + event.ObjectRef.Subresource
+		}
+	}
+
+	identities := []string{"User:" + event.User.Username}
+	for _, group := range event.User.Groups {
+		identities = append(identities, "Group:"+group)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, identity := range identities {
+		identityAccess, found := s.accessByIdentity[identity]
+		if !found {
+			if len(s.accessByIdentity) >= s.maxIdentities {
+				continue
+			}
+			identityAccess = map[AccessT]struct{}{}
+			s.accessByIdentity[identity] = identityAccess
+		}
+
+		if _, found := identityAccess[access]; !found && len(identityAccess) >= s.maxAccessPerIdentity {
+			continue
+		}
+		identityAccess[access] = struct{}{}
+	}
+}
+
+// GetAccess returns the access observed for a subject, sorted. ServiceAccounts are looked for by their username
+func (s *Store) GetAccess(kind, namespace, name string) (result []AccessT) {
+
+	identity := "User:" + name
+	switch kind {
+	case "Group":
+		identity = "Group:" + name
+	case "ServiceAccount":
+		identity = "User:" + serviceAccountUsernamePrefix + namespace + ":" + name
+	}
+
+	s.mutex.RLock()
+	for access := range s.accessByIdentity[identity] {
+		result = append(result, access)
+	}
+	s.mutex.RUnlock()
+
+	slices.SortFunc(result, func(a, b AccessT) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return result
+}
+
+// String returns a compact representation of the access, e.g. 'patch deployments.apps/scale in default'
+func (a AccessT) String() string {
+	if a.NonResourceURL != "" {
+		return a.Verb + " " + a.NonResourceURL
+	}
+
+	resource, subresource, _ := strings.Cut(a.Resource, "/")
+	if a.APIGroup != "" {
+		resource += "." + a.APIGroup
+	}
+	if subresource != "" {
+		resource += "/" + subresource
+	}
+	if a.Namespace != "" {
+		resource += " in " + a.Namespace
+	}
+	return a.Verb + " " + resource
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newEvent returns an event of a completed request on a resource, or on a non-resource URL when objectRef is nil
+func newEvent(username string, groups []string, verb string, objectRef *ObjectReferenceT, code int) EventT {
+	return EventT{
+		Stage:          stageResponseComplete,
+		RequestURI:     "/version?timeout=32s",
+		Verb:           verb,
+		User:           UserInfoT{Username: username, Groups: groups},
+		ObjectRef:      objectRef,
+		ResponseStatus: &ResponseStatusT{Code: code},
+	}
+}
+
+var _ = Describe("Store", func() {
+
+	It("should record the access of the user and each of its groups", func() {
+		store := NewStore()
+		store.Record(newEvent("alice", []string{"developers"}, "patch",
+			&ObjectReferenceT{APIGroup: "apps", Resource: "deployments", Subresource: "scale", Namespace: "payments"}, 200))
+		store.Record(newEvent("alice", nil, "get", nil, 200))
+
+		expectedAccess := AccessT{Namespace: "payments", APIGroup: "apps", Resource: "deployments/scale", Verb: "patch"}
+		Expect(store.GetAccess("User", "", "alice")).To(Equal([]AccessT{
+			{NonResourceURL: "/version", Verb: "get"},
+			expectedAccess,
+		}))
+		Expect(store.GetAccess("Group", "", "developers")).To(Equal([]AccessT{expectedAccess}))
+		Expect(expectedAccess.String()).To(Equal("patch deployments.apps/scale in payments"))
+	})
+
+	It("should look for ServiceAccounts by their username", func() {
+		store := NewStore()
+		store.Record(newEvent("system:serviceaccount:payments:deployer", nil, "list", &ObjectReferenceT{Resource: "pods", Namespace: "payments"}, 200))
+
+		Expect(store.GetAccess("ServiceAccount", "payments", "deployer")).To(HaveLen(1))
+		Expect(store.GetAccess("ServiceAccount", "shipping", "deployer")).To(BeEmpty())
+	})
+
+	DescribeTable("ignoring the events not granting any access",
+		func(event EventT) {
+			store := NewStore()
+			store.Record(event)
+			Expect(store.accessByIdentity).To(BeEmpty())
+		},
+		Entry("stages other than ResponseComplete", EventT{Stage: "RequestReceived", Verb: "get", User: UserInfoT{Username: "alice"}}),
+		Entry("unauthenticated requests", newEvent("alice", nil, "get", nil, 401)),
+		Entry("forbidden requests", newEvent("alice", nil, "get", nil, 403)),
+		Entry("events without user", newEvent("", nil, "get", nil, 200)),
+	)
+
+	It("should drop the access exceeding its limits", func() {
+		store := NewStore()
+		store.maxIdentities = 2
+		store.maxAccessPerIdentity = 2
+
+		for _, verb := range []string{"get", "list", "watch"} {
+			store.Record(newEvent("alice", nil, verb, &ObjectReferenceT{Resource: "pods"}, 200))
+		}
+		store.Record(newEvent("bob", nil, "get", &ObjectReferenceT{Resource: "pods"}, 200))
+		store.Record(newEvent("carol", nil, "get", &ObjectReferenceT{Resource: "pods"}, 200))
+
+		// Access already recorded is still accepted once full
+		store.Record(newEvent("alice", nil, "get", &ObjectReferenceT{Resource: "pods"}, 200))
+
+		Expect(store.GetAccess("User", "", "alice")).To(HaveLen(2))
+		Expect(store.GetAccess("User", "", "bob")).To(HaveLen(1))
+		Expect(store.GetAccess("User", "", "carol")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("WebhookReceiver", func() {
+
+	eventListBody := func() *bytes.Buffer {
+		body, err := json.Marshal(EventListT{Items: []EventT{newEvent("alice", nil, "get", nil, 200)}})
+		Expect(err).NotTo(HaveOccurred())
+		return bytes.NewBuffer(body)
+	}
+
+	It("should refuse to start without TLS or credentials", func() {
+		store := NewStore()
+		Expect((&WebhookReceiver{Store: store, Token: "secret"}).Start(context.Background())).
+			To(MatchError(ContainSubstring("certificate directory is required")))
+		Expect((&WebhookReceiver{Store: store, CertDir: GinkgoT().TempDir()}).Start(context.Background())).
+			To(MatchError(ContainSubstring("token or a client CA is required")))
+	})
+
+	It("should refuse to start with a client CA without certificates", func() {
+		clientCAFile := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+		Expect(os.WriteFile(clientCAFile, []byte("not a certificate"), 0o600)).To(Succeed())
+
+		receiver := &WebhookReceiver{Store: NewStore(), CertDir: GinkgoT().TempDir(), ClientCAFile: clientCAFile}
+		Expect(receiver.Start(context.Background())).To(MatchError(ContainSubstring("no PEM certificates found")))
+	})
+
+	DescribeTable("authenticating the API server",
+		func(receiver *WebhookReceiver, authorization string, verifiedClientCertificate bool, expectedCode int) {
+			receiver.Store = NewStore()
+
+			request := httptest.NewRequest(http.MethodPost, WebhookPath, eventListBody())
+			if authorization != "" {
+				request.Header.Set("Authorization", authorization)
+			}
+			request.TLS = &tls.ConnectionState{}
+			if verifiedClientCertificate {
+				request.TLS.VerifiedChains = [][]*x509.Certificate{{{}}}
+			}
+
+			response := httptest.NewRecorder()
+			receiver.ServeHTTP(response, request)
+			Expect(response.Code).To(Equal(expectedCode))
+
+			recordedAccess := receiver.Store.GetAccess("User", "", "alice")
+			if expectedCode == http.StatusOK {
+				Expect(recordedAccess).To(HaveLen(1))
+			} else {
+				Expect(recordedAccess).To(BeEmpty())
+			}
+		},
+		Entry("valid token", &WebhookReceiver{Token: "secret"}, "Bearer secret", false, http.StatusOK),
+		Entry("invalid token", &WebhookReceiver{Token: "secret"}, "Bearer guess", false, http.StatusUnauthorized),
+		Entry("missing token", &WebhookReceiver{Token: "secret"}, "", false, http.StatusUnauthorized),
+		Entry("verified client certificate", &WebhookReceiver{ClientCAFile: "ca.crt"}, "", true, http.StatusOK),
+		Entry("client certificate without client CA", &WebhookReceiver{Token: "secret"}, "", true, http.StatusUnauthorized),
+		Entry("empty token without client CA", &WebhookReceiver{ClientCAFile: "ca.crt"}, "Bearer ", false, http.StatusUnauthorized),
+	)
+
+	It("should reject the requests other than POST and invalid bodies", func() {
+		receiver := &WebhookReceiver{Store: NewStore(), Token: "secret"}
+
+		request := httptest.NewRequest(http.MethodGet, WebhookPath, nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+		receiver.ServeHTTP(response, request)
+		Expect(response.Code).To(Equal(http.StatusMethodNotAllowed))
+
+		request = httptest.NewRequest(http.MethodPost, WebhookPath, bytes.NewBufferString("{"))
+		request.Header.Set("Authorization", "Bearer secret")
+		response = httptest.NewRecorder()
+		receiver.ServeHTTP(response, request)
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+})
+
+var _ = Describe("LogFileReader", func() {
+
+	eventLine := func(username string) []byte {
+		line, err := json.Marshal(newEvent(username, nil, "get", nil, 200))
+		Expect(err).NotTo(HaveOccurred())
+		return append(line, '\n')
+	}
+
+	It("should record complete lines, and read rotated files from the beginning", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "audit.log")
+		Expect(os.WriteFile(path, append(append(eventLine("alice"), []byte("not json\n")...), []byte(`{"stage":`)...), 0o600)).To(Succeed())
+
+		reader := &LogFileReader{Store: NewStore(), Path: path}
+		defer reader.close()
+
+		By("reading the complete lines only")
+		Expect(reader.readNewEvents()).To(Succeed())
+		Expect(reader.Store.GetAccess("User", "", "alice")).To(HaveLen(1))
+
+		By("reading the file again once truncated by a rotation")
+		Expect(os.WriteFile(path, eventLine("bob"), 0o600)).To(Succeed())
+		Expect(reader.readNewEvents()).To(Succeed())
+		Expect(reader.Store.GetAccess("User", "", "bob")).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("LeaderPodLabeler", func() {
+
+	newPod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kuberbac", Labels: labels}}
+	}
+
+	It("should only label the pod of the leader while it runs", func() {
+		fakeClient := fake.NewClientBuilder().WithObjects(
+			newPod("previous-leader", map[string]string{LeaderPodLabel: "true", "app": "kuberbac"}),
+			newPod("leader", map[string]string{"app": "kuberbac"}),
+		).Build()
+		labeler := &LeaderPodLabeler{Client: fakeClient, APIReader: fakeClient, PodName: "leader", PodNamespace: "kuberbac"}
+
+		getLabels := func(name string) map[string]string {
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(context.Background(), client.ObjectKey{Name: name, Namespace: "kuberbac"}, pod)).To(Succeed())
+			return pod.Labels
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- labeler.Start(ctx)
+		}()
+
+		By("moving the label from the previous leader")
+		Eventually(func() map[string]string { return getLabels("leader") }).Should(HaveKeyWithValue(LeaderPodLabel, "true"))
+		Expect(getLabels("previous-leader")).To(Equal(map[string]string{"app": "kuberbac"}))
+
+		By("removing the label when stopping")
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(getLabels("leader")).To(Equal(map[string]string{"app": "kuberbac"}))
+	})
+
+	It("should refuse to start without the pod", func() {
+		labeler := &LeaderPodLabeler{Client: fake.NewClientBuilder().Build(), PodNamespace: "kuberbac"}
+		Expect(labeler.Start(context.Background())).To(MatchError(ContainSubstring("name and namespace of the pod are required")))
+	})
+})
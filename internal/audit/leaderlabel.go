@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// LeaderPodLabel is set on the pod of the leader replica. The Service sending the events of the API server
+	// to the audit webhook must select it, so events only reach the replica recording them
+	LeaderPodLabel = "kuberbac.prosimcorp.com/audit-leader"
+)
+
+// LeaderPodLabeler labels the pod of the replica once it is elected as leader, removing the label from the rest
+// of pods of its namespace, which keep it when a previous leader restarts without releasing it.
+// The label is removed from the pod when the replica stops
+type LeaderPodLabeler struct {
+	Client       client.Client
+	APIReader    client.Reader
+	PodName      string
+	PodNamespace string
+}
+
+// Start labels the pod of the replica and waits until the context is cancelled. It implements manager.Runnable
+func (l *LeaderPodLabeler) Start(ctx context.Context) error {
+
+	logger := log.FromContext(ctx).WithName("audit-leader-labeler")
+
+	if l.PodName == "" || l.PodNamespace == "" {
+		return fmt.Errorf("the name and namespace of the pod are required to label the leader of the audit webhook")
+	}
+
+	// Pods are read from the API server, as they are not cached by the manager
+	podList := &corev1.PodList{}
+	err := l.APIReader.List(ctx, podList, client.InNamespace(l.PodNamespace), client.HasLabels{LeaderPodLabel})
+	if err != nil {
+		return fmt.Errorf("error listing the pods labeled as leaders of the audit webhook: %s", err.Error())
+	}
+
+	for _, pod := range podList.Items {
+		if pod.Name == l.PodName {
+			continue
+		}
+
+		err = l.setLabel(ctx, pod.Name, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = l.setLabel(ctx, l.PodName, true)
+	if err != nil {
+		return err
+	}
+	logger.Info("Pod labeled as leader of the audit webhook", "pod", l.PodName, "label", LeaderPodLabel)
+
+	<-ctx.Done()
+
+	// The context is already cancelled, so a new one is needed to release the label
+	releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return l.setLabel(releaseCtx, l.PodName, false)
+}
+
+// NeedLeaderElection returns true, so only the leader is labeled
+func (l *LeaderPodLabeler) NeedLeaderElection() bool {
+	return true
+}
+
+// setLabel adds or removes the leader label of a pod of the namespace
+func (l *LeaderPodLabeler) setLabel(ctx context.Context, podName string, leader bool) error {
+
+	labelValue := any(nil)
+	if leader {
+		labelValue = "true"
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"labels": map[string]any{LeaderPodLabel: labelValue},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	pod := &corev1.Pod{}
+	pod.Name, pod.Namespace = podName, l.PodNamespace
+	err = l.Client.Patch(ctx, pod, client.RawPatch(types.MergePatchType, patch))
+	if err != nil {
+		return fmt.Errorf("error labeling the pod '%s' as leader of the audit webhook: %s", podName, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultPollInterval is the time between consecutive reads of the audit log file
+	DefaultPollInterval = 5 * time.Second
+)
+
+// LogFileReader follows the audit log file written by the API server, one JSON event per line, recording the events.
+// The whole file is read on start. Rotations are detected when the path points to another file, or it is truncated,
+// so the new file is read from the beginning
+type LogFileReader struct {
+	Store        *Store
+	Path         string
+	PollInterval time.Duration
+
+	//
+	file   *os.File
+	reader *bufio.Reader
+	offset int64
+}
+
+// Start follows the file until the context is cancelled. It implements manager.Runnable
+func (l *LogFileReader) Start(ctx context.Context) error {
+
+	logger := log.FromContext(ctx).WithName("audit-log")
+
+	interval := l.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer l.close()
+
+	logger.Info("Reading audit log file", "path", l.Path)
+	for {
+		err := l.readNewEvents()
+		if err != nil {
+			logger.Info("Failed to read audit log file", "path", l.Path, "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns false, so events are read on every replica
+func (l *LogFileReader) NeedLeaderElection() bool {
+	return false
+}
+
+// readNewEvents records the complete lines written since the last read, reopening the file when it was rotated
+func (l *LogFileReader) readNewEvents() (err error) {
+
+	err = l.reopenOnRotation()
+	if err != nil {
+		return err
+	}
+
+	for {
+		line, err := l.reader.ReadBytes('\n')
+
+		// Incomplete lines are read again on the next poll, once the API server finishes writing them
+		if errors.Is(err, io.EOF) {
+			_, err = l.file.Seek(l.offset, io.SeekStart)
+			l.reader.Reset(l.file)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		l.offset += int64(len(line))
+
+		event := EventT{}
+		if json.Unmarshal(line, &event) != nil {
+			continue
+		}
+		l.Store.Record(event)
+	}
+}
+
+// reopenOnRotation opens the file when it is not opened yet, or it was rotated or truncated since the last read
+func (l *LogFileReader) reopenOnRotation() (err error) {
+
+	pathInfo, err := os.Stat(l.Path)
+	if err != nil {
+		return err
+	}
+
+	if l.file != nil {
+		fileInfo, err := l.file.Stat()
+		if err == nil && os.SameFile(pathInfo, fileInfo) && pathInfo.Size() >= l.offset {
+			return nil
+		}
+		l.close()
+	}
+
+	l.file, err = os.Open(l.Path)
+	if err != nil {
+		return err
+	}
+	l.reader = bufio.NewReader(l.file)
+	l.offset = 0
+
+	return err
+}
+
+// close closes the file, when opened
+func (l *LogFileReader) close() {
+	if l.file != nil {
+		_ = l.file.Close()
+		l.file = nil
+	}
+}
@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// WebhookPath is the path where the API server must send the events, configured in its audit webhook kubeconfig
+	WebhookPath = "/audit"
+
+	// maxWebhookBodyBytes limits the size of each batch of events sent by the API server
+	maxWebhookBodyBytes = 32 * 1024 * 1024
+)
+
+// WebhookReceiver serves the endpoint of the audit webhook backend of the API server, recording the received events.
+// Events decide the suggested rules, so the endpoint is always served with TLS, reading the files 'tls.crt' and 'tls.key'
+// from CertDir, and the API server must authenticate with the bearer Token, or a client certificate signed
+// by the authority in ClientCAFile. At least one of them is required
+type WebhookReceiver struct {
+	Store        *Store
+	BindAddress  string
+	CertDir      string
+	Token        string
+	ClientCAFile string
+}
+
+// Start serves the endpoint until the context is cancelled. It implements manager.Runnable
+func (w *WebhookReceiver) Start(ctx context.Context) error {
+
+	logger := log.FromContext(ctx).WithName("audit-webhook")
+
+	if w.CertDir == "" {
+		return fmt.Errorf("a certificate directory is required to serve the audit webhook")
+	}
+	if w.Token == "" && w.ClientCAFile == "" {
+		return fmt.Errorf("a token or a client CA is required to serve the audit webhook")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(WebhookPath, w.ServeHTTP)
+
+	server := &http.Server{
+		Addr:              w.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	// Client certificates are verified when presented. Requests without them must carry the token
+	if w.ClientCAFile != "" {
+		clientCA, err := os.ReadFile(w.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("error reading the client CA of the audit webhook: %s", err.Error())
+		}
+		server.TLSConfig.ClientCAs = x509.NewCertPool()
+		if !server.TLSConfig.ClientCAs.AppendCertsFromPEM(clientCA) {
+			return fmt.Errorf("error reading the client CA of the audit webhook: no PEM certificates found")
+		}
+		server.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Serving audit webhook", "address", w.BindAddress, "path", WebhookPath)
+
+	err := server.ListenAndServeTLS(filepath.Join(w.CertDir, "tls.crt"), filepath.Join(w.CertDir, "tls.key"))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// NeedLeaderElection returns true, so events are only received by the leader, which is the replica suggesting rules.
+// Events recorded by the rest of replicas would never reach any suggestion. See LeaderPodLabeler
+func (w *WebhookReceiver) NeedLeaderElection() bool {
+	return true
+}
+
+// ServeHTTP records the events of a batch sent by the API server
+func (w *WebhookReceiver) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+
+	if !w.isAuthenticated(request) {
+		http.Error(response, "missing or invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	if request.Method != http.MethodPost {
+		http.Error(response, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	eventList := EventListT{}
+	err := json.NewDecoder(http.MaxBytesReader(response, request.Body, maxWebhookBodyBytes)).Decode(&eventList)
+	if err != nil {
+		http.Error(response, "invalid event list: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range eventList.Items {
+		w.Store.Record(event)
+	}
+
+	response.WriteHeader(http.StatusOK)
+}
+
+// isAuthenticated returns whether the request carries a verified client certificate, or the expected bearer token
+func (w *WebhookReceiver) isAuthenticated(request *http.Request) bool {
+
+	if w.ClientCAFile != "" && request.TLS != nil && len(request.TLS.VerifiedChains) > 0 {
+		return true
+	}
+
+	token, found := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	return found && w.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(w.Token)) == 1
+}
@@ -1,8 +1,49 @@
 package controller
 
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/mutationhook"
+)
+
 const (
-	DynamicClusterRoleResourceType = "DynamicClusterRole"
-	DynamicRoleBindingResourceType = "DynamicRoleBinding"
+	DynamicAccessResourceType         = "DynamicAccess"
+	DynamicClusterRoleResourceType    = "DynamicClusterRole"
+	DynamicRoleBindingResourceType    = "DynamicRoleBinding"
+	DynamicServiceAccountResourceType = "DynamicServiceAccount"
+	RBACReportResourceType            = "RBACReport"
+	RBACSuggestionResourceType        = "RBACSuggestion"
 
 	//
 	scheduleSynchronization = "Schedule synchronization for %s '%s' in: %s"
@@ -10,12 +51,695 @@ const (
 	//
 	resourceNotFoundError          = "%s '%s' resource not found. Ignoring since object must be deleted."
 	resourceRetrievalError         = "Error getting the %s '%s' from the cluster: %s"
-	resourceTargetsDeleteError     = "Failed to delete targets of %s '%s': %s"
+	resourceTargetsDeleteError     = "Failed to delete or orphan targets of %s '%s': %s"
 	resourceFinalizersUpdateError  = "Failed to update finalizer of %s '%s': %s"
 	resourceConditionUpdateError   = "Failed to update the condition on %s '%s': %s"
 	resourceSyncTimeRetrievalError = "Can not get synchronization time from the %s '%s': %s"
 	syncTargetError                = "Can not sync the target for the %s '%s': %s"
+	resourceListError              = "Failed to list %s resources: %s"
+	propagateTargetsError          = "Can not propagate the targets of %s '%s' onto member clusters: %s"
 
 	//
 	resourceFinalizer = "kuberbac.prosimcorp.com/finalizer"
+
+	// Reasons of the Events emitted after synchronizing a resource
+	eventReasonSynced     = "Synced"
+	eventReasonRendered   = "Rendered"
+	eventReasonPreviewed  = "Previewed"
+	eventReasonSyncFailed = "SyncFailed"
+	eventReasonChanged    = "Changed"
+	eventReasonExpired    = "Expired"
+
+	// eventReasonOutsideSchedule is emitted when the bindings are deleted as the schedule does not grant the access
+	eventReasonOutsideSchedule = "OutsideSchedule"
+
+	// eventReasonPropagationFailed is emitted when the generated resources can not be propagated onto member clusters
+	eventReasonPropagationFailed = "PropagationFailed"
+
+	// Verbosity levels of the logs, enabled with the flag '--zap-log-level'.
+	// Changes applied on the cluster are always logged, while the rest of levels help debugging selectors and rules
+	logLevelChanges   = 0
+	logLevelDecisions = 1
+	logLevelTraces    = 2
+
+	// listPageSize is the maximum number of objects requested to the API server on each paginated List call
+	listPageSize = 500
+
+	// syncChangeEntriesLimit is the maximum number of items kept on each list of a summary of changes
+	syncChangeEntriesLimit = 20
+
+	// fieldManager is the manager name used to own the fields of generated resources on Server-Side Apply
+	fieldManager = "kuberbac"
+
+	// Standard labels stamped on generated ClusterRoles and bindings when enabled. The hash label holds
+	// a hash of the desired state of the object, so it is not written again while nothing changes
+	managedByLabel = "app.kubernetes.io/managed-by"
+	partOfLabel    = "app.kubernetes.io/part-of"
+	hashLabel      = "kuberbac.prosimcorp.com/hash"
+
+	// operatorAnnotationPrefix is the prefix of the annotations managed by the operator, which are never propagated
+	operatorAnnotationPrefix = "kuberbac.prosimcorp.com/"
+
+	// labelValueMaxLength is the maximum length of a label value allowed by Kubernetes
+	labelValueMaxLength = 63
+
+	// Delays used by default to requeue failed synchronizations. They grow exponentially on consecutive failures
+	DefaultRetryBaseDelay = 5 * time.Millisecond
+	DefaultRetryMaxDelay  = 5 * time.Minute
+
+	// Synchronization schedule used by default. Resources not setting spec.synchronization.time are synchronized
+	// on the default time, randomly shifted by the jitter fraction, and never more often than the min time
+	DefaultSyncTime    = 5 * time.Minute
+	DefaultSyncJitter  = 0.1
+	DefaultMinSyncTime = 10 * time.Second
+
+	// OwnershipModeAnnotations tracks generated resources only by reference annotations.
+	// Their cleanup is always done by the finalizer of the owner
+	OwnershipModeAnnotations = "annotations"
+
+	// OwnershipModeReferences tracks generated resources by reference annotations, and also sets OwnerReferences
+	// on those living in the same namespace as the owner, so the Kubernetes garbage collector can delete them.
+	// Cluster-scoped or cross-namespace resources can not be referenced, so they are still cleaned by the finalizer
+	OwnershipModeReferences = "references"
 )
+
+var (
+	// errInvalidSpec is returned when a resource can not be synchronized because of its spec.
+	// Retrying is useless until the spec changes, so those failures are only surfaced on the status
+	errInvalidSpec = errors.New("invalid spec")
+
+	// errInvalidSelector is returned when some selector of the spec can not be evaluated.
+	// It wraps errInvalidSpec, so it is not retried either
+	errInvalidSelector = fmt.Errorf("%w: invalid selector", errInvalidSpec)
+
+	// errInvalidRegex is returned when some regular expression of the selectors can not be compiled.
+	// It wraps errInvalidSelector, so it is not retried either
+	errInvalidRegex = fmt.Errorf("%w: invalid regular expression", errInvalidSelector)
+
+	// errTargetWriteFailed is returned when a generated resource can not be written into the cluster
+	errTargetWriteFailed = errors.New("target write failed")
+
+	// errDiscoveryFailed is returned when the resources available in the cluster can not be discovered
+	errDiscoveryFailed = errors.New("discovery failed")
+
+	// errMutationHookFailed is returned when the mutation hook can not be reached, or it rejects a generated resource
+	errMutationHookFailed = errors.New("mutation hook failed")
+)
+
+// newRetryRateLimiter returns the rate limiter used to requeue failed synchronizations.
+// Each resource is delayed exponentially on consecutive failures, up to the max delay,
+// while the overall rate is limited so a burst of failures never hot-loops against the API server
+func newRetryRateLimiter(baseDelay, maxDelay time.Duration) workqueue.RateLimiter {
+
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// SyncScheduleT defines when resources are synchronized again after a synchronization
+type SyncScheduleT struct {
+
+	// DefaultTime is used for the resources not setting spec.synchronization.time
+	DefaultTime time.Duration
+
+	// Jitter is the fraction of the time randomly added or subtracted on each requeue,
+	// so resources created at once do not synchronize at the same time
+	Jitter float64
+
+	// MinTime is the shortest time between synchronizations, protecting the API server from tiny intervals
+	MinTime time.Duration
+}
+
+// GetRequeueTime returns the time until the next synchronization of a resource: its spec.synchronization.time,
+// or the default time when not set, shifted by the jitter and never shorter than the min time.
+// Invalid times return the default one along with the error, so the resource keeps being synchronized
+func (s *SyncScheduleT) GetRequeueTime(synchronization kuberbacv1alpha1.SynchronizationT) (requeueTime time.Duration, err error) {
+
+	defaultTime := s.DefaultTime
+	if defaultTime <= 0 {
+		defaultTime = DefaultSyncTime
+	}
+
+	requeueTime = defaultTime
+	if synchronization.Time != "" {
+		requeueTime, err = time.ParseDuration(synchronization.Time)
+		if err != nil || requeueTime <= 0 {
+			requeueTime = defaultTime
+			err = fmt.Errorf("invalid synchronization time '%s': using the default of %s", synchronization.Time,
+				defaultTime.String())
+		}
+	}
+
+	if s.Jitter > 0 {
+		requeueTime += time.Duration((rand.Float64()*2 - 1) * s.Jitter * float64(requeueTime))
+	}
+
+	return max(requeueTime, s.MinTime), err
+}
+
+// syncErrorResult returns the result of a reconciliation whose synchronization failed.
+// Invalid specs are not requeued, as a change on the resource triggers a new reconciliation.
+// The rest of errors, such as throttling or conflicts, are returned so the request is requeued with backoff
+func syncErrorResult(err error) (result ctrl.Result, resultErr error) {
+
+	if errors.Is(err, errInvalidSpec) {
+		return result, resultErr
+	}
+
+	return result, err
+}
+
+// getNextSyncTime returns the time when a reconciliation ending with the given result requeues the resource.
+// It is nil when the time is not known in advance: errors are retried with backoff,
+// and results without requeue wait for a change on the resource
+func getNextSyncTime(result ctrl.Result, err error) *metav1.Time {
+
+	if err != nil || result.RequeueAfter <= 0 {
+		return nil
+	}
+
+	return &metav1.Time{Time: time.Now().Add(result.RequeueAfter)}
+}
+
+// getSyncDuration returns the time spent on a synchronization, rounded so it is readable on the status
+func getSyncDuration(syncDuration time.Duration) *metav1.Duration {
+	return &metav1.Duration{Duration: syncDuration.Round(time.Millisecond)}
+}
+
+// syncFailureCondition returns the condition of a failed synchronization, with a reason for each kind of failure.
+// The error is part of the message, so the offending field is known without digging into the logs
+func syncFailureCondition(err error) metav1.Condition {
+
+	reason, message := globals.ConditionReasonKubernetesApiCallErrorType, globals.ConditionReasonKubernetesApiCallErrorMessage
+	switch {
+	case errors.Is(err, errInvalidRegex):
+		reason, message = globals.ConditionReasonInvalidRegexType, globals.ConditionReasonInvalidRegexMessage
+	case errors.Is(err, errInvalidSelector):
+		reason, message = globals.ConditionReasonSelectorErrorType, globals.ConditionReasonSelectorErrorMessage
+	case errors.Is(err, errInvalidSpec):
+		reason, message = globals.ConditionReasonInvalidSpecType, globals.ConditionReasonInvalidSpecMessage
+	case errors.Is(err, errTargetWriteFailed):
+		reason, message = globals.ConditionReasonTargetWriteFailedType, globals.ConditionReasonTargetWriteFailedMessage
+	case errors.Is(err, errDiscoveryFailed):
+		reason, message = globals.ConditionReasonDiscoveryFailedType, globals.ConditionReasonDiscoveryFailedMessage
+	case errors.Is(err, errMutationHookFailed):
+		reason, message = globals.ConditionReasonMutationHookFailedType, globals.ConditionReasonMutationHookFailedMessage
+	case errors.Is(err, errAmbiguousRoleRef):
+		reason, message = globals.ConditionReasonAmbiguousRoleRefType, globals.ConditionReasonAmbiguousRoleRefMessage
+	}
+
+	return globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionFalse, reason, message+": "+err.Error())
+}
+
+// mutateResource sends the object to the mutation hook, when configured, replacing it by the returned one.
+// It must be called right before applying the object, once everything else is set on it
+func mutateResource(ctx context.Context, hook *mutationhook.Hook, object client.Object) (err error) {
+
+	if hook == nil {
+		return nil
+	}
+
+	err = hook.Mutate(ctx, object)
+	if err != nil {
+		return fmt.Errorf("%w: error mutating %s '%s': %s", errMutationHookFailed,
+			object.GetObjectKind().GroupVersionKind().Kind, object.GetName(), err.Error())
+	}
+
+	return nil
+}
+
+// applyResource creates the object when it does not exist in the cluster, or applies it
+// using Server-Side Apply otherwise. This way, fields owned by other writers are kept
+// and drifts on the fields owned by this operator are healed on each synchronization.
+// Attention: the TypeMeta of the object MUST be filled, as it is required by Server-Side Apply.
+func applyResource(ctx context.Context, c client.Client, object client.Object) (err error) {
+
+	existentObject := object.DeepCopyObject().(client.Object)
+	err = c.Get(ctx, client.ObjectKeyFromObject(object), existentObject)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		err = c.Create(ctx, object, client.FieldOwner(fieldManager))
+
+		// Another writer created it in the meantime, such as a second replica of the operator, so apply it instead
+		if !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	} else if isUpToDate(object, existentObject) {
+		return nil
+	} else if isRoleRefChanged(object, existentObject) {
+
+		// The role referenced by a binding can not be changed, so the binding is replaced
+		uid := existentObject.GetUID()
+		err = c.Delete(ctx, existentObject, client.Preconditions{UID: &uid})
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+
+		return c.Create(ctx, object, client.FieldOwner(fieldManager))
+	}
+
+	return c.Patch(ctx, object, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
+
+// isRoleRefChanged returns whether the desired binding references a different role than the existent one
+func isRoleRefChanged(desiredObject, existentObject client.Object) bool {
+
+	switch typedObject := desiredObject.(type) {
+	case *rbacv1.ClusterRoleBinding:
+		return typedObject.RoleRef != existentObject.(*rbacv1.ClusterRoleBinding).RoleRef
+	case *rbacv1.RoleBinding:
+		return typedObject.RoleRef != existentObject.(*rbacv1.RoleBinding).RoleRef
+	}
+
+	return false
+}
+
+// setStandardLabels stamps a generated ClusterRole or binding with the labels recommended by Kubernetes,
+// identifying the operator and the resource generating it, and with the hash of its desired state.
+// Labels are copied, as the maps of the objects are usually shared with the spec of their owner
+func setStandardLabels(object client.Object, ownerName string) (err error) {
+
+	labels := maps.Clone(object.GetLabels())
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[managedByLabel] = fieldManager
+	labels[partOfLabel] = strings.TrimRight(ownerName[:min(len(ownerName), labelValueMaxLength)], "-.")
+	delete(labels, hashLabel)
+	object.SetLabels(labels)
+
+	content, found := getObjectContent(object)
+	if !found {
+		return fmt.Errorf("standard labels are not supported on %T", object)
+	}
+
+	data, err := json.Marshal([]any{labels, object.GetAnnotations(), object.GetOwnerReferences(), content})
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	labels[hashLabel] = hex.EncodeToString(hash[:])[:16]
+
+	return err
+}
+
+// propagateAnnotations copies the annotations of the owner matching the given keys onto a generated object,
+// so GitOps tools can track it. Annotations already set on the object are kept.
+// Annotations are copied, as the maps of the objects are usually shared with the spec of their owner
+func propagateAnnotations(owner, object metav1.Object, keys []string) {
+
+	propagatedAnnotations := getPropagatedAnnotations(owner, keys)
+	if len(propagatedAnnotations) == 0 {
+		return
+	}
+
+	annotations := maps.Clone(object.GetAnnotations())
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range propagatedAnnotations {
+		if _, found := annotations[key]; !found {
+			annotations[key] = value
+		}
+	}
+	object.SetAnnotations(annotations)
+}
+
+// getPropagatedAnnotations returns the annotations of the owner matching the given keys.
+// Keys ending with '*' match every annotation starting with them. Annotations of the operator are never returned
+func getPropagatedAnnotations(owner metav1.Object, keys []string) (annotations map[string]string) {
+
+	annotations = map[string]string{}
+	for key, value := range owner.GetAnnotations() {
+
+		if strings.HasPrefix(key, operatorAnnotationPrefix) {
+			continue
+		}
+
+		if slices.ContainsFunc(keys, func(propagatedKey string) bool {
+			prefix, isPrefix := strings.CutSuffix(propagatedKey, "*")
+			return key == propagatedKey || (isPrefix && strings.HasPrefix(key, prefix))
+		}) {
+			annotations[key] = value
+		}
+	}
+
+	return annotations
+}
+
+// propagatedAnnotationsChangedPredicate returns a predicate matching the updates of resources changing
+// the annotations propagated onto the resources they generate, which do not change the generation
+func propagatedAnnotationsChangedPredicate(keys []string) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return len(keys) > 0 && !maps.Equal(
+				getPropagatedAnnotations(e.ObjectOld, keys), getPropagatedAnnotations(e.ObjectNew, keys))
+		},
+	}
+}
+
+// getObjectContent returns the fields, other than metadata, written by the operator on a generated role,
+// binding, ServiceAccount or ConfigMap. Rules of aggregated ClusterRoles are filled by Kubernetes,
+// so only their aggregation rule is returned
+func getObjectContent(object client.Object) (content any, found bool) {
+
+	switch typedObject := object.(type) {
+	case *rbacv1.ClusterRole:
+		if typedObject.AggregationRule != nil {
+			return typedObject.AggregationRule, true
+		}
+		return typedObject.Rules, true
+	case *rbacv1.Role:
+		return typedObject.Rules, true
+	case *rbacv1.ClusterRoleBinding:
+		return []any{typedObject.RoleRef, typedObject.Subjects}, true
+	case *rbacv1.RoleBinding:
+		return []any{typedObject.RoleRef, typedObject.Subjects}, true
+	case *corev1.ServiceAccount:
+		return content, true
+	case *corev1.ConfigMap:
+		return []any{typedObject.Data, typedObject.BinaryData}, true
+	}
+
+	return content, false
+}
+
+// isUpToDate returns whether applying the desired object would not change the existent one, so writing it can be skipped.
+// Objects are compared semantically: labels, annotations and owner references of the desired object must be present
+// on the existent one, and the rest of fields written by the operator must be equal, so drifts made by other writers
+// are still healed. Objects stamped with a hash label are not compared when it differs
+func isUpToDate(desiredObject, existentObject client.Object) bool {
+
+	hash := desiredObject.GetLabels()[hashLabel]
+	if hash != "" && existentObject.GetLabels()[hashLabel] != hash {
+		return false
+	}
+
+	if !globals.IsSubset(desiredObject.GetLabels(), existentObject.GetLabels()) ||
+		!globals.IsSubset(desiredObject.GetAnnotations(), existentObject.GetAnnotations()) {
+		return false
+	}
+
+	for _, ownerReference := range desiredObject.GetOwnerReferences() {
+		if !slices.ContainsFunc(existentObject.GetOwnerReferences(), func(existentOwnerReference metav1.OwnerReference) bool {
+			return existentOwnerReference.UID == ownerReference.UID
+		}) {
+			return false
+		}
+	}
+
+	// Objects whose content is unknown are always written
+	desiredContent, found := getObjectContent(desiredObject)
+	if !found {
+		return false
+	}
+	existentContent, _ := getObjectContent(existentObject)
+	return equality.Semantic.DeepEqual(desiredContent, existentContent)
+}
+
+// updateResource applies mutateFunc on the object and updates it. On conflicts, caused by concurrent writers
+// such as other replicas of the operator or mutating admission webhooks, the object is read again
+// and mutateFunc is applied on the fresh copy before retrying. Reads are served by the cache, so retries
+// are spaced with a backoff to let it catch up
+func updateResource(ctx context.Context, c client.Client, object client.Object, mutateFunc func()) (err error) {
+
+	firstAttempt := true
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if !firstAttempt {
+			err = c.Get(ctx, client.ObjectKeyFromObject(object), object)
+			if err != nil {
+				return err
+			}
+		}
+		firstAttempt = false
+
+		mutateFunc()
+		return c.Update(ctx, object)
+	})
+}
+
+// updateResourceStatus updates the status of the object. On conflicts, the latest version of the object is read again,
+// the status computed on this one is set on it, and the update is retried. This way, optimistic concurrency
+// is kept: nothing is written over a version of the object that was not read first
+func updateResourceStatus(ctx context.Context, c client.Client, object client.Object) (err error) {
+
+	firstAttempt := true
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if firstAttempt {
+			firstAttempt = false
+			return c.Status().Update(ctx, object)
+		}
+
+		latestObject := object.DeepCopyObject().(client.Object)
+		err = c.Get(ctx, client.ObjectKeyFromObject(object), latestObject)
+		if err != nil {
+			return err
+		}
+
+		err = copyStatus(latestObject, object.DeepCopyObject())
+		if err != nil {
+			return err
+		}
+
+		err = c.Status().Update(ctx, latestObject)
+		if err != nil {
+			return err
+		}
+
+		object.SetResourceVersion(latestObject.GetResourceVersion())
+		return err
+	})
+}
+
+// copyStatus sets the 'Status' field of an object to the one of another object of the same type.
+// Every resource of kuberbac keeps the computed state on that field, but objects without it are reported
+// instead of panicking
+func copyStatus(destination, source runtime.Object) error {
+
+	destinationValue := reflect.ValueOf(destination)
+	sourceValue := reflect.ValueOf(source)
+	if destinationValue.Kind() != reflect.Pointer || sourceValue.Kind() != reflect.Pointer ||
+		destinationValue.Elem().Kind() != reflect.Struct || sourceValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("error copying the status of %T: it is not a pointer to a struct", source)
+	}
+
+	destinationStatus := destinationValue.Elem().FieldByName("Status")
+	sourceStatus := sourceValue.Elem().FieldByName("Status")
+	if !destinationStatus.IsValid() || !sourceStatus.IsValid() ||
+		!destinationStatus.CanSet() || sourceStatus.Type() != destinationStatus.Type() {
+		return fmt.Errorf("error copying the status of %T: it has no settable 'Status' field", source)
+	}
+
+	destinationStatus.Set(sourceStatus)
+	return nil
+}
+
+// listInPages lists objects in pages of listPageSize items, calling pageFunc after retrieving each one into the list.
+// This way, huge collections are never loaded in memory at once.
+// Attention: the cache does not support pagination, so it MUST only be used for reads served by the API server,
+// such as the ones of unstructured objects
+func listInPages(ctx context.Context, c client.Reader, list client.ObjectList, pageFunc func() error, opts ...client.ListOption) (err error) {
+
+	opts = append(opts, client.Limit(listPageSize))
+	continueToken := ""
+	for {
+		err = c.List(ctx, list, append(opts, client.Continue(continueToken))...)
+		if err != nil {
+			return err
+		}
+
+		err = pageFunc()
+		if err != nil {
+			return err
+		}
+
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return err
+		}
+	}
+}
+
+// setOwnerReference sets the owner as controller of the object when the ownership mode is 'references'.
+// This is only done when both of them live in the same namespace, as Kubernetes does not allow
+// cross-namespace or namespaced-to-cluster-scoped owner references
+func setOwnerReference(ownershipMode string, owner, object metav1.Object, scheme *runtime.Scheme) (err error) {
+
+	if ownershipMode != OwnershipModeReferences ||
+		object.GetNamespace() == "" || object.GetNamespace() != owner.GetNamespace() {
+		return err
+	}
+
+	return controllerutil.SetControllerReference(owner, object, scheme)
+}
+
+// adoptResource upgrades an already existing object, tracked only by reference annotations,
+// by setting the owner reference on it when the ownership mode is 'references'
+func adoptResource(ctx context.Context, c client.Client, ownershipMode string, owner client.Object, object client.Object) (err error) {
+
+	if ownershipMode != OwnershipModeReferences ||
+		object.GetNamespace() == "" || object.GetNamespace() != owner.GetNamespace() {
+		return err
+	}
+
+	for _, ownerReference := range object.GetOwnerReferences() {
+		if ownerReference.UID == owner.GetUID() {
+			return err
+		}
+	}
+
+	patch := client.MergeFrom(object.DeepCopyObject().(client.Object))
+	err = controllerutil.SetControllerReference(owner, object, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	return c.Patch(ctx, object, patch)
+}
+
+// releaseResource deletes an object generated by the owner, or orphans it when the deletion policy is 'Orphan'.
+// Orphaned objects lose the reference annotations and the owner reference, so they are kept in the cluster
+// untracked: neither the finalizer nor the Kubernetes garbage collector will delete them
+func releaseResource(ctx context.Context, c client.Client, deletionPolicy string, owner client.Object, object client.Object,
+	referenceAnnotations map[string]string) (err error) {
+
+	if deletionPolicy != kuberbacv1alpha1.DeletionPolicyOrphan {
+		return client.IgnoreNotFound(c.Delete(ctx, object))
+	}
+
+	patch := client.MergeFrom(object.DeepCopyObject().(client.Object))
+
+	annotations := object.GetAnnotations()
+	for key := range referenceAnnotations {
+		delete(annotations, key)
+	}
+	object.SetAnnotations(annotations)
+
+	object.SetOwnerReferences(slices.DeleteFunc(object.GetOwnerReferences(), func(ownerReference metav1.OwnerReference) bool {
+		return ownerReference.UID == owner.GetUID()
+	}))
+
+	return client.IgnoreNotFound(c.Patch(ctx, object, patch))
+}
+
+// ownerAnnotationsMapFunc returns a function that maps a generated object to a request for its owner,
+// read from the reference annotations. Objects not generated by a resource of the given kind are ignored.
+// It is used to watch generated resources, so drifts on them are repaired without waiting for the next synchronization
+func ownerAnnotationsMapFunc(ownerKind string) handler.MapFunc {
+	return func(_ context.Context, object client.Object) []reconcile.Request {
+
+		annotations := object.GetAnnotations()
+		if annotations["kuberbac.prosimcorp.com/owner-kind"] != ownerKind ||
+			annotations["kuberbac.prosimcorp.com/owner-name"] == "" {
+			return nil
+		}
+
+		return []reconcile.Request{{
+			NamespacedName: types.NamespacedName{
+				Name:      annotations["kuberbac.prosimcorp.com/owner-name"],
+				Namespace: annotations["kuberbac.prosimcorp.com/owner-namespace"],
+			},
+		}}
+	}
+}
+
+// diffStrings returns the items only present in the next list, and those only present in the previous one.
+// Duplicated items are ignored and results are sorted
+func diffStrings(previous, next []string) (added, removed []string) {
+
+	previousItems := make(map[string]struct{}, len(previous))
+	for _, item := range previous {
+		previousItems[item] = struct{}{}
+	}
+
+	nextItems := make(map[string]struct{}, len(next))
+	for _, item := range next {
+		nextItems[item] = struct{}{}
+	}
+
+	for item := range nextItems {
+		if _, found := previousItems[item]; !found {
+			added = append(added, item)
+		}
+	}
+
+	for item := range previousItems {
+		if _, found := nextItems[item]; !found {
+			removed = append(removed, item)
+		}
+	}
+
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
+
+// newSyncChange summarizes the differences on the rules and subjects of the generated resources
+// before and after a synchronization. It returns nil when nothing changed
+func newSyncChange(previousRules, nextRules, previousSubjects, nextSubjects []string) (change *kuberbacv1alpha1.SyncChangeT) {
+
+	addedRules, removedRules := diffStrings(previousRules, nextRules)
+	addedSubjects, removedSubjects := diffStrings(previousSubjects, nextSubjects)
+
+	if len(addedRules) == 0 && len(removedRules) == 0 && len(addedSubjects) == 0 && len(removedSubjects) == 0 {
+		return change
+	}
+
+	truncate := func(entries []string) []string {
+		return entries[:min(len(entries), syncChangeEntriesLimit)]
+	}
+
+	return &kuberbacv1alpha1.SyncChangeT{
+		Time: metav1.Now(),
+
+		AddedRulesCount:   len(addedRules),
+		RemovedRulesCount: len(removedRules),
+		AddedRules:        truncate(addedRules),
+		RemovedRules:      truncate(removedRules),
+
+		AddedSubjectsCount:   len(addedSubjects),
+		RemovedSubjectsCount: len(removedSubjects),
+		AddedSubjects:        truncate(addedSubjects),
+		RemovedSubjects:      truncate(removedSubjects),
+	}
+}
+
+// syncChangeMessage returns a human-readable description of a summary of changes, to be emitted as an Event
+func syncChangeMessage(change *kuberbacv1alpha1.SyncChangeT) string {
+
+	describe := func(count int, entries []string, description string) string {
+		message := fmt.Sprintf("%d %s: %s", count, description, strings.Join(entries, "; "))
+		if count > len(entries) {
+			message += "; ..."
+		}
+		return message
+	}
+
+	messages := []string{}
+	if change.AddedRulesCount > 0 {
+		messages = append(messages, describe(change.AddedRulesCount, change.AddedRules, "rules added"))
+	}
+	if change.RemovedRulesCount > 0 {
+		messages = append(messages, describe(change.RemovedRulesCount, change.RemovedRules, "rules removed"))
+	}
+	if change.AddedSubjectsCount > 0 {
+		messages = append(messages, describe(change.AddedSubjectsCount, change.AddedSubjects, "subjects added"))
+	}
+	if change.RemovedSubjectsCount > 0 {
+		messages = append(messages, describe(change.RemovedSubjectsCount, change.RemovedSubjects, "subjects removed"))
+	}
+
+	return strings.Join(messages, ". ")
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+var _ = Describe("Summaries of changes", func() {
+
+	DescribeTable("When diffing lists of strings",
+		func(previous, next, expectedAdded, expectedRemoved []string) {
+			added, removed := diffStrings(previous, next)
+			Expect(added).To(Equal(expectedAdded))
+			Expect(removed).To(Equal(expectedRemoved))
+		},
+		Entry("should return only added items",
+			[]string{"a"}, []string{"c", "a", "b"}, []string{"b", "c"}, nil),
+		Entry("should return only removed items",
+			[]string{"c", "a", "b"}, []string{"a"}, nil, []string{"b", "c"}),
+		Entry("should return added and removed items",
+			[]string{"a", "b"}, []string{"b", "c"}, []string{"c"}, []string{"a"}),
+		Entry("should return nothing when lists are equal in any order",
+			[]string{"a", "b"}, []string{"b", "a"}, nil, nil),
+		Entry("should ignore duplicated items",
+			[]string{"a", "a"}, []string{"b", "b"}, []string{"b"}, []string{"a"}),
+	)
+
+	It("should not summarize a synchronization without changes", func() {
+		Expect(newSyncChange([]string{"rule"}, []string{"rule"}, []string{"subject"}, []string{"subject"})).To(BeNil())
+	})
+
+	It("should summarize the changes on rules and subjects", func() {
+		change := newSyncChange([]string{"rule-a"}, []string{"rule-b"}, nil, []string{"subject-a"})
+		Expect(change).NotTo(BeNil())
+		Expect(change.AddedRulesCount).To(Equal(1))
+		Expect(change.AddedRules).To(Equal([]string{"rule-b"}))
+		Expect(change.RemovedRulesCount).To(Equal(1))
+		Expect(change.RemovedRules).To(Equal([]string{"rule-a"}))
+		Expect(change.AddedSubjectsCount).To(Equal(1))
+		Expect(change.AddedSubjects).To(Equal([]string{"subject-a"}))
+		Expect(change.RemovedSubjectsCount).To(BeZero())
+		Expect(change.RemovedSubjects).To(BeEmpty())
+
+		Expect(syncChangeMessage(change)).To(Equal(
+			"1 rules added: rule-b. 1 rules removed: rule-a. 1 subjects added: subject-a"))
+	})
+
+	It("should truncate long lists of changes, keeping their counts", func() {
+		nextRules := []string{}
+		for index := 0; index < syncChangeEntriesLimit+5; index++ {
+			nextRules = append(nextRules, fmt.Sprintf("rule-%02d", index))
+		}
+
+		change := newSyncChange(nil, nextRules, nil, nil)
+		Expect(change).NotTo(BeNil())
+		Expect(change.AddedRulesCount).To(Equal(syncChangeEntriesLimit + 5))
+		Expect(change.AddedRules).To(Equal(nextRules[:syncChangeEntriesLimit]))
+
+		message := syncChangeMessage(change)
+		Expect(message).To(HavePrefix(fmt.Sprintf("%d rules added: rule-00; rule-01;", syncChangeEntriesLimit+5)))
+		Expect(message).To(HaveSuffix(fmt.Sprintf("rule-%02d; ...", syncChangeEntriesLimit-1)))
+		Expect(message).NotTo(ContainSubstring(fmt.Sprintf("rule-%02d", syncChangeEntriesLimit)))
+	})
+
+	DescribeTable("When formatting PolicyRules",
+		func(rule rbacv1.PolicyRule, expected string) {
+			Expect(FormatPolicyRule(rule)).To(Equal(expected))
+		},
+		Entry("should format resource rules",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+			`apiGroups=[""] resources=["pods"] verbs=["get" "list"]`),
+		Entry("should format resource rules with names",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"token"}, Verbs: []string{"get"}},
+			`apiGroups=[""] resources=["secrets"] verbs=["get"] resourceNames=["token"]`),
+		Entry("should format non-resource rules",
+			rbacv1.PolicyRule{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+			`nonResourceURLs=["/healthz"] verbs=["get"]`),
+	)
+
+	It("should format namespaced and cluster-scoped subjects", func() {
+		Expect(FormatSubjects([]rbacv1.Subject{
+			{Kind: rbacv1.ServiceAccountKind, Name: "builder", Namespace: "ci"},
+			{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "developers"},
+		})).To(Equal([]string{"ServiceAccount:ci/builder", "Group:developers"}))
+	})
+
+	Context("When recording the subjects changed by a DynamicRoleBinding", func() {
+
+		var recorder *record.FakeRecorder
+		var reconciler *DynamicRoleBindingReconciler
+		var resource *kuberbacv1alpha1.DynamicRoleBinding
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &DynamicRoleBindingReconciler{Recorder: recorder}
+			resource = &kuberbacv1alpha1.DynamicRoleBinding{}
+		})
+
+		It("should store the change and emit an Event", func() {
+			reconciler.RecordSubjectChanges(context.Background(), resource,
+				[]string{"Group:developers"}, []string{"Group:developers", "User:jane"})
+
+			Expect(resource.Status.LastChange).NotTo(BeNil())
+			Expect(resource.Status.LastChange.AddedSubjects).To(Equal([]string{"User:jane"}))
+			Expect(resource.Status.LastChange.RemovedSubjects).To(BeEmpty())
+			Expect(recorder.Events).To(Receive(Equal("Normal " + eventReasonChanged + " 1 subjects added: User:jane")))
+		})
+
+		It("should record nothing when subjects did not change", func() {
+			reconciler.RecordSubjectChanges(context.Background(), resource,
+				[]string{"Group:developers"}, []string{"Group:developers"})
+
+			Expect(resource.Status.LastChange).To(BeNil())
+			Expect(recorder.Events).NotTo(Receive())
+		})
+	})
+})
+
+// newFakeClientBuilder returns a builder of fake clients knowing about the kuberbac resources.
+// Specs using it do not need the test environment
+func newFakeClientBuilder() *fake.ClientBuilder {
+	fakeScheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(fakeScheme)).To(Succeed())
+	Expect(kuberbacv1alpha1.AddToScheme(fakeScheme)).To(Succeed())
+
+	return fake.NewClientBuilder().WithScheme(fakeScheme)
+}
+
+// newFakeApplyClient returns a fake client holding the given objects. The fake client does not implement
+// Server-Side Apply, so applied objects replace the existing ones
+func newFakeApplyClient(objects ...client.Object) client.Client {
+	return newFakeClientBuilder().
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, object client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if patch.Type() != types.ApplyPatchType {
+					return c.Patch(ctx, object, patch, opts...)
+				}
+
+				existentObject := object.DeepCopyObject().(client.Object)
+				err := c.Get(ctx, client.ObjectKeyFromObject(object), existentObject)
+				if err != nil {
+					return err
+				}
+				object.SetResourceVersion(existentObject.GetResourceVersion())
+				return c.Update(ctx, object)
+			},
+		}).
+		Build()
+}
+
+var _ = Describe("Release of generated resources", func() {
+
+	ctx := context.Background()
+
+	// generatedMeta returns the metadata of a resource generated by the owner, carrying a foreign annotation
+	// that must be kept after the release
+	generatedMeta := func(owner client.Object, namespace, name string) metav1.ObjectMeta {
+		apiVersion, kind := owner.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				"kuberbac.prosimcorp.com/owner-apiversion": apiVersion,
+				"kuberbac.prosimcorp.com/owner-kind":       kind,
+				"kuberbac.prosimcorp.com/owner-name":       owner.GetName(),
+				"kuberbac.prosimcorp.com/owner-namespace":  owner.GetNamespace(),
+				"description": "kept after the release",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Name:       owner.GetName(),
+				UID:        owner.GetUID(),
+			}},
+		}
+	}
+
+	ownerMeta := metav1.ObjectMeta{Name: "release-owner", Namespace: "default", UID: types.UID("release-owner-uid")}
+	ownerTypeMeta := func(kind string) metav1.TypeMeta {
+		return metav1.TypeMeta{APIVersion: kuberbacv1alpha1.GroupVersion.String(), Kind: kind}
+	}
+
+	// releaseCase builds the owner with the given deletion policy, the resources generated by it,
+	// and a function releasing them through the reconciler of the owner
+	type releaseCase func(deletionPolicy string) (owner client.Object, generated []client.Object,
+		deleteTargets func(client.Client) error)
+
+	dynamicAccess := func(deletionPolicy string) (client.Object, []client.Object, func(client.Client) error) {
+		owner := &kuberbacv1alpha1.DynamicAccess{TypeMeta: ownerTypeMeta("DynamicAccess"), ObjectMeta: ownerMeta}
+		owner.Spec.DeletionPolicy = deletionPolicy
+
+		role := &rbacv1.Role{ObjectMeta: generatedMeta(owner, "default", "release-generated")}
+		roleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: generatedMeta(owner, "default", "release-generated"),
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name},
+		}
+
+		return owner, []client.Object{role, roleBinding}, func(c client.Client) error {
+			return (&DynamicAccessReconciler{Client: c}).DeleteTargets(ctx, owner)
+		}
+	}
+
+	dynamicClusterRole := func(deletionPolicy string) (client.Object, []client.Object, func(client.Client) error) {
+		owner := &kuberbacv1alpha1.DynamicClusterRole{TypeMeta: ownerTypeMeta("DynamicClusterRole"), ObjectMeta: ownerMeta}
+		owner.Spec.DeletionPolicy = deletionPolicy
+
+		clusterRole := &rbacv1.ClusterRole{ObjectMeta: generatedMeta(owner, "", "release-generated")}
+		explanationConfigMap := &corev1.ConfigMap{
+			ObjectMeta: generatedMeta(owner, "default", owner.Name+explanationConfigMapSuffix),
+		}
+
+		return owner, []client.Object{clusterRole, explanationConfigMap}, func(c client.Client) error {
+			return (&DynamicClusterRoleReconciler{Client: c}).DeleteTargets(ctx, owner)
+		}
+	}
+
+	dynamicRoleBinding := func(deletionPolicy string) (client.Object, []client.Object, func(client.Client) error) {
+		owner := &kuberbacv1alpha1.DynamicRoleBinding{TypeMeta: ownerTypeMeta("DynamicRoleBinding"), ObjectMeta: ownerMeta}
+		owner.Spec.DeletionPolicy = deletionPolicy
+
+		roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"}
+		clusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: generatedMeta(owner, "", "release-generated"), RoleRef: roleRef}
+		roleBinding := &rbacv1.RoleBinding{ObjectMeta: generatedMeta(owner, "default", "release-generated"), RoleRef: roleRef}
+
+		return owner, []client.Object{clusterRoleBinding, roleBinding}, func(c client.Client) error {
+			return (&DynamicRoleBindingReconciler{Client: c}).DeleteTargets(ctx, owner)
+		}
+	}
+
+	dynamicServiceAccount := func(deletionPolicy string) (client.Object, []client.Object, func(client.Client) error) {
+		owner := &kuberbacv1alpha1.DynamicServiceAccount{TypeMeta: ownerTypeMeta("DynamicServiceAccount"), ObjectMeta: ownerMeta}
+		owner.Spec.DeletionPolicy = deletionPolicy
+
+		serviceAccount := &corev1.ServiceAccount{ObjectMeta: generatedMeta(owner, "default", "release-generated")}
+
+		return owner, []client.Object{serviceAccount}, func(c client.Client) error {
+			return (&DynamicServiceAccountReconciler{Client: c}).DeleteTargets(ctx, owner)
+		}
+	}
+
+	DescribeTable("should orphan the generated resources untracked when the deletion policy is 'Orphan'",
+		func(newCase releaseCase) {
+			owner, generated, deleteTargets := newCase(kuberbacv1alpha1.DeletionPolicyOrphan)
+			fakeClient := newFakeClientBuilder().WithObjects(append(generated, owner)...).Build()
+
+			Expect(deleteTargets(fakeClient)).To(Succeed())
+			Expect(fakeClient.Delete(ctx, owner)).To(Succeed())
+
+			for _, object := range generated {
+				released := object.DeepCopyObject().(client.Object)
+				Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(object), released)).To(Succeed())
+				Expect(released.GetAnnotations()).To(Equal(map[string]string{"description": "kept after the release"}))
+				Expect(released.GetOwnerReferences()).To(BeEmpty())
+			}
+		},
+		Entry("of a DynamicAccess", releaseCase(dynamicAccess)),
+		Entry("of a DynamicClusterRole", releaseCase(dynamicClusterRole)),
+		Entry("of a DynamicRoleBinding", releaseCase(dynamicRoleBinding)),
+		Entry("of a DynamicServiceAccount", releaseCase(dynamicServiceAccount)),
+	)
+
+	DescribeTable("should delete the generated resources when the deletion policy is 'Delete'",
+		func(newCase releaseCase) {
+			owner, generated, deleteTargets := newCase(kuberbacv1alpha1.DeletionPolicyDelete)
+			fakeClient := newFakeClientBuilder().WithObjects(append(generated, owner)...).Build()
+
+			Expect(deleteTargets(fakeClient)).To(Succeed())
+
+			for _, object := range generated {
+				err := fakeClient.Get(ctx, client.ObjectKeyFromObject(object), object.DeepCopyObject().(client.Object))
+				Expect(errors.IsNotFound(err)).To(BeTrue(), "%T '%s' should be deleted", object, object.GetName())
+			}
+		},
+		Entry("of a DynamicAccess", releaseCase(dynamicAccess)),
+		Entry("of a DynamicClusterRole", releaseCase(dynamicClusterRole)),
+		Entry("of a DynamicRoleBinding", releaseCase(dynamicRoleBinding)),
+		Entry("of a DynamicServiceAccount", releaseCase(dynamicServiceAccount)),
+	)
+
+	It("should keep the resources generated by other owners", func() {
+		owner, generated, deleteTargets := dynamicServiceAccount(kuberbacv1alpha1.DeletionPolicyDelete)
+		foreign := &corev1.ServiceAccount{ObjectMeta: generatedMeta(owner, "default", "foreign")}
+		foreign.Annotations["kuberbac.prosimcorp.com/owner-name"] = "another-owner"
+
+		fakeClient := newFakeClientBuilder().WithObjects(append(generated, owner, foreign)...).Build()
+		Expect(deleteTargets(fakeClient)).To(Succeed())
+
+		kept := &corev1.ServiceAccount{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(foreign), kept)).To(Succeed())
+		Expect(kept.Annotations).To(HaveKeyWithValue("kuberbac.prosimcorp.com/owner-name", "another-owner"))
+	})
+})
+
+var _ = Describe("Status updates", func() {
+
+	ctx := context.Background()
+
+	It("should write the status over the latest version of the object on conflicts", func() {
+		resource := &kuberbacv1alpha1.RBACReport{
+			ObjectMeta: metav1.ObjectMeta{Name: "status-conflict", Namespace: "default"},
+		}
+		fakeClient := newFakeClientBuilder().WithStatusSubresource(resource).WithObjects(resource).Build()
+
+		staleResource := &kuberbacv1alpha1.RBACReport{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(resource), staleResource)).To(Succeed())
+
+		By("changing the object by another writer")
+		latestResource := staleResource.DeepCopy()
+		latestResource.Labels = map[string]string{"changed-by": "another-writer"}
+		Expect(fakeClient.Update(ctx, latestResource)).To(Succeed())
+		Expect(errors.IsConflict(fakeClient.Status().Update(ctx, staleResource.DeepCopy()))).To(BeTrue())
+
+		By("updating the status computed on the stale version")
+		staleResource.Status.SubjectsCount = 3
+		Expect(updateResourceStatus(ctx, fakeClient, staleResource)).To(Succeed())
+
+		storedResource := &kuberbacv1alpha1.RBACReport{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(resource), storedResource)).To(Succeed())
+		Expect(storedResource.Status.SubjectsCount).To(Equal(3))
+		Expect(storedResource.Labels).To(HaveKeyWithValue("changed-by", "another-writer"))
+		Expect(staleResource.ResourceVersion).To(Equal(storedResource.ResourceVersion))
+	})
+
+	It("should report the objects without a status field instead of panicking on conflicts", func() {
+		clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "without-status"}}
+		fakeClient := newFakeClientBuilder().WithObjects(clusterRole).WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				return errors.NewConflict(rbacv1.Resource("clusterroles"), obj.GetName(), fmt.Errorf("stale object"))
+			},
+		}).Build()
+
+		var err error
+		Expect(func() { err = updateResourceStatus(ctx, fakeClient, clusterRole) }).NotTo(Panic())
+		Expect(err).To(MatchError(ContainSubstring("has no settable 'Status' field")))
+	})
+})
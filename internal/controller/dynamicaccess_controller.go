@@ -0,0 +1,274 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/groupprovider"
+	"prosimcorp.com/kuberbac/internal/metrics"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+	"prosimcorp.com/kuberbac/internal/mutationhook"
+	"prosimcorp.com/kuberbac/pkg/policy"
+)
+
+// DynamicAccessReconciler reconciles a DynamicAccess object
+type DynamicAccessReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events about the synchronization of the resources
+	Recorder record.EventRecorder
+
+	// OwnershipMode defines how generated resources are tracked: 'annotations' or 'references'
+	OwnershipMode string
+
+	// PropagatedAnnotations lists the annotations copied from resources onto the ones they generate,
+	// such as the tracking ids of GitOps tools. Items ending with '*' are prefixes
+	PropagatedAnnotations []string
+
+	// DiscoveryCache is shared between reconcilers to avoid requesting resources to the API server on each sync
+	DiscoveryCache *discoverycache.DiscoveryCache
+
+	// EscalationProtection rejects the DynamicAccesses whose generated rules contain privileged verbs
+	// not included in AllowedPrivilegedVerbs, or write privileged resources not included in AllowedPrivilegedResources
+	EscalationProtection       bool
+	AllowedPrivilegedVerbs     []string
+	AllowedPrivilegedResources []string
+
+	// WildcardVerbs defines how wildcard verbs are expanded
+	WildcardVerbs policy.WildcardVerbsT
+
+	// ObjectListing restricts the objects read to evaluate deny rules by name or by object selector
+	ObjectListing ObjectListingT
+
+	// SelfProtection denies the generated rules giving control over the operator
+	SelfProtection SelfProtectionT
+
+	// StandardLabels stamps the generated Roles and RoleBindings with the 'app.kubernetes.io' labels and the hash
+	// of their desired state, which also skips writing them while nothing changes
+	StandardLabels bool
+
+	// MutationHook receives the generated Roles and RoleBindings before they are applied, so they can be
+	// changed or rejected by an external webhook. Disabled when nil
+	MutationHook *mutationhook.Hook
+
+	// MemberClusters is the inventory of clusters the generated Roles and RoleBindings are propagated onto.
+	// Disabled when nil
+	MemberClusters *multicluster.Inventory
+
+	// GroupProvider lists the groups of an external directory to select Group subjects by regular expression. Optional
+	GroupProvider groupprovider.Provider
+
+	// UserProvider lists the users of an external directory to select User subjects by regular expression. Optional
+	UserProvider groupprovider.UserProvider
+
+	// ExcludeSystemNamespaces skips system namespaces when selecting target namespaces,
+	// unless resources override it
+	ExcludeSystemNamespaces bool
+
+	// WatchNamespaces restricts the namespaces where resources are generated. All of them are used when empty
+	WatchNamespaces []string
+
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff applied to requeue failed synchronizations
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// SyncSchedule defines when resources are synchronized again after a synchronization
+	SyncSchedule SyncScheduleT
+}
+
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicaccesses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicaccesses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicaccesses/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete;bind;escalate
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="*",resources="*",verbs=get;list
+// +kubebuilder:rbac:groups="certificates.k8s.io",resources=certificatesigningrequests,verbs=list
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.18.2/pkg/reconcile
+func (r *DynamicAccessReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	//1. Get the content of the Patch
+	dynamicAccessResource := &kuberbacv1alpha1.DynamicAccess{}
+	err = r.Get(ctx, req.NamespacedName, dynamicAccessResource)
+
+	// 2. Check existence on the cluster
+	if err != nil {
+
+		// 2.1 It does NOT exist: manage removal
+		if err = client.IgnoreNotFound(err); err == nil {
+			logger.Info(fmt.Sprintf(resourceNotFoundError, DynamicAccessResourceType, req.NamespacedName))
+			return result, err
+		}
+
+		// 2.2 Failed to get the resource, requeue the request
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, DynamicAccessResourceType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 3. Check if the DynamicAccess instance is marked to be deleted: indicated by the deletion timestamp being set
+	if !dynamicAccessResource.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(dynamicAccessResource, resourceFinalizer) {
+
+			// Delete or orphan all created targets, depending on the deletion policy
+			err = r.DeleteTargets(ctx, dynamicAccessResource)
+			if err != nil {
+				logger.Info(fmt.Sprintf(resourceTargetsDeleteError, DynamicAccessResourceType, req.NamespacedName, err.Error()))
+				return result, err
+			}
+
+			// Member clusters are cleaned too, unless targets are orphaned. Unreachable ones do not block the deletion
+			if dynamicAccessResource.Spec.DeletionPolicy != kuberbacv1alpha1.DeletionPolicyOrphan {
+				err = r.PropagateTargets(ctx, dynamicAccessResource, nil)
+				if err != nil {
+					logger.Info(fmt.Sprintf(propagateTargetsError, DynamicAccessResourceType, req.NamespacedName, err.Error()))
+					r.Recorder.Event(dynamicAccessResource, corev1.EventTypeWarning, eventReasonPropagationFailed, err.Error())
+				}
+			}
+
+			// Remove the finalizers on CR
+			err = updateResource(ctx, r.Client, dynamicAccessResource, func() {
+				controllerutil.RemoveFinalizer(dynamicAccessResource, resourceFinalizer)
+			})
+			if err != nil {
+				logger.Info(fmt.Sprintf(resourceFinalizersUpdateError, DynamicAccessResourceType, req.NamespacedName, err.Error()))
+			}
+
+			// Forget the metrics related to this resource
+			metrics.DeleteResourceMetrics(DynamicAccessResourceType, req.Namespace, req.Name)
+		}
+		result = ctrl.Result{}
+		err = nil
+		return result, err
+	}
+
+	// 4. Add finalizer to the DynamicAccess CR
+	if !controllerutil.ContainsFinalizer(dynamicAccessResource, resourceFinalizer) {
+		err = updateResource(ctx, r.Client, dynamicAccessResource, func() {
+			controllerutil.AddFinalizer(dynamicAccessResource, resourceFinalizer)
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// 5. Update the status before the requeue, along with the time of the next synchronization
+	defer func() {
+		dynamicAccessResource.Status.NextSyncTime = getNextSyncTime(result, err)
+		globals.UpdateReadyCondition(&dynamicAccessResource.Status.Conditions, dynamicAccessResource.Generation)
+		statusErr := updateResourceStatus(ctx, r.Client, dynamicAccessResource)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, DynamicAccessResourceType, req.NamespacedName, statusErr.Error()))
+			result = ctrl.Result{}
+			err = errors.Join(err, statusErr)
+		}
+	}()
+
+	// 6. Schedule periodical request. Invalid times fall back to the default one, so the resource is still synchronized
+	RequeueTime, err := r.SyncSchedule.GetRequeueTime(dynamicAccessResource.Spec.Synchronization)
+	if err != nil {
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, DynamicAccessResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicAccessResource, corev1.EventTypeWarning, globals.ConditionReasonInvalidSpecType, err.Error())
+		err = nil
+	}
+	result = ctrl.Result{
+		RequeueAfter: RequeueTime,
+	}
+
+	// 7. The Patch CR already exist: manage the update
+	syncStartTime := time.Now()
+	generatedObjects, err := r.SyncTarget(ctx, dynamicAccessResource)
+	syncDuration := time.Since(syncStartTime)
+	metrics.SyncDuration.WithLabelValues(DynamicAccessResourceType, req.Namespace, req.Name).Observe(syncDuration.Seconds())
+	dynamicAccessResource.Status.LastSyncDuration = getSyncDuration(syncDuration)
+	if err != nil {
+		metrics.SyncErrors.WithLabelValues(DynamicAccessResourceType, req.Namespace, req.Name).Inc()
+		eventReason := eventReasonSyncFailed
+		switch {
+		case errors.Is(err, errEscalationRejected):
+			eventReason = globals.ConditionReasonEscalationRejectedType
+			r.UpdateConditionEscalationRejected(dynamicAccessResource)
+		default:
+			eventReason = r.UpdateConditionSyncFailure(dynamicAccessResource, err)
+		}
+		logger.Info(fmt.Sprintf(syncTargetError, DynamicAccessResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicAccessResource, corev1.EventTypeWarning, eventReason, err.Error())
+
+		// Invalid specs wait for changes, while the rest of failures are retried with backoff
+		result, err = syncErrorResult(err)
+		return result, err
+	}
+
+	// 8. Propagate the generated Roles and RoleBindings onto the member clusters. Failures are reported on the status
+	// of each member cluster, but they do not fail the synchronization, as the management cluster is already synchronized
+	err = r.PropagateTargets(ctx, dynamicAccessResource, generatedObjects)
+	if err != nil {
+		logger.Info(fmt.Sprintf(propagateTargetsError, DynamicAccessResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicAccessResource, corev1.EventTypeWarning, eventReasonPropagationFailed, err.Error())
+		err = nil
+	}
+
+	// 9. Success, update the status
+	dynamicAccessResource.Status.ObservedGeneration = dynamicAccessResource.Generation
+	r.UpdateConditionSuccess(dynamicAccessResource)
+	r.Recorder.Event(dynamicAccessResource, corev1.EventTypeNormal, eventReasonSynced, "Synced Roles and RoleBindings on targeted namespaces")
+
+	logger.Info(fmt.Sprintf(scheduleSynchronization, DynamicAccessResourceType, req.NamespacedName, result.RequeueAfter.String()))
+
+	return result, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DynamicAccessReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// Generated Roles and RoleBindings are watched, so manual changes on them are reverted on the spot
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kuberbacv1alpha1.DynamicAccess{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			propagatedAnnotationsChangedPredicate(r.PropagatedAnnotations),
+		))).
+		Watches(&rbacv1.Role{}, handler.EnqueueRequestsFromMapFunc(ownerAnnotationsMapFunc(DynamicAccessResourceType))).
+		Watches(&rbacv1.RoleBinding{}, handler.EnqueueRequestsFromMapFunc(ownerAnnotationsMapFunc(DynamicAccessResourceType))).
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.RetryBaseDelay, r.RetryMaxDelay)}).
+		Complete(r)
+}
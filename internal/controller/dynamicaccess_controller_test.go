@@ -0,0 +1,223 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+	"prosimcorp.com/kuberbac/internal/globals"
+)
+
+var _ = Describe("DynamicAccess Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+		const targetName = "test-access"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		targetNamespacedName := types.NamespacedName{
+			Name:      targetName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicAccessReconciler {
+			return &DynamicAccessReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				Recorder:       &record.FakeRecorder{},
+				DiscoveryCache: discoverycache.NewDiscoveryCache(discovery.NewDiscoveryClientForConfigOrDie(cfg), time.Minute),
+			}
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind DynamicAccess")
+			resource := &kuberbacv1alpha1.DynamicAccess{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicAccessSpec{
+					Synchronization: kuberbacv1alpha1.SynchronizationT{
+						Time: "10s",
+					},
+					Allow: []rbacv1.PolicyRule{
+						{APIGroups: []string{""}, Resources: []string{"configmaps", "nodes"}, Verbs: []string{"get"}},
+					},
+					Source: kuberbacv1alpha1.DynamicAccessSource{
+						Subject: kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+							Kind:     "Group",
+							ApiGroup: rbacv1.GroupName,
+							NameSelector: kuberbacv1alpha1.NameSelectorT{
+								MatchList: []string{"developers"},
+							},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicAccessTargets{
+						Name: targetName,
+						NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+							MatchList: []string{"default"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicAccess{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance DynamicAccess")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, targetNamespacedName, &rbacv1.Role{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(ctx, targetNamespacedName, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should generate a Role and a RoleBinding bound to it on targeted namespaces", func() {
+			By("Reconciling the created resource")
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Nodes are cluster-scoped, so Roles can not grant them
+			role := &rbacv1.Role{}
+			Expect(k8sClient.Get(ctx, targetNamespacedName, role)).To(Succeed())
+			Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			}))
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, targetNamespacedName, roleBinding)).To(Succeed())
+			Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: targetName}))
+			Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{
+				{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "developers"},
+			}))
+
+			resource := &kuberbacv1alpha1.DynamicAccess{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.TargetNamespacesCount).To(Equal(1))
+			Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+		})
+	})
+
+	Context("When the escalation protection is enabled", func() {
+		const resourceName = "test-escalating-resource"
+		const targetName = "test-escalating-access"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		targetNamespacedName := types.NamespacedName{
+			Name:      targetName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicAccessReconciler {
+			return &DynamicAccessReconciler{
+				Client:               k8sClient,
+				Scheme:               k8sClient.Scheme(),
+				Recorder:             &record.FakeRecorder{},
+				DiscoveryCache:       discoverycache.NewDiscoveryCache(discovery.NewDiscoveryClientForConfigOrDie(cfg), time.Minute),
+				EscalationProtection: true,
+			}
+		}
+
+		BeforeEach(func() {
+			By("creating a DynamicAccess granting privileged verbs on Roles")
+			resource := &kuberbacv1alpha1.DynamicAccess{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicAccessSpec{
+					Allow: []rbacv1.PolicyRule{
+						{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"roles"}, Verbs: []string{"get", "bind", "escalate"}},
+					},
+					Source: kuberbacv1alpha1.DynamicAccessSource{
+						Subject: kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+							Kind:     "Group",
+							ApiGroup: rbacv1.GroupName,
+							NameSelector: kuberbacv1alpha1.NameSelectorT{
+								MatchList: []string{"developers"},
+							},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicAccessTargets{
+						Name: targetName,
+						NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+							MatchList: []string{"default"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicAccess{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject the resource without generating the Role nor the RoleBinding", func() {
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("privileged verbs not allowed: bind, escalate"))
+
+			err = k8sClient.Get(ctx, targetNamespacedName, &rbacv1.Role{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(ctx, targetNamespacedName, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			resource := &kuberbacv1alpha1.DynamicAccess{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			condition := meta.FindStatusCondition(resource.Status.Conditions, globals.ConditionTypeResourceSynced)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(globals.ConditionReasonEscalationRejectedType))
+		})
+	})
+})
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"prosimcorp.com/kuberbac/internal/globals"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+func (r *DynamicAccessReconciler) UpdateConditionSuccess(resource *kuberbacv1alpha1.DynamicAccess) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionTrue,
+		globals.ConditionReasonTargetSynced, globals.ConditionReasonTargetSyncedMessage)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+}
+
+func (r *DynamicAccessReconciler) UpdateConditionSyncFailure(resource *kuberbacv1alpha1.DynamicAccess, err error) (reason string) {
+
+	//
+	condition := syncFailureCondition(err)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+	return condition.Reason
+}
+
+func (r *DynamicAccessReconciler) UpdateConditionEscalationRejected(resource *kuberbacv1alpha1.DynamicAccess) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionFalse,
+		globals.ConditionReasonEscalationRejectedType, globals.ConditionReasonEscalationRejectedMessage)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+}
@@ -0,0 +1,379 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+)
+
+// RenderRoleRules calculates the rules of the Roles generated by a DynamicAccess. They are evaluated as a
+// DynamicClusterRole separating scopes would do, keeping only the namespaced ones, as Roles can not grant the rest
+func (r *DynamicAccessReconciler) RenderRoleRules(ctx context.Context, resource *kuberbacv1alpha1.DynamicAccess) (
+	policyRules []rbacv1.PolicyRule, err error) {
+
+	dynamicClusterRole := &kuberbacv1alpha1.DynamicClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: resource.APIVersion,
+			Kind:       resource.Kind,
+		},
+		ObjectMeta: *resource.ObjectMeta.DeepCopy(),
+		Spec: kuberbacv1alpha1.DynamicClusterRoleSpec{
+			Target: kuberbacv1alpha1.TargetT{
+				Name:           resource.Spec.Targets.Name,
+				SeparateScopes: true,
+			},
+			Allow:              resource.Spec.Allow,
+			Deny:               resource.Spec.Deny,
+			DefaultDeniedVerbs: resource.Spec.DefaultDeniedVerbs,
+		},
+	}
+
+	targetClusterRoles, _, _, _, err := RenderClusterRoles(ctx, r.Client, r.DiscoveryCache, r.WildcardVerbs,
+		r.ObjectListing, r.SelfProtection, dynamicClusterRole)
+	if err != nil {
+		return policyRules, err
+	}
+
+	for _, targetClusterRole := range targetClusterRoles {
+		for _, clusterRole := range targetClusterRole.ClusterRoles {
+			if clusterRole.Labels[scopeLabel] == scopeLabelNamespace {
+				policyRules = append(policyRules, clusterRole.Rules...)
+				continue
+			}
+
+			if len(clusterRole.Rules) > 0 {
+				log.FromContext(ctx).V(logLevelDecisions).Info("Rules left out: they can not be granted by Roles",
+					"rules", len(clusterRole.Rules))
+			}
+		}
+	}
+
+	return policyRules, err
+}
+
+// SyncTarget call Kubernetes API to actually perform actions over the resource.
+// The applied Roles and RoleBindings are returned, so they can be propagated onto the member clusters
+func (r *DynamicAccessReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.DynamicAccess) (
+	generatedObjects []client.Object, err error) {
+
+	// Check the selectors of the subjects fit their kind before looking for them
+	err = CheckSourceSubject(&resource.Spec.Source.Subject)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	policyRules, err := r.RenderRoleRules(ctx, resource)
+	if err != nil {
+		return generatedObjects, fmt.Errorf("error rendering the rules of the Roles: %w", err)
+	}
+
+	// Reject the rules exceeding the ceiling when the protection is enabled, the same way as DynamicClusterRoles
+	if r.EscalationProtection {
+		escalationReconciler := &DynamicClusterRoleReconciler{
+			AllowedPrivilegedVerbs:     r.AllowedPrivilegedVerbs,
+			AllowedPrivilegedResources: r.AllowedPrivilegedResources,
+		}
+		err = escalationReconciler.CheckPrivilegedVerbs(policyRules)
+		if err != nil {
+			return generatedObjects, err
+		}
+	}
+
+	// Get all the namespaces and filter them by namespaceSelector later
+	namespaceList := &corev1.NamespaceList{}
+	err = r.Client.List(ctx, namespaceList)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	targetFilteredNamespaces, err := FilterNamespaceListBySelector(namespaceList, &resource.Spec.Targets.NamespaceSelector)
+	if err != nil {
+		return generatedObjects, fmt.Errorf("error selecting the namespaces of targets: %w", err)
+	}
+	targetFilteredNamespaces = RemoveSystemNamespaces(targetFilteredNamespaces,
+		resource.Spec.Targets.ExcludeSystemNamespaces, r.ExcludeSystemNamespaces)
+	targetFilteredNamespaces = RemoveExcludedNamespaces(targetFilteredNamespaces, namespaceList)
+	targetFilteredNamespaces = RemoveUnwatchedNamespaces(targetFilteredNamespaces, r.WatchNamespaces)
+	targetFilteredNamespaces = RemoveInactiveNamespaces(targetFilteredNamespaces, namespaceList)
+
+	// Subjects are selected the same way DynamicRoleBindings do
+	subjectsReconciler := &DynamicRoleBindingReconciler{
+		Client:          r.Client,
+		GroupProvider:   r.GroupProvider,
+		UserProvider:    r.UserProvider,
+		WatchNamespaces: r.WatchNamespaces,
+	}
+	expandedSubjects, err := subjectsReconciler.ExpandSubjects(ctx, &resource.Spec.Source.Subject, namespaceList)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	resource.Status.RulesCount = len(policyRules)
+	resource.Status.SubjectsCount = len(expandedSubjects)
+	resource.Status.TargetNamespacesCount = 0
+
+	// Create a generic Role and RoleBinding structure
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	annotations := map[string]string{}
+	maps.Copy(annotations, resource.Spec.Targets.Annotations)
+	maps.Copy(annotations, referenceAnnotations)
+
+	// Get Roles and RoleBindings to check the ownership of the existing ones later
+	existentRoleList := rbacv1.RoleList{}
+	err = r.Client.List(ctx, &existentRoleList)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	existentRoleBindingList := rbacv1.RoleBindingList{}
+	err = r.Client.List(ctx, &existentRoleBindingList)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	// Upgrade already existing Roles and RoleBindings tracked only by reference annotations
+	for _, role := range existentRoleList.Items {
+		if !globals.IsSubset(referenceAnnotations, role.Annotations) {
+			continue
+		}
+
+		err = adoptResource(ctx, r.Client, r.OwnershipMode, resource, &role)
+		if err != nil {
+			return generatedObjects, fmt.Errorf("error adopting Role: %s", err.Error())
+		}
+	}
+
+	for _, roleBinding := range existentRoleBindingList.Items {
+		if !globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+			continue
+		}
+
+		err = adoptResource(ctx, r.Client, r.OwnershipMode, resource, &roleBinding)
+		if err != nil {
+			return generatedObjects, fmt.Errorf("error adopting RoleBinding: %s", err.Error())
+		}
+	}
+
+	// Create the Role and the RoleBinding on targeted namespaces.
+	// Desired namespaces are stored to clean abandoned resources later
+	desiredNamespaces := []string{}
+	for _, namespace := range targetFilteredNamespaces {
+
+		// Check potential already existing Roles or RoleBindings that match the same name and namespace,
+		// but are not owned by this resource. They are never touched
+		objectFound := false
+		for _, role := range existentRoleList.Items {
+			if role.Namespace == namespace && role.Name == resource.Spec.Targets.Name &&
+				!globals.IsSubset(referenceAnnotations, role.Annotations) {
+				objectFound = true
+				break
+			}
+		}
+		for _, roleBinding := range existentRoleBindingList.Items {
+			if roleBinding.Namespace == namespace && roleBinding.Name == resource.Spec.Targets.Name &&
+				!globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+				objectFound = true
+				break
+			}
+		}
+
+		if objectFound {
+			log.FromContext(ctx).V(logLevelDecisions).Info("Namespace skipped: the Role or the RoleBinding already exists and is not owned by this resource",
+				"namespace", namespace, "name", resource.Spec.Targets.Name)
+			continue
+		}
+
+		desiredNamespaces = append(desiredNamespaces, namespace)
+
+		roleResource := rbacv1.Role{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "Role",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        resource.Spec.Targets.Name,
+				Namespace:   namespace,
+				Labels:      maps.Clone(resource.Spec.Targets.Labels),
+				Annotations: maps.Clone(annotations),
+			},
+			Rules: policyRules,
+		}
+
+		roleBindingResource := rbacv1.RoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "RoleBinding",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        resource.Spec.Targets.Name,
+				Namespace:   namespace,
+				Labels:      maps.Clone(resource.Spec.Targets.Labels),
+				Annotations: maps.Clone(annotations),
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     resource.Spec.Targets.Name,
+			},
+			Subjects: expandedSubjects,
+		}
+
+		// The Role goes first, so the RoleBinding never points to a missing one
+		for _, object := range []client.Object{&roleResource, &roleBindingResource} {
+			err = setOwnerReference(r.OwnershipMode, resource, object, r.Scheme)
+			if err != nil {
+				return generatedObjects, fmt.Errorf("error setting owner reference on %s: %s", object.GetObjectKind().GroupVersionKind().Kind, err.Error())
+			}
+			propagateAnnotations(resource, object, r.PropagatedAnnotations)
+
+			if r.StandardLabels {
+				err = setStandardLabels(object, resource.Name)
+				if err != nil {
+					return generatedObjects, err
+				}
+			}
+
+			err = mutateResource(ctx, r.MutationHook, object)
+			if err != nil {
+				return generatedObjects, err
+			}
+
+			err = applyResource(ctx, r.Client, object)
+			if err != nil {
+				return generatedObjects, fmt.Errorf("%w: error applying %s: %s", errTargetWriteFailed, object.GetObjectKind().GroupVersionKind().Kind, err.Error())
+			}
+			generatedObjects = append(generatedObjects, object.DeepCopyObject().(client.Object))
+		}
+	}
+	resource.Status.TargetNamespacesCount = len(desiredNamespaces)
+
+	// Remove owned Roles and RoleBindings not defined in manifest
+	var allErrors []error
+	staleObjects := []client.Object{}
+	for _, role := range existentRoleList.Items {
+		if globals.IsSubset(referenceAnnotations, role.Annotations) &&
+			(role.Name != resource.Spec.Targets.Name || !slices.Contains(desiredNamespaces, role.Namespace)) {
+			staleObjects = append(staleObjects, &role)
+		}
+	}
+	for _, roleBinding := range existentRoleBindingList.Items {
+		if globals.IsSubset(referenceAnnotations, roleBinding.Annotations) &&
+			(roleBinding.Name != resource.Spec.Targets.Name || !slices.Contains(desiredNamespaces, roleBinding.Namespace)) {
+			staleObjects = append(staleObjects, &roleBinding)
+		}
+	}
+
+	for _, object := range staleObjects {
+		kind := "Role"
+		if _, isRoleBinding := object.(*rbacv1.RoleBinding); isRoleBinding {
+			kind = "RoleBinding"
+		}
+
+		err = r.Client.Delete(ctx, object)
+		if err = client.IgnoreNotFound(err); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting not needed %s: %s", errTargetWriteFailed, kind, err.Error()))
+			continue
+		}
+		log.FromContext(ctx).V(logLevelChanges).Info(kind+" deleted: it is not targeted anymore",
+			"namespace", object.GetNamespace(), "name", object.GetName())
+	}
+
+	return generatedObjects, errors.Join(allErrors...)
+}
+
+// DeleteTargets deletes all the Roles and RoleBindings that are owned by the DynamicAccess resource,
+// or orphans them when its deletion policy is 'Orphan'
+func (r *DynamicAccessReconciler) DeleteTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicAccess) (err error) {
+
+	var allErrors []error
+
+	// Create a generic Role and RoleBinding structure
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	// Get RoleBinding objects first, so no binding is left pointing to a released Role
+	roleBindingList := rbacv1.RoleBindingList{}
+	err = r.Client.List(ctx, &roleBindingList)
+	if err != nil {
+		return err
+	}
+
+	for _, roleBinding := range roleBindingList.Items {
+
+		if globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+			err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &roleBinding, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing RoleBinding: %s", err.Error()))
+			}
+		}
+	}
+
+	// Get Role objects and release those with reference annotations
+	roleList := rbacv1.RoleList{}
+	err = r.Client.List(ctx, &roleList)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roleList.Items {
+
+		if globals.IsSubset(referenceAnnotations, role.Annotations) {
+			err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &role, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing Role: %s", err.Error()))
+			}
+		}
+	}
+
+	return errors.Join(allErrors...)
+}
+
+// PropagateTargets mirrors the Roles and RoleBindings generated by the DynamicAccess onto the member clusters
+// of the inventory, recording the result of each one on the status. They are only propagated to the namespaces
+// existing on each member. Released ones are removed from the members by passing no generated objects
+func (r *DynamicAccessReconciler) PropagateTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicAccess,
+	generatedObjects []client.Object) (err error) {
+
+	if r.MemberClusters == nil {
+		return err
+	}
+
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	lists := []client.ObjectList{&rbacv1.RoleList{}, &rbacv1.RoleBindingList{}}
+
+	desiredObjects := func(ctx context.Context, memberCluster multicluster.MemberCluster) ([]client.Object, error) {
+		return generatedObjects, nil
+	}
+
+	resource.Status.MemberClusters, err = propagateTargets(ctx, r.MemberClusters, referenceAnnotations,
+		desiredObjects, lists, resource.Status.MemberClusters)
+	return err
+}
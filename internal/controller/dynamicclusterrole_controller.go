@@ -18,19 +18,40 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
-	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/metrics"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+	"prosimcorp.com/kuberbac/internal/mutationhook"
+	"prosimcorp.com/kuberbac/pkg/policy"
 )
 
 // DynamicClusterRoleReconciler reconciles a DynamicClusterRole object
@@ -38,15 +59,62 @@ type DynamicClusterRoleReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	// TODO
-	DiscoveryClient discovery.DiscoveryClient
+	// Recorder emits Kubernetes Events about the synchronization of the resources
+	Recorder record.EventRecorder
+
+	// DiscoveryCache is shared between reconcilers to avoid requesting resources to the API server on each sync
+	DiscoveryCache *discoverycache.DiscoveryCache
+
+	// EscalationProtection rejects the DynamicClusterRoles whose generated rules contain privileged verbs
+	// not included in AllowedPrivilegedVerbs, or write privileged resources not included in AllowedPrivilegedResources
+	EscalationProtection       bool
+	AllowedPrivilegedVerbs     []string
+	AllowedPrivilegedResources []string
+
+	// WildcardVerbs defines how wildcard verbs are expanded
+	WildcardVerbs policy.WildcardVerbsT
+
+	// ObjectListing restricts the objects read to evaluate deny rules by name or by object selector
+	ObjectListing ObjectListingT
+
+	// SelfProtection denies the generated rules giving control over the operator
+	SelfProtection SelfProtectionT
+
+	// StandardLabels stamps the generated ClusterRoles with the 'app.kubernetes.io' labels and the hash of their
+	// desired state, which also skips writing them while nothing changes
+	StandardLabels bool
+
+	// PropagatedAnnotations lists the annotations copied from resources onto the ones they generate,
+	// such as the tracking ids of GitOps tools. Items ending with '*' are prefixes
+	PropagatedAnnotations []string
+
+	// MutationHook receives the generated ClusterRoles and bindings before they are applied, so they can be
+	// changed or rejected by an external webhook. Disabled when nil
+	MutationHook *mutationhook.Hook
+
+	// MemberClusters is the inventory of clusters the generated ClusterRoles are propagated onto. Disabled when nil
+	MemberClusters *multicluster.Inventory
+
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff applied to requeue failed synchronizations
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// SyncSchedule defines when resources are synchronized again after a synchronization
+	SyncSchedule SyncScheduleT
 }
 
 // +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicclusterroles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicclusterroles/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicclusterroles/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=get;list;watch;create;update;patch;delete;bind;escalate
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingadmissionpolicies;validatingadmissionpolicybindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="*",resources="*",verbs=get;list
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=clusterprotectionpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups="apiregistration.k8s.io",resources=apiservices,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -76,19 +144,32 @@ func (r *DynamicClusterRoleReconciler) Reconcile(ctx context.Context, req ctrl.R
 	// 3. Check if the DynamicClusterRole instance is marked to be deleted: indicated by the deletion timestamp being set
 	if !dynamicClusterRoleResource.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(dynamicClusterRoleResource, resourceFinalizer) {
-			// Delete all created targets
+			// Delete or orphan all created targets, depending on the deletion policy
 			err = r.DeleteTargets(ctx, dynamicClusterRoleResource)
 			if err != nil {
 				logger.Info(fmt.Sprintf(resourceTargetsDeleteError, DynamicClusterRoleResourceType, req.NamespacedName, err.Error()))
 				return result, err
 			}
 
+			// Member clusters are cleaned too, unless targets are orphaned. Unreachable ones do not block the deletion
+			if dynamicClusterRoleResource.Spec.DeletionPolicy != kuberbacv1alpha1.DeletionPolicyOrphan {
+				err = r.PropagateTargets(ctx, dynamicClusterRoleResource, true)
+				if err != nil {
+					logger.Info(fmt.Sprintf(propagateTargetsError, DynamicClusterRoleResourceType, req.NamespacedName, err.Error()))
+					r.Recorder.Event(dynamicClusterRoleResource, corev1.EventTypeWarning, eventReasonPropagationFailed, err.Error())
+				}
+			}
+
 			// Remove the finalizers on Patch CR
-			controllerutil.RemoveFinalizer(dynamicClusterRoleResource, resourceFinalizer)
-			err = r.Update(ctx, dynamicClusterRoleResource)
+			err = updateResource(ctx, r.Client, dynamicClusterRoleResource, func() {
+				controllerutil.RemoveFinalizer(dynamicClusterRoleResource, resourceFinalizer)
+			})
 			if err != nil {
 				logger.Info(fmt.Sprintf(resourceFinalizersUpdateError, DynamicClusterRoleResourceType, req.NamespacedName, err.Error()))
 			}
+
+			// Forget the metrics related to this resource
+			metrics.DeleteResourceMetrics(DynamicClusterRoleResourceType, req.Namespace, req.Name)
 		}
 		result = ctrl.Result{}
 		err = nil
@@ -97,41 +178,95 @@ func (r *DynamicClusterRoleReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	// 4. Add finalizer to the DynamicClusterRole CR
 	if !controllerutil.ContainsFinalizer(dynamicClusterRoleResource, resourceFinalizer) {
-		controllerutil.AddFinalizer(dynamicClusterRoleResource, resourceFinalizer)
-		err = r.Update(ctx, dynamicClusterRoleResource)
+		err = updateResource(ctx, r.Client, dynamicClusterRoleResource, func() {
+			controllerutil.AddFinalizer(dynamicClusterRoleResource, resourceFinalizer)
+		})
 		if err != nil {
 			return result, err
 		}
 	}
 
-	// 5. Update the status before the requeue
+	// 5. Update the status before the requeue, along with the time of the next synchronization
 	defer func() {
-		err = r.Status().Update(ctx, dynamicClusterRoleResource)
-		if err != nil {
-			logger.Info(fmt.Sprintf(resourceConditionUpdateError, DynamicClusterRoleResourceType, req.NamespacedName, err.Error()))
+		dynamicClusterRoleResource.Status.NextSyncTime = getNextSyncTime(result, err)
+		globals.UpdateReadyCondition(&dynamicClusterRoleResource.Status.Conditions, dynamicClusterRoleResource.Generation)
+		statusErr := updateResourceStatus(ctx, r.Client, dynamicClusterRoleResource)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, DynamicClusterRoleResourceType, req.NamespacedName, statusErr.Error()))
+			result = ctrl.Result{}
+			err = errors.Join(err, statusErr)
 		}
 	}()
 
-	// 6. Schedule periodical request
-	RequeueTime, err := time.ParseDuration(dynamicClusterRoleResource.Spec.Synchronization.Time)
+	// 6. Schedule periodical request. Invalid times fall back to the default one, so the resource is still synchronized
+	RequeueTime, err := r.SyncSchedule.GetRequeueTime(dynamicClusterRoleResource.Spec.Synchronization)
 	if err != nil {
 		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, DynamicClusterRoleResourceType, req.NamespacedName, err.Error()))
-		return result, err
+		r.Recorder.Event(dynamicClusterRoleResource, corev1.EventTypeWarning, globals.ConditionReasonInvalidSpecType, err.Error())
+		err = nil
 	}
 	result = ctrl.Result{
 		RequeueAfter: RequeueTime,
 	}
 
-	// 7. The Patch CR already exist: manage the update
+	// 7. The Patch CR already exist: manage the update.
+	// A new summary of changes is stored on the status only when the generated rules changed
+	previousChange := dynamicClusterRoleResource.Status.LastChange
+	syncStartTime := time.Now()
 	err = r.SyncTarget(ctx, dynamicClusterRoleResource)
+	syncDuration := time.Since(syncStartTime)
+	metrics.SyncDuration.WithLabelValues(DynamicClusterRoleResourceType, req.Namespace, req.Name).Observe(syncDuration.Seconds())
+	dynamicClusterRoleResource.Status.LastSyncDuration = getSyncDuration(syncDuration)
 	if err != nil {
-		r.UpdateConditionKubernetesApiCallFailure(dynamicClusterRoleResource)
+		metrics.SyncErrors.WithLabelValues(DynamicClusterRoleResourceType, req.Namespace, req.Name).Inc()
+		eventReason := eventReasonSyncFailed
+		switch {
+		case errors.Is(err, errEscalationRejected):
+			eventReason = globals.ConditionReasonEscalationRejectedType
+			r.UpdateConditionEscalationRejected(dynamicClusterRoleResource)
+		case errors.Is(err, errTargetOwnershipConflict):
+			eventReason = globals.ConditionReasonTargetOwnershipConflictType
+			r.UpdateConditionTargetOwnershipConflict(dynamicClusterRoleResource)
+		default:
+			eventReason = r.UpdateConditionSyncFailure(dynamicClusterRoleResource, err)
+		}
 		logger.Info(fmt.Sprintf(syncTargetError, DynamicClusterRoleResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicClusterRoleResource, corev1.EventTypeWarning, eventReason, err.Error())
+
+		// Invalid specs wait for changes, while the rest of failures are retried with backoff
+		result, err = syncErrorResult(err)
 		return result, err
 	}
 
-	// 8. Success, update the status
-	r.UpdateConditionSuccess(dynamicClusterRoleResource)
+	// 8. Propagate the generated ClusterRoles onto the member clusters. Failures are reported on the status of each
+	// member cluster, but they do not fail the synchronization, as the management cluster is already synchronized
+	err = r.PropagateTargets(ctx, dynamicClusterRoleResource, false)
+	if err != nil {
+		logger.Info(fmt.Sprintf(propagateTargetsError, DynamicClusterRoleResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicClusterRoleResource, corev1.EventTypeWarning, eventReasonPropagationFailed, err.Error())
+		err = nil
+	}
+
+	// 9. Success, update the status
+	dynamicClusterRoleResource.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	dynamicClusterRoleResource.Status.ObservedGeneration = dynamicClusterRoleResource.Generation
+	if !slices.ContainsFunc(GetClusterRoleTargets(dynamicClusterRoleResource), func(target kuberbacv1alpha1.TargetT) bool {
+		return !target.DryRun
+	}) {
+		r.UpdateConditionDryRun(dynamicClusterRoleResource)
+		r.Recorder.Eventf(dynamicClusterRoleResource, corev1.EventTypeNormal, eventReasonRendered,
+			"Rendered %d ClusterRoles with %d rules into the status", len(dynamicClusterRoleResource.Status.RenderedClusterRoles),
+			dynamicClusterRoleResource.Status.RulesCount)
+	} else {
+		r.UpdateConditionSuccess(dynamicClusterRoleResource)
+
+		// Unchanged synchronizations are not reported, so periodic ones do not flood the Events of the resource
+		if dynamicClusterRoleResource.Status.LastChange != previousChange {
+			r.Recorder.Eventf(dynamicClusterRoleResource, corev1.EventTypeNormal, eventReasonSynced,
+				"Synced ClusterRoles %s with %d rules", strings.Join(dynamicClusterRoleResource.Status.GeneratedClusterRoles, ", "),
+				dynamicClusterRoleResource.Status.RulesCount)
+		}
+	}
 
 	logger.Info(fmt.Sprintf(scheduleSynchronization, DynamicClusterRoleResourceType, req.NamespacedName, result.RequeueAfter.String()))
 	return result, err
@@ -140,8 +275,222 @@ func (r *DynamicClusterRoleReconciler) Reconcile(ctx context.Context, req ctrl.R
 // SetupWithManager sets up the controller with the Manager.
 // Ref: https://github.com/kubernetes-sigs/kubebuilder/issues/618
 func (r *DynamicClusterRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kuberbacv1alpha1.DynamicClusterRole{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+
+	// DynamicClusterRoles declaring the same target names are synchronized together, so their conflicts are reported
+	// on all of them, and the rest take over the targets of those being deleted.
+	// Protection policies affect all the DynamicClusterRoles, so all of them are synchronized on their changes.
+	// Resources available in the cluster change when CRDs or APIServices are added, removed or become available,
+	// so discovery results are invalidated on those events, and the DynamicClusterRoles covering their group
+	// are synchronized again. Only metadata is watched, so every change is considered, status ones included.
+	// ConfigMaps, and Secrets when allowed, are watched to apply the changes of the values read through valuesFrom.
+	// Only their metadata is kept in memory, as their content is read when needed.
+	// ClusterRoles imported through from are watched too, so changes on their rules are imported on the spot
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1",
+		Kind:    "CustomResourceDefinition",
+	})
+
+	apiService := &metav1.PartialObjectMetadata{}
+	apiService.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "apiregistration.k8s.io",
+		Version: "v1",
+		Kind:    "APIService",
+	})
+
+	configMap := &metav1.PartialObjectMetadata{}
+	configMap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr)
+	if r.ObjectListing.SecretValues {
+		secret := &metav1.PartialObjectMetadata{}
+		secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+
+		controllerBuilder = controllerBuilder.WatchesMetadata(secret,
+			handler.EnqueueRequestsFromMapFunc(r.mapValuesSourceToDynamicClusterRoles("Secret")))
+	}
+
+	// Generated ClusterRoles are watched, so manual changes on them are reverted on the spot
+	mapToOwner := handler.EnqueueRequestsFromMapFunc(ownerAnnotationsMapFunc(DynamicClusterRoleResourceType))
+
+	return controllerBuilder.
+		For(&kuberbacv1alpha1.DynamicClusterRole{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			propagatedAnnotationsChangedPredicate(r.PropagatedAnnotations),
+		))).
+		Watches(&rbacv1.ClusterRole{}, mapToOwner).
+		Watches(&rbacv1.ClusterRole{}, handler.EnqueueRequestsFromMapFunc(r.mapImportedClusterRoleToDynamicClusterRoles),
+			builder.WithPredicates(predicate.Funcs{
+				// Changing labels can make ClusterRoles start or stop matching selectors
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return !maps.Equal(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+						!equality.Semantic.DeepEqual(e.ObjectOld.(*rbacv1.ClusterRole).Rules, e.ObjectNew.(*rbacv1.ClusterRole).Rules)
+				},
+			})).
+		Watches(&kuberbacv1alpha1.DynamicClusterRole{}, handler.EnqueueRequestsFromMapFunc(r.mapToDynamicClusterRolesSharingTargets),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&kuberbacv1alpha1.ClusterProtectionPolicy{}, handler.EnqueueRequestsFromMapFunc(r.mapToAllDynamicClusterRoles),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WatchesMetadata(crd, handler.EnqueueRequestsFromMapFunc(r.mapDiscoveryChangeToDynamicClusterRoles)).
+		WatchesMetadata(apiService, handler.EnqueueRequestsFromMapFunc(r.mapDiscoveryChangeToDynamicClusterRoles)).
+		WatchesMetadata(configMap, handler.EnqueueRequestsFromMapFunc(r.mapValuesSourceToDynamicClusterRoles("ConfigMap"))).
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.RetryBaseDelay, r.RetryMaxDelay)}).
 		Complete(r)
 }
+
+// mapDiscoveryChangeToDynamicClusterRoles invalidates the discovery results when the resources available in the cluster
+// change, and returns a request for each DynamicClusterRole whose rules cover the group of the changed CRD or APIService.
+// Their names are '<plural>.<group>' and '<version>.<group>', so the group is what follows the first dot
+func (r *DynamicClusterRoleReconciler) mapDiscoveryChangeToDynamicClusterRoles(ctx context.Context, object client.Object) (requests []reconcile.Request) {
+
+	if r.DiscoveryCache != nil {
+		r.DiscoveryCache.Invalidate()
+	}
+
+	_, group, _ := strings.Cut(object.GetName(), ".")
+
+	dynamicClusterRoleList := &kuberbacv1alpha1.DynamicClusterRoleList{}
+	err := r.List(ctx, dynamicClusterRoleList)
+	if err != nil {
+		log.FromContext(ctx).Info(fmt.Sprintf(resourceListError, DynamicClusterRoleResourceType, err.Error()))
+		return requests
+	}
+
+	for _, dynamicClusterRole := range dynamicClusterRoleList.Items {
+		rules := slices.Clone(dynamicClusterRole.Spec.Allow)
+		for _, denyRule := range dynamicClusterRole.Spec.Deny {
+			rules = append(rules, denyRule.PolicyRule)
+		}
+
+		if policy.CoversGroup(rules, group) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dynamicClusterRole)})
+		}
+	}
+
+	return requests
+}
+
+// mapValuesSourceToDynamicClusterRoles returns a handler.MapFunc returning a request for each DynamicClusterRole
+// reading values from the changed ConfigMap or Secret, depending on the kind, through valuesFrom
+func (r *DynamicClusterRoleReconciler) mapValuesSourceToDynamicClusterRoles(kind string) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) (requests []reconcile.Request) {
+
+		dynamicClusterRoleList := &kuberbacv1alpha1.DynamicClusterRoleList{}
+		err := r.List(ctx, dynamicClusterRoleList, client.InNamespace(object.GetNamespace()))
+		if err != nil {
+			log.FromContext(ctx).Info(fmt.Sprintf(resourceListError, DynamicClusterRoleResourceType, err.Error()))
+			return requests
+		}
+
+		for _, dynamicClusterRole := range dynamicClusterRoleList.Items {
+			if slices.ContainsFunc(dynamicClusterRole.Spec.ValuesFrom, func(source kuberbacv1alpha1.ValuesSourceT) bool {
+				if kind == "Secret" {
+					return source.SecretRef != nil && source.SecretRef.Name == object.GetName()
+				}
+				return source.ConfigMapRef != nil && source.ConfigMapRef.Name == object.GetName()
+			}) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dynamicClusterRole)})
+			}
+		}
+
+		return requests
+	}
+}
+
+// mapImportedClusterRoleToDynamicClusterRoles returns a request for each DynamicClusterRole importing the rules
+// of the changed ClusterRole through from, by name or by selector. Its own ClusterRoles are never imported
+func (r *DynamicClusterRoleReconciler) mapImportedClusterRoleToDynamicClusterRoles(ctx context.Context, object client.Object) (
+	requests []reconcile.Request) {
+
+	dynamicClusterRoleList := &kuberbacv1alpha1.DynamicClusterRoleList{}
+	err := r.List(ctx, dynamicClusterRoleList)
+	if err != nil {
+		log.FromContext(ctx).Info(fmt.Sprintf(resourceListError, DynamicClusterRoleResourceType, err.Error()))
+		return requests
+	}
+
+	ownerRequests := ownerAnnotationsMapFunc(DynamicClusterRoleResourceType)(ctx, object)
+	for _, dynamicClusterRole := range dynamicClusterRoleList.Items {
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dynamicClusterRole)}
+		if slices.Contains(ownerRequests, request) || !importsClusterRole(&dynamicClusterRole, object) {
+			continue
+		}
+		requests = append(requests, request)
+	}
+
+	return requests
+}
+
+// importsClusterRole returns whether some source of from references the ClusterRole by its name or selects it by its labels
+func importsClusterRole(resource *kuberbacv1alpha1.DynamicClusterRole, clusterRole client.Object) bool {
+	return slices.ContainsFunc(resource.Spec.From, func(source kuberbacv1alpha1.ClusterRoleSourceT) bool {
+		if source.Name != "" {
+			return source.Name == clusterRole.GetName()
+		}
+
+		if source.Selector == nil {
+			return false
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(source.Selector)
+		if err != nil {
+			return false
+		}
+		return selector.Matches(labels.Set(clusterRole.GetLabels()))
+	})
+}
+
+// mapToDynamicClusterRolesSharingTargets returns a request for each other DynamicClusterRole declaring
+// some target name of the changed one
+func (r *DynamicClusterRoleReconciler) mapToDynamicClusterRolesSharingTargets(ctx context.Context, object client.Object) (
+	requests []reconcile.Request) {
+
+	changedDynamicClusterRole, ok := object.(*kuberbacv1alpha1.DynamicClusterRole)
+	if !ok {
+		return requests
+	}
+
+	targetNames := []string{}
+	for _, target := range GetClusterRoleTargets(changedDynamicClusterRole) {
+		targetNames = append(targetNames, target.Name)
+	}
+
+	dynamicClusterRoleList := &kuberbacv1alpha1.DynamicClusterRoleList{}
+	err := r.List(ctx, dynamicClusterRoleList)
+	if err != nil {
+		log.FromContext(ctx).Info(fmt.Sprintf(resourceListError, DynamicClusterRoleResourceType, err.Error()))
+		return requests
+	}
+
+	for _, dynamicClusterRole := range dynamicClusterRoleList.Items {
+		if client.ObjectKeyFromObject(&dynamicClusterRole) == client.ObjectKeyFromObject(object) {
+			continue
+		}
+
+		if slices.ContainsFunc(GetClusterRoleTargets(&dynamicClusterRole), func(target kuberbacv1alpha1.TargetT) bool {
+			return slices.Contains(targetNames, target.Name)
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dynamicClusterRole)})
+		}
+	}
+
+	return requests
+}
+
+// mapToAllDynamicClusterRoles returns a request for each DynamicClusterRole in the cluster
+func (r *DynamicClusterRoleReconciler) mapToAllDynamicClusterRoles(ctx context.Context, _ client.Object) (requests []reconcile.Request) {
+
+	dynamicClusterRoleList := &kuberbacv1alpha1.DynamicClusterRoleList{}
+	err := r.List(ctx, dynamicClusterRoleList)
+	if err != nil {
+		log.FromContext(ctx).Info(fmt.Sprintf(resourceListError, DynamicClusterRoleResourceType, err.Error()))
+		return requests
+	}
+
+	for _, dynamicClusterRole := range dynamicClusterRoleList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dynamicClusterRole)})
+	}
+
+	return requests
+}
@@ -18,16 +18,32 @@ package controller
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/pkg/policy"
 )
 
 var _ = Describe("DynamicClusterRole Controller", func() {
@@ -69,8 +85,9 @@ var _ = Describe("DynamicClusterRole Controller", func() {
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &DynamicClusterRoleReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -82,3 +99,991 @@ var _ = Describe("DynamicClusterRole Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("DynamicClusterRole target renaming", func() {
+	Context("When the name of the target changes", func() {
+		const resourceName = "renamed-target"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicClusterRoleReconciler {
+			return &DynamicClusterRoleReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				Recorder:       &record.FakeRecorder{},
+				DiscoveryCache: discoverycache.NewDiscoveryCache(discovery.NewDiscoveryClientForConfigOrDie(cfg), time.Minute),
+			}
+		}
+
+		BeforeEach(func() {
+			resource := &kuberbacv1alpha1.DynamicClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicClusterRoleSpec{
+					Target: kuberbacv1alpha1.TargetT{Name: "renamed-target-before"},
+					Allow: []rbacv1.PolicyRule{
+						{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+					},
+					Deny: []kuberbacv1alpha1.DenyPolicyRuleT{},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicClusterRole{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should delete the ClusterRole generated under the previous name", func() {
+			controllerReconciler := newReconciler()
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "renamed-target-before"}, &rbacv1.ClusterRole{})).To(Succeed())
+
+			resource := &kuberbacv1alpha1.DynamicClusterRole{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Target.Name = "renamed-target-after"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "renamed-target-after"}, &rbacv1.ClusterRole{})).To(Succeed())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "renamed-target-before"}, &rbacv1.ClusterRole{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.GeneratedClusterRoles).To(Equal([]string{"renamed-target-after"}))
+
+			// Ready reflects the synchronization of the current generation
+			readyCondition := apimeta.FindStatusCondition(resource.Status.Conditions, globals.ConditionTypeReady)
+			Expect(readyCondition).NotTo(BeNil())
+			Expect(readyCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(readyCondition.ObservedGeneration).To(Equal(resource.Generation))
+			Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+
+			// The timing of the synchronization is reported, as the resource is requeued periodically
+			Expect(resource.Status.NextSyncTime).NotTo(BeNil())
+			Expect(resource.Status.NextSyncTime.Time).To(BeTemporally(">", time.Now()))
+			Expect(resource.Status.LastSyncDuration).NotTo(BeNil())
+		})
+	})
+})
+
+var _ = Describe("DynamicClusterRole deny rules by name", func() {
+	Context("When evaluating them for namespaced renderings", func() {
+		const otherNamespace = "deny-by-name"
+
+		ctx := context.Background()
+
+		configMaps := []*corev1.ConfigMap{
+			{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: otherNamespace}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: otherNamespace}},
+		}
+
+		allowMap := map[string]rbacv1.PolicyRule{
+			"#configmaps#": {APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+		}
+		denyMap := map[string]rbacv1.PolicyRule{
+			"#configmaps#db-credentials": {APIGroups: []string{""}, Resources: []string{"configmaps"},
+				ResourceNames: []string{"db-credentials"}, Verbs: []string{"get"}},
+		}
+
+		BeforeEach(func() {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: otherNamespace}}
+			if err := k8sClient.Create(ctx, namespace); err != nil && !errors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			for _, configMap := range configMaps {
+				if err := k8sClient.Create(ctx, configMap.DeepCopy()); err != nil && !errors.IsAlreadyExists(err) {
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}
+		})
+
+		AfterEach(func() {
+			for _, configMap := range configMaps {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, configMap.DeepCopy()))).To(Succeed())
+			}
+		})
+
+		newPolicyRulesProcessor := func() policy.ProcessorT {
+			return policy.ProcessorT{
+				ObjectLister: &clientObjectLister{Client: k8sClient},
+				ResourcesByGroup: map[string][]policy.GVKR{
+					"": {
+						{GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Resource: "configmaps", Namespaced: true},
+					},
+				},
+			}
+		}
+
+		It("should only expand the names of the objects living in each namespace", func() {
+			policyRulesProcessor := newPolicyRulesProcessor()
+
+			result, err := policyRulesProcessor.EvaluatePolicyRulesInNamespaces(ctx, allowMap, denyMap, []string{"default", otherNamespace})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result["default"]).To(HaveKey("#configmaps#app-config"))
+			Expect(result["default"]).To(HaveKey("#configmaps#web-config"))
+			Expect(result["default"]).NotTo(HaveKey("#configmaps#db-credentials"))
+
+			Expect(result[otherNamespace]).To(HaveKey("#configmaps#app-config"))
+			Expect(result[otherNamespace]).NotTo(HaveKey("#configmaps#web-config"))
+			Expect(result[otherNamespace]).NotTo(HaveKey("#configmaps#db-credentials"))
+
+			Expect(allowMap).To(HaveLen(1))
+		})
+
+		It("should expand the names of the objects of every namespace when no namespace is set", func() {
+			policyRulesProcessor := newPolicyRulesProcessor()
+
+			result, err := policyRulesProcessor.EvaluateSpecialCases(ctx, map[string]rbacv1.PolicyRule{
+				"#configmaps#": allowMap["#configmaps#"],
+			}, denyMap)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(HaveKey("#configmaps#web-config"))
+			Expect(result).To(HaveKey("#configmaps#db-credentials"))
+			Expect(result).NotTo(HaveKey("#configmaps#"))
+		})
+	})
+})
+
+var _ = Describe("DynamicClusterRole rules export", func() {
+	Context("When a target asks for exporting its rules", func() {
+		const resourceName = "exported-rules"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicClusterRoleReconciler {
+			return &DynamicClusterRoleReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				Recorder:       &record.FakeRecorder{},
+				DiscoveryCache: discoverycache.NewDiscoveryCache(discovery.NewDiscoveryClientForConfigOrDie(cfg), time.Minute),
+			}
+		}
+
+		BeforeEach(func() {
+			resource := &kuberbacv1alpha1.DynamicClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicClusterRoleSpec{
+					Target: kuberbacv1alpha1.TargetT{
+						Name:            "exported-rules-target",
+						ExportConfigMap: &kuberbacv1alpha1.ExportConfigMapT{},
+					},
+					Allow: []rbacv1.PolicyRule{
+						{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+					},
+					Deny: []kuberbacv1alpha1.DenyPolicyRuleT{},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicClusterRole{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should write the generated rules into a ConfigMap until the export is removed", func() {
+			controllerReconciler := newReconciler()
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			configMapName := types.NamespacedName{Name: "exported-rules-target", Namespace: "default"}
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, configMapName, configMap)).To(Succeed())
+			Expect(configMap.Labels).To(HaveKeyWithValue(exportConfigMapLabel, "true"))
+			Expect(configMap.Data[exportConfigMapKey]).To(ContainSubstring("configmaps"))
+
+			resource := &kuberbacv1alpha1.DynamicClusterRole{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Target.ExportConfigMap = nil
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, configMapName, &corev1.ConfigMap{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("DynamicClusterRole self-protection", func() {
+	Context("When generated rules give control over the operator", func() {
+		ctx := context.Background()
+
+		serviceAccount := &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultOperatorServiceAccount, Namespace: "default"},
+		}
+
+		selfProtection := SelfProtectionT{
+			ServiceAccount: DefaultOperatorServiceAccount,
+			ClusterRole:    DefaultOperatorClusterRole,
+		}
+
+		newResource := func() *kuberbacv1alpha1.DynamicClusterRole {
+			return &kuberbacv1alpha1.DynamicClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kuberbacv1alpha1.GroupVersion.String(), Kind: DynamicClusterRoleResourceType},
+				ObjectMeta: metav1.ObjectMeta{Name: "self-protection", Namespace: "default"},
+				Spec: kuberbacv1alpha1.DynamicClusterRoleSpec{
+					Target: kuberbacv1alpha1.TargetT{Name: "self-protection-target"},
+					Allow: []rbacv1.PolicyRule{
+						{APIGroups: []string{kuberbacv1alpha1.GroupVersion.Group}, Resources: []string{"dynamicclusterroles"},
+							Verbs: []string{"get", "create", "delete"}},
+						{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"get", "update"}},
+					},
+				},
+			}
+		}
+
+		render := func(selfProtection SelfProtectionT) []rbacv1.PolicyRule {
+			discoveryCache := discoverycache.NewDiscoveryCache(discovery.NewDiscoveryClientForConfigOrDie(cfg), time.Minute)
+			_, policyRules, _, _, err := RenderClusterRoles(ctx, k8sClient, discoveryCache, policy.WildcardVerbsT{},
+				ObjectListingT{}, selfProtection, newResource())
+			Expect(err).NotTo(HaveOccurred())
+			return policyRules
+		}
+
+		BeforeEach(func() {
+			if err := k8sClient.Create(ctx, serviceAccount.DeepCopy()); err != nil && !errors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, serviceAccount.DeepCopy()))).To(Succeed())
+		})
+
+		It("should remove the write access to kuberbac resources and to the ServiceAccount of the operator", func() {
+			policyRules := render(selfProtection)
+
+			Expect(policyRules).To(ContainElement(rbacv1.PolicyRule{
+				APIGroups: []string{kuberbacv1alpha1.GroupVersion.Group}, Resources: []string{"dynamicclusterroles"},
+				Verbs: []string{"get"},
+			}))
+			Expect(policyRules).To(ContainElement(rbacv1.PolicyRule{
+				APIGroups: []string{""}, Resources: []string{"serviceaccounts"},
+				ResourceNames: []string{DefaultOperatorServiceAccount}, Verbs: []string{"get"},
+			}))
+		})
+
+		It("should keep the rules of allowed resources as they are", func() {
+			selfProtection := selfProtection
+			selfProtection.AllowedResources = []string{"DynamicClusterRole/default/self-protection"}
+			policyRules := render(selfProtection)
+
+			Expect(policyRules).To(ContainElement(rbacv1.PolicyRule{
+				APIGroups: []string{kuberbacv1alpha1.GroupVersion.Group}, Resources: []string{"dynamicclusterroles"},
+				Verbs: []string{"create", "delete", "get"},
+			}))
+			Expect(policyRules).To(ContainElement(rbacv1.PolicyRule{
+				APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"get", "update"},
+			}))
+		})
+	})
+})
+
+var _ = Describe("Self-protection rules", func() {
+
+	selfProtection := SelfProtectionT{
+		ServiceAccount:     DefaultOperatorServiceAccount,
+		ClusterRole:        DefaultOperatorClusterRole,
+		ClusterRoleBinding: DefaultOperatorClusterRoleBinding,
+		AllowedResources:   []string{"DynamicClusterRole/platform/kuberbac-admins"},
+	}
+
+	newResource := func(kind string) *kuberbacv1alpha1.DynamicClusterRole {
+		return &kuberbacv1alpha1.DynamicClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: kuberbacv1alpha1.GroupVersion.String(), Kind: kind},
+			ObjectMeta: metav1.ObjectMeta{Name: "kuberbac-admins", Namespace: "platform"},
+		}
+	}
+
+	// protectedResources returns the resources denied by the rules, with their names when they are denied by name
+	protectedResources := func(policyRules []rbacv1.PolicyRule) (resources []string) {
+		for _, policyRule := range policyRules {
+			for _, resource := range policyRule.Resources {
+				resources = append(resources, strings.Join(append([]string{resource}, policyRule.ResourceNames...), "/"))
+			}
+		}
+		return resources
+	}
+
+	DescribeTable("When denying the rules giving control over the operator",
+		func(kind string, objectListing ObjectListingT, online bool, expected []string) {
+			policyRules := selfProtection.GetPolicyRules(newResource(kind), objectListing, online)
+			Expect(protectedResources(policyRules)).To(Equal(expected))
+		},
+		Entry("should keep the rules of an allowed DynamicClusterRole",
+			DynamicClusterRoleResourceType, ObjectListingT{}, true, nil),
+		Entry("should not allow a DynamicAccess sharing the namespace and name of an allowed DynamicClusterRole",
+			DynamicAccessResourceType, ObjectListingT{}, true, []string{
+				"*",
+				"serviceaccounts/" + DefaultOperatorServiceAccount,
+				"serviceaccounts/token/" + DefaultOperatorServiceAccount,
+				"clusterroles/" + DefaultOperatorClusterRole,
+				"clusterrolebindings/" + DefaultOperatorClusterRoleBinding,
+			}),
+		Entry("should only protect the ServiceAccount when the objects of the rbac group can not be listed",
+			DynamicAccessResourceType, ObjectListingT{Groups: []string{""}}, true, []string{
+				"*",
+				"serviceaccounts/" + DefaultOperatorServiceAccount,
+				"serviceaccounts/token/" + DefaultOperatorServiceAccount,
+			}),
+		Entry("should only protect the resources of kuberbac when rendering offline",
+			DynamicAccessResourceType, ObjectListingT{}, false, []string{"*"}),
+	)
+
+	DescribeTable("When reporting the objects of the operator that can not be protected",
+		func(objectListing ObjectListingT, online bool, expected []string) {
+			Expect(selfProtection.Unprotected(objectListing, online)).To(Equal(expected))
+		},
+		Entry("should report nothing when every object can be listed",
+			ObjectListingT{}, true, nil),
+		Entry("should report the rbac objects when only the core group can be listed",
+			ObjectListingT{Groups: []string{""}}, true, []string{
+				"ClusterRole/" + DefaultOperatorClusterRole,
+				"ClusterRoleBinding/" + DefaultOperatorClusterRoleBinding,
+			}),
+		Entry("should report every object when listing is disabled",
+			ObjectListingT{Disabled: true}, true, []string{
+				"ServiceAccount/" + DefaultOperatorServiceAccount,
+				"ClusterRole/" + DefaultOperatorClusterRole,
+				"ClusterRoleBinding/" + DefaultOperatorClusterRoleBinding,
+			}),
+		Entry("should report every object when rendering offline",
+			ObjectListingT{}, false, []string{
+				"ServiceAccount/" + DefaultOperatorServiceAccount,
+				"ClusterRole/" + DefaultOperatorClusterRole,
+				"ClusterRoleBinding/" + DefaultOperatorClusterRoleBinding,
+			}),
+	)
+
+	DescribeTable("When validating the allowed resources",
+		func(allowedResource string, valid bool) {
+			selfProtection := SelfProtectionT{AllowedResources: []string{allowedResource}}
+			if valid {
+				Expect(selfProtection.Validate()).To(Succeed())
+			} else {
+				Expect(selfProtection.Validate()).NotTo(Succeed())
+			}
+		},
+		Entry("should accept DynamicClusterRoles", "DynamicClusterRole/platform/kuberbac-admins", true),
+		Entry("should accept DynamicAccesses", "DynamicAccess/platform/kuberbac-admins", true),
+		Entry("should reject resources without kind", "platform/kuberbac-admins", false),
+		Entry("should reject unknown kinds", "DynamicRoleBinding/platform/kuberbac-admins", false),
+		Entry("should reject resources without namespace", "DynamicClusterRole//kuberbac-admins", false),
+	)
+})
+
+var _ = Describe("DynamicClusterRole template values", func() {
+
+	ctx := context.Background()
+
+	configMapRef := func(name string, optional bool) kuberbacv1alpha1.ValuesSourceT {
+		return kuberbacv1alpha1.ValuesSourceT{ConfigMapRef: &kuberbacv1alpha1.ValuesReferenceT{Name: name, Optional: optional}}
+	}
+	secretRef := func(name string, optional bool) kuberbacv1alpha1.ValuesSourceT {
+		return kuberbacv1alpha1.ValuesSourceT{SecretRef: &kuberbacv1alpha1.ValuesReferenceT{Name: name, Optional: optional}}
+	}
+
+	newResource := func(valuesFrom ...kuberbacv1alpha1.ValuesSourceT) *kuberbacv1alpha1.DynamicClusterRole {
+		return &kuberbacv1alpha1.DynamicClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "values", Namespace: "payments"},
+			Spec:       kuberbacv1alpha1.DynamicClusterRoleSpec{ValuesFrom: valuesFrom},
+		}
+	}
+
+	// Sources living in the namespace of the resource, and one with the same name elsewhere that must never be read
+	fakeClient := newFakeClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "rbac-values", Namespace: "payments"},
+			Data:       map[string]string{"database": "postgres-staging", "environment": "staging"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "rbac-values-production", Namespace: "payments"},
+			Data:       map[string]string{"database": "postgres-production"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "rbac-values-shipping", Namespace: "shipping"},
+			Data:       map[string]string{"database": "mysql"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rbac-values", Namespace: "payments"},
+			Data:       map[string][]byte{"token": []byte("secret-token")},
+		},
+	).Build()
+
+	It("should merge the values of the sources, later ones overriding previous keys", func() {
+		values, err := GetTemplateValues(ctx, fakeClient,
+			newResource(configMapRef("rbac-values", false), configMapRef("rbac-values-production", false)), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{"database": "postgres-production", "environment": "staging"}))
+	})
+
+	It("should skip the optional sources not found", func() {
+		values, err := GetTemplateValues(ctx, fakeClient,
+			newResource(configMapRef("rbac-values", false), configMapRef("missing", true), secretRef("missing", true)), true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(HaveLen(2))
+	})
+
+	DescribeTable("When the sources can not be read",
+		func(allowSecrets bool, valuesFrom []kuberbacv1alpha1.ValuesSourceT, expectedError string) {
+			_, err := GetTemplateValues(ctx, fakeClient, newResource(valuesFrom...), allowSecrets)
+			Expect(err).To(MatchError(errInvalidSpec))
+			Expect(err.Error()).To(ContainSubstring(expectedError))
+		},
+		Entry("should reject required sources not found", false,
+			[]kuberbacv1alpha1.ValuesSourceT{configMapRef("missing", false)}, "ConfigMap 'missing' of valuesFrom does not exist"),
+		Entry("should only look for sources in the namespace of the resource", false,
+			[]kuberbacv1alpha1.ValuesSourceT{configMapRef("rbac-values-shipping", false)}, "does not exist"),
+		Entry("should reject Secrets when they are not allowed", false,
+			[]kuberbacv1alpha1.ValuesSourceT{secretRef("rbac-values", false)}, "can not be read from Secrets"),
+		Entry("should reject sources setting both references", true,
+			[]kuberbacv1alpha1.ValuesSourceT{{
+				ConfigMapRef: &kuberbacv1alpha1.ValuesReferenceT{Name: "rbac-values"},
+				SecretRef:    &kuberbacv1alpha1.ValuesReferenceT{Name: "rbac-values"},
+			}}, "exactly one of configMapRef or secretRef"),
+	)
+
+	It("should read the values of Secrets when they are allowed", func() {
+		values, err := GetTemplateValues(ctx, fakeClient, newResource(secretRef("rbac-values", false)), true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{"token": "secret-token"}))
+	})
+
+	It("should render the values into the names of targets and the resourceNames of rules", func() {
+		resource := newResource()
+		resource.Spec.Target.Name = "{{ .Values.environment }}-readers"
+		resource.Spec.Deny = []kuberbacv1alpha1.DenyPolicyRuleT{{PolicyRule: rbacv1.PolicyRule{
+			APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"},
+			ResourceNames: []string{"{{ .Values.database }}"},
+		}}}
+
+		rendered, err := RenderTemplatedFields(resource, ClusterRoleTemplateData{
+			Values: map[string]string{"environment": "staging", "database": "postgres-staging"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rendered.Spec.Target.Name).To(Equal("staging-readers"))
+		Expect(rendered.Spec.Deny[0].ResourceNames).To(Equal([]string{"postgres-staging"}))
+		Expect(resource.Spec.Target.Name).To(Equal("{{ .Values.environment }}-readers"))
+	})
+
+	DescribeTable("When templates can not be rendered",
+		func(targetName string) {
+			resource := newResource()
+			resource.Spec.Target.Name = targetName
+
+			_, err := RenderTemplatedFields(resource, ClusterRoleTemplateData{Values: map[string]string{}})
+			Expect(err).To(MatchError(errInvalidSpec))
+		},
+		Entry("should reject malformed templates", "{{ .Values.environment"),
+		Entry("should reject names rendered empty", "{{ .Values.environment }}"),
+	)
+
+	It("should synchronize the DynamicClusterRoles reading values from the changed source", func() {
+		readers := newResource(configMapRef("rbac-values", false))
+		readers.Name = "readers"
+		writers := newResource(secretRef("rbac-values", false))
+		writers.Name = "writers"
+		others := newResource(configMapRef("rbac-values-production", false))
+		others.Name = "others"
+
+		reconciler := &DynamicClusterRoleReconciler{Client: newFakeClientBuilder().WithObjects(readers, writers, others).Build()}
+		changed := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "rbac-values", Namespace: "payments"}}
+
+		Expect(reconciler.mapValuesSourceToDynamicClusterRoles("ConfigMap")(ctx, changed)).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: "payments", Name: "readers"}},
+		}))
+		Expect(reconciler.mapValuesSourceToDynamicClusterRoles("Secret")(ctx, changed)).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: "payments", Name: "writers"}},
+		}))
+
+		changed.Namespace = "shipping"
+		Expect(reconciler.mapValuesSourceToDynamicClusterRoles("ConfigMap")(ctx, changed)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("DynamicClusterRole admission policies", func() {
+
+	ctx := context.Background()
+
+	resource := &kuberbacv1alpha1.DynamicClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kuberbacv1alpha1.GroupVersion.String(), Kind: DynamicClusterRoleResourceType},
+		ObjectMeta: metav1.ObjectMeta{Name: "developers", Namespace: "default"},
+	}
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": kuberbacv1alpha1.GroupVersion.String(),
+		"kuberbac.prosimcorp.com/owner-kind":       DynamicClusterRoleResourceType,
+		"kuberbac.prosimcorp.com/owner-name":       "developers",
+		"kuberbac.prosimcorp.com/owner-namespace":  "default",
+	}
+
+	denySecrets := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"delete", "get"}}
+
+	targetClusterRoles := func(target kuberbacv1alpha1.TargetT, denyRules ...rbacv1.PolicyRule) TargetClusterRolesT {
+		return TargetClusterRolesT{
+			Target:       target,
+			ClusterRoles: []rbacv1.ClusterRole{{ObjectMeta: metav1.ObjectMeta{Name: target.Name}}},
+			DenyRules:    denyRules,
+		}
+	}
+
+	DescribeTable("When translating deny rules into admission rules",
+		func(denyRule rbacv1.PolicyRule, expected []admissionregistrationv1.NamedRuleWithOperations) {
+			Expect(GetAdmissionRules([]rbacv1.PolicyRule{denyRule})).To(Equal(expected))
+		},
+		Entry("should only mirror the writing verbs", denySecrets,
+			[]admissionregistrationv1.NamedRuleWithOperations{{RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Delete},
+				Rule:       admissionregistrationv1.Rule{APIGroups: []string{""}, APIVersions: []string{"*"}, Resources: []string{"secrets"}},
+			}}}),
+		Entry("should drop the rules with only reading verbs",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list", "watch"}}, nil),
+		Entry("should drop the rules about nonResourceURLs",
+			rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"*"}}, nil),
+		Entry("should drop the rules whose resource names are all patterns",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"delete"}, ResourceNames: []string{"prod-*"}}, nil),
+		Entry("should include subresources for wildcard resources",
+			rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"*"}, ResourceNames: []string{"api", "prod-*"}},
+			[]admissionregistrationv1.NamedRuleWithOperations{{
+				ResourceNames: []string{"api"},
+				RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+					Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll},
+					Rule:       admissionregistrationv1.Rule{APIGroups: []string{"apps"}, APIVersions: []string{"*"}, Resources: []string{"*", "*/*"}},
+				},
+			}}),
+	)
+
+	DescribeTable("When building the admission policy of a target",
+		func(admissionFailurePolicy string, expected admissionregistrationv1.FailurePolicyType) {
+			policy, binding := BuildAdmissionPolicy(resource, targetClusterRoles(kuberbacv1alpha1.TargetT{
+				Name:                   "developers",
+				EmitAdmissionPolicy:    true,
+				AdmissionFailurePolicy: admissionFailurePolicy,
+			}, denySecrets), `"developers" in request.userInfo.groups`, referenceAnnotations)
+
+			Expect(*policy.Spec.FailurePolicy).To(Equal(expected))
+			Expect(policy.Spec.MatchConditions[0].Expression).To(Equal(`"developers" in request.userInfo.groups`))
+			Expect(policy.Annotations).To(Equal(referenceAnnotations))
+			Expect(binding.Spec.PolicyName).To(Equal(policy.Name))
+		},
+		Entry("should admit the requests when the policy can not be evaluated by default", "", admissionregistrationv1.Ignore),
+		Entry("should reject the requests when the policy can not be evaluated if asked", "Fail", admissionregistrationv1.Fail),
+	)
+
+	It("should not build an admission policy when no deny rule can be enforced on admission", func() {
+		policy, binding := BuildAdmissionPolicy(resource, targetClusterRoles(kuberbacv1alpha1.TargetT{Name: "developers"},
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}), "false", referenceAnnotations)
+		Expect(policy).To(BeNil())
+		Expect(binding).To(BeNil())
+	})
+
+	It("should apply the admission policies of the targets and delete the owned ones not needed anymore", func() {
+		stalePolicy := &admissionregistrationv1.ValidatingAdmissionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "developers-previous", Annotations: referenceAnnotations},
+		}
+		unownedPolicy := &admissionregistrationv1.ValidatingAdmissionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "platform"}}
+		fakeClient := newFakeApplyClient(stalePolicy, unownedPolicy, &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "developers"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "developers"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "developers"}},
+		})
+		reconciler := &DynamicClusterRoleReconciler{Client: fakeClient}
+
+		Expect(reconciler.SyncAdmissionPolicies(ctx, resource, []TargetClusterRolesT{
+			targetClusterRoles(kuberbacv1alpha1.TargetT{Name: "developers", EmitAdmissionPolicy: true}, denySecrets),
+			targetClusterRoles(kuberbacv1alpha1.TargetT{Name: "developers-without-policy"}, denySecrets),
+		}, referenceAnnotations)).To(Succeed())
+
+		policy := &admissionregistrationv1.ValidatingAdmissionPolicy{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "developers"}, policy)).To(Succeed())
+		Expect(policy.Spec.MatchConditions[0].Expression).To(Equal(`"developers" in request.userInfo.groups`))
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "developers"}, &admissionregistrationv1.ValidatingAdmissionPolicyBinding{})).To(Succeed())
+
+		err := fakeClient.Get(ctx, client.ObjectKey{Name: "developers-without-policy"}, &admissionregistrationv1.ValidatingAdmissionPolicy{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		err = fakeClient.Get(ctx, client.ObjectKey{Name: "developers-previous"}, &admissionregistrationv1.ValidatingAdmissionPolicy{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "platform"}, &admissionregistrationv1.ValidatingAdmissionPolicy{})).To(Succeed())
+	})
+
+	DescribeTable("When an admission policy or binding with the name of a target is not owned by the resource",
+		func(unownedObject client.Object) {
+			fakeClient := newFakeApplyClient(unownedObject)
+			reconciler := &DynamicClusterRoleReconciler{Client: fakeClient}
+
+			err := reconciler.SyncAdmissionPolicies(ctx, resource, []TargetClusterRolesT{
+				targetClusterRoles(kuberbacv1alpha1.TargetT{Name: "developers", EmitAdmissionPolicy: true}, denySecrets),
+			}, referenceAnnotations)
+			Expect(err).To(MatchError(errTargetOwnershipConflict))
+			Expect(err).To(MatchError(ContainSubstring("developers")))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(unownedObject), unownedObject)).To(Succeed())
+			Expect(unownedObject.GetAnnotations()).To(BeEmpty())
+		},
+		Entry("should not take over the policy",
+			&admissionregistrationv1.ValidatingAdmissionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "developers"}}),
+		Entry("should not take over the binding",
+			&admissionregistrationv1.ValidatingAdmissionPolicyBinding{ObjectMeta: metav1.ObjectMeta{Name: "developers"}}),
+	)
+
+	Context("When admission policies are not served by the cluster", func() {
+
+		// newClusterWithoutAdmissionPolicies returns a fake client failing as old clusters do for admission policies
+		newClusterWithoutAdmissionPolicies := func(objects ...client.Object) client.Client {
+			noMatchError := &apimeta.NoKindMatchError{
+				GroupKind:        admissionregistrationv1.SchemeGroupVersion.WithKind("ValidatingAdmissionPolicy").GroupKind(),
+				SearchedVersions: []string{"v1"},
+			}
+			isAdmissionPolicy := func(object runtime.Object) bool {
+				switch object.(type) {
+				case *admissionregistrationv1.ValidatingAdmissionPolicy, *admissionregistrationv1.ValidatingAdmissionPolicyBinding,
+					*admissionregistrationv1.ValidatingAdmissionPolicyList, *admissionregistrationv1.ValidatingAdmissionPolicyBindingList:
+					return true
+				}
+				return false
+			}
+
+			return newFakeClientBuilder().WithObjects(objects...).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, object client.Object, opts ...client.GetOption) error {
+					if isAdmissionPolicy(object) {
+						return noMatchError
+					}
+					return c.Get(ctx, key, object, opts...)
+				},
+				Create: func(ctx context.Context, c client.WithWatch, object client.Object, opts ...client.CreateOption) error {
+					if isAdmissionPolicy(object) {
+						return noMatchError
+					}
+					return c.Create(ctx, object, opts...)
+				},
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if isAdmissionPolicy(list) {
+						return noMatchError
+					}
+					return c.List(ctx, list, opts...)
+				},
+				Patch: func(ctx context.Context, c client.WithWatch, object client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if isAdmissionPolicy(object) {
+						return noMatchError
+					}
+					return c.Patch(ctx, object, patch, opts...)
+				},
+			}).Build()
+		}
+
+		It("should synchronize the targets not asking for admission policies", func() {
+			reconciler := &DynamicClusterRoleReconciler{Client: newClusterWithoutAdmissionPolicies()}
+			Expect(reconciler.SyncAdmissionPolicies(ctx, resource, []TargetClusterRolesT{
+				targetClusterRoles(kuberbacv1alpha1.TargetT{Name: "developers"}, denySecrets),
+			}, referenceAnnotations)).To(Succeed())
+		})
+
+		It("should reject the targets asking for admission policies as an invalid spec", func() {
+			reconciler := &DynamicClusterRoleReconciler{Client: newClusterWithoutAdmissionPolicies()}
+			err := reconciler.SyncAdmissionPolicies(ctx, resource, []TargetClusterRolesT{
+				targetClusterRoles(kuberbacv1alpha1.TargetT{Name: "developers", EmitAdmissionPolicy: true}, denySecrets),
+			}, referenceAnnotations)
+			Expect(err).To(MatchError(errInvalidSpec))
+		})
+
+		It("should release the rest of the generated resources on deletion", func() {
+			fakeClient := newClusterWithoutAdmissionPolicies(&rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "developers", Annotations: referenceAnnotations},
+			})
+			reconciler := &DynamicClusterRoleReconciler{Client: fakeClient}
+
+			Expect(reconciler.DeleteTargets(ctx, resource)).To(Succeed())
+			err := fakeClient.Get(ctx, client.ObjectKey{Name: "developers"}, &rbacv1.ClusterRole{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("DynamicClusterRole imported ClusterRoles", func() {
+
+	ctx := context.Background()
+
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": kuberbacv1alpha1.GroupVersion.String(),
+		"kuberbac.prosimcorp.com/owner-kind":       DynamicClusterRoleResourceType,
+		"kuberbac.prosimcorp.com/owner-name":       "developers",
+		"kuberbac.prosimcorp.com/owner-namespace":  "default",
+	}
+
+	readPods := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}
+	readSecrets := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+	readConfigMaps := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}
+
+	// newCluster returns a fake client holding some ClusterRoles, one of them generated by the DynamicClusterRole
+	newCluster := func(objects ...client.Object) client.Client {
+		return newFakeClientBuilder().WithObjects(append(objects,
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "view"}, Rules: []rbacv1.PolicyRule{readPods}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "team-secrets", Labels: map[string]string{"team": "developers"}},
+				Rules: []rbacv1.PolicyRule{readSecrets}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "developers", Labels: map[string]string{"team": "developers"},
+				Annotations: referenceAnnotations}, Rules: []rbacv1.PolicyRule{readConfigMaps}},
+		)...).Build()
+	}
+
+	newResource := func(name string, from ...kuberbacv1alpha1.ClusterRoleSourceT) *kuberbacv1alpha1.DynamicClusterRole {
+		return &kuberbacv1alpha1.DynamicClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       kuberbacv1alpha1.DynamicClusterRoleSpec{From: from},
+		}
+	}
+
+	teamSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "developers"}}
+
+	It("should import the rules of the ClusterRoles referenced by name or selector, except its own ones", func() {
+		rules, err := GetImportedPolicyRules(ctx, newCluster(), newResource("developers",
+			kuberbacv1alpha1.ClusterRoleSourceT{Name: "view"},
+			kuberbacv1alpha1.ClusterRoleSourceT{Selector: teamSelector},
+		), referenceAnnotations)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(Equal([]rbacv1.PolicyRule{readPods, readSecrets}))
+	})
+
+	DescribeTable("When a source does not set exactly one of name or selector",
+		func(source kuberbacv1alpha1.ClusterRoleSourceT) {
+			_, err := GetImportedPolicyRules(ctx, newCluster(), newResource("developers", source), referenceAnnotations)
+			Expect(err).To(MatchError(errInvalidSpec))
+		},
+		Entry("should be an invalid spec when both are set", kuberbacv1alpha1.ClusterRoleSourceT{Name: "view", Selector: teamSelector}),
+		Entry("should be an invalid spec when none is set", kuberbacv1alpha1.ClusterRoleSourceT{}),
+	)
+
+	It("should synchronize the DynamicClusterRoles importing a changed ClusterRole", func() {
+		fakeClient := newCluster(
+			newResource("developers", kuberbacv1alpha1.ClusterRoleSourceT{Selector: teamSelector}),
+			newResource("viewers", kuberbacv1alpha1.ClusterRoleSourceT{Name: "view"}),
+			newResource("auditors", kuberbacv1alpha1.ClusterRoleSourceT{Selector: teamSelector}),
+			newResource("unrelated"),
+		)
+		reconciler := &DynamicClusterRoleReconciler{Client: fakeClient}
+
+		requestNames := func(clusterRoleName string) (names []string) {
+			clusterRole := &rbacv1.ClusterRole{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: clusterRoleName}, clusterRole)).To(Succeed())
+			for _, request := range reconciler.mapImportedClusterRoleToDynamicClusterRoles(ctx, clusterRole) {
+				names = append(names, request.Name)
+			}
+			return names
+		}
+
+		Expect(requestNames("view")).To(ConsistOf("viewers"))
+		Expect(requestNames("team-secrets")).To(ConsistOf("developers", "auditors"))
+
+		By("not synchronizing a DynamicClusterRole on the changes of its own ClusterRoles")
+		Expect(requestNames("developers")).To(ConsistOf("auditors"))
+	})
+})
+
+var _ = Describe("DynamicClusterRole escalation protection", func() {
+
+	DescribeTable("When checking the generated rules against the ceiling",
+		func(allowedVerbs, allowedResources []string, policyRule rbacv1.PolicyRule, expectedError string) {
+			reconciler := &DynamicClusterRoleReconciler{
+				AllowedPrivilegedVerbs:     allowedVerbs,
+				AllowedPrivilegedResources: allowedResources,
+			}
+
+			err := reconciler.CheckPrivilegedVerbs([]rbacv1.PolicyRule{policyRule})
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(err).To(MatchError(errEscalationRejected))
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("should accept the rules without privileged verbs nor resources", nil, nil,
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods", "secrets"}, Verbs: []string{"get", "list", "delete"}}, ""),
+		Entry("should reject privileged verbs", nil, nil,
+			rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"clusterroles"}, Verbs: []string{"bind"}},
+			"privileged verbs not allowed: bind"),
+		Entry("should accept the privileged verbs explicitly allowed", []string{"bind"}, nil,
+			rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"clusterroles"}, Verbs: []string{"bind"}}, ""),
+		Entry("should reject wildcard verbs on RBAC objects, even with the privileged verbs allowed",
+			[]string{"bind", "escalate", "impersonate"}, nil,
+			rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"rolebindings"}, Verbs: []string{"*"}},
+			"writes to privileged resources not allowed: rolebindings.rbac.authorization.k8s.io"),
+		Entry("should accept explicit writes to RBAC objects, as the API server checks them", nil, nil,
+			rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"rolebindings"}, Verbs: []string{"create", "update"}}, ""),
+		Entry("should reject writes to Secrets", nil, nil,
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "patch"}},
+			"writes to privileged resources not allowed: secrets"),
+		Entry("should reject requesting ServiceAccount tokens", nil, nil,
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"serviceaccounts/token"}, Verbs: []string{"create"}},
+			"writes to privileged resources not allowed: serviceaccounts/token"),
+		Entry("should reject wildcard subresources matching ServiceAccount tokens", nil, nil,
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"*/token"}, Verbs: []string{"create"}},
+			"writes to privileged resources not allowed: serviceaccounts/token"),
+		Entry("should reject wildcard groups and resources", []string{"bind", "escalate", "impersonate"}, nil,
+			rbacv1.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			"writes to privileged resources not allowed: clusterrolebindings.rbac.authorization.k8s.io, "+
+				"clusterroles.rbac.authorization.k8s.io, rolebindings.rbac.authorization.k8s.io, "+
+				"roles.rbac.authorization.k8s.io, secrets, serviceaccounts/token"),
+		Entry("should report the privileged verbs and resources together", nil, nil,
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"create", "impersonate"}},
+			"privileged verbs not allowed: impersonate; writes to privileged resources not allowed: secrets, serviceaccounts/token"),
+		Entry("should accept the privileged resources explicitly allowed", nil, []string{"secrets", "serviceaccounts/token"},
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"create"}}, ""),
+	)
+})
+
+var _ = Describe("DynamicClusterRole rules compaction", func() {
+
+	DescribeTable("merging the rules granting exactly the same",
+		func(policyRules []rbacv1.PolicyRule, expected []rbacv1.PolicyRule) {
+			Expect(CompactPolicyRules(policyRules)).To(ConsistOf(expected))
+		},
+		Entry("names of the same resource",
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"b"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"a"}, Verbs: []string{"get"}},
+			},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"a", "b"}, Verbs: []string{"get"}},
+			}),
+		Entry("named rules with the ones granting every name",
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"a"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"a"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			}),
+		Entry("resources of the same group, and then the groups with the same resources",
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"list"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"statefulsets"}, Verbs: []string{"list"}},
+				{APIGroups: []string{"extensions"}, Resources: []string{"deployments"}, Verbs: []string{"list"}},
+				{APIGroups: []string{"extensions"}, Resources: []string{"statefulsets"}, Verbs: []string{"list"}},
+			},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{"apps", "extensions"}, Resources: []string{"deployments", "statefulsets"}, Verbs: []string{"list"}},
+			}),
+		Entry("nothing with different verbs",
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list"}},
+			},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list"}},
+			}),
+		Entry("non-resource URLs apart from the resources",
+			[]rbacv1.PolicyRule{
+				{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+				{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+				{NonResourceURLs: []string{"/healthz", "/metrics"}, Verbs: []string{"get"}},
+			}),
+	)
+})
+
+var _ = Describe("DynamicClusterRole sharding", func() {
+
+	newClusterRole := func(rulesCount int, labels map[string]string) rbacv1.ClusterRole {
+		clusterRole := rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "developers", Labels: labels}}
+		for index := range rulesCount {
+			clusterRole.Rules = append(clusterRole.Rules, rbacv1.PolicyRule{
+				APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{strings.Repeat("a", index+1)}, Verbs: []string{"get"},
+			})
+		}
+		return clusterRole
+	}
+
+	It("should return the ClusterRole untouched when sharding is not needed", func() {
+		clusterRole := newClusterRole(3, nil)
+
+		for _, maxRules := range []int{0, 3} {
+			shards, err := ShardClusterRole(clusterRole, maxRules)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(shards).To(Equal([]rbacv1.ClusterRole{clusterRole}))
+		}
+	})
+
+	It("should split the rules into shards aggregated by the ClusterRole", func() {
+		clusterRole := newClusterRole(5, nil)
+
+		shards, err := ShardClusterRole(clusterRole, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shards).To(HaveLen(4))
+
+		Expect(shards[0].Name).To(Equal("developers"))
+		Expect(shards[0].Rules).To(BeEmpty())
+		Expect(shards[0].AggregationRule.ClusterRoleSelectors).To(Equal([]metav1.LabelSelector{
+			{MatchLabels: map[string]string{aggregatedShardLabel: "developers"}},
+		}))
+
+		aggregatedRules := []rbacv1.PolicyRule{}
+		for index, shard := range shards[1:] {
+			Expect(shard.Name).To(Equal("developers-shard-" + strconv.Itoa(index)))
+			Expect(len(shard.Rules)).To(BeNumerically("<=", 2))
+			Expect(shard.Labels).To(HaveKeyWithValue(aggregatedShardLabel, "developers"))
+			aggregatedRules = append(aggregatedRules, shard.Rules...)
+		}
+		Expect(aggregatedRules).To(Equal(clusterRole.Rules))
+	})
+
+	It("should only keep the owned labels on the shards", func() {
+		clusterRole := newClusterRole(2, map[string]string{
+			"rbac.authorization.k8s.io/aggregate-to-admin": "true",
+			scopeLabel: scopeLabelNamespace,
+		})
+
+		shards, err := ShardClusterRole(clusterRole, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shards[0].Labels).To(Equal(clusterRole.Labels))
+		for _, shard := range shards[1:] {
+			Expect(shard.Labels).To(Equal(map[string]string{aggregatedShardLabel: "developers", scopeLabel: scopeLabelNamespace}))
+		}
+	})
+
+	It("should reject the ClusterRoles labelled as shards", func() {
+		for _, maxRules := range []int{0, 1} {
+			_, err := ShardClusterRole(newClusterRole(2, map[string]string{aggregatedShardLabel: "platform"}), maxRules)
+			Expect(err).To(MatchError(errInvalidSpec))
+		}
+	})
+})
@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"fmt"
+	"strings"
+
 	"prosimcorp.com/kuberbac/internal/globals"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func (r *DynamicClusterRoleReconciler) UpdateConditionSuccess(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole) {
@@ -16,11 +20,79 @@ func (r *DynamicClusterRoleReconciler) UpdateConditionSuccess(dynamicClusterRole
 	globals.UpdateCondition(&dynamicClusterRole.Status.Conditions, condition)
 }
 
-func (r *DynamicClusterRoleReconciler) UpdateConditionKubernetesApiCallFailure(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole) {
+func (r *DynamicClusterRoleReconciler) UpdateConditionSyncFailure(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole, err error) (reason string) {
+
+	//
+	condition := syncFailureCondition(err)
+
+	globals.UpdateCondition(&dynamicClusterRole.Status.Conditions, condition)
+	return condition.Reason
+}
+
+func (r *DynamicClusterRoleReconciler) UpdateConditionDryRun(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole) {
 
 	//
 	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionTrue,
-		globals.ConditionReasonKubernetesApiCallErrorType, globals.ConditionReasonKubernetesApiCallErrorMessage)
+		globals.ConditionReasonTargetRendered, globals.ConditionReasonTargetRenderedMessage)
+
+	globals.UpdateCondition(&dynamicClusterRole.Status.Conditions, condition)
+}
+
+func (r *DynamicClusterRoleReconciler) UpdateConditionEscalationRejected(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionFalse,
+		globals.ConditionReasonEscalationRejectedType, globals.ConditionReasonEscalationRejectedMessage)
+
+	globals.UpdateCondition(&dynamicClusterRole.Status.Conditions, condition)
+}
+
+func (r *DynamicClusterRoleReconciler) UpdateConditionTargetOwnershipConflict(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionFalse,
+		globals.ConditionReasonTargetOwnershipConflictType, globals.ConditionReasonTargetOwnershipConflictMessage)
+
+	globals.UpdateCondition(&dynamicClusterRole.Status.Conditions, condition)
+}
+
+func (r *DynamicClusterRoleReconciler) UpdateConditionSizePressure(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole, approachingLimit bool) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeSizePressure, metav1.ConditionFalse,
+		globals.ConditionReasonWithinSizeLimitType, globals.ConditionReasonWithinSizeLimitMessage)
+
+	if approachingLimit {
+		condition = globals.NewCondition(globals.ConditionTypeSizePressure, metav1.ConditionTrue,
+			globals.ConditionReasonApproachingSizeLimitType, globals.ConditionReasonApproachingSizeLimitMessage)
+	}
+
+	globals.UpdateCondition(&dynamicClusterRole.Status.Conditions, condition)
+}
+
+func (r *DynamicClusterRoleReconciler) UpdateConditionTargetConflict(dynamicClusterRole *kuberbacv1alpha1.DynamicClusterRole,
+	targetNameConflicts map[string][]client.ObjectKey) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeTargetConflict, metav1.ConditionFalse,
+		globals.ConditionReasonNoTargetConflictType, globals.ConditionReasonNoTargetConflictMessage)
+
+	if len(targetNameConflicts) > 0 {
+		details := []string{}
+		for _, targetName := range sortedKeys(targetNameConflicts) {
+			resources := []string{}
+			for _, resourceKey := range targetNameConflicts[targetName] {
+				if resourceKey != client.ObjectKeyFromObject(dynamicClusterRole) {
+					resources = append(resources, resourceKey.String())
+				}
+			}
+			details = append(details, fmt.Sprintf("'%s' is also declared by %s", targetName, strings.Join(resources, ", ")))
+		}
+
+		condition = globals.NewCondition(globals.ConditionTypeTargetConflict, metav1.ConditionTrue,
+			globals.ConditionReasonTargetNameSharedType,
+			globals.ConditionReasonTargetNameSharedMessage+": "+strings.Join(details, "; "))
+	}
 
 	globals.UpdateCondition(&dynamicClusterRole.Status.Conditions, condition)
 }
@@ -2,663 +2,1901 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
-	"time"
 
 	"golang.org/x/exp/maps"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/discovery"
+	"k8s.io/apimachinery/pkg/util/validation"
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
 	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/metrics"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+	"prosimcorp.com/kuberbac/pkg/policy"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 )
 
 const (
-	// parseSyncTimeError error message for invalid value on 'synchronization' parameter
-	parseSyncTimeError = "can not parse the synchronization time from dynamicClusterRole: %s"
+	// explanationConfigMapSuffix and explanationConfigMapKey define where the explanation of the generated rules is written
+	explanationConfigMapSuffix = "-explanation"
+	explanationConfigMapKey    = "explanation.yaml"
+
+	// exportConfigMapKey defines where the rules of a target are written inside its export ConfigMap.
+	// exportConfigMapLabel marks the export ConfigMaps, so external tools can find them
+	exportConfigMapKey   = "rules.yaml"
+	exportConfigMapLabel = "kuberbac.prosimcorp.com/exported-rules"
+
+	// aggregatedShardLabel selects the shards aggregated into the ClusterRole of a target. Its value is the target name.
+	// Shards only carry the labels under ownedLabelPrefix, so no other aggregating ClusterRole can select them
+	aggregatedShardLabel = "kuberbac.prosimcorp.com/aggregate-to"
+	ownedLabelPrefix     = "kuberbac.prosimcorp.com/"
+
+	// scopeLabel marks the ClusterRoles generated for a single scope when separating scopes: 'cluster' or 'namespace'.
+	// DynamicRoleBindings generating both kinds of bindings use it to bind each of them only with the right kind
+	scopeLabel          = "kuberbac.prosimcorp.com/scope"
+	scopeLabelCluster   = "cluster"
+	scopeLabelNamespace = "namespace"
+
+	// clusterRoleSizeWarningBytes is the size considered too close to the object size limit of etcd, 1.5MiB by default
+	clusterRoleSizeWarningBytes = 1024 * 1024
+
+	// stretchedRulesWarningCount is the number of stretched allow rules considered a sign of pathological wildcards
+	stretchedRulesWarningCount = 10000
+
+	// largestAllowSourcesCount is the number of allow rules reported when some size threshold is exceeded
+	largestAllowSourcesCount = 5
 )
 
-// GVKR represents a resource type inside Kubernetes
-type GVKR struct {
-	GVK         schema.GroupVersionKind
-	Resource    string
-	Subresource string
+var (
+	// PrivilegedVerbs are those verbs that allow a subject to grant permissions beyond its own ones
+	// Ref: https://kubernetes.io/docs/concepts/security/rbac-good-practices/#escalate-verb
+	PrivilegedVerbs = []string{"bind", "escalate", "impersonate"}
+
+	// PrivilegedResources are those resources whose write access allows a subject to get permissions beyond its own ones,
+	// named as '<resource>.<group>', with the verbs granting it. Wildcard verbs contain all of them.
+	// Secrets can hold ServiceAccount tokens, and RBAC objects under wildcard verbs can be bound and escalated
+	PrivilegedResources = map[string][]string{
+		"roles.rbac.authorization.k8s.io":               {"*"},
+		"clusterroles.rbac.authorization.k8s.io":        {"*"},
+		"rolebindings.rbac.authorization.k8s.io":        {"*"},
+		"clusterrolebindings.rbac.authorization.k8s.io": {"*"},
+		"secrets":               {"create", "update", "patch"},
+		"serviceaccounts/token": {"create"},
+	}
 
-	//
-	Namespaced  bool
-	UsableVerbs []string // Intended for future use polishing resulting verbs
+	// errEscalationRejected is returned when generated rules exceed the ceiling configured in the operator
+	errEscalationRejected = errors.New("escalation rejected")
+
+	// errTargetOwnershipConflict is returned when a target name is taken by a ClusterRole not owned by the DynamicClusterRole
+	errTargetOwnershipConflict = errors.New("target ownership conflict")
+)
+
+// PolicyRuleSourceT represents a rule of a DynamicClusterRole, named after the place where it is defined
+type PolicyRuleSourceT struct {
+	Name string
+	Rule rbacv1.PolicyRule
 }
 
-// PolicyRulesProcessorT represents the things done
-// in the backstage to process PolicyRules
-type PolicyRulesProcessorT struct {
-	Context context.Context
+// AllowSourceExpansionT represents the number of stretched rules produced by a single allow rule
+type AllowSourceExpansionT struct {
+	Source         string
+	Rule           string
+	StretchedRules int
+}
 
-	//
-	Client          client.Client
-	DiscoveryClient discovery.DiscoveryClient
+// RuleExpansionT summarizes how much the allow rules of a DynamicClusterRole grow once stretched
+type RuleExpansionT struct {
+	StretchedRules int
 
-	//
-	ResourcesByGroup map[string][]GVKR
-	ResourceList     []string
+	// LargestSources are the allow rules producing more stretched rules.
+	// They are only computed when some threshold is exceeded, as it requires stretching each rule alone
+	LargestSources []AllowSourceExpansionT
 }
 
-func NewPolicyRuleProcessor(context context.Context, client client.Client, discoveryClient discovery.DiscoveryClient) (prp PolicyRulesProcessorT, err error) {
-	prp.Context = context
-	prp.Client = client
-	prp.DiscoveryClient = discoveryClient
+// GetLargestAllowSources returns the allow rules producing more stretched rules, sorted from the largest one
+func GetLargestAllowSources(p *policy.ProcessorT, allowSources []PolicyRuleSourceT, limit int) (result []AllowSourceExpansionT) {
 
-	err = prp.SetResourcesByGroup()
-	if err != nil {
-		return prp, err
+	for _, source := range allowSources {
+		stretchedRules := p.StretchPolicyRules(p.ExpandPolicyRules([]rbacv1.PolicyRule{source.Rule}))
+		result = append(result, AllowSourceExpansionT{
+			Source:         source.Name,
+			Rule:           FormatPolicyRule(source.Rule),
+			StretchedRules: len(p.GetMapFromStretchedPolicyRules(stretchedRules)),
+		})
 	}
-	prp.SetResourceList()
 
-	return prp, err
+	slices.SortStableFunc(result, func(a, b AllowSourceExpansionT) int {
+		return b.StretchedRules - a.StretchedRules
+	})
+
+	return result[:min(len(result), limit)]
 }
 
-// SetResourcesByGroup retrieves all resources available in the cluster
-// and store a map of groups with their resources inside it into the PolicyRulesProcessorT struct
-func (p *PolicyRulesProcessorT) SetResourcesByGroup() (err error) {
+// RuleExplanationT maps a generated PolicyRule to the rules producing it
+type RuleExplanationT struct {
+	Rule      string   `json:"rule"`
+	AllowedBy []string `json:"allowedBy"`
+	TrimmedBy []string `json:"trimmedBy,omitempty"`
+}
 
-	p.ResourcesByGroup = make(map[string][]GVKR)
+// ExplainPolicyRules maps each PolicyRule of the result map to the sources producing it: the allow rules granting it,
+// and the deny rules removing some of the verbs it had before evaluating them, as kept in the evaluated allow map
+func ExplainPolicyRules(p *policy.ProcessorT, allowSources, denySources []PolicyRuleSourceT,
+	evaluatedAllowMap, resultMap map[string]rbacv1.PolicyRule) (result []RuleExplanationT) {
 
-	// Retrieve all types of resources available in the cluster
-	_, apiGroupResourcesLists, err := p.DiscoveryClient.ServerGroupsAndResources()
-	if err != nil {
-		return err
+	// Process each source alone, so its keys can be compared with the ones of the result
+	getSourceMaps := func(sources []PolicyRuleSourceT) (sourceMaps []map[string]rbacv1.PolicyRule) {
+		for _, source := range sources {
+			stretchedRules := p.StretchPolicyRules(p.ExpandPolicyRules([]rbacv1.PolicyRule{source.Rule}))
+			sourceMaps = append(sourceMaps, p.GetMapFromStretchedPolicyRules(stretchedRules))
+		}
+		return sourceMaps
 	}
+	allowSourceMaps := getSourceMaps(allowSources)
+	denySourceMaps := getSourceMaps(denySources)
 
-	// Process the resources and group them by API group
-	for _, resourcesLists := range apiGroupResourcesLists {
-
-		//
-		groupVersion := strings.Split(resourcesLists.GroupVersion, "/")
-
-		//
-		group := ""
-		version := groupVersion[0]
+	resultKeys := maps.Keys(resultMap)
+	slices.Sort(resultKeys)
+	for _, resultKey := range resultKeys {
 
-		if len(groupVersion) == 2 {
-			group = groupVersion[0]
-			version = groupVersion[1]
+		explanation := RuleExplanationT{
+			Rule:      FormatPolicyRule(resultMap[resultKey]),
+			AllowedBy: []string{},
 		}
 
-		p.ResourcesByGroup[group] = []GVKR{}
-
-		for _, apiResource := range resourcesLists.APIResources {
+		// Rules with resourceNames can come from allow rules without them,
+		// expanded to every object when some of the names are denied
+		genericKey := resultKey
+		if !strings.HasPrefix(resultKey, "nonresourceurl#") {
+			keyParts := strings.Split(resultKey, "#")
+			genericKey = keyParts[0] + "#" + keyParts[1] + "#"
+		}
 
-			resourceSubResource := strings.Split(apiResource.Name, "/")
-			resource := resourceSubResource[0]
-			subresource := ""
-			if len(resourceSubResource) > 1 {
-				subresource = strings.Join(resourceSubResource[1:], "/")
+		for index, sourceMap := range allowSourceMaps {
+			_, found := sourceMap[resultKey]
+			_, genericFound := sourceMap[genericKey]
+			if (found || genericFound) && !slices.Contains(explanation.AllowedBy, allowSources[index].Name) {
+				explanation.AllowedBy = append(explanation.AllowedBy, allowSources[index].Name)
 			}
-			p.ResourcesByGroup[group] = append(p.ResourcesByGroup[group], GVKR{
-				Resource:    resource,
-				Subresource: subresource,
-				GVK: schema.GroupVersionKind{
-					Group:   group,
-					Version: version,
-					Kind:    apiResource.Kind,
-				},
-				Namespaced:  apiResource.Namespaced,
-				UsableVerbs: apiResource.Verbs,
-			})
 		}
-	}
 
-	return err
-}
+		allowedVerbs := evaluatedAllowMap[resultKey].Verbs
+		for index, sourceMap := range denySourceMaps {
+			for denyKey, denyRule := range sourceMap {
+				if !policy.MatchDenyKey(denyKey, resultKey) ||
+					!slices.ContainsFunc(denyRule.Verbs, func(verb string) bool { return slices.Contains(allowedVerbs, verb) }) {
+					continue
+				}
 
-// SetResourceList constructs a simple list of resources available in the cluster
-// and store it into the PolicyRulesProcessorT struct
-func (p *PolicyRulesProcessorT) SetResourceList() {
-	for _, resList := range p.ResourcesByGroup {
-		for _, res := range resList {
-			if res.Subresource != "" {
-				p.ResourceList = append(p.ResourceList, res.Resource+"/"+res.Subresource)
-				continue
+				if !slices.Contains(explanation.TrimmedBy, denySources[index].Name) {
+					explanation.TrimmedBy = append(explanation.TrimmedBy, denySources[index].Name)
+				}
+				break
 			}
-
-			p.ResourceList = append(p.ResourceList, res.Resource)
 		}
+
+		result = append(result, explanation)
 	}
+
+	return result
 }
 
-// GetSurvivingVerbs returns allowed verbs that are not in the deny list
-func (p *PolicyRulesProcessorT) GetSurvivingVerbs(allowVerbs []string, denyVerbs []string) (result []string) {
-	tmpMap := map[string]int{}
+// mergePolicyRules merges the rules sharing the same key, keeping the order in which the keys are found first
+func mergePolicyRules(policyRules []rbacv1.PolicyRule, key func(rbacv1.PolicyRule) string,
+	merge func(merged *rbacv1.PolicyRule, policyRule rbacv1.PolicyRule)) (result []rbacv1.PolicyRule) {
 
-	for _, allowVerbsVal := range allowVerbs { // list
-		tmpMap[allowVerbsVal] = 1
-	}
+	indexByKey := map[string]int{}
+	for _, policyRule := range policyRules {
+		ruleKey := key(policyRule)
 
-	for _, denyVerbsVal := range denyVerbs { // get
-		if _, ok := tmpMap[denyVerbsVal]; !ok {
+		if index, found := indexByKey[ruleKey]; found {
+			merge(&result[index], policyRule)
 			continue
 		}
 
-		tmpMap[denyVerbsVal] = tmpMap[denyVerbsVal] + 1
-	}
-
-	for tmpMapKey, tmpMapVal := range tmpMap {
-		if tmpMapVal == 1 {
-			result = append(result, tmpMapKey)
-		}
+		indexByKey[ruleKey] = len(result)
+		result = append(result, *policyRule.DeepCopy())
 	}
 
 	return result
 }
 
-// ExpandPolicyRules gets a list of PolicyRules and expands wildcard items to specific ones
-func (p *PolicyRulesProcessorT) ExpandPolicyRules(policyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
-
-	for _, policyRule := range policyRules {
+// joinSorted returns a sorted representation of a list, used to compare lists regardless of their order
+func joinSorted(list []string) string {
+	sorted := slices.Clone(list)
+	slices.Sort(sorted)
+	return strings.Join(sorted, ",")
+}
 
-		// No verbs? Kubernets will ignore you, so we will too
-		if len(policyRule.Verbs) == 0 {
-			continue
-		}
+// appendSorted appends the items not present in a list, keeping it sorted
+func appendSorted(list []string, items ...string) []string {
+	list = append(list, items...)
+	slices.Sort(list)
+	return slices.Compact(list)
+}
 
-		// Rules with NonResourceUrls can NOT come with APIGroups or Resources or ResourceNames
-		if len(policyRule.NonResourceURLs) != 0 &&
-			(len(policyRule.APIGroups) != 0 || len(policyRule.Resources) != 0 || len(policyRule.ResourceNames) != 0) {
-			continue
-		}
+// CompactPolicyRules merges the stretched rules sharing the same verbs into fewer rules. A PolicyRule grants
+// the verbs for every combination of its groups, resources and names, so rules are only merged when the result
+// grants exactly the same: first the names of the same resource, then the resources of the same group
+// with the same names, and last the groups with the same resources and names
+func CompactPolicyRules(policyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
 
-		// Rules without NonResourceUrls MUST come with APIgroups and Resources defined
-		if len(policyRule.NonResourceURLs) == 0 &&
-			(len(policyRule.APIGroups) == 0 || len(policyRule.Resources) == 0) {
+	nonResourceRules := []rbacv1.PolicyRule{}
+	resourceRules := []rbacv1.PolicyRule{}
+	for _, policyRule := range policyRules {
+		if len(policyRule.NonResourceURLs) > 0 {
+			nonResourceRules = append(nonResourceRules, policyRule)
 			continue
 		}
+		resourceRules = append(resourceRules, policyRule)
+	}
 
-		// Rules with ResourceNames MUST come with Resources and APIGroups defined
-		if len(policyRule.ResourceNames) != 0 &&
-			(len(policyRule.APIGroups) == 0 || len(policyRule.Resources) == 0) {
-			continue
-		}
+	// NonResourceURLs sharing the same verbs
+	nonResourceRules = mergePolicyRules(nonResourceRules,
+		func(policyRule rbacv1.PolicyRule) string {
+			return joinSorted(policyRule.Verbs)
+		},
+		func(merged *rbacv1.PolicyRule, policyRule rbacv1.PolicyRule) {
+			merged.NonResourceURLs = appendSorted(merged.NonResourceURLs, policyRule.NonResourceURLs...)
+		})
+
+	// Names of the same resource. Rules without names grant all of them, so they are never merged with named ones
+	resourceRules = mergePolicyRules(resourceRules,
+		func(policyRule rbacv1.PolicyRule) string {
+			return joinSorted(policyRule.APIGroups) + "#" + joinSorted(policyRule.Resources) + "#" +
+				joinSorted(policyRule.Verbs) + "#" + strconv.FormatBool(len(policyRule.ResourceNames) > 0)
+		},
+		func(merged *rbacv1.PolicyRule, policyRule rbacv1.PolicyRule) {
+			merged.ResourceNames = appendSorted(merged.ResourceNames, policyRule.ResourceNames...)
+		})
+
+	// Resources of the same group with the same names
+	resourceRules = mergePolicyRules(resourceRules,
+		func(policyRule rbacv1.PolicyRule) string {
+			return joinSorted(policyRule.APIGroups) + "#" + joinSorted(policyRule.ResourceNames) + "#" + joinSorted(policyRule.Verbs)
+		},
+		func(merged *rbacv1.PolicyRule, policyRule rbacv1.PolicyRule) {
+			merged.Resources = appendSorted(merged.Resources, policyRule.Resources...)
+		})
+
+	// Groups with the same resources and names
+	resourceRules = mergePolicyRules(resourceRules,
+		func(policyRule rbacv1.PolicyRule) string {
+			return joinSorted(policyRule.Resources) + "#" + joinSorted(policyRule.ResourceNames) + "#" + joinSorted(policyRule.Verbs)
+		},
+		func(merged *rbacv1.PolicyRule, policyRule rbacv1.PolicyRule) {
+			merged.APIGroups = appendSorted(merged.APIGroups, policyRule.APIGroups...)
+		})
 
-		//
-		newPolicyRule := rbacv1.PolicyRule{}
-
-		// 1. Expand groups in the PolicyRule.
-		// Add all of them or user-specified ones.
-		if slices.Contains(policyRule.APIGroups, "*") {
-			for group := range p.ResourcesByGroup {
-				newPolicyRule.APIGroups = append(newPolicyRule.APIGroups, group)
-			}
-		} else {
-			for _, group := range policyRule.APIGroups {
-				if _, ok := p.ResourcesByGroup[group]; ok {
-					newPolicyRule.APIGroups = append(newPolicyRule.APIGroups, group)
-				}
-			}
-		}
+	result = append(resourceRules, nonResourceRules...)
+	return result
+}
 
-		// 2. Expand resources in the PolicyRule.
-		// Add all of them or user-specified ones.
-		if slices.Contains(policyRule.Resources, "*") {
+// withLabel returns a copy of the labels with an extra one, as the labels of the targets are shared between ClusterRoles
+func withLabel(labels map[string]string, key, value string) (result map[string]string) {
 
-			// Replace '*' with all resources owned by groups defined in the PolicyRule
-			// Loop over defined groups, probe their existence, and get their probed resources
-			for _, group := range newPolicyRule.APIGroups {
+	result = maps.Clone(labels)
+	if result == nil {
+		result = map[string]string{}
+	}
+	result[key] = value
 
-				if _, ok := p.ResourcesByGroup[group]; ok {
+	return result
+}
 
-					for _, gvkr := range p.ResourcesByGroup[group] {
+// ShardClusterRole splits the rules of a ClusterRole into several ones, named '<name>-shard-<index>', with at most
+// maxRules each. The original ClusterRole aggregates them, so Kubernetes fills its rules with those of the shards.
+// It is returned untouched when sharding is disabled or not needed
+func ShardClusterRole(clusterRole rbacv1.ClusterRole, maxRules int) (result []rbacv1.ClusterRole, err error) {
 
-						if gvkr.Subresource != "" {
-							newPolicyRule.Resources = append(newPolicyRule.Resources, gvkr.Resource+"/"+gvkr.Subresource)
-							continue
-						}
+	// Targets labelled as shards would have their rules aggregated into the ClusterRole of another target
+	if _, isShard := clusterRole.Labels[aggregatedShardLabel]; isShard {
+		return result, fmt.Errorf("%w: ClusterRole '%s' can not be labelled with '%s'", errInvalidSpec, clusterRole.Name, aggregatedShardLabel)
+	}
 
-						newPolicyRule.Resources = append(newPolicyRule.Resources, gvkr.Resource)
-					}
-				}
-			}
-		} else {
+	if maxRules <= 0 || len(clusterRole.Rules) <= maxRules {
+		return []rbacv1.ClusterRole{clusterRole}, err
+	}
 
-			for _, resource := range policyRule.Resources {
+	// Label values are shorter than names, and the name of the ClusterRole is used to select the shards
+	if errs := validation.IsValidLabelValue(clusterRole.Name); len(errs) > 0 {
+		return result, fmt.Errorf("%w: ClusterRole '%s' can not be sharded: %s", errInvalidSpec, clusterRole.Name, strings.Join(errs, ", "))
+	}
 
-				// Add only resources that exists
-				if slices.Contains(p.ResourceList, resource) {
-					newPolicyRule.Resources = append(newPolicyRule.Resources, resource)
-				}
-			}
-		}
+	aggregatedClusterRole := *clusterRole.DeepCopy()
+	aggregatedClusterRole.Rules = nil
+	aggregatedClusterRole.AggregationRule = &rbacv1.AggregationRule{
+		ClusterRoleSelectors: []metav1.LabelSelector{
+			{MatchLabels: map[string]string{aggregatedShardLabel: clusterRole.Name}},
+		},
+	}
+	result = append(result, aggregatedClusterRole)
 
-		// 2.1. This is a middle cleanup step after previous expansions
-		// Delete groups that should NOT be there for the resources present in the PolicyRule
-		// When the resource type is not found, delete it too
-		newGroupList := []string{}
-		for _, resource := range newPolicyRule.Resources {
-			for _, group := range newPolicyRule.APIGroups {
+	for start := 0; start < len(clusterRole.Rules); start += maxRules {
+		shard := *clusterRole.DeepCopy()
+		shard.Name = fmt.Sprintf("%s-shard-%d", clusterRole.Name, start/maxRules)
+		shard.Rules = shard.Rules[start:min(start+maxRules, len(shard.Rules))]
 
-				// Add group to marked-groups only when a resource type is found for that group in the huge map
-				for _, gvkr := range p.ResourcesByGroup[group] {
-					resourceType := strings.Split(resource, "/")[0]
-					if strings.Compare(gvkr.Resource, resourceType) == 0 && !slices.Contains(newGroupList, group) {
-						newGroupList = append(newGroupList, group)
-						break
-					}
-				}
+		shard.Labels = map[string]string{aggregatedShardLabel: clusterRole.Name}
+		for key, value := range clusterRole.Labels {
+			if strings.HasPrefix(key, ownedLabelPrefix) {
+				shard.Labels[key] = value
 			}
 		}
-		newPolicyRule.APIGroups = newGroupList
 
-		// 3. Add some fields as it
-		newPolicyRule.ResourceNames = policyRule.ResourceNames
-		newPolicyRule.NonResourceURLs = policyRule.NonResourceURLs
+		result = append(result, shard)
+	}
 
-		// 4. Expand verbs in the PolicyRule.
-		if slices.Contains(policyRule.Verbs, "*") {
-			newPolicyRule.Verbs = []string{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}
-		} else {
-			newPolicyRule.Verbs = policyRule.Verbs
-		}
+	return result, err
+}
 
-		result = append(result, newPolicyRule)
+// GetClusterRoleSize returns the size of a ClusterRole once serialized, to compare it with the object size limits
+func GetClusterRoleSize(clusterRole rbacv1.ClusterRole) int {
+	clusterRoleBytes, err := json.Marshal(clusterRole)
+	if err != nil {
+		return 0
 	}
-
-	return result
+	return len(clusterRoleBytes)
 }
 
-// StretchPolicyRules gets a list of complex PolicyRules and returns a new list with single resource per item
-func (p *PolicyRulesProcessorT) StretchPolicyRules(policyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
+// CheckPrivilegedVerbs returns an error when some of the rules contain privileged verbs, or write privileged resources,
+// not explicitly allowed. Wildcard verbs are considered to contain all of them
+func (r *DynamicClusterRoleReconciler) CheckPrivilegedVerbs(policyRules []rbacv1.PolicyRule) (err error) {
 
+	var rejectedVerbs []string
 	for _, policyRule := range policyRules {
+		for _, verb := range policyRule.Verbs {
 
-		// Append rules with NonResourceURLs without expansion
-		if len(policyRule.NonResourceURLs) > 0 {
-			for _, url := range policyRule.NonResourceURLs {
-				result = append(result, rbacv1.PolicyRule{
-					NonResourceURLs: []string{url},
-					Verbs:           policyRule.Verbs,
-				})
+			candidateVerbs := []string{verb}
+			if verb == "*" {
+				candidateVerbs = PrivilegedVerbs
+			}
+
+			for _, candidateVerb := range candidateVerbs {
+				if !slices.Contains(PrivilegedVerbs, candidateVerb) ||
+					slices.Contains(r.AllowedPrivilegedVerbs, candidateVerb) ||
+					slices.Contains(rejectedVerbs, candidateVerb) {
+					continue
+				}
+				rejectedVerbs = append(rejectedVerbs, candidateVerb)
 			}
+		}
+	}
+
+	var rejectedResources []string
+	for privilegedResource, privilegedVerbs := range PrivilegedResources {
+		if slices.Contains(r.AllowedPrivilegedResources, privilegedResource) {
 			continue
 		}
 
-		// Append the rest of the rules expanding them
-		// We are checking that resource exists in a group
-		for _, resource := range policyRule.Resources {
+		for _, policyRule := range policyRules {
+			if grantsPrivilegedResource(policyRule, privilegedResource, privilegedVerbs) {
+				rejectedResources = append(rejectedResources, privilegedResource)
+				break
+			}
+		}
+	}
 
-			for _, group := range policyRule.APIGroups {
+	var rejections []string
+	if len(rejectedVerbs) > 0 {
+		slices.Sort(rejectedVerbs)
+		rejections = append(rejections, "privileged verbs not allowed: "+strings.Join(rejectedVerbs, ", "))
+	}
+	if len(rejectedResources) > 0 {
+		slices.Sort(rejectedResources)
+		rejections = append(rejections, "writes to privileged resources not allowed: "+strings.Join(rejectedResources, ", "))
+	}
 
-				//
-				resourceFound := false
-				for _, gvkr := range p.ResourcesByGroup[group] {
+	if len(rejections) > 0 {
+		err = fmt.Errorf("%w: %s", errEscalationRejected, strings.Join(rejections, "; "))
+	}
 
-					tmpResourceName := gvkr.Resource
-					if gvkr.Subresource != "" {
-						tmpResourceName += "/" + gvkr.Subresource
-					}
+	return err
+}
 
-					if strings.Compare(tmpResourceName, resource) == 0 {
-						resourceFound = true
-					}
-				}
+// grantsPrivilegedResource returns whether the rule grants some of the given verbs on the resource,
+// named as '<resource>.<group>'. Wildcards are matched the same way the API server does
+func grantsPrivilegedResource(policyRule rbacv1.PolicyRule, privilegedResource string, privilegedVerbs []string) bool {
 
-				if !resourceFound {
-					continue
-				}
+	resource, group, _ := strings.Cut(privilegedResource, ".")
+	_, subresource, _ := strings.Cut(resource, "/")
 
-				//
-				if len(policyRule.ResourceNames) != 0 {
-					for _, name := range policyRule.ResourceNames {
-						result = append(result, rbacv1.PolicyRule{
-							APIGroups:     []string{group},
-							Resources:     []string{resource},
-							ResourceNames: []string{name},
-							Verbs:         policyRule.Verbs,
-						})
-					}
-					continue
-				}
+	if !slices.Contains(policyRule.APIGroups, group) && !slices.Contains(policyRule.APIGroups, rbacv1.APIGroupAll) {
+		return false
+	}
 
-				//
-				result = append(result, rbacv1.PolicyRule{
-					APIGroups: []string{group},
-					Resources: []string{resource},
-					Verbs:     policyRule.Verbs,
-				})
-			}
-		}
+	if !slices.ContainsFunc(policyRule.Resources, func(ruleResource string) bool {
+		return ruleResource == resource || ruleResource == rbacv1.ResourceAll ||
+			(subresource != "" && ruleResource == "*/"+subresource)
+	}) {
+		return false
 	}
 
-	return result
+	return slices.ContainsFunc(policyRule.Verbs, func(verb string) bool {
+		return verb == rbacv1.VerbAll || slices.Contains(privilegedVerbs, verb)
+	})
 }
 
-// GetMapFromStretchedPolicyRules return a map with the keys in the form of
-// "group#resource#resourceName" or "nonresourceurl#url", and the value as PolicyRule
-func (p *PolicyRulesProcessorT) GetMapFromStretchedPolicyRules(policyRules []rbacv1.PolicyRule) (result map[string]rbacv1.PolicyRule) {
+// TargetClusterRolesT represents the ClusterRoles generated for a single target of a DynamicClusterRole
+type TargetClusterRolesT struct {
+	Target       kuberbacv1alpha1.TargetT
+	ClusterRoles []rbacv1.ClusterRole
 
-	result = make(map[string]rbacv1.PolicyRule)
+	// DenyRules are the rules denied to the ClusterRoles, used to generate the admission policies
+	DenyRules []rbacv1.PolicyRule
 
-	for _, policyRule := range policyRules {
+	// LargestClusterRoleSize is the size of the largest ClusterRole before sharding,
+	// as aggregated ClusterRoles end up holding the rules of all their shards
+	LargestClusterRoleSize int
+}
+
+// GetClusterRoleTargets returns all the targets defined in a DynamicClusterRole,
+// merging the single 'target' with the list of 'targets'. Targets without name are ignored
+func GetClusterRoleTargets(resource *kuberbacv1alpha1.DynamicClusterRole) (targets []kuberbacv1alpha1.TargetT) {
+	for _, target := range append([]kuberbacv1alpha1.TargetT{resource.Spec.Target}, resource.Spec.Targets...) {
+		if target.Name != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
 
-		// For NonResourceURLs rules
-		if len(policyRule.NonResourceURLs) != 0 {
+// GetTargetNameConflicts returns, for each target name of the DynamicClusterRole declared by other ones too,
+// the keys of all the resources declaring it, sorted from the oldest to the newest. Resources being deleted are ignored,
+// so the rest can take over their targets. Templated names are only known once rendered, so they are never compared
+func GetTargetNameConflicts(ctx context.Context, c client.Reader, resource *kuberbacv1alpha1.DynamicClusterRole) (
+	conflicts map[string][]client.ObjectKey, err error) {
 
-			nonResourceUrlMapKey := "nonresourceurl#" + policyRule.NonResourceURLs[0]
+	dynamicClusterRoleList := &kuberbacv1alpha1.DynamicClusterRoleList{}
+	err = c.List(ctx, dynamicClusterRoleList)
+	if err != nil {
+		return conflicts, err
+	}
 
-			if _, nonResourceUrlKeyFound := result[nonResourceUrlMapKey]; nonResourceUrlKeyFound {
-				tmp := append(result[nonResourceUrlMapKey].Verbs, policyRule.Verbs...)
-				slices.Sort(tmp)
-				tmp = slices.Compact(tmp)
+	// Index the resources by the names of their targets
+	claimants := map[string][]kuberbacv1alpha1.DynamicClusterRole{}
+	for _, dynamicClusterRole := range dynamicClusterRoleList.Items {
+		if !dynamicClusterRole.DeletionTimestamp.IsZero() {
+			continue
+		}
 
-				result[nonResourceUrlMapKey] = rbacv1.PolicyRule{
-					NonResourceURLs: policyRule.NonResourceURLs,
-					Verbs:           tmp,
-				}
-				continue
+		targetNames := []string{}
+		for _, target := range GetClusterRoleTargets(&dynamicClusterRole) {
+			if !strings.Contains(target.Name, "{{") && !slices.Contains(targetNames, target.Name) {
+				targetNames = append(targetNames, target.Name)
 			}
+		}
 
-			result[nonResourceUrlMapKey] = policyRule
+		for _, targetName := range targetNames {
+			claimants[targetName] = append(claimants[targetName], dynamicClusterRole)
+		}
+	}
 
+	conflicts = map[string][]client.ObjectKey{}
+	for _, target := range GetClusterRoleTargets(resource) {
+		targetClaimants := claimants[target.Name]
+		if len(targetClaimants) < 2 {
 			continue
 		}
 
-		// For ResourceNames rules
-		resourceKey := policyRule.APIGroups[0] + "#" + policyRule.Resources[0] + "#"
-		if len(policyRule.ResourceNames) != 0 {
-			resourceKey += policyRule.ResourceNames[0]
+		slices.SortFunc(targetClaimants, func(a, b kuberbacv1alpha1.DynamicClusterRole) int {
+			if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+				return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+			}
+			return strings.Compare(client.ObjectKeyFromObject(&a).String(), client.ObjectKeyFromObject(&b).String())
+		})
+
+		conflicts[target.Name] = nil
+		for _, targetClaimant := range targetClaimants {
+			conflicts[target.Name] = append(conflicts[target.Name], client.ObjectKeyFromObject(&targetClaimant))
 		}
+	}
 
-		if _, resourceKeyFound := result[resourceKey]; resourceKeyFound {
+	return conflicts, err
+}
 
-			tmp := append(result[resourceKey].Verbs, policyRule.Verbs...)
-			slices.Sort(tmp)
-			tmp = slices.Compact(tmp)
+// FormatPolicyRule returns a compact representation of a PolicyRule, used to summarize changes
+func FormatPolicyRule(rule rbacv1.PolicyRule) string {
 
-			result[resourceKey] = rbacv1.PolicyRule{
-				APIGroups:     policyRule.APIGroups,
-				Resources:     policyRule.Resources,
-				ResourceNames: policyRule.ResourceNames,
-				Verbs:         tmp,
-			}
-			continue
-		}
+	if len(rule.NonResourceURLs) > 0 {
+		return fmt.Sprintf("nonResourceURLs=%q verbs=%q", rule.NonResourceURLs, rule.Verbs)
+	}
 
-		result[resourceKey] = policyRule
+	result := fmt.Sprintf("apiGroups=%q resources=%q verbs=%q", rule.APIGroups, rule.Resources, rule.Verbs)
+	if len(rule.ResourceNames) > 0 {
+		result += fmt.Sprintf(" resourceNames=%q", rule.ResourceNames)
 	}
+
 	return result
 }
 
-// EvaluateSpecialCases checks for special cases in the PolicyRules maps
-// and returns the resulting map with them evaluated
-func (p *PolicyRulesProcessorT) EvaluateSpecialCases(allowMap, denyMap map[string]rbacv1.PolicyRule) (result map[string]rbacv1.PolicyRule, err error) {
+// GetProtectedPolicyRules returns the rules of all the ClusterProtectionPolicies in the cluster
+func GetProtectedPolicyRules(ctx context.Context, c client.Client) (result []rbacv1.PolicyRule, err error) {
 
-	for denyMapkey, policyRule := range denyMap {
-		if strings.HasPrefix(denyMapkey, "nonresourceurl") {
-			continue
-		}
+	// Clusters where the CRD is not installed have no policies, e.g. when rendering from the CLI
+	protectionPolicyList := &kuberbacv1alpha1.ClusterProtectionPolicyList{}
+	err = c.List(ctx, protectionPolicyList)
+	if meta.IsNoMatchError(err) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
 
-		// Generic resource found, ignore it
-		parts := strings.Split(denyMapkey, "#")
-		if parts[2] == "" {
-			continue
-		}
+	for _, protectionPolicy := range protectionPolicyList.Items {
+		result = append(result, protectionPolicy.Spec.Rules...)
+	}
+
+	return result, err
+}
 
-		// We found a deny rule acting on a Resource with ResourceName,
-		// Find the Resources without ResourceName in the allow map
-		// and add all the resource names minus the ones in the deny rule
-		key := strings.Join(parts[:2], "#") + "#"
-		if _, ok := allowMap[key]; ok {
+// GetImportedPolicyRules returns the rules of the existing ClusterRoles referenced by the sources of a DynamicClusterRole.
+// ClusterRoles generated by the same DynamicClusterRole are ignored, so its own output is never imported back
+func GetImportedPolicyRules(ctx context.Context, c client.Client, resource *kuberbacv1alpha1.DynamicClusterRole,
+	referenceAnnotations map[string]string) (result []rbacv1.PolicyRule, err error) {
 
-			// Find the GVKR for the resource allocated in deny
-			tmpGvkr := GVKR{}
-			coreResourceType := strings.Split(policyRule.Resources[0], "/")[0]
-			for _, gvkr := range p.ResourcesByGroup[policyRule.APIGroups[0]] {
-				if gvkr.Resource == coreResourceType {
-					tmpGvkr = gvkr
-				}
-			}
+	for _, source := range resource.Spec.From {
+
+		// Check exactly one of name or selector is set
+		if (source.Name == "") == (source.Selector == nil) {
+			return result, fmt.Errorf("%w: exactly one of name or selector must be set on each source of from", errInvalidSpec)
+		}
 
-			// Get a list of all the resources of the same type
-			sourceObjectList := &unstructured.UnstructuredList{}
-			sourceObjectList.SetGroupVersionKind(tmpGvkr.GVK)
-			err = p.Client.List(p.Context, sourceObjectList, []client.ListOption{}...)
+		clusterRoleList := rbacv1.ClusterRoleList{}
+		if source.Name != "" {
+			clusterRole := rbacv1.ClusterRole{}
+			err = c.Get(ctx, client.ObjectKey{Name: source.Name}, &clusterRole)
 			if err != nil {
-				return result, err
+				return result, fmt.Errorf("error getting ClusterRole '%s': %s", source.Name, err.Error())
 			}
+			clusterRoleList.Items = append(clusterRoleList.Items, clusterRole)
+		}
 
-			for _, sourceObject := range sourceObjectList.Items {
+		if source.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(source.Selector)
+			if err != nil {
+				return result, fmt.Errorf("%w: error parsing from.selector: %s", errInvalidSelector, err.Error())
+			}
 
-				allowMap[key+sourceObject.GetName()] = rbacv1.PolicyRule{
-					APIGroups:     allowMap[key].APIGroups,
-					Resources:     allowMap[key].Resources,
-					ResourceNames: []string{sourceObject.GetName()},
-					Verbs:         allowMap[key].Verbs,
-				}
+			err = c.List(ctx, &clusterRoleList, client.MatchingLabelsSelector{Selector: selector})
+			if err != nil {
+				return result, fmt.Errorf("error listing ClusterRoles: %s", err.Error())
 			}
+		}
 
-			delete(allowMap, key)
+		for _, clusterRole := range clusterRoleList.Items {
+			if globals.IsSubset(referenceAnnotations, clusterRole.Annotations) {
+				continue
+			}
+			result = append(result, clusterRole.Rules...)
 		}
 	}
 
-	result = allowMap
 	return result, err
 }
 
-// EvaluatePolicyRules compares the allow and deny PolicyRule maps and returns the resulting map
-func (p *PolicyRulesProcessorT) EvaluatePolicyRules(allowMap, denyMap map[string]rbacv1.PolicyRule) (result map[string]rbacv1.PolicyRule, err error) {
-
-	for denyMapKey, policyRule := range denyMap {
+// ClusterRoleTemplateData represents the data injected into the templates of a DynamicClusterRole
+type ClusterRoleTemplateData struct {
+	// Values are read from the ConfigMaps and Secrets referenced in valuesFrom
+	Values map[string]string
 
-		// NonResourceURLs rules
-		if strings.HasPrefix(denyMapKey, "nonresourceurl") {
-
-			// Wildcard deny rule found for a NonResourceURLs,
-			// Treat verbs for all allow rules that match the prefix
-			if strings.HasSuffix(denyMapKey, "*") {
+	// Owner is the metadata of the DynamicClusterRole that creates the ClusterRoles
+	Owner metav1.ObjectMeta
+}
 
-				nonResourceUrlPrefix := strings.TrimSuffix(denyMapKey, "*")
+// GetTemplateValues returns the values read from the ConfigMaps and Secrets referenced by a DynamicClusterRole.
+// They are looked for in the namespace of the resource, and later sources override the keys of previous ones.
+// Secrets are only read when allowed, as the values end up in the generated ClusterRoles, readable by others
+func GetTemplateValues(ctx context.Context, c client.Reader, resource *kuberbacv1alpha1.DynamicClusterRole,
+	allowSecrets bool) (values map[string]string, err error) {
 
-				for allowMapKey, _ := range allowMap {
+	values = map[string]string{}
+	for _, source := range resource.Spec.ValuesFrom {
 
-					if strings.HasPrefix(allowMapKey, nonResourceUrlPrefix) {
-						tmpPolicyRule := allowMap[allowMapKey]
-						tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[allowMapKey].Verbs, policyRule.Verbs)
-						allowMap[allowMapKey] = tmpPolicyRule
-					}
+		// Check exactly one of configMapRef or secretRef is set
+		if (source.ConfigMapRef == nil) == (source.SecretRef == nil) {
+			return values, fmt.Errorf("%w: exactly one of configMapRef or secretRef must be set on each source of valuesFrom", errInvalidSpec)
+		}
 
-					if len(allowMap[allowMapKey].Verbs) == 0 {
-						delete(allowMap, allowMapKey)
+		if source.ConfigMapRef != nil {
+			configMap := corev1.ConfigMap{}
+			err = c.Get(ctx, client.ObjectKey{Namespace: resource.Namespace, Name: source.ConfigMapRef.Name}, &configMap)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					if source.ConfigMapRef.Optional {
+						continue
 					}
+					return values, fmt.Errorf("%w: ConfigMap '%s' of valuesFrom does not exist", errInvalidSpec, source.ConfigMapRef.Name)
 				}
-				continue
+				return values, fmt.Errorf("error getting values from ConfigMap '%s': %w", source.ConfigMapRef.Name, err)
 			}
-
-			// Treat the verbs on all allow rules that match the exact NonResourceURLs
-			tmpPolicyRule := allowMap[denyMapKey]
-			tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[denyMapKey].Verbs, policyRule.Verbs)
-			allowMap[denyMapKey] = tmpPolicyRule
-
-			if len(allowMap[denyMapKey].Verbs) == 0 {
-				delete(allowMap, denyMapKey)
-			}
-
+			maps.Copy(values, configMap.Data)
 			continue
 		}
 
-		denyMapKeyParts := strings.Split(denyMapKey, "#")
-
-		// Deny rule found for a Resouce NOT defining a ResourceName,
-		// Treat verbs for all allow rules that match the prefix
-		if denyMapKeyParts[2] == "" {
-			for allowMapKey, _ := range allowMap {
-				if strings.HasPrefix(allowMapKey, denyMapKey) {
-					tmpPolicyRule := allowMap[allowMapKey]
-					tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[allowMapKey].Verbs, policyRule.Verbs)
-					allowMap[allowMapKey] = tmpPolicyRule
-				}
+		if !allowSecrets {
+			return values, fmt.Errorf("%w: values can not be read from Secrets, as it is not allowed in the controller", errInvalidSpec)
+		}
 
-				if len(allowMap[allowMapKey].Verbs) == 0 {
-					delete(allowMap, allowMapKey)
+		secret := corev1.Secret{}
+		err = c.Get(ctx, client.ObjectKey{Namespace: resource.Namespace, Name: source.SecretRef.Name}, &secret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				if source.SecretRef.Optional {
+					continue
 				}
+				return values, fmt.Errorf("%w: Secret '%s' of valuesFrom does not exist", errInvalidSpec, source.SecretRef.Name)
 			}
-			continue
+			return values, fmt.Errorf("error getting values from Secret '%s': %w", source.SecretRef.Name, err)
 		}
+		for key, value := range secret.Data {
+			values[key] = string(value)
+		}
+	}
 
-		// Deny rule found for a Resouce DO defining a ResourceName,
-		// Treat verbs for all allow rules that match the prefix
-		if denyMapKeyParts[2] != "" {
-			if _, ok := allowMap[denyMapKey]; ok {
-				tmpPolicyRule := allowMap[denyMapKey]
-				tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[denyMapKey].Verbs, policyRule.Verbs)
-				allowMap[denyMapKey] = tmpPolicyRule
+	return values, nil
+}
 
-				if len(allowMap[denyMapKey].Verbs) == 0 {
-					delete(allowMap, denyMapKey)
-				}
-			}
+// renderTemplateList executes the templates present on the items of a list with the given data
+func renderTemplateList(templates []string, data any) (result []string, err error) {
+
+	for _, value := range templates {
+		renderedValue, err := globals.RenderTemplate(value, data)
+		if err != nil {
+			return result, err
 		}
+		result = append(result, renderedValue)
 	}
 
-	result = allowMap
-
 	return result, err
 }
 
-// SplitPolicyRules separates PolicyRules into two lists: clusterScopedRules and namespaceScopedRules
-func (p *PolicyRulesProcessorT) SplitPolicyRules(policyRules []rbacv1.PolicyRule) (clusterScopedRules, namespaceScopedRules []rbacv1.PolicyRule) {
+// RenderTemplatedFields returns a copy of the DynamicClusterRole with the templates executed on the names
+// of its targets, and on the resourceNames and nonResourceURLs of its rules. The original resource is never modified
+func RenderTemplatedFields(resource *kuberbacv1alpha1.DynamicClusterRole, templateData ClusterRoleTemplateData) (
+	result *kuberbacv1alpha1.DynamicClusterRole, err error) {
 
-	for _, policyRule := range policyRules {
+	result = resource.DeepCopy()
 
-		// Look for current PolicyRule in the resourcesByGroup map
-		for _, resource := range p.ResourcesByGroup[policyRule.APIGroups[0]] {
+	// Render the names of the targets. Those not filled are kept, as they are ignored later
+	targets := []*kuberbacv1alpha1.TargetT{&result.Spec.Target}
+	for index := range result.Spec.Targets {
+		targets = append(targets, &result.Spec.Targets[index])
+	}
 
-			//
-			resourceName := resource.Resource
-			if resource.Subresource != "" {
-				resourceName += "/" + resource.Subresource
-			}
+	for _, target := range targets {
+		if target.Name == "" {
+			continue
+		}
 
-			// Ignore when it is not the correct resource
-			if policyRule.Resources[0] != resourceName {
-				continue
-			}
+		target.Name, err = globals.RenderTemplate(target.Name, templateData)
+		if err != nil {
+			return result, fmt.Errorf("%w: error rendering target name: %s", errInvalidSpec, err.Error())
+		}
 
-			// Add to the corresponding list
-			if resource.Namespaced {
-				namespaceScopedRules = append(namespaceScopedRules, policyRule)
-			} else {
-				clusterScopedRules = append(clusterScopedRules, policyRule)
-			}
+		if target.Name == "" {
+			return result, fmt.Errorf("%w: target name can not be rendered empty", errInvalidSpec)
+		}
+	}
+
+	// Render the rules
+	renderRule := func(rule *rbacv1.PolicyRule) (err error) {
+		rule.ResourceNames, err = renderTemplateList(rule.ResourceNames, templateData)
+		if err != nil {
+			return fmt.Errorf("%w: error rendering resourceNames: %s", errInvalidSpec, err.Error())
+		}
+
+		rule.NonResourceURLs, err = renderTemplateList(rule.NonResourceURLs, templateData)
+		if err != nil {
+			return fmt.Errorf("%w: error rendering nonResourceURLs: %s", errInvalidSpec, err.Error())
+		}
+
+		return err
+	}
+
+	for index := range result.Spec.Allow {
+		if err = renderRule(&result.Spec.Allow[index]); err != nil {
+			return result, err
+		}
+	}
 
-			break
+	for index := range result.Spec.Deny {
+		if err = renderRule(&result.Spec.Deny[index].PolicyRule); err != nil {
+			return result, err
 		}
 	}
 
-	return clusterScopedRules, namespaceScopedRules
+	return result, err
 }
 
-// GetSyncTime return the spec.synchronization.time as duration, or default time on failures
-func (r *DynamicClusterRoleReconciler) GetSyncTime(resource *kuberbacv1alpha1.DynamicClusterRole) (syncTime time.Duration, err error) {
+// ObjectListingT restricts the objects read from the cluster to evaluate deny rules by name or by object selector,
+// so the controller can run without permissions to read every resource of the cluster
+type ObjectListingT struct {
+	// Disabled rejects the deny rules needing to list objects
+	Disabled bool
 
-	syncTime, err = time.ParseDuration(resource.Spec.Synchronization.Time)
-	if err != nil {
-		err = fmt.Errorf(parseSyncTimeError, resource.Name)
-		return syncTime, err
-	}
+	// Groups are the only API groups whose objects can be listed, being "" the core group. All of them when empty
+	Groups []string
 
-	return syncTime, err
+	// SecretValues allows reading the values of valuesFrom from Secrets. Otherwise, only ConfigMaps are read
+	SecretValues bool
 }
 
-// SyncTarget call Kubernetes API to actually perform actions over the resource
-func (r *DynamicClusterRoleReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole) (err error) {
+// Allows returns whether the objects of an API group can be listed
+func (o *ObjectListingT) Allows(group string) bool {
+	return !o.Disabled && (len(o.Groups) == 0 || slices.Contains(o.Groups, group))
+}
 
-	policyRulesProcessor, err := NewPolicyRuleProcessor(ctx, r.Client, r.DiscoveryClient)
-	if err != nil {
-		return fmt.Errorf("error generating PolicyRulesProcessor: %s", err.Error())
+// clientObjectLister lists the names of the objects of a resource through a client, page by page,
+// so the PolicyRules processor can evaluate deny rules by name or by object selector
+type clientObjectLister struct {
+	Client client.Reader
+
+	// Listing restricts the API groups whose objects can be listed
+	Listing ObjectListingT
+}
+
+// ListObjectNames returns the names of the objects of a resource, optionally inside a namespace and matching a selector
+func (l *clientObjectLister) ListObjectNames(ctx context.Context, gvk schema.GroupVersionKind, namespace string,
+	selector labels.Selector) (result []string, err error) {
+
+	if !l.Listing.Allows(gvk.Group) {
+		return result, fmt.Errorf("%w: listing the objects of '%s' is not allowed in the controller, "+
+			"so deny rules with resourceNames or objectSelector can not be evaluated on them", errInvalidSpec, gvk.GroupKind().String())
+	}
+
+	listOptions := []client.ListOption{}
+	if namespace != "" {
+		listOptions = append(listOptions, client.InNamespace(namespace))
+	}
+	if selector != nil {
+		listOptions = append(listOptions, client.MatchingLabelsSelector{Selector: selector})
 	}
 
-	// Transform '*' symbols with actual things
-	expandedAllowList := policyRulesProcessor.ExpandPolicyRules(resource.Spec.Allow)
-	expandedDenyList := policyRulesProcessor.ExpandPolicyRules(resource.Spec.Deny)
+	objectList := &unstructured.UnstructuredList{}
+	objectList.SetGroupVersionKind(gvk)
+	err = listInPages(ctx, l.Client, objectList, func() error {
+		for _, object := range objectList.Items {
+			result = append(result, object.GetName())
+		}
+		return nil
+	}, listOptions...)
 
-	// Stretch policy rules to a single resource per item
-	stretchAllowList := policyRulesProcessor.StretchPolicyRules(expandedAllowList)
-	stretchDenyList := policyRulesProcessor.StretchPolicyRules(expandedDenyList)
+	return result, err
+}
 
-	// Craft a map with stretched policy rules. Its keys are created as unique identifiers.
-	// This is done to increase performance when evaluating the rules.
-	allowMap := policyRulesProcessor.GetMapFromStretchedPolicyRules(stretchAllowList)
-	denyMap := policyRulesProcessor.GetMapFromStretchedPolicyRules(stretchDenyList)
+// GetSelectedNamespaces returns the names of the namespaces matching the namespaceSelector of a DynamicClusterRole
+func GetSelectedNamespaces(ctx context.Context, c client.Client, resource *kuberbacv1alpha1.DynamicClusterRole) (
+	result []string, err error) {
 
-	//
-	allowMap, err = policyRulesProcessor.EvaluateSpecialCases(allowMap, denyMap)
+	selector, err := metav1.LabelSelectorAsSelector(resource.Spec.NamespaceSelector)
 	if err != nil {
-		return fmt.Errorf("error evaluating especial cases: %s", err.Error())
+		return result, fmt.Errorf("%w: error parsing namespaceSelector: %s", errInvalidSelector, err.Error())
 	}
 
-	//
-	result, err := policyRulesProcessor.EvaluatePolicyRules(allowMap, denyMap)
+	namespaceList := corev1.NamespaceList{}
+	err = c.List(ctx, &namespaceList, client.MatchingLabelsSelector{Selector: selector})
 	if err != nil {
-		return fmt.Errorf("error evaluating allow and deny maps: %s", err.Error())
+		return result, err
 	}
 
-	// Create a list of ClusterRoles to be created.
-	// We assume always only one ClusterRole, but this will be transformed into two when asked to separate scopes.
-	clusterRoles := []rbacv1.ClusterRole{}
-
-	referenceAnnotations := map[string]string{
-		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
-		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
-		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
-		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	// An empty list is kept apart from a nil one, as it means no namespace is selected at all
+	result = []string{}
+	for _, namespace := range namespaceList.Items {
+		result = append(result, namespace.Name)
 	}
 
-	if len(resource.Spec.Target.Annotations) == 0 {
-		resource.Spec.Target.Annotations = map[string]string{}
-	}
+	return result, err
+}
 
-	clusterRoleResource := rbacv1.ClusterRole{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        resource.Spec.Target.Name,
-			Annotations: referenceAnnotations,
-			Labels:      resource.Spec.Target.Labels,
-		},
-		Rules: maps.Values(result),
-		// TODO: Implement AggregationRules later
+// GetImpersonationRules returns the rules allowing to impersonate the identities of the allowImpersonate section
+// of a DynamicClusterRole. ServiceAccounts selected by labels are looked for on each synchronization
+func GetImpersonationRules(ctx context.Context, c client.Client, resource *kuberbacv1alpha1.DynamicClusterRole) (
+	result []rbacv1.PolicyRule, err error) {
+
+	if resource.Spec.AllowImpersonate == nil {
+		return result, err
 	}
-	clusterRoles = append(clusterRoles, clusterRoleResource)
 
-	//
-	if resource.Spec.Target.SeparateScopes {
-		clusterScopedRules, namespaceScopedRules := policyRulesProcessor.SplitPolicyRules(maps.Values(result))
+	serviceAccountNames := []string{}
+	for index, serviceAccount := range resource.Spec.AllowImpersonate.ServiceAccounts {
 
-		// Assume first ClusterRole as clusterScoped
-		clusterRoles[0].Rules = clusterScopedRules
-		clusterRoles[0].Name = resource.Spec.Target.Name + "-cluster"
+		if (serviceAccount.Name == "") == (serviceAccount.Selector == nil) {
+			return result, fmt.Errorf("%w: exactly one of name or selector must be set in allowImpersonate.serviceAccounts[%d]",
+				errInvalidSpec, index)
+		}
 
-		// Create a new ClusterRole for namespaceScoped
-		clusterRoles = append(clusterRoles, *clusterRoleResource.DeepCopy())
-		clusterRoles[1].Rules = namespaceScopedRules
-		clusterRoles[1].Name = resource.Spec.Target.Name + "-namespace"
-	}
+		if serviceAccount.Name != "" {
+			serviceAccountNames = append(serviceAccountNames, serviceAccount.Name)
+			continue
+		}
 
-	//
-	for _, clusterRole := range clusterRoles {
-		err = r.Client.Update(ctx, &clusterRole)
+		if c == nil {
+			return result, fmt.Errorf("ServiceAccounts can not be selected without a cluster")
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(serviceAccount.Selector)
 		if err != nil {
-			err = fmt.Errorf("error updating ClusterRole: %s", err.Error())
-			break
+			return result, fmt.Errorf("%w: error parsing allowImpersonate.serviceAccounts[%d].selector: %s",
+				errInvalidSelector, index, err.Error())
 		}
-	}
 
-	return err
-}
+		listOptions := []client.ListOption{client.MatchingLabelsSelector{Selector: selector}}
+		if serviceAccount.Namespace != "" {
+			listOptions = append(listOptions, client.InNamespace(serviceAccount.Namespace))
+		}
 
-// DeleteTargets deletes all the ClusterRoles that are owned by the DynamicClusterRole resource
+		serviceAccountList := corev1.ServiceAccountList{}
+		err = c.List(ctx, &serviceAccountList, listOptions...)
+		if err != nil {
+			return result, err
+		}
+
+		for _, item := range serviceAccountList.Items {
+			serviceAccountNames = append(serviceAccountNames, item.Name)
+		}
+	}
+
+	result = append(result, policy.GetImpersonationRule(policy.ImpersonatedUsers, resource.Spec.AllowImpersonate.Users)...)
+	result = append(result, policy.GetImpersonationRule(policy.ImpersonatedGroups, resource.Spec.AllowImpersonate.Groups)...)
+	result = append(result, policy.GetImpersonationRule(policy.ImpersonatedServiceAccounts, serviceAccountNames)...)
+
+	return result, err
+}
+
+// ResolveObjectSelectors converts deny rules into PolicyRules. Those with an object selector are translated into
+// rules with the names of the objects matching it, so they can be evaluated as usual. Rules matching no objects are dropped
+func ResolveObjectSelectors(ctx context.Context, p *policy.ProcessorT, denyRules []kuberbacv1alpha1.DenyPolicyRuleT) (
+	result []rbacv1.PolicyRule, err error) {
+
+	for _, denyRule := range denyRules {
+
+		if denyRule.ObjectSelector == nil {
+			result = append(result, denyRule.PolicyRule)
+			continue
+		}
+
+		// Only objects of resources can be selected by their labels
+		if len(denyRule.Resources) == 0 || len(denyRule.NonResourceURLs) > 0 {
+			return result, fmt.Errorf("%w: deny.objectSelector requires resources, and is not allowed with nonResourceURLs", errInvalidSpec)
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(denyRule.ObjectSelector)
+		if err != nil {
+			return result, fmt.Errorf("%w: error parsing deny.objectSelector: %s", errInvalidSelector, err.Error())
+		}
+
+		resolvedRules, err := p.ResolveObjectSelector(ctx, denyRule.PolicyRule, selector)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, resolvedRules...)
+	}
+
+	return result, err
+}
+
+// RenderClusterRoles calculates the ClusterRoles produced by a DynamicClusterRole without touching the cluster.
+// It returns them grouped by target, together with the whole list of generated PolicyRules.
+// The client is only used to read objects when deny rules contain resourceNames, rules are imported from
+// existing ClusterRoles or values are read from ConfigMaps and Secrets, so it can be nil otherwise.
+// Objects are only read from the API groups allowed by the object listing restrictions.
+// Rules giving control over the operator are removed according to the self-protection.
+// When the resource asks for it, the explanation of each generated PolicyRule is returned too
+func RenderClusterRoles(ctx context.Context, c client.Client, discoverer policy.ResourceDiscoverer, wildcardVerbs policy.WildcardVerbsT,
+	objectListing ObjectListingT, selfProtection SelfProtectionT, resource *kuberbacv1alpha1.DynamicClusterRole) (
+	clusterRoles []TargetClusterRolesT, policyRules []rbacv1.PolicyRule, explanations []RuleExplanationT, expansion RuleExpansionT, err error) {
+
+	logger := log.FromContext(ctx).V(logLevelTraces)
+
+	var objectLister policy.ObjectLister
+	if c != nil {
+		objectLister = &clientObjectLister{Client: c, Listing: objectListing}
+	}
+
+	policyRulesProcessor, err := policy.NewProcessor(discoverer, objectLister)
+	if err != nil {
+		return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("%w: error generating PolicyRulesProcessor: %s", errDiscoveryFailed, err.Error())
+	}
+	policyRulesProcessor.WildcardVerbs = wildcardVerbs
+
+	// Inject the values into the templated fields. From now on, the rendered copy of the resource is used
+	templateData := ClusterRoleTemplateData{
+		Owner: resource.ObjectMeta,
+	}
+	if len(resource.Spec.ValuesFrom) > 0 {
+		if c == nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("%w: values can not be read from ConfigMaps or Secrets without a cluster", errInvalidSpec)
+		}
+
+		templateData.Values, err = GetTemplateValues(ctx, c, resource, objectListing.SecretValues)
+		if err != nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error reading values: %w", err)
+		}
+	}
+
+	resource, err = RenderTemplatedFields(resource, templateData)
+	if err != nil {
+		return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error rendering templates: %w", err)
+	}
+
+	// NonResourceURLs never matching a request are surely a mistake, so they are rejected instead of ignored
+	specRules := slices.Clone(resource.Spec.Allow)
+	for _, denyRule := range resource.Spec.Deny {
+		specRules = append(specRules, denyRule.PolicyRule)
+	}
+	err = policy.ValidateNonResourceURLs(specRules)
+	if err != nil {
+		return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("%w: %s", errInvalidSpec, err.Error())
+	}
+
+	// Reference annotations identify the ClusterRoles generated by this resource
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	// Import the rules of existing ClusterRoles into the allow list.
+	// The source of each rule is kept to explain the generated rules later
+	allowList := slices.Clone(resource.Spec.Allow)
+	allowSources := []PolicyRuleSourceT{}
+	for index, rule := range resource.Spec.Allow {
+		allowSources = append(allowSources, PolicyRuleSourceT{Name: fmt.Sprintf("allow[%d]", index), Rule: rule})
+	}
+
+	// Default denied verbs only apply to the allow rules written in the resource, so imported ones are kept as they are
+	if len(resource.Spec.DefaultDeniedVerbs) > 0 {
+		allowList = policyRulesProcessor.ExcludeVerbs(allowList, resource.Spec.DefaultDeniedVerbs)
+	}
+
+	if len(resource.Spec.From) > 0 {
+		if c == nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("rules can not be imported from existing ClusterRoles without a cluster")
+		}
+
+		importedRules, err := GetImportedPolicyRules(ctx, c, resource, referenceAnnotations)
+		if err != nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error importing rules from ClusterRoles: %w", err)
+		}
+		allowList = append(allowList, importedRules...)
+		for _, rule := range importedRules {
+			allowSources = append(allowSources, PolicyRuleSourceT{Name: "from", Rule: rule})
+		}
+	}
+
+	// Names of namespaced objects are only collected from the selected namespaces, when asked
+	if resource.Spec.NamespaceSelector != nil {
+		if c == nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("namespaces can not be selected without a cluster")
+		}
+
+		policyRulesProcessor.Namespaces, err = GetSelectedNamespaces(ctx, c, resource)
+		if err != nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error selecting namespaces: %w", err)
+		}
+		logger.Info("Namespaces selected to list objects", "namespaces", policyRulesProcessor.Namespaces)
+	}
+
+	// Translate deny rules with object selectors into rules with resource names
+	denyList := []rbacv1.PolicyRule{}
+	denySources := []PolicyRuleSourceT{}
+	for index, denyRule := range resource.Spec.Deny {
+		resolvedRules, err := ResolveObjectSelectors(ctx, &policyRulesProcessor, []kuberbacv1alpha1.DenyPolicyRuleT{denyRule})
+		if err != nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error resolving object selectors: %w", err)
+		}
+		denyList = append(denyList, resolvedRules...)
+
+		for _, rule := range resolvedRules {
+			denySources = append(denySources, PolicyRuleSourceT{Name: fmt.Sprintf("deny[%d]", index), Rule: rule})
+		}
+	}
+
+	// Protected resources are denied on every DynamicClusterRole.
+	// Policies are stored in the cluster, so they are not applied when rendering without it
+	if c != nil {
+		protectedRules, err := GetProtectedPolicyRules(ctx, c)
+		if err != nil {
+			return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error getting protected resources: %s", err.Error())
+		}
+		denyList = append(denyList, protectedRules...)
+		for _, rule := range protectedRules {
+			denySources = append(denySources, PolicyRuleSourceT{Name: "clusterProtectionPolicy", Rule: rule})
+		}
+	}
+
+	// Rules giving control over the operator are denied too, unless the resource is allowed to keep them
+	selfProtectionRules := selfProtection.GetPolicyRules(resource, objectListing, c != nil)
+	denyList = append(denyList, selfProtectionRules...)
+	for _, rule := range selfProtectionRules {
+		denySources = append(denySources, PolicyRuleSourceT{Name: "selfProtection", Rule: rule})
+	}
+
+	// Expand and stretch the rules to a single resource per item, keyed as unique identifiers on maps
+	allowMap, denyMap, err := policyRulesProcessor.GetPolicyRuleMaps(ctx, allowList, denyList)
+	if err != nil {
+		return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error evaluating especial cases: %w", err)
+	}
+	logger.Info("Policy rules stretched", "allow", len(allowList), "stretchedAllow", len(allowMap),
+		"deny", len(denyList), "stretchedDeny", len(denyMap))
+	expansion.StretchedRules = len(allowMap)
+
+	// Evaluating deny rules modifies the allow map, so keep a copy to explain which verbs were removed
+	var evaluatedAllowMap map[string]rbacv1.PolicyRule
+	if resource.Spec.Explain {
+		evaluatedAllowMap = maps.Clone(allowMap)
+	}
+
+	//
+	result, err := policyRulesProcessor.EvaluatePolicyRules(allowMap, denyMap)
+	if err != nil {
+		return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error evaluating allow and deny maps: %s", err.Error())
+	}
+	logger.Info("Policy rules evaluated", "allow", len(allowMap), "deny", len(denyMap), "result", len(result))
+
+	// Keep the rules sorted by their unique identifiers, so the output is stable between calls
+	policyRules = policy.GetSortedPolicyRules(result)
+
+	if resource.Spec.Explain {
+		explanations = ExplainPolicyRules(&policyRulesProcessor, allowSources, denySources, evaluatedAllowMap, result)
+	}
+
+	// Impersonated identities are not expanded as the rest of the resources, so their rules are added at the end
+	impersonationRules, err := GetImpersonationRules(ctx, c, resource)
+	if err != nil {
+		return clusterRoles, policyRules, explanations, expansion, fmt.Errorf("error generating impersonation rules: %w", err)
+	}
+	impersonationRules = policy.ExcludeDeniedImpersonations(impersonationRules, denyList)
+	policyRules = append(policyRules, impersonationRules...)
+
+	if resource.Spec.Explain {
+		for _, rule := range impersonationRules {
+			explanations = append(explanations, RuleExplanationT{Rule: FormatPolicyRule(rule), AllowedBy: []string{"allowImpersonate"}})
+		}
+	}
+
+	// Create a list of ClusterRoles to be created for each target.
+	// We assume always only one ClusterRole, but this will be transformed into two when asked to separate scopes.
+	for _, target := range GetClusterRoleTargets(resource) {
+
+		annotations := map[string]string{}
+		maps.Copy(annotations, target.Annotations)
+		maps.Copy(annotations, referenceAnnotations)
+
+		clusterRoleResource := rbacv1.ClusterRole{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "ClusterRole",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        target.Name,
+				Annotations: annotations,
+				Labels:      target.Labels,
+			},
+			Rules: policyRules,
+			// TODO: Implement AggregationRules later
+		}
+		targetClusterRoles := TargetClusterRolesT{
+			Target:       target,
+			ClusterRoles: []rbacv1.ClusterRole{clusterRoleResource},
+			DenyRules:    denyList,
+		}
+
+		//
+		if target.SeparateScopes {
+			clusterScopedRules, namespaceScopedRules := policyRulesProcessor.SplitPolicyRules(policyRules)
+
+			// Assume first ClusterRole as clusterScoped
+			targetClusterRoles.ClusterRoles[0].Rules = clusterScopedRules
+			targetClusterRoles.ClusterRoles[0].Name = target.Name + "-cluster"
+			targetClusterRoles.ClusterRoles[0].Labels = withLabel(target.Labels, scopeLabel, scopeLabelCluster)
+
+			// Create a new ClusterRole for namespaceScoped
+			targetClusterRoles.ClusterRoles = append(targetClusterRoles.ClusterRoles, *clusterRoleResource.DeepCopy())
+			targetClusterRoles.ClusterRoles[1].Rules = namespaceScopedRules
+			targetClusterRoles.ClusterRoles[1].Name = target.Name + "-namespace"
+			targetClusterRoles.ClusterRoles[1].Labels = withLabel(target.Labels, scopeLabel, scopeLabelNamespace)
+		}
+
+		// Keep the ClusterRoles small when asked: merge their rules, and shard them into aggregated ones
+		shardedClusterRoles := []rbacv1.ClusterRole{}
+		for _, clusterRole := range targetClusterRoles.ClusterRoles {
+			if target.CompactRules {
+				clusterRole.Rules = CompactPolicyRules(clusterRole.Rules)
+			}
+			targetClusterRoles.LargestClusterRoleSize = max(targetClusterRoles.LargestClusterRoleSize, GetClusterRoleSize(clusterRole))
+
+			shards, err := ShardClusterRole(clusterRole, target.MaxRulesPerClusterRole)
+			if err != nil {
+				return clusterRoles, policyRules, explanations, expansion, err
+			}
+			shardedClusterRoles = append(shardedClusterRoles, shards...)
+		}
+		targetClusterRoles.ClusterRoles = shardedClusterRoles
+
+		clusterRoles = append(clusterRoles, targetClusterRoles)
+	}
+
+	// Point to the allow rules to blame when the generated ClusterRoles grow too much
+	largestClusterRoleSize := 0
+	for _, targetClusterRoles := range clusterRoles {
+		largestClusterRoleSize = max(largestClusterRoleSize, targetClusterRoles.LargestClusterRoleSize)
+	}
+	if expansion.StretchedRules > stretchedRulesWarningCount || largestClusterRoleSize > clusterRoleSizeWarningBytes {
+		expansion.LargestSources = GetLargestAllowSources(&policyRulesProcessor, allowSources, largestAllowSourcesCount)
+	}
+
+	return clusterRoles, policyRules, explanations, expansion, err
+}
+
+// admissionOperationsByVerb maps the RBAC verbs to the admission operations checking them.
+// Reading verbs never reach admission, so they are not present
+var admissionOperationsByVerb = map[string][]admissionregistrationv1.OperationType{
+	"*":                {admissionregistrationv1.OperationAll},
+	"create":           {admissionregistrationv1.Create, admissionregistrationv1.Connect},
+	"update":           {admissionregistrationv1.Update},
+	"patch":            {admissionregistrationv1.Update},
+	"delete":           {admissionregistrationv1.Delete},
+	"deletecollection": {admissionregistrationv1.Delete},
+}
+
+// GetAdmissionRules translates deny PolicyRules into admission rules. Rules about NonResourceURLs,
+// or containing only reading verbs, can not be enforced on admission, so they are dropped
+func GetAdmissionRules(denyRules []rbacv1.PolicyRule) (result []admissionregistrationv1.NamedRuleWithOperations) {
+
+	for _, rule := range denyRules {
+		if len(rule.Resources) == 0 {
+			continue
+		}
+
+		operations := []admissionregistrationv1.OperationType{}
+		for _, verb := range policy.ExpandVerbAliases(rule.Verbs) {
+			for _, operation := range admissionOperationsByVerb[verb] {
+				if !slices.Contains(operations, operation) {
+					operations = append(operations, operation)
+				}
+			}
+		}
+
+		if len(operations) == 0 {
+			continue
+		}
+
+		// The wildcard operation must be alone in the list
+		if slices.Contains(operations, admissionregistrationv1.OperationAll) {
+			operations = []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll}
+		}
+
+		// Admission only matches exact names, so patterns can not be mirrored. Rules only acting on patterns are ignored,
+		// as dropping their names would deny the whole resource
+		resourceNames := slices.DeleteFunc(slices.Clone(rule.ResourceNames), policy.IsResourceNamePattern)
+		if len(rule.ResourceNames) > 0 && len(resourceNames) == 0 {
+			continue
+		}
+
+		// Wildcard resources include subresources in RBAC, but they must be explicitly requested on admission
+		resources := slices.Clone(rule.Resources)
+		if slices.Contains(resources, "*") && !slices.Contains(resources, "*/*") {
+			resources = append(resources, "*/*")
+		}
+
+		result = append(result, admissionregistrationv1.NamedRuleWithOperations{
+			ResourceNames: resourceNames,
+			RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+				Operations: operations,
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   rule.APIGroups,
+					APIVersions: []string{"*"},
+					Resources:   resources,
+				},
+			},
+		})
+	}
+
+	return result
+}
+
+// subjectExpression returns a CEL expression matching the requests made by a subject of a binding
+func subjectExpression(subject rbacv1.Subject, bindingNamespace string) string {
+
+	switch subject.Kind {
+	case "User":
+		return fmt.Sprintf("request.userInfo.username == %q", subject.Name)
+	case "Group":
+		return fmt.Sprintf("%q in request.userInfo.groups", subject.Name)
+	case "ServiceAccount":
+		if subject.Namespace == "" {
+			subject.Namespace = bindingNamespace
+		}
+		return fmt.Sprintf("request.userInfo.username == %q", "system:serviceaccount:"+subject.Namespace+":"+subject.Name)
+	}
+
+	return ""
+}
+
+// GetBoundSubjectsExpression returns a CEL expression matching the requests made by the subjects bound to some ClusterRoles.
+// Subjects bound by RoleBindings only match inside the namespace of the binding. It is 'false' when nobody is bound
+func GetBoundSubjectsExpression(ctx context.Context, c client.Client, clusterRoleNames []string) (expression string, err error) {
+
+	clauses := []string{}
+	appendClause := func(clause string) {
+		if clause != "" && !slices.Contains(clauses, clause) {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	clusterRoleBindingList := rbacv1.ClusterRoleBindingList{}
+	err = c.List(ctx, &clusterRoleBindingList)
+	if err != nil {
+		return expression, fmt.Errorf("error listing ClusterRoleBindings: %s", err.Error())
+	}
+
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+		if !slices.Contains(clusterRoleNames, clusterRoleBinding.RoleRef.Name) {
+			continue
+		}
+
+		for _, subject := range clusterRoleBinding.Subjects {
+			appendClause(subjectExpression(subject, ""))
+		}
+	}
+
+	roleBindingList := rbacv1.RoleBindingList{}
+	err = c.List(ctx, &roleBindingList)
+	if err != nil {
+		return expression, fmt.Errorf("error listing RoleBindings: %s", err.Error())
+	}
+
+	for _, roleBinding := range roleBindingList.Items {
+		if roleBinding.RoleRef.Kind != "ClusterRole" || !slices.Contains(clusterRoleNames, roleBinding.RoleRef.Name) {
+			continue
+		}
+
+		for _, subject := range roleBinding.Subjects {
+			clause := subjectExpression(subject, roleBinding.Namespace)
+			if clause != "" {
+				clause = fmt.Sprintf("(request.namespace == %q && %s)", roleBinding.Namespace, clause)
+			}
+			appendClause(clause)
+		}
+	}
+
+	if len(clauses) == 0 {
+		return "false", err
+	}
+
+	// Keep the expression stable between synchronizations
+	slices.Sort(clauses)
+	return strings.Join(clauses, " || "), err
+}
+
+// BuildAdmissionPolicy returns the ValidatingAdmissionPolicy, and its binding, rejecting the requests matching the deny rules
+// of a target when they are made by the subjects bound to its ClusterRoles. Nothing is returned when no deny rule
+// can be enforced on admission
+func BuildAdmissionPolicy(resource *kuberbacv1alpha1.DynamicClusterRole, targetClusterRoles TargetClusterRolesT,
+	boundSubjectsExpression string, referenceAnnotations map[string]string) (
+	policy *admissionregistrationv1.ValidatingAdmissionPolicy, binding *admissionregistrationv1.ValidatingAdmissionPolicyBinding) {
+
+	admissionRules := GetAdmissionRules(targetClusterRoles.DenyRules)
+	if len(admissionRules) == 0 {
+		return nil, nil
+	}
+
+	annotations := map[string]string{}
+	maps.Copy(annotations, targetClusterRoles.Target.Annotations)
+	maps.Copy(annotations, referenceAnnotations)
+
+	// Requests are admitted when the policy can not be evaluated, unless the target asks for the opposite.
+	// Otherwise, a failing expression would reject the requests of any subject on the mirrored resources
+	failurePolicy := admissionregistrationv1.Ignore
+	if targetClusterRoles.Target.AdmissionFailurePolicy == string(admissionregistrationv1.Fail) {
+		failurePolicy = admissionregistrationv1.Fail
+	}
+	reason := metav1.StatusReasonForbidden
+
+	policy = &admissionregistrationv1.ValidatingAdmissionPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingAdmissionPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        targetClusterRoles.Target.Name,
+			Annotations: annotations,
+			Labels:      targetClusterRoles.Target.Labels,
+		},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			FailurePolicy: &failurePolicy,
+			MatchConstraints: &admissionregistrationv1.MatchResources{
+				ResourceRules: admissionRules,
+			},
+			MatchConditions: []admissionregistrationv1.MatchCondition{{
+				Name:       "bound-subjects",
+				Expression: boundSubjectsExpression,
+			}},
+			Validations: []admissionregistrationv1.Validation{{
+				Expression: "false",
+				Message: fmt.Sprintf("denied by DynamicClusterRole '%s/%s'",
+					resource.ObjectMeta.Namespace, resource.ObjectMeta.Name),
+				Reason: &reason,
+			}},
+		},
+	}
+
+	binding = &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingAdmissionPolicyBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        targetClusterRoles.Target.Name,
+			Annotations: annotations,
+			Labels:      targetClusterRoles.Target.Labels,
+		},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        policy.Name,
+			ValidationActions: []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny},
+		},
+	}
+
+	return policy, binding
+}
+
+// isAdmissionPolicyOwned returns whether the ValidatingAdmissionPolicy and the binding with the given name
+// are owned by the resource, or do not exist yet
+func (r *DynamicClusterRoleReconciler) isAdmissionPolicyOwned(ctx context.Context, name string,
+	referenceAnnotations map[string]string) (owned bool, err error) {
+
+	for _, existentObject := range []client.Object{
+		&admissionregistrationv1.ValidatingAdmissionPolicy{},
+		&admissionregistrationv1.ValidatingAdmissionPolicyBinding{},
+	} {
+		err = r.Get(ctx, client.ObjectKey{Name: name}, existentObject)
+		if meta.IsNoMatchError(err) {
+			return false, fmt.Errorf("%w: ValidatingAdmissionPolicies are not served by the cluster, so emitAdmissionPolicy can not be set",
+				errInvalidSpec)
+		}
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("error getting admission policy objects named '%s': %s", name, err.Error())
+		}
+
+		if !globals.IsSubset(referenceAnnotations, existentObject.GetAnnotations()) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// SyncAdmissionPolicies applies the ValidatingAdmissionPolicies, and their bindings, of the targets asking for them,
+// and deletes the owned ones that are not needed anymore
+func (r *DynamicClusterRoleReconciler) SyncAdmissionPolicies(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole,
+	clusterRoles []TargetClusterRolesT, referenceAnnotations map[string]string) (err error) {
+
+	logger := log.FromContext(ctx)
+
+	desiredPolicies := []string{}
+	conflictingPolicies := []string{}
+	for _, targetClusterRoles := range clusterRoles {
+		if !targetClusterRoles.Target.EmitAdmissionPolicy || targetClusterRoles.Target.DryRun {
+			continue
+		}
+
+		clusterRoleNames := []string{}
+		for _, clusterRole := range targetClusterRoles.ClusterRoles {
+			clusterRoleNames = append(clusterRoleNames, clusterRole.Name)
+		}
+
+		boundSubjectsExpression, err := GetBoundSubjectsExpression(ctx, r.Client, clusterRoleNames)
+		if err != nil {
+			return err
+		}
+
+		policy, binding := BuildAdmissionPolicy(resource, targetClusterRoles, boundSubjectsExpression, referenceAnnotations)
+		if policy == nil {
+			logger.V(logLevelDecisions).Info("ValidatingAdmissionPolicy skipped: no deny rule can be enforced on admission",
+				"target", targetClusterRoles.Target.Name)
+			continue
+		}
+
+		// Policies and bindings with the same name, not generated by this resource, are never taken over
+		owned, err := r.isAdmissionPolicyOwned(ctx, policy.Name, referenceAnnotations)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			logger.V(logLevelDecisions).Info("ValidatingAdmissionPolicy skipped: it already exists and is not owned by this resource",
+				"validatingAdmissionPolicy", policy.Name)
+			conflictingPolicies = append(conflictingPolicies, policy.Name)
+			continue
+		}
+		desiredPolicies = append(desiredPolicies, policy.Name)
+
+		propagateAnnotations(resource, policy, r.PropagatedAnnotations)
+		propagateAnnotations(resource, binding, r.PropagatedAnnotations)
+
+		err = applyResource(ctx, r.Client, policy)
+		if meta.IsNoMatchError(err) {
+			return fmt.Errorf("%w: ValidatingAdmissionPolicies are not served by the cluster, so emitAdmissionPolicy can not be set",
+				errInvalidSpec)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: error applying ValidatingAdmissionPolicy: %s", errTargetWriteFailed, err.Error())
+		}
+
+		err = applyResource(ctx, r.Client, binding)
+		if err != nil {
+			return fmt.Errorf("%w: error applying ValidatingAdmissionPolicyBinding: %s", errTargetWriteFailed, err.Error())
+		}
+		logger.V(logLevelDecisions).Info("ValidatingAdmissionPolicy applied",
+			"validatingAdmissionPolicy", policy.Name, "rules", len(policy.Spec.MatchConstraints.ResourceRules))
+	}
+
+	// Remove owned policies and bindings not needed anymore, e.g. after disabling them on a target.
+	// Admission policies are not served by old clusters, so nothing was generated there
+	var allErrors []error
+	if len(conflictingPolicies) > 0 {
+		allErrors = append(allErrors, fmt.Errorf("%w: ValidatingAdmissionPolicies or their bindings already exist and are not owned by this resource: %s",
+			errTargetOwnershipConflict, strings.Join(conflictingPolicies, ", ")))
+	}
+
+	existentBindingList := admissionregistrationv1.ValidatingAdmissionPolicyBindingList{}
+	err = r.Client.List(ctx, &existentBindingList)
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range existentBindingList.Items {
+		if !globals.IsSubset(referenceAnnotations, binding.Annotations) || slices.Contains(desiredPolicies, binding.Name) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &binding)
+		if err = client.IgnoreNotFound(err); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting not needed ValidatingAdmissionPolicyBinding: %s", errTargetWriteFailed, err.Error()))
+		}
+	}
+
+	existentPolicyList := admissionregistrationv1.ValidatingAdmissionPolicyList{}
+	err = r.Client.List(ctx, &existentPolicyList)
+	if meta.IsNoMatchError(err) {
+		return errors.Join(allErrors...)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range existentPolicyList.Items {
+		if !globals.IsSubset(referenceAnnotations, policy.Annotations) || slices.Contains(desiredPolicies, policy.Name) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &policy)
+		if err = client.IgnoreNotFound(err); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting not needed ValidatingAdmissionPolicy: %s", errTargetWriteFailed, err.Error()))
+			continue
+		}
+		logger.V(logLevelChanges).Info("ValidatingAdmissionPolicy deleted: it is not needed anymore", "validatingAdmissionPolicy", policy.Name)
+	}
+
+	return errors.Join(allErrors...)
+}
+
+// SyncExplanation writes the explanation of the generated rules into a ConfigMap living in the namespace
+// of the DynamicClusterRole, or deletes the owned one when the explanation is not asked anymore
+func (r *DynamicClusterRoleReconciler) SyncExplanation(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole,
+	explanations []RuleExplanationT, referenceAnnotations map[string]string) (err error) {
+
+	logger := log.FromContext(ctx)
+
+	existentConfigMap := corev1.ConfigMap{}
+	err = r.Get(ctx, client.ObjectKey{
+		Namespace: resource.Namespace,
+		Name:      resource.Name + explanationConfigMapSuffix,
+	}, &existentConfigMap)
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("error getting explanation ConfigMap: %s", err.Error())
+	}
+	configMapFound := err == nil
+
+	// Review reference annotations when the ConfigMap already exists
+	if configMapFound && !globals.IsSubset(referenceAnnotations, existentConfigMap.Annotations) {
+		logger.V(logLevelDecisions).Info("Explanation ConfigMap skipped: it already exists and is not owned by this resource",
+			"configMap", existentConfigMap.Name)
+		return nil
+	}
+
+	if !resource.Spec.Explain {
+		if !configMapFound {
+			return nil
+		}
+
+		err = r.Client.Delete(ctx, &existentConfigMap)
+		if err = client.IgnoreNotFound(err); err != nil {
+			return fmt.Errorf("%w: error deleting not needed explanation ConfigMap: %s", errTargetWriteFailed, err.Error())
+		}
+		logger.V(logLevelChanges).Info("Explanation ConfigMap deleted: it is not asked anymore", "configMap", existentConfigMap.Name)
+		return nil
+	}
+
+	explanationOutput, err := yaml.Marshal(explanations)
+	if err != nil {
+		return fmt.Errorf("error encoding explanation: %s", err.Error())
+	}
+
+	configMap := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        resource.Name + explanationConfigMapSuffix,
+			Namespace:   resource.Namespace,
+			Annotations: referenceAnnotations,
+		},
+		Data: map[string]string{
+			explanationConfigMapKey: string(explanationOutput),
+		},
+	}
+	propagateAnnotations(resource, &configMap, r.PropagatedAnnotations)
+
+	err = applyResource(ctx, r.Client, &configMap)
+	if err != nil {
+		return fmt.Errorf("%w: error applying explanation ConfigMap: %s", errTargetWriteFailed, err.Error())
+	}
+	logger.V(logLevelDecisions).Info("Explanation ConfigMap applied", "configMap", configMap.Name, "rules", len(explanations))
+
+	return err
+}
+
+// SyncExportConfigMaps writes the rules generated for each target asking for it into a ConfigMap,
+// and deletes the owned ones not asked anymore. Existing ConfigMaps not owned by this resource are never overwritten
+func (r *DynamicClusterRoleReconciler) SyncExportConfigMaps(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole,
+	clusterRoles []TargetClusterRolesT, referenceAnnotations map[string]string) (err error) {
+
+	logger := log.FromContext(ctx)
+
+	existentConfigMapList := corev1.ConfigMapList{}
+	err = r.Client.List(ctx, &existentConfigMapList, client.HasLabels{exportConfigMapLabel})
+	if err != nil {
+		return fmt.Errorf("error listing export ConfigMaps: %s", err.Error())
+	}
+
+	var allErrors []error
+	desiredConfigMaps := []string{}
+	conflictingConfigMaps := []string{}
+	for _, targetClusterRoles := range clusterRoles {
+
+		exportConfigMap := targetClusterRoles.Target.ExportConfigMap
+		if exportConfigMap == nil {
+			continue
+		}
+
+		configMapKey := client.ObjectKey{Namespace: exportConfigMap.Namespace, Name: exportConfigMap.Name}
+		if configMapKey.Namespace == "" {
+			configMapKey.Namespace = resource.Namespace
+		}
+		if configMapKey.Name == "" {
+			configMapKey.Name = targetClusterRoles.Target.Name
+		}
+
+		if errs := validation.IsDNS1123Subdomain(configMapKey.Name); len(errs) > 0 {
+			allErrors = append(allErrors, fmt.Errorf("%w: export ConfigMap name '%s' of target '%s' is not valid: %s",
+				errInvalidSpec, configMapKey.Name, targetClusterRoles.Target.Name, strings.Join(errs, ", ")))
+			continue
+		}
+		desiredConfigMaps = append(desiredConfigMaps, configMapKey.String())
+
+		existentConfigMap := corev1.ConfigMap{}
+		err = r.Get(ctx, configMapKey, &existentConfigMap)
+		if client.IgnoreNotFound(err) != nil {
+			allErrors = append(allErrors, fmt.Errorf("error getting export ConfigMap: %s", err.Error()))
+			continue
+		}
+
+		if err == nil && !globals.IsSubset(referenceAnnotations, existentConfigMap.Annotations) {
+			logger.V(logLevelDecisions).Info("Export ConfigMap skipped: it already exists and is not owned by this resource",
+				"configMap", configMapKey.String())
+			conflictingConfigMaps = append(conflictingConfigMaps, configMapKey.String())
+			continue
+		}
+
+		// Aggregated ClusterRoles hold no rules, as they are filled from their shards
+		policyRules := []rbacv1.PolicyRule{}
+		for _, clusterRole := range targetClusterRoles.ClusterRoles {
+			policyRules = append(policyRules, clusterRole.Rules...)
+		}
+
+		rulesOutput, err := yaml.Marshal(policyRules)
+		if err != nil {
+			return fmt.Errorf("error encoding exported rules: %s", err.Error())
+		}
+
+		configMap := corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: corev1.SchemeGroupVersion.String(),
+				Kind:       "ConfigMap",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        configMapKey.Name,
+				Namespace:   configMapKey.Namespace,
+				Labels:      map[string]string{exportConfigMapLabel: "true"},
+				Annotations: referenceAnnotations,
+			},
+			Data: map[string]string{
+				exportConfigMapKey: string(rulesOutput),
+			},
+		}
+		propagateAnnotations(resource, &configMap, r.PropagatedAnnotations)
+
+		err = applyResource(ctx, r.Client, &configMap)
+		if err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error applying export ConfigMap: %s", errTargetWriteFailed, err.Error()))
+			continue
+		}
+		logger.V(logLevelDecisions).Info("Export ConfigMap applied", "configMap", configMapKey.String(), "rules", len(policyRules))
+	}
+
+	if len(conflictingConfigMaps) > 0 {
+		allErrors = append(allErrors, fmt.Errorf("%w: export ConfigMaps already exist and are not owned by this resource: %s",
+			errTargetOwnershipConflict, strings.Join(conflictingConfigMaps, ", ")))
+	}
+
+	// Remove owned ConfigMaps not asked anymore, e.g. after removing the export of a target or renaming it
+	for _, configMap := range existentConfigMapList.Items {
+
+		if !globals.IsSubset(referenceAnnotations, configMap.Annotations) ||
+			slices.Contains(desiredConfigMaps, client.ObjectKeyFromObject(&configMap).String()) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &configMap)
+		if err = client.IgnoreNotFound(err); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting not needed export ConfigMap: %s", errTargetWriteFailed, err.Error()))
+			continue
+		}
+		logger.V(logLevelChanges).Info("Export ConfigMap deleted: it is not asked anymore", "configMap", client.ObjectKeyFromObject(&configMap).String())
+	}
+
+	return errors.Join(allErrors...)
+}
+
+// SyncTarget call Kubernetes API to actually perform actions over the resource
+func (r *DynamicClusterRoleReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole) (err error) {
+
+	if len(GetClusterRoleTargets(resource)) == 0 {
+		return fmt.Errorf("%w: at least one target with a name must be defined in target or targets", errInvalidSpec)
+	}
+
+	clusterRoles, policyRules, explanations, expansion, err := RenderClusterRoles(ctx, r.Client, r.DiscoveryCache,
+		r.WildcardVerbs, r.ObjectListing, r.SelfProtection, resource)
+	if err != nil {
+		return err
+	}
+
+	// Reject the rules exceeding the ceiling when the protection is enabled
+	if r.EscalationProtection {
+		err = r.CheckPrivilegedVerbs(policyRules)
+		if err != nil {
+			return err
+		}
+	}
+
+	metrics.GeneratedRules.WithLabelValues(DynamicClusterRoleResourceType, resource.Namespace, resource.Name).Set(float64(len(policyRules)))
+	resource.Status.RulesCount = len(policyRules)
+
+	// Warn before the generated ClusterRoles are rejected for exceeding the object size limits
+	largestClusterRoleSize := 0
+	for _, targetClusterRoles := range clusterRoles {
+		largestClusterRoleSize = max(largestClusterRoleSize, targetClusterRoles.LargestClusterRoleSize)
+	}
+
+	metrics.StretchedRules.WithLabelValues(DynamicClusterRoleResourceType, resource.Namespace, resource.Name).Set(float64(expansion.StretchedRules))
+	metrics.GeneratedObjectBytes.WithLabelValues(DynamicClusterRoleResourceType, resource.Namespace, resource.Name).Set(float64(largestClusterRoleSize))
+
+	// Pathological wildcards are reported with the allow rules producing them, so they can be fixed before etcd rejects them
+	if len(expansion.LargestSources) > 0 {
+		largestSources := []string{}
+		for _, source := range expansion.LargestSources {
+			largestSources = append(largestSources, fmt.Sprintf("%s (%d rules): %s", source.Source, source.StretchedRules, source.Rule))
+		}
+		log.FromContext(ctx).Info("Allow rules are expanded into too many rules",
+			"stretchedRules", expansion.StretchedRules, "warningStretchedRules", stretchedRulesWarningCount,
+			"bytes", largestClusterRoleSize, "warningBytes", clusterRoleSizeWarningBytes, "largestAllowRules", largestSources)
+	}
+
+	r.UpdateConditionSizePressure(resource, largestClusterRoleSize > clusterRoleSizeWarningBytes)
+	if largestClusterRoleSize > clusterRoleSizeWarningBytes {
+		log.FromContext(ctx).V(logLevelDecisions).Info("Generated ClusterRoles are approaching the object size limits",
+			"bytes", largestClusterRoleSize, "warningBytes", clusterRoleSizeWarningBytes)
+		r.Recorder.Eventf(resource, corev1.EventTypeWarning, globals.ConditionReasonApproachingSizeLimitType,
+			"Largest generated ClusterRole takes %d bytes, close to the object size limits. Consider compacting or sharding its rules",
+			largestClusterRoleSize)
+	}
+
+	// Get owned ClusterRoles to summarize the changes and clean the abandoned ones later
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	existentClusterRoleList := rbacv1.ClusterRoleList{}
+	err = r.Client.List(ctx, &existentClusterRoleList)
+	if err != nil {
+		return err
+	}
+
+	// Target names declared by several DynamicClusterRoles are only created by the oldest of them,
+	// so they never overwrite each other when the ClusterRole does not exist yet
+	targetNameConflicts, err := GetTargetNameConflicts(ctx, r.Client, resource)
+	if err != nil {
+		return err
+	}
+	r.UpdateConditionTargetConflict(resource, targetNameConflicts)
+
+	// Apply the ClusterRoles of each target. They are created when missing, but never overwritten
+	// when they exist and are not owned by this resource, as they could be system ones like 'system:*'.
+	// On dry-run mode, expose the rendered ClusterRoles in the status without touching the cluster
+	resource.Status.RenderedClusterRoles = nil
+	resource.Status.GeneratedClusterRoles = nil
+	desiredClusterRoles := []string{}
+	conflictingClusterRoles := []string{}
+	sharedClusterRoles := []string{}
+	for _, targetClusterRoles := range clusterRoles {
+		for _, clusterRole := range targetClusterRoles.ClusterRoles {
+			desiredClusterRoles = append(desiredClusterRoles, clusterRole.Name)
+
+			if targetClusterRoles.Target.DryRun {
+				resource.Status.RenderedClusterRoles = append(resource.Status.RenderedClusterRoles, kuberbacv1alpha1.RenderedClusterRoleT{
+					Name:  clusterRole.Name,
+					Rules: clusterRole.Rules,
+				})
+				continue
+			}
+
+			existentClusterRoleIndex := slices.IndexFunc(existentClusterRoleList.Items, func(existentClusterRole rbacv1.ClusterRole) bool {
+				return existentClusterRole.Name == clusterRole.Name
+			})
+			if existentClusterRoleIndex != -1 &&
+				!globals.IsSubset(referenceAnnotations, existentClusterRoleList.Items[existentClusterRoleIndex].Annotations) {
+				log.FromContext(ctx).V(logLevelDecisions).Info("ClusterRole skipped: it already exists and is not owned by this resource",
+					"clusterRole", clusterRole.Name)
+				conflictingClusterRoles = append(conflictingClusterRoles, clusterRole.Name)
+				continue
+			}
+
+			claimants, found := targetNameConflicts[targetClusterRoles.Target.Name]
+			if existentClusterRoleIndex == -1 && found && claimants[0] != client.ObjectKeyFromObject(resource) {
+				log.FromContext(ctx).V(logLevelDecisions).Info("ClusterRole skipped: its target name is declared by an older resource",
+					"clusterRole", clusterRole.Name, "resource", claimants[0].String())
+				sharedClusterRoles = append(sharedClusterRoles, clusterRole.Name)
+				continue
+			}
+
+			err = r.prepareClusterRole(ctx, resource, &clusterRole)
+			if err != nil {
+				return err
+			}
+
+			err = applyResource(ctx, r.Client, &clusterRole)
+			if err != nil {
+				err = fmt.Errorf("%w: error applying ClusterRole: %s", errTargetWriteFailed, err.Error())
+				return err
+			}
+			resource.Status.GeneratedClusterRoles = append(resource.Status.GeneratedClusterRoles, clusterRole.Name)
+		}
+	}
+
+	// Summarize the rules changed on owned ClusterRoles. Those not applied on this synchronization
+	// keep their rules when they are still desired, e.g. on dry-run mode, or are deleted below otherwise
+	previousRules := []string{}
+	nextRules := []string{}
+	for _, clusterRole := range existentClusterRoleList.Items {
+
+		// Rules of aggregated ClusterRoles are filled by Kubernetes from their shards, which are already summarized
+		if !globals.IsSubset(referenceAnnotations, clusterRole.Annotations) || clusterRole.AggregationRule != nil {
+			continue
+		}
+
+		for _, rule := range clusterRole.Rules {
+			previousRules = append(previousRules, FormatPolicyRule(rule))
+		}
+
+		if slices.Contains(desiredClusterRoles, clusterRole.Name) &&
+			!slices.Contains(resource.Status.GeneratedClusterRoles, clusterRole.Name) {
+			for _, rule := range clusterRole.Rules {
+				nextRules = append(nextRules, FormatPolicyRule(rule))
+			}
+		}
+	}
+
+	for _, targetClusterRoles := range clusterRoles {
+		for _, clusterRole := range targetClusterRoles.ClusterRoles {
+			if !targetClusterRoles.Target.DryRun {
+				for _, rule := range clusterRole.Rules {
+					nextRules = append(nextRules, FormatPolicyRule(rule))
+				}
+			}
+		}
+	}
+
+	if change := newSyncChange(previousRules, nextRules, nil, nil); change != nil {
+		log.FromContext(ctx).V(logLevelChanges).Info("Rules changed", "added", change.AddedRules, "removed", change.RemovedRules)
+		resource.Status.LastChange = change
+		r.Recorder.Event(resource, corev1.EventTypeNormal, eventReasonChanged, syncChangeMessage(change))
+	}
+
+	// Remove owned ClusterRoles not defined in manifest, e.g. after removing or renaming a target
+	var allErrors []error
+	if len(conflictingClusterRoles) > 0 {
+		allErrors = append(allErrors, fmt.Errorf("%w: ClusterRoles already exist and are not owned by this resource: %s",
+			errTargetOwnershipConflict, strings.Join(conflictingClusterRoles, ", ")))
+	}
+	if len(sharedClusterRoles) > 0 {
+		allErrors = append(allErrors, fmt.Errorf("%w: ClusterRoles are declared by older resources too: %s",
+			errTargetOwnershipConflict, strings.Join(sharedClusterRoles, ", ")))
+	}
+	for _, clusterRole := range existentClusterRoleList.Items {
+
+		if !globals.IsSubset(referenceAnnotations, clusterRole.Annotations) ||
+			slices.Contains(desiredClusterRoles, clusterRole.Name) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &clusterRole)
+		if err = client.IgnoreNotFound(err); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting not needed ClusterRole: %s", errTargetWriteFailed, err.Error()))
+			continue
+		}
+		log.FromContext(ctx).V(logLevelChanges).Info("ClusterRole deleted: it is not defined as target anymore", "clusterRole", clusterRole.Name)
+	}
+
+	// Mirror the deny rules into admission policies for the targets asking for them
+	err = r.SyncAdmissionPolicies(ctx, resource, clusterRoles, referenceAnnotations)
+	if err != nil {
+		allErrors = append(allErrors, err)
+	}
+
+	// Explain where the generated rules come from, when asked
+	err = r.SyncExplanation(ctx, resource, explanations, referenceAnnotations)
+	if err != nil {
+		allErrors = append(allErrors, err)
+	}
+
+	// Export the generated rules for external tools, when asked
+	err = r.SyncExportConfigMaps(ctx, resource, clusterRoles, referenceAnnotations)
+	if err != nil {
+		allErrors = append(allErrors, err)
+	}
+
+	return errors.Join(allErrors...)
+}
+
+// DeleteTargets deletes all the ClusterRoles that are owned by the DynamicClusterRole resource,
+// or orphans them when its deletion policy is 'Orphan'
 func (r *DynamicClusterRoleReconciler) DeleteTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole) (err error) {
 
 	var allErrors []error
@@ -671,7 +1909,7 @@ func (r *DynamicClusterRoleReconciler) DeleteTargets(ctx context.Context, resour
 		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
 	}
 
-	// Get ClusterRole objects and delete those with reference annotations
+	// Get ClusterRole objects and release those with reference annotations
 	clusterRoleList := rbacv1.ClusterRoleList{}
 	err = r.Client.List(ctx, &clusterRoleList)
 	if err != nil {
@@ -681,12 +1919,179 @@ func (r *DynamicClusterRoleReconciler) DeleteTargets(ctx context.Context, resour
 	for _, clusterRole := range clusterRoleList.Items {
 
 		if globals.IsSubset(referenceAnnotations, clusterRole.Annotations) {
-			err = r.Client.Delete(ctx, &clusterRole)
-			if err = client.IgnoreNotFound(err); err != nil {
-				allErrors = append(allErrors, fmt.Errorf("error deleting ClusterRoleBinding: %s", err.Error()))
+			err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &clusterRole, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing ClusterRole: %s", err.Error()))
+			}
+		}
+	}
+
+	// Release the explanation ConfigMap when it has reference annotations
+	explanationConfigMap := corev1.ConfigMap{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: resource.Namespace, Name: resource.Name + explanationConfigMapSuffix}, &explanationConfigMap)
+	if client.IgnoreNotFound(err) != nil {
+		return errors.Join(append(allErrors, err)...)
+	}
+
+	if err == nil && globals.IsSubset(referenceAnnotations, explanationConfigMap.Annotations) {
+		err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &explanationConfigMap, referenceAnnotations)
+		if err != nil {
+			allErrors = append(allErrors, fmt.Errorf("error releasing explanation ConfigMap: %s", err.Error()))
+		}
+	}
+
+	// Get export ConfigMaps and release those with reference annotations
+	exportConfigMapList := corev1.ConfigMapList{}
+	err = r.Client.List(ctx, &exportConfigMapList, client.HasLabels{exportConfigMapLabel})
+	if err != nil {
+		return errors.Join(append(allErrors, err)...)
+	}
+
+	for _, configMap := range exportConfigMapList.Items {
+		if globals.IsSubset(referenceAnnotations, configMap.Annotations) {
+			err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &configMap, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing export ConfigMap: %s", err.Error()))
+			}
+		}
+	}
+
+	// Get admission policies and their bindings, and release those with reference annotations.
+	// Admission policies are not served by old clusters, so nothing was generated there
+	validatingAdmissionPolicyBindingList := admissionregistrationv1.ValidatingAdmissionPolicyBindingList{}
+	err = r.Client.List(ctx, &validatingAdmissionPolicyBindingList)
+	if meta.IsNoMatchError(err) {
+		return errors.Join(allErrors...)
+	}
+	if err != nil {
+		return errors.Join(append(allErrors, err)...)
+	}
+
+	for _, binding := range validatingAdmissionPolicyBindingList.Items {
+
+		if globals.IsSubset(referenceAnnotations, binding.Annotations) {
+			err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &binding, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing ValidatingAdmissionPolicyBinding: %s", err.Error()))
+			}
+		}
+	}
+
+	validatingAdmissionPolicyList := admissionregistrationv1.ValidatingAdmissionPolicyList{}
+	err = r.Client.List(ctx, &validatingAdmissionPolicyList)
+	if meta.IsNoMatchError(err) {
+		return errors.Join(allErrors...)
+	}
+	if err != nil {
+		return errors.Join(append(allErrors, err)...)
+	}
+
+	for _, policy := range validatingAdmissionPolicyList.Items {
+
+		if globals.IsSubset(referenceAnnotations, policy.Annotations) {
+			err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &policy, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing ValidatingAdmissionPolicy: %s", err.Error()))
 			}
 		}
 	}
 
 	return errors.Join(allErrors...)
 }
+
+// prepareClusterRole completes a rendered ClusterRole with the metadata set on every applied one,
+// and passes it through the mutation hook
+func (r *DynamicClusterRoleReconciler) prepareClusterRole(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole,
+	clusterRole *rbacv1.ClusterRole) (err error) {
+
+	propagateAnnotations(resource, clusterRole, r.PropagatedAnnotations)
+
+	if r.StandardLabels {
+		err = setStandardLabels(clusterRole, resource.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return mutateResource(ctx, r.MutationHook, clusterRole)
+}
+
+// PropagateTargets mirrors the ClusterRoles generated by the DynamicClusterRole onto the member clusters of the
+// inventory, recording the result of each one on the status. Rules are expanded against the resources available
+// on each member, and only the ClusterRoles generated on the management cluster are propagated.
+// Those of dry-run targets are kept as they are on the management cluster. Released ones are removed from the members
+func (r *DynamicClusterRoleReconciler) PropagateTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole,
+	released bool) (err error) {
+
+	if r.MemberClusters == nil {
+		return err
+	}
+
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	lists := []client.ObjectList{&rbacv1.ClusterRoleList{}}
+
+	// ClusterRoles of dry-run targets are not touched on the management cluster, so the owned ones are kept
+	var dryRunObjects []client.Object
+	if !released && len(resource.Status.RenderedClusterRoles) > 0 {
+		existentObjects, err := listObjects(ctx, r.Client, lists...)
+		if err != nil {
+			return err
+		}
+
+		for _, existentObject := range existentObjects {
+			rendered := slices.ContainsFunc(resource.Status.RenderedClusterRoles, func(clusterRole kuberbacv1alpha1.RenderedClusterRoleT) bool {
+				return clusterRole.Name == existentObject.GetName()
+			})
+			if rendered && globals.IsSubset(referenceAnnotations, existentObject.GetAnnotations()) {
+				dryRunObjects = append(dryRunObjects, existentObject)
+			}
+		}
+	}
+
+	desiredObjects := func(ctx context.Context, memberCluster multicluster.MemberCluster) (objects []client.Object, err error) {
+		if released {
+			return objects, err
+		}
+
+		var discoverer policy.ResourceDiscoverer = r.DiscoveryCache
+		if memberCluster.Discovery != nil {
+			discoverer = memberCluster.Discovery
+		}
+
+		clusterRoles, _, _, _, err := RenderClusterRoles(ctx, r.Client, discoverer,
+			r.WildcardVerbs, r.ObjectListing, r.SelfProtection, resource)
+		if err != nil {
+			return objects, err
+		}
+
+		for _, targetClusterRoles := range clusterRoles {
+			if targetClusterRoles.Target.DryRun {
+				continue
+			}
+
+			for _, clusterRole := range targetClusterRoles.ClusterRoles {
+				if !slices.Contains(resource.Status.GeneratedClusterRoles, clusterRole.Name) {
+					continue
+				}
+
+				err = r.prepareClusterRole(ctx, resource, &clusterRole)
+				if err != nil {
+					return objects, err
+				}
+				objects = append(objects, clusterRole.DeepCopy())
+			}
+		}
+
+		return append(objects, dryRunObjects...), err
+	}
+
+	resource.Status.MemberClusters, err = propagateTargets(ctx, r.MemberClusters, referenceAnnotations,
+		desiredObjects, lists, resource.Status.MemberClusters)
+	return err
+}
@@ -18,19 +18,36 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
-
-	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/groupprovider"
+	"prosimcorp.com/kuberbac/internal/metrics"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+	"prosimcorp.com/kuberbac/internal/mutationhook"
 )
 
 // DynamicRoleBindingReconciler reconciles a DynamicRoleBinding object
@@ -38,16 +55,62 @@ type DynamicRoleBindingReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	// TODO
-	DiscoveryClient discovery.DiscoveryClient
+	// Recorder emits Kubernetes Events about the synchronization of the resources
+	Recorder record.EventRecorder
+
+	// OwnershipMode defines how generated resources are tracked: 'annotations' or 'references'
+	OwnershipMode string
+
+	// StandardLabels stamps the generated bindings with the 'app.kubernetes.io' labels and the hash of their
+	// desired state, which also skips writing them while nothing changes
+	StandardLabels bool
+
+	// PropagatedAnnotations lists the annotations copied from resources onto the ones they generate,
+	// such as the tracking ids of GitOps tools. Items ending with '*' are prefixes
+	PropagatedAnnotations []string
+
+	// MutationHook receives the generated ClusterRoles and bindings before they are applied, so they can be
+	// changed or rejected by an external webhook. Disabled when nil
+	MutationHook *mutationhook.Hook
+
+	// MemberClusters is the inventory of clusters the generated bindings are propagated onto. Disabled when nil
+	MemberClusters *multicluster.Inventory
+
+	// DiscoveryCache is shared between reconcilers to avoid requesting resources to the API server on each sync
+	DiscoveryCache *discoverycache.DiscoveryCache
+
+	// GroupProvider lists the groups of an external directory to select Group subjects by regular expression. Optional
+	GroupProvider groupprovider.Provider
+
+	// UserProvider lists the users of an external directory to select User subjects by regular expression. Optional
+	UserProvider groupprovider.UserProvider
+
+	// ExcludeSystemNamespaces skips system namespaces when selecting target namespaces,
+	// unless resources override it
+	ExcludeSystemNamespaces bool
+
+	// WatchNamespaces restricts the namespaces where resources are generated. All of them are used when empty
+	WatchNamespaces []string
+
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff applied to requeue failed synchronizations
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// SyncSchedule defines when resources are synchronized again after a synchronization
+	SyncSchedule SyncScheduleT
 }
 
 // +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicrolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicrolebindings/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicrolebindings/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=rolebindings;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete;bind;escalate
-// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list
-// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=namespaceselectorclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="certificates.k8s.io",resources=certificatesigningrequests,verbs=list
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -78,19 +141,32 @@ func (r *DynamicRoleBindingReconciler) Reconcile(ctx context.Context, req ctrl.R
 	if !dynamicRoleBindingResource.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(dynamicRoleBindingResource, resourceFinalizer) {
 
-			// Delete all created targets
+			// Delete or orphan all created targets, depending on the deletion policy
 			err = r.DeleteTargets(ctx, dynamicRoleBindingResource)
 			if err != nil {
 				logger.Info(fmt.Sprintf(resourceTargetsDeleteError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
 				return result, err
 			}
 
+			// Member clusters are cleaned too, unless targets are orphaned. Unreachable ones do not block the deletion
+			if dynamicRoleBindingResource.Spec.DeletionPolicy != kuberbacv1alpha1.DeletionPolicyOrphan {
+				err = r.PropagateTargets(ctx, dynamicRoleBindingResource, nil)
+				if err != nil {
+					logger.Info(fmt.Sprintf(propagateTargetsError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+					r.Recorder.Event(dynamicRoleBindingResource, corev1.EventTypeWarning, eventReasonPropagationFailed, err.Error())
+				}
+			}
+
 			// Remove the finalizers on CR
-			controllerutil.RemoveFinalizer(dynamicRoleBindingResource, resourceFinalizer)
-			err = r.Update(ctx, dynamicRoleBindingResource)
+			err = updateResource(ctx, r.Client, dynamicRoleBindingResource, func() {
+				controllerutil.RemoveFinalizer(dynamicRoleBindingResource, resourceFinalizer)
+			})
 			if err != nil {
 				logger.Info(fmt.Sprintf(resourceFinalizersUpdateError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
 			}
+
+			// Forget the metrics related to this resource
+			metrics.DeleteResourceMetrics(DynamicRoleBindingResourceType, req.Namespace, req.Name)
 		}
 		result = ctrl.Result{}
 		err = nil
@@ -99,51 +175,399 @@ func (r *DynamicRoleBindingReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	// 4. Add finalizer to the DynamicClusterRole CR
 	if !controllerutil.ContainsFinalizer(dynamicRoleBindingResource, resourceFinalizer) {
-		controllerutil.AddFinalizer(dynamicRoleBindingResource, resourceFinalizer)
-		err = r.Update(ctx, dynamicRoleBindingResource)
+		err = updateResource(ctx, r.Client, dynamicRoleBindingResource, func() {
+			controllerutil.AddFinalizer(dynamicRoleBindingResource, resourceFinalizer)
+		})
 		if err != nil {
 			return result, err
 		}
 	}
 
-	// 5. Update the status before the requeue
+	// 5. Update the status before the requeue, along with the time of the next synchronization
 	defer func() {
-		err = r.Status().Update(ctx, dynamicRoleBindingResource)
-		if err != nil {
-			logger.Info(fmt.Sprintf(resourceConditionUpdateError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+		dynamicRoleBindingResource.Status.NextSyncTime = getNextSyncTime(result, err)
+		globals.UpdateReadyCondition(&dynamicRoleBindingResource.Status.Conditions, dynamicRoleBindingResource.Generation)
+		statusErr := updateResourceStatus(ctx, r.Client, dynamicRoleBindingResource)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, DynamicRoleBindingResourceType, req.NamespacedName, statusErr.Error()))
+			result = ctrl.Result{}
+			err = errors.Join(err, statusErr)
 		}
 	}()
 
-	// 6. Schedule periodical request
-	RequeueTime, err := time.ParseDuration(dynamicRoleBindingResource.Spec.Synchronization.Time)
+	// 6. Schedule periodical request. Invalid times fall back to the default one, so the resource is still synchronized
+	RequeueTime, err := r.SyncSchedule.GetRequeueTime(dynamicRoleBindingResource.Spec.Synchronization)
 	if err != nil {
 		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
-		return result, err
+		r.Recorder.Event(dynamicRoleBindingResource, corev1.EventTypeWarning, globals.ConditionReasonInvalidSpecType, err.Error())
+		err = nil
 	}
 	result = ctrl.Result{
 		RequeueAfter: RequeueTime,
 	}
 
-	// 7. The Patch CR already exist: manage the update
-	err = r.SyncTarget(ctx, dynamicRoleBindingResource)
+	// 7. Preview the selectors when requested through the annotations, without touching the bindings.
+	// The explanation extends the preview with the decision taken for each evaluated namespace and ServiceAccount.
+	// The annotations are removed first, as it refreshes the resource, so the preview survives in the status
+	explainRequested := dynamicRoleBindingResource.Annotations[explainAnnotation] == "true"
+	if dynamicRoleBindingResource.Annotations[previewAnnotation] == "true" || explainRequested {
+		err = updateResource(ctx, r.Client, dynamicRoleBindingResource, func() {
+			delete(dynamicRoleBindingResource.Annotations, previewAnnotation)
+			delete(dynamicRoleBindingResource.Annotations, explainAnnotation)
+		})
+		if err != nil {
+			return result, err
+		}
+
+		err = r.PreviewTarget(ctx, dynamicRoleBindingResource, explainRequested)
+		if err != nil {
+			logger.Info(fmt.Sprintf(syncTargetError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+			r.Recorder.Event(dynamicRoleBindingResource, corev1.EventTypeWarning, eventReasonSyncFailed,
+				"Preview failed: "+err.Error())
+			err = nil
+			return result, err
+		}
+
+		logger.V(logLevelDecisions).Info("Selectors previewed into the status",
+			"subjects", len(dynamicRoleBindingResource.Status.RenderedSubjects),
+			"namespaces", len(dynamicRoleBindingResource.Status.RenderedNamespaces),
+			"decisions", len(dynamicRoleBindingResource.Status.SelectionExplanation))
+		r.Recorder.Eventf(dynamicRoleBindingResource, corev1.EventTypeNormal, eventReasonPreviewed,
+			"Previewed %d subjects and %d namespaces into the status", len(dynamicRoleBindingResource.Status.RenderedSubjects),
+			len(dynamicRoleBindingResource.Status.RenderedNamespaces))
+		return result, err
+	}
+
+	// 8. Delete the bindings once they expire, or while the schedule does not grant the access.
+	// Expired resources are not synchronized again until the expiration changes, while scheduled ones
+	// are requeued on time to create the bindings again
+	expirationTime, expirationErr := r.GetExpirationTime(dynamicRoleBindingResource)
+	scheduleActive, nextScheduleChangeTime, scheduleErr := r.GetScheduleState(dynamicRoleBindingResource, time.Now())
+	err = errors.Join(expirationErr, scheduleErr)
+	if err != nil {
+		logger.Info(fmt.Sprintf(syncTargetError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+		eventReason := r.UpdateConditionSyncFailure(dynamicRoleBindingResource, err)
+		r.Recorder.Event(dynamicRoleBindingResource, corev1.EventTypeWarning, eventReason, err.Error())
+		result, err = syncErrorResult(err)
+		return result, err
+	}
+	dynamicRoleBindingResource.Status.ExpirationTime = expirationTime
+	dynamicRoleBindingResource.Status.NextScheduleChangeTime = nextScheduleChangeTime
+
+	// Dry-run resources never touch the bindings, so their schedule is only reported
+	expired := expirationTime != nil && !time.Now().Before(expirationTime.Time)
+	unscheduled := !scheduleActive && !dynamicRoleBindingResource.Spec.Targets.DryRun
+	if expired || unscheduled {
+		err = r.RevokeTargets(ctx, dynamicRoleBindingResource)
+		if err != nil {
+			logger.Info(fmt.Sprintf(syncTargetError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+			r.UpdateConditionSyncFailure(dynamicRoleBindingResource, err)
+			result, err = syncErrorResult(err)
+			return result, err
+		}
+
+		// Access is revoked on the member clusters too
+		err = r.PropagateTargets(ctx, dynamicRoleBindingResource, nil)
+		if err != nil {
+			logger.Info(fmt.Sprintf(propagateTargetsError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+			r.Recorder.Event(dynamicRoleBindingResource, corev1.EventTypeWarning, eventReasonPropagationFailed, err.Error())
+			result, err = syncErrorResult(err)
+			return result, err
+		}
+
+		revokedBindingsCount := len(dynamicRoleBindingResource.Status.GeneratedBindings)
+		dynamicRoleBindingResource.Status.GeneratedBindings = nil
+		dynamicRoleBindingResource.Status.GeneratedBindingsCount = 0
+
+		if expired {
+			if revokedBindingsCount > 0 {
+				logger.V(logLevelChanges).Info("Bindings deleted: they expired", "expirationTime", expirationTime.String())
+				r.Recorder.Eventf(dynamicRoleBindingResource, corev1.EventTypeNormal, eventReasonExpired,
+					"Deleted %d bindings as they expired at %s", revokedBindingsCount, expirationTime.String())
+			}
+			r.UpdateConditionBindingsExpired(dynamicRoleBindingResource)
+
+			result = ctrl.Result{}
+			return result, err
+		}
+
+		if revokedBindingsCount > 0 {
+			logger.V(logLevelChanges).Info("Bindings deleted: the schedule does not grant the access",
+				"nextScheduleChangeTime", nextScheduleChangeTime.String())
+			r.Recorder.Eventf(dynamicRoleBindingResource, corev1.EventTypeNormal, eventReasonOutsideSchedule,
+				"Deleted %d bindings as the schedule does not grant the access until %s", revokedBindingsCount,
+				nextScheduleChangeTime.String())
+		}
+		r.UpdateConditionOutsideSchedule(dynamicRoleBindingResource)
+
+		// Schedules that never grant the access again wait for changes
+		result = ctrl.Result{}
+		if nextScheduleChangeTime != nil {
+			result.RequeueAfter = max(time.Until(nextScheduleChangeTime.Time), time.Second)
+		}
+		return result, err
+	}
+
+	// 9. The Patch CR already exist: manage the update.
+	// A new summary of changes is stored on the status only when the bound subjects changed
+	previousChange := dynamicRoleBindingResource.Status.LastChange
+	syncStartTime := time.Now()
+	generatedObjects, err := r.SyncTarget(ctx, dynamicRoleBindingResource)
+	syncDuration := time.Since(syncStartTime)
+	metrics.SyncDuration.WithLabelValues(DynamicRoleBindingResourceType, req.Namespace, req.Name).Observe(syncDuration.Seconds())
+	dynamicRoleBindingResource.Status.LastSyncDuration = getSyncDuration(syncDuration)
 	if err != nil {
-		r.UpdateConditionKubernetesApiCallFailure(dynamicRoleBindingResource)
+		metrics.SyncErrors.WithLabelValues(DynamicRoleBindingResourceType, req.Namespace, req.Name).Inc()
+		eventReason := eventReasonSyncFailed
+		switch {
+		case errors.Is(err, errRoleRefNotFound):
+			eventReason = globals.ConditionReasonRoleRefNotFoundType
+			r.UpdateConditionRoleRefNotFound(dynamicRoleBindingResource)
+		default:
+			eventReason = r.UpdateConditionSyncFailure(dynamicRoleBindingResource, err)
+		}
 		logger.Info(fmt.Sprintf(syncTargetError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicRoleBindingResource, corev1.EventTypeWarning, eventReason, err.Error())
+
+		// Invalid specs wait for changes, while the rest of failures are retried with backoff
+		result, err = syncErrorResult(err)
 		return result, err
 	}
 
-	// 8. Success, update the status
-	r.UpdateConditionSuccess(dynamicRoleBindingResource)
+	// 10. Propagate the generated bindings onto the member clusters. Failures are reported on the status of each
+	// member cluster, but they do not fail the synchronization, as the management cluster is already synchronized.
+	// On dry-run mode the member clusters are not touched, the same way as the management one
+	if !dynamicRoleBindingResource.Spec.Targets.DryRun {
+		err = r.PropagateTargets(ctx, dynamicRoleBindingResource, generatedObjects)
+	}
+	if err != nil {
+		logger.Info(fmt.Sprintf(propagateTargetsError, DynamicRoleBindingResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicRoleBindingResource, corev1.EventTypeWarning, eventReasonPropagationFailed, err.Error())
+		err = nil
+	}
+
+	// 11. Success, update the status
+	dynamicRoleBindingResource.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	dynamicRoleBindingResource.Status.ObservedGeneration = dynamicRoleBindingResource.Generation
+
+	// Expiration starts counting when the bindings are created, and the resource is requeued on time to delete them
+	if dynamicRoleBindingResource.Spec.Targets.ExpiresAfter != "" && !dynamicRoleBindingResource.Spec.Targets.DryRun {
+		if dynamicRoleBindingResource.Status.BindingsCreationTime == nil {
+			dynamicRoleBindingResource.Status.BindingsCreationTime = dynamicRoleBindingResource.Status.LastSyncTime
+		}
+
+		expirationTime, err = r.GetExpirationTime(dynamicRoleBindingResource)
+		if err != nil {
+			return result, err
+		}
+		dynamicRoleBindingResource.Status.ExpirationTime = expirationTime
+		result.RequeueAfter = min(result.RequeueAfter, max(time.Until(expirationTime.Time), time.Second))
+	}
+
+	// The resource is requeued on time to delete the bindings once the schedule stops granting the access
+	if nextScheduleChangeTime != nil && !dynamicRoleBindingResource.Spec.Targets.DryRun {
+		result.RequeueAfter = min(result.RequeueAfter, max(time.Until(nextScheduleChangeTime.Time), time.Second))
+	}
+
+	if dynamicRoleBindingResource.Spec.Targets.DryRun {
+		r.UpdateConditionDryRun(dynamicRoleBindingResource)
+		r.Recorder.Eventf(dynamicRoleBindingResource, corev1.EventTypeNormal, eventReasonRendered,
+			"Rendered %d subjects and %d namespaces into the status", dynamicRoleBindingResource.Status.SubjectsCount,
+			dynamicRoleBindingResource.Status.TargetNamespacesCount)
+	} else {
+		r.UpdateConditionSuccess(dynamicRoleBindingResource)
+
+		// Unchanged synchronizations are not reported, so periodic ones do not flood the Events of the resource
+		if dynamicRoleBindingResource.Status.LastChange != previousChange {
+			r.Recorder.Eventf(dynamicRoleBindingResource, corev1.EventTypeNormal, eventReasonSynced,
+				"Synced %d bindings for %d subjects", len(dynamicRoleBindingResource.Status.GeneratedBindings),
+				dynamicRoleBindingResource.Status.SubjectsCount)
+		}
+	}
 
 	logger.Info(fmt.Sprintf(scheduleSynchronization, DynamicRoleBindingResourceType, req.NamespacedName, result.RequeueAfter.String()))
 
 	return result, err
 }
 
+// referencesClusterRole returns whether the source references the ClusterRole by its name or selects it by its labels
+func referencesClusterRole(source *kuberbacv1alpha1.DynamicRoleBindingSource, clusterRole client.Object) bool {
+
+	if source.ClusterRole == clusterRole.GetName() || slices.Contains(source.ClusterRoles, clusterRole.GetName()) {
+		return true
+	}
+
+	if source.ClusterRoleSelector == nil {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(source.ClusterRoleSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(clusterRole.GetLabels()))
+}
+
+// referencingRoleBindingsMapFunc maps a ClusterRole to requests for the DynamicRoleBindings referencing it,
+// so they notice on the spot when it is created, deleted or relabeled
+func (r *DynamicRoleBindingReconciler) referencingRoleBindingsMapFunc(ctx context.Context, object client.Object) (requests []reconcile.Request) {
+
+	dynamicRoleBindingList := kuberbacv1alpha1.DynamicRoleBindingList{}
+	err := r.List(ctx, &dynamicRoleBindingList)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list DynamicRoleBindings referencing a ClusterRole", "clusterRole", object.GetName())
+		return nil
+	}
+
+	for _, dynamicRoleBinding := range dynamicRoleBindingList.Items {
+		if !referencesClusterRole(&dynamicRoleBinding.Spec.Source, object) {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&dynamicRoleBinding),
+		})
+	}
+
+	return requests
+}
+
+// bootstrappingRoleBindingsMapFunc maps a freshly created Namespace to requests for the DynamicRoleBindings
+// bootstrapping the namespaces matching their target selector, so their bindings are created on the spot
+func (r *DynamicRoleBindingReconciler) bootstrappingRoleBindingsMapFunc(ctx context.Context, object client.Object) (requests []reconcile.Request) {
+	return r.targetingRoleBindingsRequests(ctx, object, true)
+}
+
+// activatedNamespaceRoleBindingsMapFunc maps a Namespace becoming active to requests for the DynamicRoleBindings
+// targeting it, as their bindings were not created on it while it was not active
+func (r *DynamicRoleBindingReconciler) activatedNamespaceRoleBindingsMapFunc(ctx context.Context, object client.Object) (requests []reconcile.Request) {
+	return r.targetingRoleBindingsRequests(ctx, object, false)
+}
+
+// targetingRoleBindingsRequests returns requests for the DynamicRoleBindings whose target selector matches the Namespace.
+// Only those bootstrapping namespaces are returned when requested
+func (r *DynamicRoleBindingReconciler) targetingRoleBindingsRequests(ctx context.Context, object client.Object,
+	onlyBootstrapping bool) (requests []reconcile.Request) {
+
+	namespace, ok := object.(*corev1.Namespace)
+	if !ok {
+		return nil
+	}
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*namespace}}
+
+	dynamicRoleBindingList := kuberbacv1alpha1.DynamicRoleBindingList{}
+	err := r.List(ctx, &dynamicRoleBindingList)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list DynamicRoleBindings targeting a Namespace", "namespace", namespace.Name)
+		return nil
+	}
+
+	for _, dynamicRoleBinding := range dynamicRoleBindingList.Items {
+		targets := &dynamicRoleBinding.Spec.Targets
+		if (onlyBootstrapping && !targets.Bootstrap.Enabled) || GetTargetsMode(targets) == kuberbacv1alpha1.TargetsModeClusterScoped {
+			continue
+		}
+
+		namespaceSelector, err := r.GetTargetsNamespaceSelector(ctx, targets)
+		if err != nil {
+			continue
+		}
+
+		selectedNamespaces, err := FilterNamespaceListBySelector(namespaceList, namespaceSelector)
+		if err != nil || len(selectedNamespaces) == 0 {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&dynamicRoleBinding),
+		})
+	}
+
+	return requests
+}
+
+// namespaceSelectorClassRoleBindingsMapFunc maps a NamespaceSelectorClass to requests for the DynamicRoleBindings
+// referencing it, so their target namespaces are selected again on the spot when it changes
+func (r *DynamicRoleBindingReconciler) namespaceSelectorClassRoleBindingsMapFunc(ctx context.Context, object client.Object) (requests []reconcile.Request) {
+
+	dynamicRoleBindingList := kuberbacv1alpha1.DynamicRoleBindingList{}
+	err := r.List(ctx, &dynamicRoleBindingList)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list DynamicRoleBindings referencing a NamespaceSelectorClass",
+			"namespaceSelectorClass", object.GetName())
+		return nil
+	}
+
+	for _, dynamicRoleBinding := range dynamicRoleBindingList.Items {
+		if dynamicRoleBinding.Spec.Targets.NamespaceSelectorClassName != object.GetName() {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&dynamicRoleBinding),
+		})
+	}
+
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *DynamicRoleBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// ServiceAccounts are indexed by name, so those selected by their names are read from the cache
+	// without going through every ServiceAccount of the targeted namespaces
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.ServiceAccount{}, serviceAccountNameField,
+		func(object client.Object) []string {
+			return []string{object.GetName()}
+		})
+	if err != nil {
+		return err
+	}
+
+	// Generated bindings are watched, so manual changes on them are reverted on the spot
+	mapToOwner := handler.EnqueueRequestsFromMapFunc(ownerAnnotationsMapFunc(DynamicRoleBindingResourceType))
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&kuberbacv1alpha1.DynamicRoleBinding{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&kuberbacv1alpha1.DynamicRoleBinding{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			// Requesting a preview or an explanation does not change the generation
+			predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return e.ObjectNew.GetAnnotations()[previewAnnotation] == "true" ||
+						e.ObjectNew.GetAnnotations()[explainAnnotation] == "true"
+				},
+			},
+			propagatedAnnotationsChangedPredicate(r.PropagatedAnnotations),
+		))).
+		Watches(&rbacv1.RoleBinding{}, mapToOwner).
+		Watches(&rbacv1.ClusterRoleBinding{}, mapToOwner).
+		Watches(&rbacv1.ClusterRole{}, handler.EnqueueRequestsFromMapFunc(r.referencingRoleBindingsMapFunc),
+			builder.WithPredicates(predicate.Funcs{
+				// Changing labels can make ClusterRoles start or stop matching selectors
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return !maps.Equal(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+				},
+			})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.bootstrappingRoleBindingsMapFunc),
+			builder.WithPredicates(predicate.Funcs{
+				// Only freshly created namespaces are bootstrapped, the rest wait for the next synchronization
+				UpdateFunc:  func(event.UpdateEvent) bool { return false },
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.activatedNamespaceRoleBindingsMapFunc),
+			builder.WithPredicates(predicate.Funcs{
+				// Namespaces are skipped while they are not active, so they are targeted again once they are
+				CreateFunc: func(event.CreateEvent) bool { return false },
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return e.ObjectOld.(*corev1.Namespace).Status.Phase != corev1.NamespaceActive &&
+						e.ObjectNew.(*corev1.Namespace).Status.Phase == corev1.NamespaceActive
+				},
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			})).
+		Watches(&kuberbacv1alpha1.NamespaceSelectorClass{}, handler.EnqueueRequestsFromMapFunc(r.namespaceSelectorClassRoleBindingsMapFunc),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.RetryBaseDelay, r.RetryMaxDelay)}).
 		Complete(r)
 }
@@ -18,16 +18,22 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/globals"
 )
 
 var _ = Describe("DynamicRoleBinding Controller", func() {
@@ -69,8 +75,9 @@ var _ = Describe("DynamicRoleBinding Controller", func() {
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &DynamicRoleBindingReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -82,3 +89,840 @@ var _ = Describe("DynamicRoleBinding Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("DynamicRoleBinding namespace selector classes", func() {
+	Context("When the targets reference a NamespaceSelectorClass", func() {
+		const resourceName = "tenant-admins"
+		const tenantNamespace = "tenant-a"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		namespaceSelectorClass := &kuberbacv1alpha1.NamespaceSelectorClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-namespaces"},
+			Spec: kuberbacv1alpha1.NamespaceSelectorClassSpec{
+				NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+					MatchLabels: map[string]string{"kuberbac.prosimcorp.com/tenant": "true"},
+				},
+			},
+		}
+
+		newReconciler := func() *DynamicRoleBindingReconciler {
+			return &DynamicRoleBindingReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+		}
+
+		BeforeEach(func() {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   tenantNamespace,
+				Labels: map[string]string{"kuberbac.prosimcorp.com/tenant": "true"},
+			}}
+			if err := k8sClient.Create(ctx, namespace); err != nil && !errors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Create(ctx, namespaceSelectorClass.DeepCopy())).To(Succeed())
+
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						Role: "tenant-admin",
+						StaticSubjects: []rbacv1.Subject{
+							{Kind: "User", APIGroup: rbacv1.GroupName, Name: "alice"},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name:                       resourceName,
+						NamespaceSelectorClassName: namespaceSelectorClass.Name,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, namespaceSelectorClass.DeepCopy()))).To(Succeed())
+		})
+
+		It("should bind the role on the namespaces selected by the class", func() {
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: tenantNamespace},
+				&rbacv1.RoleBinding{})).To(Succeed())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should reject an inline namespaceSelector along with the class", func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Targets.NamespaceSelector.MatchList = []string{"default"}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(ContainElement(HaveField("Reason", globals.ConditionReasonInvalidSpecType)))
+		})
+	})
+})
+
+var _ = Describe("DynamicRoleBinding selection explanation", func() {
+	Context("When an explanation is requested through the annotation", func() {
+		const resourceName = "explained-admins"
+		const selectedNamespace = "explained-a"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicRoleBindingReconciler {
+			return &DynamicRoleBindingReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+		}
+
+		BeforeEach(func() {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   selectedNamespace,
+				Labels: map[string]string{"kuberbac.prosimcorp.com/explained": "true"},
+			}}
+			if err := k8sClient.Create(ctx, namespace); err != nil && !errors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        resourceName,
+					Namespace:   "default",
+					Annotations: map[string]string{explainAnnotation: "true"},
+				},
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						Role: "admin",
+						StaticSubjects: []rbacv1.Subject{
+							{Kind: "User", APIGroup: rbacv1.GroupName, Name: "alice"},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name: resourceName,
+						NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+							MatchLabels: map[string]string{"kuberbac.prosimcorp.com/explained": "true"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should write the decision taken for each namespace without creating bindings", func() {
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Annotations).NotTo(HaveKey(explainAnnotation))
+			Expect(resource.Status.SelectionExplanation).To(ContainElement(kuberbacv1alpha1.SelectionDecisionT{
+				Selector: "targets.namespaceSelector",
+				Kind:     "Namespace",
+				Name:     selectedNamespace,
+				Selected: true,
+				Reason:   "labels match 'kuberbac.prosimcorp.com/explained=true'",
+			}))
+			Expect(resource.Status.SelectionExplanation).To(ContainElement(kuberbacv1alpha1.SelectionDecisionT{
+				Selector: "targets.namespaceSelector",
+				Kind:     "Namespace",
+				Name:     "default",
+				Selected: false,
+				Reason:   "labels do not match 'kuberbac.prosimcorp.com/explained=true'",
+			}))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: selectedNamespace}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When the subject namespaces are not watched by the operator", func() {
+		const unwatchedNamespace = "explained-unwatched"
+
+		ctx := context.Background()
+
+		It("should explain them as not selected, and look for no ServiceAccount", func() {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: unwatchedNamespace}}
+			if err := k8sClient.Create(ctx, namespace); err != nil && !errors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			reconciler := &DynamicRoleBindingReconciler{
+				Client:          k8sClient,
+				Scheme:          k8sClient.Scheme(),
+				Recorder:        &record.FakeRecorder{},
+				WatchNamespaces: []string{"default"},
+			}
+
+			result, err := reconciler.ExplainSelection(ctx, &kuberbacv1alpha1.DynamicRoleBinding{
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						Role: "admin",
+						Subject: &kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+							Kind: rbacv1.ServiceAccountKind,
+							NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+								MatchList: []string{unwatchedNamespace},
+							},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name:          "explained-unwatched",
+						ClusterScoped: true,
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(ContainElement(kuberbacv1alpha1.SelectionDecisionT{
+				Selector: "source.subject.namespaceSelector",
+				Kind:     "Namespace",
+				Name:     unwatchedNamespace,
+				Selected: false,
+				Reason:   "namespace is not watched by the operator",
+			}))
+			Expect(result).NotTo(ContainElement(HaveField("Kind", rbacv1.ServiceAccountKind)))
+		})
+	})
+})
+
+var _ = Describe("DynamicRoleBinding regular expressions", func() {
+	Context("When the selectors contain regular expressions", func() {
+		const resourceName = "regex-admins"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newResource := func(namespaceSelector kuberbacv1alpha1.NamespaceSelectorT) *kuberbacv1alpha1.DynamicRoleBinding {
+			return &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						Role: "admin",
+						StaticSubjects: []rbacv1.Subject{
+							{Kind: "User", APIGroup: rbacv1.GroupName, Name: "alice"},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name:              resourceName,
+						NamespaceSelector: namespaceSelector,
+					},
+				},
+			}
+		}
+
+		It("should reject a matchRegex expression not compiling on admission", func() {
+			resource := newResource(kuberbacv1alpha1.NamespaceSelectorT{
+				MatchRegex: kuberbacv1alpha1.MatchRegexT{Expression: "^(team-"},
+			})
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("should report a matchAnnotationsRegex expression not compiling with its own reason", func() {
+			resource := newResource(kuberbacv1alpha1.NamespaceSelectorT{
+				MatchAnnotationsRegex: map[string]string{"team.company.com/id": "^(payments-"},
+			})
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &DynamicRoleBindingReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(ContainElement(HaveField("Reason", globals.ConditionReasonInvalidRegexType)))
+
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("DynamicRoleBinding schedule", func() {
+	Context("When the bindings are limited by a schedule", func() {
+		const resourceName = "on-call"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicRoleBindingReconciler {
+			return &DynamicRoleBindingReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+		}
+
+		createResource := func(schedule *kuberbacv1alpha1.ScheduleT) {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						ClusterRole: "view",
+						StaticSubjects: []rbacv1.Subject{
+							{Kind: "User", APIGroup: rbacv1.GroupName, Name: "alice"},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name: resourceName,
+						NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+							MatchList: []string{"default"},
+						},
+					},
+					Schedule: schedule,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		}
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should create the bindings while the schedule grants the access", func() {
+			activeTo := metav1.NewTime(time.Now().Add(time.Hour))
+			createResource(&kuberbacv1alpha1.ScheduleT{ActiveTo: &activeTo})
+
+			result, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: "default"},
+				&rbacv1.RoleBinding{})).To(Succeed())
+
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.NextScheduleChangeTime).NotTo(BeNil())
+			Expect(resource.Status.NextScheduleChangeTime.Time).To(BeTemporally("~", activeTo.Time, time.Second))
+		})
+
+		It("should not create the bindings while the schedule does not grant the access", func() {
+			activeFrom := metav1.NewTime(time.Now().Add(time.Hour))
+			createResource(&kuberbacv1alpha1.ScheduleT{ActiveFrom: &activeFrom})
+
+			result, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(ContainElement(HaveField("Reason", globals.ConditionReasonOutsideScheduleType)))
+		})
+	})
+})
+
+var _ = Describe("DynamicRoleBinding namespace ServiceAccounts", func() {
+	Context("When the subjects are all the ServiceAccounts of some namespaces", func() {
+		const resourceName = "namespace-service-accounts"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicRoleBindingReconciler {
+			return &DynamicRoleBindingReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+		}
+
+		BeforeEach(func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						ClusterRole: "view",
+						Subject: &kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+							Kind: kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts,
+							NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+								MatchList: []string{"default"},
+							},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name: resourceName,
+						NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+							MatchList: []string{"default"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should bind the group of the ServiceAccounts of each selected namespace", func() {
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: "default"}, roleBinding)).To(Succeed())
+			Expect(roleBinding.Subjects).To(ConsistOf(rbacv1.Subject{
+				Kind:     rbacv1.GroupKind,
+				APIGroup: rbacv1.GroupName,
+				Name:     "system:serviceaccounts:default",
+			}))
+		})
+
+		It("should reject selecting the ServiceAccounts by name", func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Source.Subject.NameSelector.MatchList = []string{"default"}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(ContainElement(HaveField("Reason", globals.ConditionReasonSelectorErrorType)))
+		})
+	})
+})
+
+var _ = Describe("DynamicRoleBinding identity providers", func() {
+	Context("When the subjects follow the identity conventions of a managed Kubernetes provider", func() {
+		const resourceName = "identity-provider-groups"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newReconciler := func() *DynamicRoleBindingReconciler {
+			return &DynamicRoleBindingReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+		}
+
+		BeforeEach(func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						ClusterRole: "view",
+						Subject: &kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+							ApiGroup:         rbacv1.GroupName,
+							Kind:             rbacv1.GroupKind,
+							IdentityProvider: kuberbacv1alpha1.IdentityProviderGKE,
+							NamePrefix:       "oidc:",
+							NameSelector: kuberbacv1alpha1.NameSelectorT{
+								MatchList: []string{"engineering@example.com", "oidc:platform@example.com"},
+							},
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name: resourceName,
+						NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{
+							MatchList: []string{"default"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should bind the groups with the prefix, keeping the names already prefixed", func() {
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: "default"}, roleBinding)).To(Succeed())
+			Expect(roleBinding.Subjects).To(ConsistOf(
+				rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "oidc:engineering@example.com"},
+				rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "oidc:platform@example.com"},
+			))
+		})
+
+		It("should reject the names not following the conventions of the provider", func() {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Source.Subject.IdentityProvider = kuberbacv1alpha1.IdentityProviderAKS
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(ContainElement(HaveField("Reason", globals.ConditionReasonInvalidSpecType)))
+		})
+	})
+})
+
+var _ = Describe("DynamicRoleBinding static subjects", func() {
+
+	owner := metav1.ObjectMeta{Name: "static-subjects", Namespace: "default", Labels: map[string]string{"team": "payments"}}
+	namespace := metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"environment": "production"}}
+
+	DescribeTable("When rendering the templates of static subjects",
+		func(staticSubject rbacv1.Subject, expected []rbacv1.Subject, expectedError string) {
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: owner,
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						StaticSubjects: []rbacv1.Subject{staticSubject},
+					},
+				},
+			}
+
+			subjects, err := RenderStaticSubjects(resource, namespace)
+			if expectedError != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedError)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subjects).To(Equal(expected))
+		},
+		Entry("should render ServiceAccounts with the target namespace",
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "{{ .Namespace.Name }}-deployer", Namespace: "{{ .Namespace.Name }}"},
+			[]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "payments-deployer", Namespace: "payments"}}, ""),
+		Entry("should render Groups with the metadata of the owner and the namespace, defaulting their APIGroup",
+			rbacv1.Subject{Kind: rbacv1.GroupKind, Name: `{{ index .Owner.Labels "team" }}-{{ index .Namespace.Labels "environment" }}`, Namespace: "ignored"},
+			[]rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "payments-production"}}, ""),
+		Entry("should fail on unknown template keys",
+			rbacv1.Subject{Kind: rbacv1.UserKind, Name: "{{ .Namespace.Unknown }}"},
+			nil, "error rendering static subject name"),
+		Entry("should fail on unknown label keys",
+			rbacv1.Subject{Kind: rbacv1.UserKind, Name: "{{ .Namespace.Labels.unknown }}"},
+			nil, "error rendering static subject name"),
+		Entry("should fail when the name is rendered empty",
+			rbacv1.Subject{Kind: rbacv1.UserKind, Name: "{{ .Namespace.Namespace }}"},
+			nil, "can not be rendered empty"),
+		Entry("should fail when a ServiceAccount is rendered without namespace",
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "{{ .Namespace.Namespace }}"},
+			nil, "can not be rendered without namespace"),
+		Entry("should fail on unknown kinds",
+			rbacv1.Subject{Kind: "Robot", Name: "deployer"},
+			nil, "kind must be one of the following values"),
+	)
+
+	It("should count the subjects rendered on each target namespace", func() {
+		fakeClient := newFakeClientBuilder().WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shipping"},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "view"}},
+		).Build()
+		reconciler := &DynamicRoleBindingReconciler{Client: fakeClient}
+
+		resource := &kuberbacv1alpha1.DynamicRoleBinding{
+			ObjectMeta: owner,
+			Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+				Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+					ClusterRole: "view",
+					StaticSubjects: []rbacv1.Subject{
+						{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "{{ .Namespace.Name }}"},
+						{Kind: rbacv1.GroupKind, Name: "auditors"},
+					},
+				},
+				Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+					Name:              "static-subjects",
+					DryRun:            true,
+					NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{MatchList: []string{"payments", "shipping"}},
+				},
+			},
+		}
+
+		_, err := reconciler.SyncTarget(context.Background(), resource)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resource.Status.RenderedNamespaces).To(ConsistOf("payments", "shipping"))
+		Expect(resource.Status.SubjectsCount).To(Equal(3))
+	})
+})
+
+var _ = Describe("DynamicRoleBinding watched namespaces", func() {
+
+	ctx := context.Background()
+
+	namespace := func(name string) corev1.Namespace {
+		return corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		}
+	}
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{namespace("payments"), namespace("shipping")}}
+
+	newReconciler := func() *DynamicRoleBindingReconciler {
+		return &DynamicRoleBindingReconciler{
+			Client: newFakeClientBuilder().WithObjects(
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "payments"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "shipping"}},
+			).Build(),
+			WatchNamespaces: []string{"payments"},
+		}
+	}
+
+	newSubject := func(kind string, namespaces ...string) *kuberbacv1alpha1.DynamicRoleBindingSourceSubject {
+		subject := &kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+			Kind:              kind,
+			NamespaceSelector: kuberbacv1alpha1.NamespaceSelectorT{MatchList: namespaces},
+		}
+		if kind == rbacv1.ServiceAccountKind {
+			subject.NameSelector.MatchRegex.Expression = "^deployer$"
+		}
+		return subject
+	}
+
+	It("should only select the ServiceAccounts of the watched namespaces", func() {
+		subjects, err := newReconciler().ExpandSubjects(ctx, newSubject(rbacv1.ServiceAccountKind, "payments", "shipping"), namespaceList)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "payments"}}))
+	})
+
+	It("should select no ServiceAccount when only unwatched namespaces are selected", func() {
+		subjects, err := newReconciler().ExpandSubjects(ctx, newSubject(rbacv1.ServiceAccountKind, "shipping"), namespaceList)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subjects).To(BeEmpty())
+	})
+
+	It("should only bind the ServiceAccounts of the watched namespaces as a whole", func() {
+		subjects, err := newReconciler().ExpandSubjects(ctx,
+			newSubject(kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts, "payments", "shipping"), namespaceList)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subjects).To(Equal([]rbacv1.Subject{
+			{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "system:serviceaccounts:payments"},
+		}))
+	})
+})
+
+var _ = Describe("DynamicRoleBinding ClusterRole selector", func() {
+
+	ctx := context.Background()
+
+	clusterRole := func(name string, labels map[string]string) *rbacv1.ClusterRole {
+		return &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+
+	newReconciler := func() *DynamicRoleBindingReconciler {
+		return &DynamicRoleBindingReconciler{
+			Client: newFakeClientBuilder().WithObjects(
+				clusterRole("cluster-admin", nil),
+				clusterRole("developers-view", map[string]string{"bundle": "developers"}),
+				clusterRole("developers-edit", map[string]string{"bundle": "developers"}),
+			).Build(),
+		}
+	}
+
+	newResource := func(selector *metav1.LabelSelector) *kuberbacv1alpha1.DynamicRoleBinding {
+		resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+		resource.Spec.Source.ClusterRoleSelector = selector
+		return resource
+	}
+
+	It("should select the ClusterRoles matching the selector", func() {
+		clusterRoles, err := newReconciler().GetSelectedClusterRoles(ctx,
+			newResource(&metav1.LabelSelector{MatchLabels: map[string]string{"bundle": "developers"}}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusterRoles).To(Equal([]string{"developers-edit", "developers-view"}))
+	})
+
+	DescribeTable("rejecting the selectors matching every ClusterRole",
+		func(selector *metav1.LabelSelector) {
+			clusterRoles, err := newReconciler().GetSelectedClusterRoles(ctx, newResource(selector))
+			Expect(err).To(MatchError(errInvalidSelector))
+			Expect(err).To(MatchError(errInvalidSpec))
+			Expect(clusterRoles).To(BeEmpty())
+		},
+		Entry("empty selector", &metav1.LabelSelector{}),
+		Entry("empty lists", &metav1.LabelSelector{MatchLabels: map[string]string{}, MatchExpressions: []metav1.LabelSelectorRequirement{}}),
+	)
+})
+
+var _ = Describe("DynamicRoleBinding subject sharding", func() {
+
+	// users returns User subjects with the given names, in the same order
+	users := func(names ...string) (result []rbacv1.Subject) {
+		for _, name := range names {
+			result = append(result, rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: name})
+		}
+		return result
+	}
+
+	DescribeTable("When splitting the subjects of a binding target into shards",
+		func(subjects []rbacv1.Subject, maxSubjects int, existentSubjects map[string][]rbacv1.Subject, expected []subjectShardT) {
+			Expect(shardSubjects("developers", subjects, maxSubjects, existentSubjects)).To(Equal(expected))
+		},
+		Entry("should return a single binding named after the target when sharding is disabled",
+			users("alice", "bob", "carol"), 0, nil,
+			[]subjectShardT{{name: "developers", subjects: users("alice", "bob", "carol")}}),
+		Entry("should fill the shards in order when no binding exists yet",
+			users("alice", "bob", "carol"), 2, nil,
+			[]subjectShardT{
+				{name: "developers-0", subjects: users("alice", "bob")},
+				{name: "developers-1", subjects: users("carol")},
+			}),
+		Entry("should bind each subject only once",
+			users("alice", "alice", "bob"), 2, nil,
+			[]subjectShardT{{name: "developers-0", subjects: users("alice", "bob")}}),
+		Entry("should keep the subjects on the shard they already are",
+			users("alice", "bob", "carol"), 2,
+			map[string][]rbacv1.Subject{"developers-0": users("carol"), "developers-1": users("alice")},
+			[]subjectShardT{
+				{name: "developers-0", subjects: users("bob", "carol")},
+				{name: "developers-1", subjects: users("alice")},
+			}),
+		Entry("should place the new subjects on the free room of the existing shards first",
+			users("alice", "bob", "carol", "dave", "erin"), 2,
+			map[string][]rbacv1.Subject{"developers-0": users("alice", "bob"), "developers-1": users("carol")},
+			[]subjectShardT{
+				{name: "developers-0", subjects: users("alice", "bob")},
+				{name: "developers-1", subjects: users("carol", "dave")},
+				{name: "developers-2", subjects: users("erin")},
+			}),
+		Entry("should move the subjects exceeding the size of a shard",
+			users("alice", "bob", "carol"), 2,
+			map[string][]rbacv1.Subject{"developers-0": users("alice", "bob", "carol")},
+			[]subjectShardT{
+				{name: "developers-0", subjects: users("alice", "bob")},
+				{name: "developers-1", subjects: users("carol")},
+			}),
+		Entry("should drop the shards left without subjects",
+			users("carol"), 2,
+			map[string][]rbacv1.Subject{"developers-0": users("alice", "bob"), "developers-1": users("carol")},
+			[]subjectShardT{{name: "developers-1", subjects: users("carol")}}),
+		Entry("should keep an empty first shard when no subject is left",
+			nil, 2,
+			map[string][]rbacv1.Subject{"developers-0": users("alice", "bob"), "developers-1": users("carol")},
+			[]subjectShardT{{name: "developers-0"}}),
+		Entry("should ignore the bindings whose suffix is not a shard index",
+			users("alice", "bob"), 2,
+			map[string][]rbacv1.Subject{
+				"developers-admins": users("alice"),
+				"developers-01":     users("alice"),
+				"developers--1":     users("bob"),
+				"developers-":       users("bob"),
+			},
+			[]subjectShardT{{name: "developers-0", subjects: users("alice", "bob")}}),
+	)
+})
+
+var _ = Describe("DynamicRoleBinding ServiceAccount listing", func() {
+	ctx := context.Background()
+
+	subject := &kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+		Kind: rbacv1.ServiceAccountKind,
+		MetaSelector: kuberbacv1alpha1.MetaSelectorT{
+			MatchLabels: map[string]string{"team": "builders"},
+		},
+	}
+
+	newReconciler := func() *DynamicRoleBindingReconciler {
+		return &DynamicRoleBindingReconciler{Client: newFakeClientBuilder().WithObjects(
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+				Name: "builder", Namespace: "team-a", Labels: map[string]string{"team": "builders"},
+			}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+				Name: "builder", Namespace: "team-b", Labels: map[string]string{"team": "builders"},
+			}},
+		).Build()}
+	}
+
+	It("should look for the ServiceAccounts only on the selected namespaces", func() {
+		serviceAccounts, err := newReconciler().GetServiceAccountsBySelectors(ctx, []string{"team-a"}, subject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serviceAccounts.Items).To(ConsistOf(HaveField("Namespace", "team-a")))
+	})
+
+	It("should select no ServiceAccount when no namespace is selected", func() {
+		serviceAccounts, err := newReconciler().GetServiceAccountsBySelectors(ctx, nil, subject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serviceAccounts.Items).To(BeEmpty())
+	})
+})
@@ -16,11 +16,47 @@ func (r *DynamicRoleBindingReconciler) UpdateConditionSuccess(resource *kuberbac
 	globals.UpdateCondition(&resource.Status.Conditions, condition)
 }
 
-func (r *DynamicRoleBindingReconciler) UpdateConditionKubernetesApiCallFailure(resource *kuberbacv1alpha1.DynamicRoleBinding) {
+func (r *DynamicRoleBindingReconciler) UpdateConditionSyncFailure(resource *kuberbacv1alpha1.DynamicRoleBinding, err error) (reason string) {
+
+	//
+	condition := syncFailureCondition(err)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+	return condition.Reason
+}
+
+func (r *DynamicRoleBindingReconciler) UpdateConditionDryRun(resource *kuberbacv1alpha1.DynamicRoleBinding) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionTrue,
+		globals.ConditionReasonTargetRendered, globals.ConditionReasonTargetRenderedMessage)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+}
+
+func (r *DynamicRoleBindingReconciler) UpdateConditionRoleRefNotFound(resource *kuberbacv1alpha1.DynamicRoleBinding) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionFalse,
+		globals.ConditionReasonRoleRefNotFoundType, globals.ConditionReasonRoleRefNotFoundMessage)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+}
+
+func (r *DynamicRoleBindingReconciler) UpdateConditionBindingsExpired(resource *kuberbacv1alpha1.DynamicRoleBinding) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionTrue,
+		globals.ConditionReasonBindingsExpiredType, globals.ConditionReasonBindingsExpiredMessage)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+}
+
+func (r *DynamicRoleBindingReconciler) UpdateConditionOutsideSchedule(resource *kuberbacv1alpha1.DynamicRoleBinding) {
 
 	//
 	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionTrue,
-		globals.ConditionReasonKubernetesApiCallErrorType, globals.ConditionReasonKubernetesApiCallErrorMessage)
+		globals.ConditionReasonOutsideScheduleType, globals.ConditionReasonOutsideScheduleMessage)
 
 	globals.UpdateCondition(&resource.Status.Conditions, condition)
 }
@@ -1,24 +1,66 @@
 package controller
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"reflect"
-	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"golang.org/x/exp/maps"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
 	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/metrics"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+)
+
+var (
+	// subjectKinds are the kinds of subjects that can be bound by a DynamicRoleBinding
+	subjectKinds = []string{"ServiceAccount", "User", "Group"}
+
+	// sourceSubjectKinds are the kinds of subjects that can be selected by source.subject.
+	// NamespaceServiceAccounts is expanded into the groups of the ServiceAccounts of whole namespaces
+	sourceSubjectKinds = append(slices.Clone(subjectKinds), kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts)
+
+	// errRoleRefNotFound is returned when the ClusterRole referenced by a DynamicRoleBinding does not exist
+	errRoleRefNotFound = errors.New("referenced role not found")
+
+	// errAmbiguousRoleRef is returned when several ClusterRoles match a selector expecting only one of them
+	errAmbiguousRoleRef = errors.New("referenced role is ambiguous")
+)
+
+const (
+	// previewAnnotation requests, when set to 'true', to write into the status what the selectors resolve to
+	// without touching the bindings. It is removed once the preview is done
+	previewAnnotation = "kuberbac.prosimcorp.com/preview"
+
+	// explainAnnotation requests, when set to 'true', a preview along with the decision taken for each evaluated
+	// namespace and ServiceAccount, and the clause of the selectors taking it. It is removed once the preview is done
+	explainAnnotation = "kuberbac.prosimcorp.com/explain"
+
+	// serviceAccountsGroupPrefix is the prefix of the group Kubernetes places all the ServiceAccounts of a namespace in,
+	// followed by the name of the namespace
+	serviceAccountsGroupPrefix = "system:serviceaccounts:"
+
+	// serviceAccountNameField is the field ServiceAccounts are indexed by in the cache. It is supported by the API server
+	// as a field selector too, so lists filtered by it work the same when they are not served by the cache
+	serviceAccountNameField = "metadata.name"
+
+	// bootstrappedByAnnotation marks the ServiceAccounts created while bootstrapping namespaces.
+	// Its value is the 'namespace/name' of the DynamicRoleBinding creating them
+	bootstrappedByAnnotation = "kuberbac.prosimcorp.com/bootstrapped-by"
 )
 
 // CheckMetaSelector checks if the metaSelector has only one field filled
@@ -27,16 +69,18 @@ func (r *DynamicRoleBindingReconciler) CheckMetaSelector(ctx context.Context, me
 	// Check just only field is filled
 	filledSelectorFields := 0
 
-	if len(metaSelector.MatchLabels) > 0 {
+	// MatchExpressions are combined with MatchLabels, so both count as a single field
+	if len(metaSelector.MatchLabels) > 0 || len(metaSelector.MatchExpressions) > 0 {
 		filledSelectorFields++
 	}
 
-	if len(metaSelector.MatchAnnotations) > 0 {
+	// MatchAnnotationsRegex is combined with MatchAnnotations, so both count as a single field
+	if len(metaSelector.MatchAnnotations) > 0 || len(metaSelector.MatchAnnotationsRegex) > 0 {
 		filledSelectorFields++
 	}
 
 	if filledSelectorFields != 1 {
-		err = fmt.Errorf("only one of the following fields is allowed as metaSelector: matchLabels, matchAnnotations")
+		err = fmt.Errorf("%w: only one of the following fields is allowed as source.subject.metaSelector: matchLabels (with matchExpressions), matchAnnotations (with matchAnnotationsRegex)", errInvalidSelector)
 	}
 
 	return err
@@ -57,140 +101,109 @@ func (r *DynamicRoleBindingReconciler) CheckNameSelector(ctx context.Context, na
 	}
 
 	if filledSelectorFields != 1 {
-		err = fmt.Errorf("only one of the following fields is allowed as nameSelector: matchList, matchRegex")
+		err = fmt.Errorf("%w: only one of the following fields is allowed as source.subject.nameSelector: matchList, matchRegex", errInvalidSelector)
 	}
 
 	return err
 }
 
-// CheckNamespaceSelector checks if the namespaceSelector has only one field filled
-func (r *DynamicRoleBindingReconciler) CheckNamespaceSelector(ctx context.Context, namespaceSelector *kuberbacv1alpha1.NamespaceSelectorT) (err error) {
-
-	// Check just only field is filled
-	filledSelectorFields := 0
+// NewServiceAccountMatcher returns a matcher for the nameSelector or metaSelector of a ServiceAccount subject
+func (r *DynamicRoleBindingReconciler) NewServiceAccountMatcher(ctx context.Context, subject *kuberbacv1alpha1.DynamicRoleBindingSourceSubject) (matcher *ObjectMatcherT, err error) {
 
-	if len(namespaceSelector.MatchLabels) > 0 {
-		filledSelectorFields++
-	}
+	matcher = &ObjectMatcherT{}
 
-	if len(namespaceSelector.MatchList) > 0 {
-		filledSelectorFields++
+	// Check nameSelector and metaSelector are NOT filled together
+	if !reflect.ValueOf(subject.NameSelector).IsZero() && !reflect.ValueOf(subject.MetaSelector).IsZero() {
+		err = fmt.Errorf("%w: source.subject.nameSelector and source.subject.metaSelector are mutually exclusive", errInvalidSelector)
+		return matcher, err
 	}
 
-	if namespaceSelector.MatchRegex.Expression != "" {
-		filledSelectorFields++
+	// Check only one metaSelector is used at once when filled
+	if !reflect.ValueOf(subject.MetaSelector).IsZero() {
+		if err = r.CheckMetaSelector(ctx, &subject.MetaSelector); err != nil {
+			return matcher, err
+		}
 	}
 
-	if filledSelectorFields != 1 {
-		err = fmt.Errorf("only one of the following fields is allowed as namespaceSelector: matchLabels, matchList, matchRegex")
+	// Check only one nameSelector is used at once when filled
+	if !reflect.ValueOf(subject.NameSelector).IsZero() {
+		if err = r.CheckNameSelector(ctx, &subject.NameSelector); err != nil {
+			return matcher, err
+		}
 	}
 
-	return err
-}
-
-// FilterNamespaceListBySelector returns a list of namespaces that match a namespaceSelector field
-func (r *DynamicRoleBindingReconciler) FilterNamespaceListBySelector(ctx context.Context, namespaceList *corev1.NamespaceList, namespaceSelector *kuberbacv1alpha1.NamespaceSelectorT) (namespaces []string, err error) {
-
-	// Return all namespaces if namespaceSelector is empty
-	if reflect.ValueOf(*namespaceSelector).IsZero() {
-		for _, namespace := range namespaceList.Items {
-			namespaces = append(namespaces, namespace.Name)
+	// Compile regex expression when filled
+	if subject.NameSelector.MatchRegex.Expression != "" {
+		matcher.MatchRegex, err = CompileMatchRegex("source.subject.nameSelector.matchRegex", subject.NameSelector.MatchRegex)
+		if err != nil {
+			return matcher, err
 		}
-
-		return namespaces, err
+		matcher.NegativeRegex = subject.NameSelector.MatchRegex.Negative
 	}
 
-	// Check just only field is filled
-	err = r.CheckNamespaceSelector(ctx, namespaceSelector)
+	matcher.LabelSelector, err = NewLabelSelector(subject.MetaSelector.MatchLabels, subject.MetaSelector.MatchExpressions)
 	if err != nil {
-		return namespaces, err
+		return matcher, err
 	}
 
-	//
-	matchRegex := &regexp.Regexp{}
-	if namespaceSelector.MatchRegex.Expression != "" {
-		matchRegex, err = regexp.Compile(namespaceSelector.MatchRegex.Expression)
-		if err != nil {
-			return namespaces, err
-		}
+	matcher.AnnotationSelector, err = NewAnnotationSelector(subject.MetaSelector.MatchAnnotations, subject.MetaSelector.MatchAnnotationsRegex)
+	if err != nil {
+		return matcher, err
 	}
 
-	//
-	for _, namespace := range namespaceList.Items {
-
-		// Check MatchLabels
-		if len(namespaceSelector.MatchLabels) > 0 {
-
-			if globals.IsSubset(namespaceSelector.MatchLabels, namespace.Labels) {
-				namespaces = append(namespaces, namespace.Name)
-			}
-		}
-
-		// Check MatchList
-		if len(namespaceSelector.MatchList) > 0 {
+	matcher.MatchList = subject.NameSelector.MatchList
 
-			if slices.Contains(namespaceSelector.MatchList, namespace.Name) {
-				namespaces = append(namespaces, namespace.Name)
-			}
-		}
-
-		// Check MatchRegex
-		if namespaceSelector.MatchRegex.Expression != "" {
-
-			namespaceMatched := matchRegex.MatchString(namespace.Name)
-
-			if !namespaceMatched && namespaceSelector.MatchRegex.Negative {
-				namespaces = append(namespaces, namespace.Name)
-				continue
-			}
-
-			if namespaceMatched && !namespaceSelector.MatchRegex.Negative {
-				namespaces = append(namespaces, namespace.Name)
-			}
-		}
-
-	}
-
-	return namespaces, err
+	return matcher, err
 }
 
-// GetServiceAccountsBySelectors TODO
+// GetServiceAccountsBySelectors returns the ServiceAccounts selected by the subject, looking for them only
+// on the given namespaces. They are read from the cache without copying them, so they MUST NOT be modified
 func (r *DynamicRoleBindingReconciler) GetServiceAccountsBySelectors(ctx context.Context, filteredNamespaceList []string, subject *kuberbacv1alpha1.DynamicRoleBindingSourceSubject) (result *corev1.ServiceAccountList, err error) {
 
+	logger := log.FromContext(ctx)
 	result = &corev1.ServiceAccountList{}
 
-	tmpServiceAccountList := &corev1.ServiceAccountList{}
-	err = r.Client.List(ctx, tmpServiceAccountList)
-	if err != nil {
-		return result, err
+	// Nothing is selected when no namespace is, instead of reading the ServiceAccounts of the whole cluster
+	if len(filteredNamespaceList) == 0 {
+		return result, nil
 	}
 
-	// Check nameSelector and metaSelector are NOT filled together
-	if !reflect.ValueOf(subject.NameSelector).IsZero() && !reflect.ValueOf(subject.MetaSelector).IsZero() {
-		err = fmt.Errorf("nameSelector and labelSelector are mutually exclusive")
+	matcher, err := r.NewServiceAccountMatcher(ctx, subject)
+	if err != nil {
 		return result, err
 	}
 
-	// Check only one metaSelector is used at once when filled
-	if !reflect.ValueOf(subject.MetaSelector).IsZero() {
-		if err = r.CheckMetaSelector(ctx, &subject.MetaSelector); err != nil {
-			return result, err
-		}
+	// Filter by labels while listing, so not matching ServiceAccounts are never copied.
+	// Listed ServiceAccounts are only read, so they are not deep copied from the cache either
+	listOptions := []client.ListOption{client.UnsafeDisableDeepCopy}
+	if matcher.LabelSelector != nil {
+		listOptions = append(listOptions, client.MatchingLabelsSelector{Selector: matcher.LabelSelector})
 	}
 
-	// Check only one nameSelector is used at once when filled
-	if !reflect.ValueOf(subject.NameSelector).IsZero() {
-		if err = r.CheckNameSelector(ctx, &subject.NameSelector); err != nil {
-			return result, err
+	// ServiceAccounts selected by their names are looked up through the name index,
+	// instead of reading every ServiceAccount of the namespaces
+	listOptionsSets := [][]client.ListOption{listOptions}
+	if matcher.LabelSelector == nil && matcher.AnnotationSelector == nil && len(matcher.MatchList) > 0 {
+		names := slices.Clone(matcher.MatchList)
+		slices.Sort(names)
+
+		listOptionsSets = nil
+		for _, name := range slices.Compact(names) {
+			listOptionsSets = append(listOptionsSets,
+				append(slices.Clone(listOptions), client.MatchingFields{serviceAccountNameField: name}))
 		}
 	}
 
-	// Compile regex expression when filled
-	matchRegex := &regexp.Regexp{}
-	if subject.NameSelector.MatchRegex.Expression != "" {
-		matchRegex, err = regexp.Compile(subject.NameSelector.MatchRegex.Expression)
-		if err != nil {
-			return result, err
+	// List ServiceAccounts only from the desired namespaces
+	tmpServiceAccountList := &corev1.ServiceAccountList{}
+	for _, listOptions := range listOptionsSets {
+		for _, namespace := range filteredNamespaceList {
+			namespaceServiceAccountList := &corev1.ServiceAccountList{}
+			err = r.Client.List(ctx, namespaceServiceAccountList, append(listOptions, client.InNamespace(namespace))...)
+			if err != nil {
+				return result, err
+			}
+			tmpServiceAccountList.Items = append(tmpServiceAccountList.Items, namespaceServiceAccountList.Items...)
 		}
 	}
 
@@ -198,313 +211,1796 @@ func (r *DynamicRoleBindingReconciler) GetServiceAccountsBySelectors(ctx context
 	for _, serviceAccount := range tmpServiceAccountList.Items {
 
 		// Ignore namespaces not present in desired list
-		if len(filteredNamespaceList) != 0 && !slices.Contains(filteredNamespaceList, serviceAccount.Namespace) {
+		if !slices.Contains(filteredNamespaceList, serviceAccount.Namespace) {
+			logger.V(logLevelDecisions).Info("ServiceAccount skipped: namespace not selected",
+				"serviceAccount", serviceAccount.Namespace+"/"+serviceAccount.Name)
 			continue
 		}
 
-		// Matching by labels
-		if !reflect.ValueOf(subject.MetaSelector.MatchLabels).IsZero() {
-			if globals.IsSubset(subject.MetaSelector.MatchLabels, serviceAccount.Labels) {
-				result.Items = append(result.Items, serviceAccount)
-			}
+		if !matcher.Matches(&serviceAccount) {
+			logger.V(logLevelDecisions).Info("ServiceAccount skipped: not matched by the subject selectors",
+				"serviceAccount", serviceAccount.Namespace+"/"+serviceAccount.Name)
 			continue
 		}
 
-		// Matching by annotations
-		if !reflect.ValueOf(subject.MetaSelector.MatchAnnotations).IsZero() {
-			if globals.IsSubset(subject.MetaSelector.MatchAnnotations, serviceAccount.Annotations) {
-				result.Items = append(result.Items, serviceAccount)
-			}
-			continue
+		result.Items = append(result.Items, serviceAccount)
+	}
+
+	return result, err
+}
+
+// GetGroupsAndUsersBySelector returns the names of the Group or User subjects selected by the nameSelector.
+// Both can be selected by exact names, or by a regular expression resolved against the groups or users
+// listed by the configured group or user provider
+func (r *DynamicRoleBindingReconciler) GetGroupsAndUsersBySelector(ctx context.Context, subject *kuberbacv1alpha1.DynamicRoleBindingSourceSubject) (result []string, err error) {
+
+	// MatchRegex nameSelector needs an external directory to look for the subjects
+	if !reflect.ValueOf(subject.NameSelector.MatchRegex).IsZero() {
+
+		var listSubjects func(ctx context.Context) ([]string, error)
+		switch {
+		case subject.Kind == "Group" && r.GroupProvider != nil:
+			listSubjects = r.GroupProvider.ListGroups
+		case subject.Kind == "User" && r.UserProvider != nil:
+			listSubjects = r.UserProvider.ListUsers
+		default:
+			err = fmt.Errorf("%w: MatchRegex nameSelector is only allowed for %s subjects when a %s provider is configured",
+				errInvalidSpec, subject.Kind, strings.ToLower(subject.Kind))
+			return result, err
 		}
 
-		// Matching by fixed list
-		if len(subject.NameSelector.MatchList) > 0 {
-			if slices.Contains(subject.NameSelector.MatchList, serviceAccount.Name) {
-				result.Items = append(result.Items, serviceAccount)
-			}
-			continue
+		if err = r.CheckNameSelector(ctx, &subject.NameSelector); err != nil {
+			return result, err
 		}
 
-		// Match by regex
-		nameMatched := matchRegex.MatchString(serviceAccount.Name)
+		matchRegex, err := CompileMatchRegex("source.subject.nameSelector.matchRegex", subject.NameSelector.MatchRegex)
+		if err != nil {
+			return result, err
+		}
 
-		if !nameMatched && subject.NameSelector.MatchRegex.Negative {
-			result.Items = append(result.Items, serviceAccount)
-			continue
+		names, err := listSubjects(ctx)
+		if err != nil {
+			return result, fmt.Errorf("error listing %ss from the %s provider: %s",
+				strings.ToLower(subject.Kind), strings.ToLower(subject.Kind), err.Error())
 		}
 
-		if nameMatched && !subject.NameSelector.MatchRegex.Negative {
-			result.Items = append(result.Items, serviceAccount)
+		for _, name := range names {
+			if matchRegex.MatchString(name) != subject.NameSelector.MatchRegex.Negative {
+				result = append(result, name)
+			}
 		}
 
+		return result, err
 	}
 
-	return result, err
+	// MatchList nameSelector is required otherwise
+	if reflect.ValueOf(subject.NameSelector.MatchList).IsZero() {
+		err = fmt.Errorf("%w: source.subject.nameSelector.matchList is required for subjects: Group, User", errInvalidSelector)
+		return result, err
+	}
+
+	return subject.NameSelector.MatchList, err
 }
 
-// SyncTarget call Kubernetes API to actually perform actions over the resource
-func (r *DynamicRoleBindingReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (err error) {
+// CheckSourceSubject returns an error when the selectors of the subject do not fit its kind
+func CheckSourceSubject(subject *kuberbacv1alpha1.DynamicRoleBindingSourceSubject) (err error) {
 
 	// Check source.subject.kind is one of the valid values
-	validKinds := []string{"ServiceAccount", "User", "Group"}
-	if !slices.Contains(validKinds, resource.Spec.Source.Subject.Kind) {
-		err = fmt.Errorf("source.subject.kind must be one of the following values: %s", strings.Join(validKinds, ", "))
+	if !slices.Contains(sourceSubjectKinds, subject.Kind) {
+		err = fmt.Errorf("%w: source.subject.kind must be one of the following values: %s", errInvalidSpec, strings.Join(sourceSubjectKinds, ", "))
+		return err
+	}
+
+	// Check the ServiceAccounts of whole namespaces are only selected by their namespaces
+	if subject.Kind == kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts &&
+		(!reflect.ValueOf(subject.NameSelector).IsZero() || !reflect.ValueOf(subject.MetaSelector).IsZero()) {
+
+		err = fmt.Errorf("%w: source.subject.nameSelector and source.subject.metaSelector are not allowed for %s subjects",
+			errInvalidSelector, kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts)
 		return err
 	}
 
 	// Check namespaceSelector does NOT exist for subjects other than ServiceAccount
-	if slices.Contains([]string{"Group", "User"}, resource.Spec.Source.Subject.Kind) &&
-		(!reflect.ValueOf(resource.Spec.Source.Subject.NamespaceSelector).IsZero() ||
-			!reflect.ValueOf(resource.Spec.Source.Subject.MetaSelector).IsZero()) {
+	if slices.Contains([]string{"Group", "User"}, subject.Kind) &&
+		(!reflect.ValueOf(subject.NamespaceSelector).IsZero() || !reflect.ValueOf(subject.MetaSelector).IsZero()) {
 
-		err = fmt.Errorf("namespaceSelector and labelSelector are only allowed for ServiceAccount subjects")
+		err = fmt.Errorf("%w: source.subject.namespaceSelector and source.subject.metaSelector are only allowed for ServiceAccount subjects", errInvalidSelector)
 		return err
 	}
 
-	// Get all the namespaces and filter them by namespaceSelector later
-	namespaceList := &corev1.NamespaceList{}
-	err = r.Client.List(ctx, namespaceList)
-	if err != nil {
+	// Check identity conventions are only applied to the subjects named by identity providers
+	if !slices.Contains([]string{"Group", "User"}, subject.Kind) && (subject.IdentityProvider != "" || subject.NamePrefix != "") {
+		err = fmt.Errorf("%w: source.subject.identityProvider and source.subject.namePrefix are only allowed for Group and User subjects", errInvalidSpec)
 		return err
 	}
 
-	//
-	subjectFilteredNamespaces, err := r.FilterNamespaceListBySelector(ctx, namespaceList, &resource.Spec.Source.Subject.NamespaceSelector)
+	return err
+}
+
+// FilterSubjectNamespaces returns the namespaces selected by source.subject where subjects are looked for,
+// removing those excluded through annotations and those not watched by the operator.
+// Both synchronizations and explanations filter the selected namespaces with it, so they always agree
+func (r *DynamicRoleBindingReconciler) FilterSubjectNamespaces(namespaces []string, namespaceList *corev1.NamespaceList) []string {
+	return RemoveUnwatchedNamespaces(RemoveExcludedNamespaces(namespaces, namespaceList), r.WatchNamespaces)
+}
+
+// ExpandSubjects returns the subjects selected by source.subject. ServiceAccounts are only looked for in the watched namespaces
+// selected by its namespaceSelector, and the ServiceAccounts of whole namespaces are expanded into the groups containing them
+func (r *DynamicRoleBindingReconciler) ExpandSubjects(ctx context.Context, subject *kuberbacv1alpha1.DynamicRoleBindingSourceSubject,
+	namespaceList *corev1.NamespaceList) (expandedSubjects []rbacv1.Subject, err error) {
+
+	subjectFilteredNamespaces, err := FilterNamespaceListBySelector(namespaceList, &subject.NamespaceSelector)
 	if err != nil {
-		return err
+		return expandedSubjects, fmt.Errorf("error selecting the namespaces of source.subject: %w", err)
 	}
+	subjectFilteredNamespaces = r.FilterSubjectNamespaces(subjectFilteredNamespaces, namespaceList)
 
 	// Create as many subjects as needed
-	expandedSubjects := []rbacv1.Subject{}
+	expandedSubjects = []rbacv1.Subject{}
 
 	// Expand Group and User subjects
-	if slices.Contains([]string{"Group", "User"}, resource.Spec.Source.Subject.Kind) {
+	if slices.Contains([]string{"Group", "User"}, subject.Kind) {
 
-		// MatchRegex nameSelector is not allowed for these subjects
-		// TODO: Stop or not the process flow?????
-		if !reflect.ValueOf(resource.Spec.Source.Subject.NameSelector.MatchRegex).IsZero() {
-			err = fmt.Errorf("MatchRegex nameSelector is not allowed for subjects: Group, User")
-			return err
+		subjectNames, err := r.GetGroupsAndUsersBySelector(ctx, subject)
+		if err != nil {
+			return expandedSubjects, err
 		}
 
-		// MatchList nameSelector is required for these subjects
-		if reflect.ValueOf(resource.Spec.Source.Subject.NameSelector.MatchList).IsZero() {
-			err = fmt.Errorf("MatchList nameSelector is required for subjects: Group, User")
-			return err
-		}
+		// Names are prefixed and checked following the conventions of the identity provider
+		for _, subjectName := range subjectNames {
+			subjectName, err = RenderSubjectName(subject, subjectName)
+			if err != nil {
+				return expandedSubjects, err
+			}
 
-		//
-		for _, listItem := range resource.Spec.Source.Subject.NameSelector.MatchList {
 			expandedSubjects = append(expandedSubjects, rbacv1.Subject{
-				Kind:     resource.Spec.Source.Subject.Kind,
-				APIGroup: resource.Spec.Source.Subject.ApiGroup,
-				Name:     listItem,
+				Kind:     subject.Kind,
+				APIGroup: subject.ApiGroup,
+				Name:     subjectName,
 			})
 		}
 	}
 
-	// Expand ServiceAccount subjects
-	if resource.Spec.Source.Subject.Kind == "ServiceAccount" {
+	// Expand ServiceAccount subjects. Nothing is listed when no namespace is selected, as all of them would be read otherwise
+	if subject.Kind == "ServiceAccount" && len(subjectFilteredNamespaces) > 0 {
 
-		serviceAccounts, err := r.GetServiceAccountsBySelectors(ctx, subjectFilteredNamespaces, &resource.Spec.Source.Subject)
+		serviceAccounts, err := r.GetServiceAccountsBySelectors(ctx, subjectFilteredNamespaces, subject)
 		if err != nil {
-			err = fmt.Errorf("error getting selected ServiceAccounts: %s", err.Error())
-			return err
+			err = fmt.Errorf("error getting selected ServiceAccounts: %w", err)
+			return expandedSubjects, err
 		}
 
 		for _, serviceAccount := range serviceAccounts.Items {
 			expandedSubjects = append(expandedSubjects, rbacv1.Subject{
 				Kind:      "ServiceAccount",
-				APIGroup:  resource.Spec.Source.Subject.ApiGroup,
+				APIGroup:  subject.ApiGroup,
 				Name:      serviceAccount.Name,
 				Namespace: serviceAccount.Namespace,
 			})
 		}
 	}
 
-	// Create a generic RoleBinding structure
-	referenceAnnotations := map[string]string{
-		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
-		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
-		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
-		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	// Expand the ServiceAccounts of whole namespaces into the group containing them, so they are not enumerated
+	if subject.Kind == kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts {
+		for _, namespace := range subjectFilteredNamespaces {
+			expandedSubjects = append(expandedSubjects, rbacv1.Subject{
+				Kind:     rbacv1.GroupKind,
+				APIGroup: rbacv1.GroupName,
+				Name:     serviceAccountsGroupPrefix + namespace,
+			})
+		}
 	}
 
-	if len(resource.Spec.Targets.Annotations) == 0 {
-		resource.Spec.Targets.Annotations = map[string]string{}
+	return SortSubjects(expandedSubjects), err
+}
+
+// FormatSubjects returns a compact representation of each subject, used to summarize changes
+func FormatSubjects(subjects []rbacv1.Subject) (result []string) {
+	for _, subject := range subjects {
+		if subject.Namespace != "" {
+			result = append(result, subject.Kind+":"+subject.Namespace+"/"+subject.Name)
+			continue
+		}
+		result = append(result, subject.Kind+":"+subject.Name)
 	}
-	maps.Copy(resource.Spec.Targets.Annotations, referenceAnnotations)
+	return result
+}
 
-	// Time to create the role binding resource. It can be ClusterRoleBinding or RoleBinding
-	// depending on the user's choice, so we assume ClusterRoleBinding
-	clusterRoleBindingResource := rbacv1.ClusterRoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        resource.Spec.Targets.Name,
-			Labels:      resource.Spec.Targets.Labels,
-			Annotations: resource.Spec.Targets.Annotations,
-		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: "rbac.authorization.k8s.io",
-			Kind:     "ClusterRole",
-			Name:     resource.Spec.Source.ClusterRole,
-		},
-		Subjects: expandedSubjects,
-	}
-
-	// Generate or update the ClusterRoleBinding resource
-	if resource.Spec.Targets.ClusterScoped {
-
-		tmpClusterRoleBindingResource := rbacv1.ClusterRoleBinding{}
-		err = r.Get(ctx, client.ObjectKey{
-			Namespace: "",
-			Name:      resource.Spec.Targets.Name,
-		}, &tmpClusterRoleBindingResource)
-
-		err = client.IgnoreNotFound(err)
-		if err != nil {
-			log.Printf("error getting ClusterRoleBinding: %s", err.Error())
-			return err
+// RoleBindingTemplateData represents the data injected into the templates of the static subjects
+// and the role name of a DynamicRoleBinding
+type RoleBindingTemplateData struct {
+	// Namespace is the metadata of the namespace where the RoleBinding is created. It is empty for ClusterRoleBindings
+	Namespace metav1.ObjectMeta
+
+	// Owner is the metadata of the DynamicRoleBinding that creates the binding
+	Owner metav1.ObjectMeta
+}
+
+// RenderRoleName renders the name of the Role bound by targets.roleNameTemplate for the given target namespace
+func RenderRoleName(resource *kuberbacv1alpha1.DynamicRoleBinding, namespace metav1.ObjectMeta) (result string, err error) {
+
+	templateData := RoleBindingTemplateData{
+		Namespace: namespace,
+		Owner:     resource.ObjectMeta,
+	}
+
+	result, err = globals.RenderTemplate(resource.Spec.Targets.RoleNameTemplate, templateData)
+	if err != nil {
+		return result, fmt.Errorf("error rendering targets.roleNameTemplate: %s", err.Error())
+	}
+
+	if result == "" {
+		return result, fmt.Errorf("targets.roleNameTemplate can not be rendered empty")
+	}
+
+	return result, err
+}
+
+// RenderStaticSubjects renders the name and namespace of the static subjects for the given target namespace.
+// Their existence is never checked. APIGroup is defaulted for Group and User subjects,
+// and ServiceAccount subjects must be rendered with a namespace
+func RenderStaticSubjects(resource *kuberbacv1alpha1.DynamicRoleBinding, namespace metav1.ObjectMeta) (result []rbacv1.Subject, err error) {
+
+	templateData := RoleBindingTemplateData{
+		Namespace: namespace,
+		Owner:     resource.ObjectMeta,
+	}
+
+	for _, subject := range resource.Spec.Source.StaticSubjects {
+
+		if !slices.Contains(subjectKinds, subject.Kind) {
+			return result, fmt.Errorf("%w: source.staticSubjects kind must be one of the following values: %s", errInvalidSpec, strings.Join(subjectKinds, ", "))
 		}
 
-		// Review reference annotations when the resource already exists
-		if !reflect.ValueOf(tmpClusterRoleBindingResource).IsZero() &&
-			!globals.IsSubset(referenceAnnotations, tmpClusterRoleBindingResource.Annotations) {
-			return err
+		subject.Name, err = globals.RenderTemplate(subject.Name, templateData)
+		if err != nil {
+			return result, fmt.Errorf("error rendering static subject name: %s", err.Error())
 		}
 
-		err = r.Client.Update(ctx, clusterRoleBindingResource.DeepCopy())
+		subject.Namespace, err = globals.RenderTemplate(subject.Namespace, templateData)
 		if err != nil {
-			log.Printf("error updating ClusterRoleBinding: %s", err.Error())
+			return result, fmt.Errorf("error rendering static subject namespace: %s", err.Error())
 		}
-		return err
-	}
 
-	// From here, we failed in our ClusterRoleBinding assumption.
-	// Generate or update RoleBinding resources.
-	roleBindingResource := rbacv1.RoleBinding(clusterRoleBindingResource)
+		if subject.Name == "" {
+			return result, fmt.Errorf("source.staticSubjects name can not be rendered empty")
+		}
 
-	// Get Rolebindings
-	existentRoleBindingList := rbacv1.RoleBindingList{}
-	err = r.Client.List(ctx, &existentRoleBindingList)
-	if err != nil {
-		return err
-	}
+		// Only ServiceAccounts live inside namespaces
+		if subject.Kind == "ServiceAccount" {
+			if subject.Namespace == "" {
+				return result, fmt.Errorf("static ServiceAccount subject '%s' can not be rendered without namespace", subject.Name)
+			}
+		} else {
+			subject.Namespace = ""
+			if subject.APIGroup == "" {
+				subject.APIGroup = rbacv1.GroupName
+			}
+		}
 
-	targetFilteredNamespaces, err := r.FilterNamespaceListBySelector(ctx, namespaceList, &resource.Spec.Targets.NamespaceSelector)
-	if err != nil {
-		return err
+		result = append(result, subject)
 	}
 
-	// Create the RoleBinding resource on targeted namespaces
-	for _, namespace := range targetFilteredNamespaces {
-		roleBindingResource.SetNamespace(namespace)
+	return result, err
+}
 
-		// Check potential already existing RoleBindings that match the same name and namespace
-		roleBindingFound := false
-		for _, roleBinding := range existentRoleBindingList.Items {
+// CreateMissingServiceAccounts creates the static ServiceAccount subjects living in a targeted namespace
+// when they do not exist yet, so the bindings of freshly created namespaces are usable instantly.
+// Existing ServiceAccounts are never modified
+func (r *DynamicRoleBindingReconciler) CreateMissingServiceAccounts(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding,
+	namespace string, staticSubjects []rbacv1.Subject) (err error) {
 
-			if roleBinding.Namespace != namespace || roleBinding.Name != roleBindingResource.Name {
-				continue
-			}
+	logger := log.FromContext(ctx)
 
-			if !globals.IsSubset(roleBindingResource.Annotations, roleBinding.Annotations) {
-				roleBindingFound = true
-				break
-			}
+	for _, subject := range staticSubjects {
+		if subject.Kind != "ServiceAccount" || subject.Namespace != namespace {
+			continue
 		}
 
-		if roleBindingFound {
+		serviceAccount := &corev1.ServiceAccount{}
+		err = r.Client.Get(ctx, client.ObjectKey{Namespace: subject.Namespace, Name: subject.Name}, serviceAccount)
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("error getting ServiceAccount '%s': %s", subject.Name, err.Error())
+		}
+		if err == nil {
 			continue
 		}
 
-		// Finally, update it!!
-		err = r.Client.Update(ctx, roleBindingResource.DeepCopy())
-		if err != nil {
-			log.Printf("error updating RoleBinding: %s", err.Error())
+		serviceAccount = &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      subject.Name,
+				Namespace: subject.Namespace,
+				Annotations: map[string]string{
+					bootstrappedByAnnotation: resource.Namespace + "/" + resource.Name,
+				},
+			},
+		}
+		err = r.Client.Create(ctx, serviceAccount)
+		if client.IgnoreAlreadyExists(err) != nil {
+			return fmt.Errorf("error creating ServiceAccount '%s': %s", subject.Name, err.Error())
 		}
+		logger.V(logLevelChanges).Info("ServiceAccount created: it is bound as static subject and was missing",
+			"namespace", subject.Namespace, "serviceAccount", subject.Name)
 	}
 
-	// For cleaning potential previous abandoned resources, get the list of namespaces
-	// that are not reconciled in this loop to look for RoleBindings there
-	targetNamespacesComplementaryList := slices.DeleteFunc(namespaceList.Items,
-		func(namespace corev1.Namespace) bool {
-			return slices.Contains(targetFilteredNamespaces, namespace.ObjectMeta.Name)
-		},
-	)
+	return nil
+}
+
+// appendSubjects returns a new list with the subjects of the first one plus those not already present on it
+func appendSubjects(subjects []rbacv1.Subject, extraSubjects ...rbacv1.Subject) (result []rbacv1.Subject) {
 
-	// Get not targeted namespace list
-	targetNamespacesComplementaryStrList := []string{}
-	for _, namespace := range targetNamespacesComplementaryList {
-		targetNamespacesComplementaryStrList = append(targetNamespacesComplementaryStrList, namespace.ObjectMeta.Name)
+	result = slices.Clone(subjects)
+	for _, subject := range extraSubjects {
+		if !slices.Contains(result, subject) {
+			result = append(result, subject)
+		}
 	}
 
-	// Remove owned RoleBidings not defined in manifest
-	for _, roleBinding := range existentRoleBindingList.Items {
-		delete := false
-		if globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
-			delete = true
+	return result
+}
+
+// SortSubjects returns a new list with the subjects sorted by kind, namespace and name, without duplicates.
+// Subjects are listed from the cluster in no particular order, so this keeps the generated bindings stable
+func SortSubjects(subjects []rbacv1.Subject) (result []rbacv1.Subject) {
+
+	result = slices.Clone(subjects)
+	slices.SortStableFunc(result, func(a, b rbacv1.Subject) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	return slices.CompactFunc(result, func(a, b rbacv1.Subject) bool {
+		return a.Kind == b.Kind && a.Namespace == b.Namespace && a.Name == b.Name
+	})
+}
+
+// subjectShardT represents one of the bindings generated for a binding target when its subjects are sharded
+type subjectShardT struct {
+	name     string
+	subjects []rbacv1.Subject
+}
+
+// shardSubjects splits the subjects bound by a binding target into shards of at most maxSubjects, named
+// '<name>-<index>'. Subjects stay on the shard they have on the existing bindings, given by name, and new ones
+// fill the free room of the shards in order, so only the shards whose subjects changed are updated.
+// A single shard named after the target is returned when sharding is disabled
+func shardSubjects(name string, subjects []rbacv1.Subject, maxSubjects int,
+	existentSubjects map[string][]rbacv1.Subject) (result []subjectShardT) {
+
+	if maxSubjects <= 0 {
+		return []subjectShardT{{name: name, subjects: subjects}}
+	}
+
+	// Subjects are compared by kind, namespace and name, as the API server defaults the rest of fields
+	subjectKeys := FormatSubjects(subjects)
+	subjectPositions := map[string]int{}
+	assigned := make([]bool, len(subjects))
+	for position, key := range subjectKeys {
+		if _, found := subjectPositions[key]; found {
+			assigned[position] = true
+			continue
 		}
+		subjectPositions[key] = position
+	}
 
-		if delete && slices.Contains(targetNamespacesComplementaryStrList, roleBinding.Namespace) {
-			err = r.Client.Delete(ctx, &roleBinding)
-			if err != nil {
-				err = fmt.Errorf("error deleting not needed rolebindings: %s", err.Error())
+	// Keep the subjects on the shard they already were, visiting shards in order
+	shards := map[int][]rbacv1.Subject{}
+	existentIndexes := []int{}
+	for existentName := range existentSubjects {
+		suffix, found := strings.CutPrefix(existentName, name+"-")
+		index, err := strconv.Atoi(suffix)
+		if !found || err != nil || index < 0 || strconv.Itoa(index) != suffix {
+			continue
+		}
+		existentIndexes = append(existentIndexes, index)
+	}
+	slices.Sort(existentIndexes)
 
+	for _, index := range existentIndexes {
+		for _, key := range FormatSubjects(existentSubjects[fmt.Sprintf("%s-%d", name, index)]) {
+			position, found := subjectPositions[key]
+			if !found || assigned[position] || len(shards[index]) >= maxSubjects {
+				continue
 			}
+			assigned[position] = true
+			shards[index] = append(shards[index], subjects[position])
 		}
 	}
 
-	return err
+	// Place the new subjects on the first shards with free room
+	index := 0
+	for position, subject := range subjects {
+		if assigned[position] {
+			continue
+		}
+		for len(shards[index]) >= maxSubjects {
+			index++
+		}
+		shards[index] = append(shards[index], subject)
+	}
+
+	// Empty shards are dropped. An empty first one is kept when no subject is left, so there is always a binding for the target
+	shardIndexes := maps.Keys(shards)
+	slices.Sort(shardIndexes)
+	for _, index := range shardIndexes {
+		result = append(result, subjectShardT{name: fmt.Sprintf("%s-%d", name, index), subjects: SortSubjects(shards[index])})
+	}
+	if len(result) == 0 {
+		result = append(result, subjectShardT{name: name + "-0"})
+	}
+
+	return result
 }
 
-// DeleteTargets deletes all the RoleBindings and ClusterRoleBindings that are owned by the DynamicRoleBinding resource
-func (r *DynamicRoleBindingReconciler) DeleteTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (err error) {
+// RecordSubjectChanges stores the subjects changed on the generated bindings into the status,
+// and emits an Event describing them. Nothing is recorded when subjects did not change
+func (r *DynamicRoleBindingReconciler) RecordSubjectChanges(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding, previousSubjects, nextSubjects []string) {
 
-	var allErrors []error
+	change := newSyncChange(nil, nil, previousSubjects, nextSubjects)
+	if change == nil {
+		return
+	}
+	log.FromContext(ctx).V(logLevelChanges).Info("Subjects changed", "added", change.AddedSubjects, "removed", change.RemovedSubjects)
 
-	// Create a generic RoleBinding structure
-	referenceAnnotations := map[string]string{
-		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
-		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
-		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
-		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	resource.Status.LastChange = change
+	r.Recorder.Event(resource, corev1.EventTypeNormal, eventReasonChanged, syncChangeMessage(change))
+}
+
+// bindingTargetT is a binding to generate: its name, the role it references, and the scope of the role
+// when it was generated for a single one: 'cluster' or 'namespace'
+type bindingTargetT struct {
+	name    string
+	roleRef rbacv1.RoleRef
+	scope   string
+}
+
+// GetTargetsMode returns the kind of bindings generated for the targets. When the mode is not set,
+// it is derived from clusterScoped, so resources written before the mode existed keep their behavior
+func GetTargetsMode(targets *kuberbacv1alpha1.DynamicRoleBindingTargets) string {
+
+	if targets.Mode != "" {
+		return targets.Mode
 	}
 
-	// Get ClusterRolebindings objects and delete those with reference annotations
-	clusterRoleBindingList := rbacv1.ClusterRoleBindingList{}
-	err = r.Client.List(ctx, &clusterRoleBindingList)
-	if err != nil {
-		return err
+	if targets.ClusterScoped {
+		return kuberbacv1alpha1.TargetsModeClusterScoped
 	}
 
-	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+	return kuberbacv1alpha1.TargetsModeNamespaced
+}
 
-		if globals.IsSubset(referenceAnnotations, clusterRoleBinding.Annotations) {
-			err = r.Client.Delete(ctx, &clusterRoleBinding)
-			if err = client.IgnoreNotFound(err); err != nil {
-				allErrors = append(allErrors, fmt.Errorf("error deleting ClusterRoleBinding: %s", err.Error()))
-			}
-		}
+// GetTargetsNamespaceSelector returns the selector of the target namespaces. When the targets reference
+// a NamespaceSelectorClass, its selector is read from the cluster
+func (r *DynamicRoleBindingReconciler) GetTargetsNamespaceSelector(ctx context.Context,
+	targets *kuberbacv1alpha1.DynamicRoleBindingTargets) (result *kuberbacv1alpha1.NamespaceSelectorT, err error) {
+
+	if targets.NamespaceSelectorClassName == "" {
+		return &targets.NamespaceSelector, err
 	}
 
-	// Get Rolebindings objects and delete those with reference annotations
-	roleBindingList := rbacv1.RoleBindingList{}
-	err = r.Client.List(ctx, &roleBindingList)
+	namespaceSelectorClass := &kuberbacv1alpha1.NamespaceSelectorClass{}
+	err = r.Client.Get(ctx, client.ObjectKey{Name: targets.NamespaceSelectorClassName}, namespaceSelectorClass)
 	if err != nil {
-		return err
+		return result, fmt.Errorf("error getting NamespaceSelectorClass '%s': %s", targets.NamespaceSelectorClassName, err.Error())
 	}
 
-	for _, roleBinding := range roleBindingList.Items {
+	return &namespaceSelectorClass.Spec.NamespaceSelector, err
+}
 
-		if globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
-			err = r.Client.Delete(ctx, &roleBinding)
+// splitBindingTargets returns the binding targets to generate as ClusterRoleBindings, and those to generate
+// as RoleBindings. When both kinds are generated, Roles and ClusterRoles generated for the namespace scope
+// are only bound inside namespaces, as binding them cluster-wide would grant them on every namespace
+func splitBindingTargets(mode string, bindingTargets []bindingTargetT) (clusterBindingTargets, namespaceBindingTargets []bindingTargetT) {
 
-			if err = client.IgnoreNotFound(err); err != nil {
-				allErrors = append(allErrors, fmt.Errorf("error deleting RoleBinding: %s", err.Error()))
-			}
-		}
+	switch mode {
+	case kuberbacv1alpha1.TargetsModeClusterScoped:
+		return bindingTargets, namespaceBindingTargets
+	case kuberbacv1alpha1.TargetsModeNamespaced:
+		return clusterBindingTargets, bindingTargets
+	}
+
+	for _, bindingTarget := range bindingTargets {
+		if bindingTarget.roleRef.Kind != "Role" && bindingTarget.scope != scopeLabelNamespace {
+			clusterBindingTargets = append(clusterBindingTargets, bindingTarget)
+		}
+
+		if bindingTarget.scope != scopeLabelCluster {
+			namespaceBindingTargets = append(namespaceBindingTargets, bindingTarget)
+		}
+	}
+
+	return clusterBindingTargets, namespaceBindingTargets
+}
+
+// GetSelectedClusterRoles returns the names of the ClusterRoles matching source.clusterRoleSelector, sorted.
+// They are narrowed to a single one according to source.clusterRoleSelectorPolicy
+func (r *DynamicRoleBindingReconciler) GetSelectedClusterRoles(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (result []string, err error) {
+
+	selector, err := metav1.LabelSelectorAsSelector(resource.Spec.Source.ClusterRoleSelector)
+	if err != nil {
+		return result, fmt.Errorf("%w: invalid source.clusterRoleSelector: %s", errInvalidSelector, err.Error())
+	}
+
+	// An empty selector matches every ClusterRole, including cluster-admin, which is never what is meant
+	if selector.Empty() {
+		return result, fmt.Errorf("%w: source.clusterRoleSelector can not be empty, as it would select every ClusterRole", errInvalidSelector)
+	}
+
+	clusterRoleList := rbacv1.ClusterRoleList{}
+	err = r.Client.List(ctx, &clusterRoleList, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return result, fmt.Errorf("error listing ClusterRoles: %s", err.Error())
+	}
+
+	for _, clusterRole := range clusterRoleList.Items {
+		result = append(result, clusterRole.Name)
+	}
+	slices.Sort(result)
+
+	switch resource.Spec.Source.ClusterRoleSelectorPolicy {
+	case kuberbacv1alpha1.ClusterRoleSelectorPolicySingle:
+		if len(result) > 1 {
+			return nil, fmt.Errorf("%w: several ClusterRoles match source.clusterRoleSelector: %s",
+				errAmbiguousRoleRef, strings.Join(result, ", "))
+		}
+
+	// Ties are broken by name, so the same ClusterRole is taken between synchronizations
+	case kuberbacv1alpha1.ClusterRoleSelectorPolicyNewest:
+		if len(result) > 1 {
+			newest := slices.MaxFunc(clusterRoleList.Items, func(a, b rbacv1.ClusterRole) int {
+				if timeComparison := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); timeComparison != 0 {
+					return timeComparison
+				}
+				return strings.Compare(b.Name, a.Name)
+			})
+			result = []string{newest.Name}
+		}
+	}
+
+	return result, err
+}
+
+// clusterRoleBindingTargets returns one binding for each ClusterRole, named '<targets.name>-<clusterRole name>'
+func clusterRoleBindingTargets(resource *kuberbacv1alpha1.DynamicRoleBinding, clusterRoles []string) (result []bindingTargetT) {
+
+	clusterRoles = slices.Clone(clusterRoles)
+	slices.Sort(clusterRoles)
+
+	for _, clusterRole := range slices.Compact(clusterRoles) {
+		result = append(result, bindingTargetT{
+			name:    resource.Spec.Targets.Name + "-" + clusterRole,
+			roleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole},
+		})
+	}
+
+	return result
+}
+
+// GetBindingTargets returns the bindings to generate for the source role of the DynamicRoleBinding.
+// A DynamicClusterRole can generate several ClusterRoles (i.e. when separating scopes), so they are discovered
+// by their owner annotations and one binding is returned for each, named '<targets.name>-<clusterRole name>'.
+// Bundles of ClusterRoles, listed or selected by labels, are named the same way
+func (r *DynamicRoleBindingReconciler) GetBindingTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (result []bindingTargetT, err error) {
+
+	switch {
+	case resource.Spec.Source.ClusterRole != "":
+		result = append(result, bindingTargetT{
+			name:    resource.Spec.Targets.Name,
+			roleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: resource.Spec.Source.ClusterRole},
+		})
+		return result, err
+
+	// Roles are resolved by Kubernetes in the same namespace as each RoleBinding.
+	// Templated names are rendered for each namespace when generating the RoleBindings
+	case resource.Spec.Targets.RoleNameTemplate != "":
+		result = append(result, bindingTargetT{
+			name:    resource.Spec.Targets.Name,
+			roleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: resource.Spec.Targets.RoleNameTemplate},
+		})
+		return result, err
+
+	case resource.Spec.Source.Role != "":
+		result = append(result, bindingTargetT{
+			name:    resource.Spec.Targets.Name,
+			roleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: resource.Spec.Source.Role},
+		})
+		return result, err
+
+	case len(resource.Spec.Source.ClusterRoles) > 0:
+		return clusterRoleBindingTargets(resource, resource.Spec.Source.ClusterRoles), err
+
+	case resource.Spec.Source.ClusterRoleSelector != nil:
+		var clusterRoles []string
+		clusterRoles, err = r.GetSelectedClusterRoles(ctx, resource)
+		if err != nil {
+			return result, err
+		}
+
+		if len(clusterRoles) == 0 {
+			log.FromContext(ctx).V(logLevelDecisions).Info("No ClusterRoles match source.clusterRoleSelector")
+		}
+
+		if resource.Spec.Source.ClusterRoleSelectorPolicy == "" ||
+			resource.Spec.Source.ClusterRoleSelectorPolicy == kuberbacv1alpha1.ClusterRoleSelectorPolicyAll {
+			return clusterRoleBindingTargets(resource, clusterRoles), err
+		}
+
+		// A single ClusterRole is expected, so its binding is not named after it, and it is kept across renames.
+		// The binding is not touched while no ClusterRole matches, as it would be pruned otherwise
+		if len(clusterRoles) == 0 {
+			return result, fmt.Errorf("%w: no ClusterRoles match source.clusterRoleSelector", errRoleRefNotFound)
+		}
+
+		result = append(result, bindingTargetT{
+			name:    resource.Spec.Targets.Name,
+			roleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRoles[0]},
+		})
+		return result, err
+	}
+
+	ownerAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-kind":      "DynamicClusterRole",
+		"kuberbac.prosimcorp.com/owner-name":      resource.Spec.Source.DynamicClusterRole,
+		"kuberbac.prosimcorp.com/owner-namespace": resource.ObjectMeta.Namespace,
+	}
+
+	clusterRoleList := rbacv1.ClusterRoleList{}
+	err = r.Client.List(ctx, &clusterRoleList)
+	if err != nil {
+		return result, fmt.Errorf("error listing ClusterRoles: %s", err.Error())
+	}
+
+	for _, clusterRole := range clusterRoleList.Items {
+		if !globals.IsSubset(ownerAnnotations, clusterRole.Annotations) {
+			continue
+		}
+
+		// Shards are already bound through the ClusterRole aggregating them
+		if _, isShard := clusterRole.Labels[aggregatedShardLabel]; isShard {
+			continue
+		}
+
+		result = append(result, bindingTargetT{
+			name:    resource.Spec.Targets.Name + "-" + clusterRole.Name,
+			roleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole.Name},
+			scope:   clusterRole.Labels[scopeLabel],
+		})
+	}
+
+	// Keep the same order between synchronizations
+	slices.SortFunc(result, func(a, b bindingTargetT) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	if len(result) == 0 {
+		log.FromContext(ctx).V(logLevelDecisions).Info("No ClusterRoles generated by the DynamicClusterRole were found",
+			"dynamicClusterRole", resource.Spec.Source.DynamicClusterRole)
+	}
+
+	return result, err
+}
+
+// PruneStaleBindings deletes the owned bindings of the kind that is not generated anymore: RoleBindings when targets
+// are clusterScoped, and ClusterRoleBindings when they are namespaced. Both kinds are kept when targets generate both,
+// and those of the generated kinds are pruned while syncing them.
+// Targets only change with the spec, so they are only looked for when its generation changed since the last synchronization
+func (r *DynamicRoleBindingReconciler) PruneStaleBindings(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding,
+	referenceAnnotations map[string]string) (err error) {
+
+	logger := log.FromContext(ctx)
+
+	if resource.Status.ObservedGeneration == resource.Generation {
+		return err
+	}
+
+	var allErrors []error
+
+	switch GetTargetsMode(&resource.Spec.Targets) {
+	case kuberbacv1alpha1.TargetsModeBoth:
+		return err
+
+	case kuberbacv1alpha1.TargetsModeClusterScoped:
+		roleBindingList := rbacv1.RoleBindingList{}
+		err = r.Client.List(ctx, &roleBindingList)
+		if err != nil {
+			return fmt.Errorf("error listing RoleBindings: %s", err.Error())
+		}
+
+		for _, roleBinding := range roleBindingList.Items {
+			if !globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+				continue
+			}
+
+			err = r.Client.Delete(ctx, &roleBinding)
+			if err = client.IgnoreNotFound(err); err != nil {
+				allErrors = append(allErrors, fmt.Errorf("%w: error deleting stale RoleBinding: %s", errTargetWriteFailed, err.Error()))
+				continue
+			}
+			logger.V(logLevelChanges).Info("RoleBinding deleted: targets are clusterScoped now",
+				"namespace", roleBinding.Namespace, "roleBinding", roleBinding.Name)
+		}
+
+		return errors.Join(allErrors...)
+	}
+
+	clusterRoleBindingList := rbacv1.ClusterRoleBindingList{}
+	err = r.Client.List(ctx, &clusterRoleBindingList)
+	if err != nil {
+		return fmt.Errorf("error listing ClusterRoleBindings: %s", err.Error())
+	}
+
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+		if !globals.IsSubset(referenceAnnotations, clusterRoleBinding.Annotations) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &clusterRoleBinding)
+		if err = client.IgnoreNotFound(err); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting stale ClusterRoleBinding: %s", errTargetWriteFailed, err.Error()))
+			continue
+		}
+		logger.V(logLevelChanges).Info("ClusterRoleBinding deleted: targets are not clusterScoped anymore",
+			"clusterRoleBinding", clusterRoleBinding.Name)
+	}
+
+	return errors.Join(allErrors...)
+}
+
+// PreviewTarget writes into the status the subjects and namespaces resolved by the selectors, the same way as dry-run
+// mode does, without touching the bindings nor the rest of the status. When explain is set, the decision taken
+// for each evaluated namespace and ServiceAccount is written too
+func (r *DynamicRoleBindingReconciler) PreviewTarget(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding, explain bool) (err error) {
+
+	previewResource := resource.DeepCopy()
+	previewResource.Spec.Targets.DryRun = true
+	_, err = r.SyncTarget(ctx, previewResource)
+
+	resource.Status.RenderedSubjects = previewResource.Status.RenderedSubjects
+	resource.Status.RenderedNamespaces = previewResource.Status.RenderedNamespaces
+	resource.Status.SelectionExplanation = nil
+
+	if err != nil || !explain {
+		return err
+	}
+
+	resource.Status.SelectionExplanation, err = r.ExplainSelection(ctx, resource)
+	return err
+}
+
+// ExplainSelection returns the decision taken for each namespace and ServiceAccount evaluated by the selectors,
+// along with the clause of the selector or the rule of the controller taking it.
+// Namespaces are only evaluated for targets when the subjects rendered by a preview are already in the status
+func (r *DynamicRoleBindingReconciler) ExplainSelection(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (result []kuberbacv1alpha1.SelectionDecisionT, err error) {
+
+	namespaceList := &corev1.NamespaceList{}
+	err = r.Client.List(ctx, namespaceList)
+	if err != nil {
+		return result, err
+	}
+
+	excludedReason := fmt.Sprintf("namespace is annotated with '%s: \"true\"'", ExcludeNamespaceAnnotation)
+
+	// Explain the namespaces and ServiceAccounts evaluated for the subjects
+	if resource.Spec.Source.Subject != nil && slices.Contains(
+		[]string{rbacv1.ServiceAccountKind, kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts}, resource.Spec.Source.Subject.Kind) {
+
+		namespaceMatcher, err := NewNamespaceMatcher(&resource.Spec.Source.Subject.NamespaceSelector)
+		if err != nil {
+			return result, fmt.Errorf("error selecting the namespaces of source.subject: %w", err)
+		}
+
+		subjectNamespaces := []string{}
+		for _, namespace := range namespaceList.Items {
+			selected, reason := namespaceMatcher.Explain(&namespace)
+			if selected && len(r.FilterSubjectNamespaces([]string{namespace.Name}, namespaceList)) == 0 {
+				selected, reason = false, "namespace is not watched by the operator"
+				if namespace.Annotations[ExcludeNamespaceAnnotation] == "true" {
+					reason = excludedReason
+				}
+			}
+
+			result = append(result, kuberbacv1alpha1.SelectionDecisionT{
+				Selector: "source.subject.namespaceSelector",
+				Kind:     "Namespace",
+				Name:     namespace.Name,
+				Selected: selected,
+				Reason:   reason,
+			})
+
+			if selected {
+				subjectNamespaces = append(subjectNamespaces, namespace.Name)
+			}
+		}
+
+		// ServiceAccounts of whole namespaces are bound through their group, so they are not evaluated one by one
+		if resource.Spec.Source.Subject.Kind == rbacv1.ServiceAccountKind {
+
+			serviceAccountMatcher, err := r.NewServiceAccountMatcher(ctx, resource.Spec.Source.Subject)
+			if err != nil {
+				return result, fmt.Errorf("error getting selected ServiceAccounts: %w", err)
+			}
+
+			serviceAccountSelector := "source.subject"
+			switch {
+			case !reflect.ValueOf(resource.Spec.Source.Subject.MetaSelector).IsZero():
+				serviceAccountSelector = "source.subject.metaSelector"
+			case !reflect.ValueOf(resource.Spec.Source.Subject.NameSelector).IsZero():
+				serviceAccountSelector = "source.subject.nameSelector"
+			}
+
+			// ServiceAccounts are only looked for in the selected namespaces, the same way as synchronizing,
+			// so nothing is listed when none is selected
+			serviceAccountList := &corev1.ServiceAccountList{}
+			for _, namespace := range subjectNamespaces {
+				namespaceServiceAccountList := &corev1.ServiceAccountList{}
+				err = r.Client.List(ctx, namespaceServiceAccountList, client.InNamespace(namespace))
+				if err != nil {
+					return result, err
+				}
+				serviceAccountList.Items = append(serviceAccountList.Items, namespaceServiceAccountList.Items...)
+			}
+
+			for _, serviceAccount := range serviceAccountList.Items {
+				selected, reason := serviceAccountMatcher.Explain(&serviceAccount)
+				result = append(result, kuberbacv1alpha1.SelectionDecisionT{
+					Selector:  serviceAccountSelector,
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      serviceAccount.Name,
+					Namespace: serviceAccount.Namespace,
+					Selected:  selected,
+					Reason:    reason,
+				})
+			}
+		}
+	}
+
+	// ClusterRoleBindings do not target namespaces
+	if GetTargetsMode(&resource.Spec.Targets) == kuberbacv1alpha1.TargetsModeClusterScoped {
+		return result, err
+	}
+
+	// Explain the namespaces evaluated for the targets, following the same order of rules as synchronizing
+	targetsNamespaceSelector, err := r.GetTargetsNamespaceSelector(ctx, &resource.Spec.Targets)
+	if err != nil {
+		return result, err
+	}
+
+	namespaceMatcher, err := NewNamespaceMatcher(targetsNamespaceSelector)
+	if err != nil {
+		return result, fmt.Errorf("error selecting the namespaces of targets: %w", err)
+	}
+
+	targetsSelector := "targets.namespaceSelector"
+	if resource.Spec.Targets.NamespaceSelectorClassName != "" {
+		targetsSelector = "targets.namespaceSelectorClassName"
+	}
+
+	for _, namespace := range namespaceList.Items {
+		namespaces := []string{namespace.Name}
+		selected, reason := namespaceMatcher.Explain(&namespace)
+
+		switch {
+		case !selected:
+		case len(RemoveSystemNamespaces(namespaces, resource.Spec.Targets.ExcludeSystemNamespaces, r.ExcludeSystemNamespaces)) == 0:
+			selected, reason = false, "system namespaces are excluded"
+		case len(RemoveExcludedNamespaces(namespaces, namespaceList)) == 0:
+			selected, reason = false, excludedReason
+		case len(RemoveUnwatchedNamespaces(namespaces, r.WatchNamespaces)) == 0:
+			selected, reason = false, "namespace is not watched by the operator"
+		case len(RemoveInactiveNamespaces(namespaces, namespaceList)) == 0:
+			selected, reason = false, "namespace is not active"
+		case resource.Spec.Targets.OnlyWhereSubjectsExist &&
+			len(RemoveNamespacesWithoutSubjects(namespaces, resource.Status.RenderedSubjects)) == 0:
+			selected, reason = false, "no selected ServiceAccount lives in the namespace"
+		}
+
+		result = append(result, kuberbacv1alpha1.SelectionDecisionT{
+			Selector: targetsSelector,
+			Kind:     "Namespace",
+			Name:     namespace.Name,
+			Selected: selected,
+			Reason:   reason,
+		})
+	}
+
+	return result, err
+}
+
+// SyncTarget call Kubernetes API to actually perform actions over the resource.
+// It returns the applied bindings, so they can be propagated onto the member clusters
+func (r *DynamicRoleBindingReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (
+	generatedObjects []client.Object, err error) {
+
+	logger := log.FromContext(ctx)
+
+	// Check at least one of source.subject or source.staticSubjects is set
+	subjectSelected := resource.Spec.Source.Subject != nil
+	if !subjectSelected && len(resource.Spec.Source.StaticSubjects) == 0 {
+		err = fmt.Errorf("%w: at least one of source.subject or source.staticSubjects must be set", errInvalidSpec)
+		return generatedObjects, err
+	}
+
+	if subjectSelected {
+		err = CheckSourceSubject(resource.Spec.Source.Subject)
+		if err != nil {
+			return generatedObjects, err
+		}
+	}
+
+	// Check exactly one of source.clusterRole, source.clusterRoles, source.clusterRoleSelector,
+	// source.role, source.dynamicClusterRole or targets.roleNameTemplate is set
+	filledSourceRoles := 0
+	for _, sourceRole := range []string{resource.Spec.Source.ClusterRole, resource.Spec.Source.Role, resource.Spec.Source.DynamicClusterRole,
+		resource.Spec.Targets.RoleNameTemplate} {
+		if sourceRole != "" {
+			filledSourceRoles++
+		}
+	}
+	if len(resource.Spec.Source.ClusterRoles) > 0 {
+		filledSourceRoles++
+	}
+	if resource.Spec.Source.ClusterRoleSelector != nil {
+		filledSourceRoles++
+	}
+
+	if filledSourceRoles != 1 {
+		err = fmt.Errorf("%w: exactly one of source.clusterRole, source.clusterRoles, source.clusterRoleSelector, "+
+			"source.role, source.dynamicClusterRole or targets.roleNameTemplate must be set", errInvalidSpec)
+		return generatedObjects, err
+	}
+
+	if resource.Spec.Source.ClusterRoleSelectorPolicy != "" && resource.Spec.Source.ClusterRoleSelector == nil {
+		err = fmt.Errorf("%w: source.clusterRoleSelectorPolicy is only allowed along with source.clusterRoleSelector", errInvalidSpec)
+		return generatedObjects, err
+	}
+
+	// Check targets.clusterScoped does not contradict targets.mode
+	targetsMode := GetTargetsMode(&resource.Spec.Targets)
+	if resource.Spec.Targets.ClusterScoped && targetsMode != kuberbacv1alpha1.TargetsModeClusterScoped {
+		err = fmt.Errorf("%w: targets.clusterScoped is only allowed along with targets.mode '%s'", errInvalidSpec,
+			kuberbacv1alpha1.TargetsModeClusterScoped)
+		return generatedObjects, err
+	}
+
+	// Roles only exist inside namespaces, so they can not be bound cluster-wide
+	if resource.Spec.Source.Role != "" && targetsMode == kuberbacv1alpha1.TargetsModeClusterScoped {
+		err = fmt.Errorf("%w: source.role is not allowed for clusterScoped targets", errInvalidSpec)
+		return generatedObjects, err
+	}
+
+	if resource.Spec.Targets.RoleNameTemplate != "" {
+		if targetsMode == kuberbacv1alpha1.TargetsModeClusterScoped {
+			err = fmt.Errorf("%w: targets.roleNameTemplate is not allowed for clusterScoped targets", errInvalidSpec)
+			return generatedObjects, err
+		}
+
+		_, err = template.New("").Parse(resource.Spec.Targets.RoleNameTemplate)
+		if err != nil {
+			err = fmt.Errorf("%w: invalid targets.roleNameTemplate: %s", errInvalidSpec, err.Error())
+			return generatedObjects, err
+		}
+	}
+
+	// Only selected ServiceAccounts live inside namespaces, so the rest of subjects can not narrow the target namespaces
+	if resource.Spec.Targets.OnlyWhereSubjectsExist &&
+		(!subjectSelected || resource.Spec.Source.Subject.Kind != rbacv1.ServiceAccountKind) {
+		err = fmt.Errorf("%w: targets.onlyWhereSubjectsExist is only allowed for ServiceAccount subjects", errInvalidSpec)
+		return generatedObjects, err
+	}
+
+	// Target namespaces are selected inline or through a shared NamespaceSelectorClass, but not both at once
+	if resource.Spec.Targets.NamespaceSelectorClassName != "" && !reflect.ValueOf(resource.Spec.Targets.NamespaceSelector).IsZero() {
+		err = fmt.Errorf("%w: targets.namespaceSelector is not allowed along with targets.namespaceSelectorClassName", errInvalidSpec)
+		return generatedObjects, err
+	}
+
+	// Operators restricted to some namespaces must not grant permissions cluster-wide
+	if targetsMode != kuberbacv1alpha1.TargetsModeNamespaced && len(r.WatchNamespaces) > 0 {
+		err = fmt.Errorf("%w: targets generating ClusterRoleBindings are not allowed when the operator only watches some namespaces", errInvalidSpec)
+		return generatedObjects, err
+	}
+
+	// Kubernetes accepts bindings referencing missing roles, which are silently broken until the role appears.
+	// Report it once the bindings are synced, or do not sync them at all when asked to wait for the role
+	referencedClusterRoles := resource.Spec.Source.ClusterRoles
+	if resource.Spec.Source.ClusterRole != "" {
+		referencedClusterRoles = []string{resource.Spec.Source.ClusterRole}
+	}
+
+	var missingClusterRoles []string
+	for _, clusterRole := range referencedClusterRoles {
+		err = r.Get(ctx, client.ObjectKey{Name: clusterRole}, &rbacv1.ClusterRole{})
+		if client.IgnoreNotFound(err) != nil {
+			return generatedObjects, fmt.Errorf("error getting ClusterRole: %s", err.Error())
+		}
+
+		if err != nil {
+			missingClusterRoles = append(missingClusterRoles, clusterRole)
+		}
+	}
+
+	if len(missingClusterRoles) > 0 {
+		roleRefErr := fmt.Errorf("%w: ClusterRoles not found: %s", errRoleRefNotFound, strings.Join(missingClusterRoles, ", "))
+		if resource.Spec.Source.WaitForRole {
+			return generatedObjects, roleRefErr
+		}
+
+		logger.V(logLevelDecisions).Info("Referenced ClusterRoles not found: bindings are synced anyway",
+			"clusterRoles", missingClusterRoles)
+		defer func() {
+			if err == nil {
+				err = roleRefErr
+			}
+		}()
+	}
+
+	// Get all the namespaces and filter them by namespaceSelector later
+	namespaceList := &corev1.NamespaceList{}
+	err = r.Client.List(ctx, namespaceList)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	targetsNamespaceSelector, err := r.GetTargetsNamespaceSelector(ctx, &resource.Spec.Targets)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	// Create as many subjects as needed
+	expandedSubjects := []rbacv1.Subject{}
+	if subjectSelected {
+		expandedSubjects, err = r.ExpandSubjects(ctx, resource.Spec.Source.Subject, namespaceList)
+		if err != nil {
+			return generatedObjects, err
+		}
+	}
+
+	// Static subjects are counted once rendered, as their templates may render different subjects on each namespace
+	resource.Status.SubjectsCount = len(expandedSubjects)
+	resource.Status.TargetNamespacesCount = 0
+	resource.Status.GeneratedBindings = nil
+	resource.Status.GeneratedBindingsCount = 0
+
+	// On dry-run mode, expose the rendered subjects and namespaces in the status without touching the cluster
+	resource.Status.RenderedSubjects = nil
+	resource.Status.RenderedNamespaces = nil
+	resource.Status.SelectionExplanation = nil
+	if resource.Spec.Targets.DryRun {
+		resource.Status.RenderedSubjects = expandedSubjects
+
+		if targetsMode != kuberbacv1alpha1.TargetsModeNamespaced {
+			var staticSubjects []rbacv1.Subject
+			staticSubjects, err = RenderStaticSubjects(resource, metav1.ObjectMeta{})
+			resource.Status.RenderedSubjects = appendSubjects(resource.Status.RenderedSubjects, staticSubjects...)
+			resource.Status.SubjectsCount = len(resource.Status.RenderedSubjects)
+			if err != nil || targetsMode == kuberbacv1alpha1.TargetsModeClusterScoped {
+				return generatedObjects, err
+			}
+		}
+
+		resource.Status.RenderedNamespaces, err = FilterNamespaceListBySelector(namespaceList, targetsNamespaceSelector)
+		if err != nil {
+			return generatedObjects, fmt.Errorf("error selecting the namespaces of targets: %w", err)
+		}
+		resource.Status.RenderedNamespaces = RemoveSystemNamespaces(resource.Status.RenderedNamespaces,
+			resource.Spec.Targets.ExcludeSystemNamespaces, r.ExcludeSystemNamespaces)
+		resource.Status.RenderedNamespaces = RemoveExcludedNamespaces(resource.Status.RenderedNamespaces, namespaceList)
+		resource.Status.RenderedNamespaces = RemoveUnwatchedNamespaces(resource.Status.RenderedNamespaces, r.WatchNamespaces)
+		resource.Status.RenderedNamespaces = RemoveInactiveNamespaces(resource.Status.RenderedNamespaces, namespaceList)
+		if resource.Spec.Targets.OnlyWhereSubjectsExist {
+			resource.Status.RenderedNamespaces = RemoveNamespacesWithoutSubjects(resource.Status.RenderedNamespaces, expandedSubjects)
+		}
+		resource.Status.TargetNamespacesCount = len(resource.Status.RenderedNamespaces)
+
+		// Static subjects are rendered once per target namespace
+		for _, namespace := range namespaceList.Items {
+			if !slices.Contains(resource.Status.RenderedNamespaces, namespace.Name) {
+				continue
+			}
+
+			staticSubjects, err := RenderStaticSubjects(resource, namespace.ObjectMeta)
+			if err != nil {
+				return generatedObjects, fmt.Errorf("error rendering static subjects for namespace '%s': %w", namespace.Name, err)
+			}
+			resource.Status.RenderedSubjects = appendSubjects(resource.Status.RenderedSubjects, staticSubjects...)
+		}
+		resource.Status.SubjectsCount = len(resource.Status.RenderedSubjects)
+		return generatedObjects, err
+	}
+
+	// Create a generic RoleBinding structure
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	if len(resource.Spec.Targets.Annotations) == 0 {
+		resource.Spec.Targets.Annotations = map[string]string{}
+	}
+	maps.Copy(resource.Spec.Targets.Annotations, referenceAnnotations)
+
+	// Get the roles to bind. There is one binding for each of them
+	bindingTargets, err := r.GetBindingTargets(ctx, resource)
+	if err != nil {
+		return generatedObjects, err
+	}
+
+	clusterBindingTargets, namespaceBindingTargets := splitBindingTargets(targetsMode, bindingTargets)
+
+	// Generate or update the ClusterRoleBinding and RoleBinding resources, depending on the mode of the targets
+	var previousSubjects, nextSubjects []string
+	if targetsMode != kuberbacv1alpha1.TargetsModeNamespaced {
+		previousSubjects, nextSubjects, generatedObjects, err = r.SyncClusterRoleBindings(ctx, resource, clusterBindingTargets, expandedSubjects, referenceAnnotations)
+		if err != nil {
+			return generatedObjects, err
+		}
+	}
+
+	if targetsMode != kuberbacv1alpha1.TargetsModeClusterScoped {
+		var previousRoleBindingSubjects, nextRoleBindingSubjects []string
+		var generatedRoleBindings []client.Object
+		previousRoleBindingSubjects, nextRoleBindingSubjects, generatedRoleBindings, err = r.SyncRoleBindings(ctx, resource, namespaceList,
+			targetsNamespaceSelector, namespaceBindingTargets, expandedSubjects, referenceAnnotations)
+		previousSubjects = append(previousSubjects, previousRoleBindingSubjects...)
+		nextSubjects = append(nextSubjects, nextRoleBindingSubjects...)
+		generatedObjects = append(generatedObjects, generatedRoleBindings...)
+	}
+
+	resource.Status.GeneratedBindingsCount = len(resource.Status.GeneratedBindings)
+	metrics.GeneratedBindings.WithLabelValues(DynamicRoleBindingResourceType, resource.Namespace, resource.Name).
+		Set(float64(resource.Status.GeneratedBindingsCount))
+
+	r.RecordSubjectChanges(ctx, resource, previousSubjects, nextSubjects)
+
+	// Count the subjects selected plus the ones rendered from the static subjects on the applied bindings
+	boundSubjects := map[string]struct{}{}
+	for _, subject := range append(FormatSubjects(expandedSubjects), nextSubjects...) {
+		boundSubjects[subject] = struct{}{}
+	}
+	resource.Status.SubjectsCount = len(boundSubjects)
+
+	// Remove the bindings of the kind generated before switching the mode of the targets
+	err = errors.Join(err, r.PruneStaleBindings(ctx, resource, referenceAnnotations))
+	return generatedObjects, err
+}
+
+// SyncClusterRoleBindings applies a ClusterRoleBinding for each binding target, and deletes the owned ones whose role
+// is not bound anymore. It returns the subjects of the bindings before and after applying them, to summarize the changes,
+// and the applied bindings
+func (r *DynamicRoleBindingReconciler) SyncClusterRoleBindings(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding,
+	bindingTargets []bindingTargetT, expandedSubjects []rbacv1.Subject, referenceAnnotations map[string]string) (
+	previousSubjects, nextSubjects []string, generatedObjects []client.Object, err error) {
+
+	logger := log.FromContext(ctx)
+
+	// Static subjects are rendered without namespace for ClusterRoleBindings
+	var staticSubjects []rbacv1.Subject
+	staticSubjects, err = RenderStaticSubjects(resource, metav1.ObjectMeta{})
+	if err != nil {
+		return previousSubjects, nextSubjects, generatedObjects, err
+	}
+
+	existentClusterRoleBindingList := rbacv1.ClusterRoleBindingList{}
+	err = r.Client.List(ctx, &existentClusterRoleBindingList)
+	if err != nil {
+		return previousSubjects, nextSubjects, generatedObjects, fmt.Errorf("error listing ClusterRoleBindings: %s", err.Error())
+	}
+
+	// Subjects of the owned ClusterRoleBindings, to keep them on the same shard
+	existentSubjects := map[string][]rbacv1.Subject{}
+	for _, clusterRoleBinding := range existentClusterRoleBindingList.Items {
+		if globals.IsSubset(referenceAnnotations, clusterRoleBinding.Annotations) {
+			existentSubjects[clusterRoleBinding.Name] = clusterRoleBinding.Subjects
+		}
+	}
+
+	bindingNames := []string{}
+	for _, bindingTarget := range bindingTargets {
+		shards := shardSubjects(bindingTarget.name, SortSubjects(appendSubjects(expandedSubjects, staticSubjects...)),
+			resource.Spec.Targets.MaxSubjectsPerBinding, existentSubjects)
+
+		for _, shard := range shards {
+			bindingNames = append(bindingNames, shard.name)
+
+			clusterRoleBindingResource := rbacv1.ClusterRoleBinding{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: rbacv1.SchemeGroupVersion.String(),
+					Kind:       "ClusterRoleBinding",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        shard.name,
+					Labels:      resource.Spec.Targets.Labels,
+					Annotations: resource.Spec.Targets.Annotations,
+				},
+				RoleRef:  bindingTarget.roleRef,
+				Subjects: shard.subjects,
+			}
+
+			// Review reference annotations when the resource already exists
+			existentClusterRoleBindingIndex := slices.IndexFunc(existentClusterRoleBindingList.Items,
+				func(clusterRoleBinding rbacv1.ClusterRoleBinding) bool {
+					return clusterRoleBinding.Name == clusterRoleBindingResource.Name
+				})
+
+			if existentClusterRoleBindingIndex != -1 {
+				existentClusterRoleBinding := existentClusterRoleBindingList.Items[existentClusterRoleBindingIndex]
+				if !globals.IsSubset(referenceAnnotations, existentClusterRoleBinding.Annotations) {
+					logger.V(logLevelDecisions).Info("ClusterRoleBinding skipped: it already exists and is not owned by this resource",
+						"clusterRoleBinding", clusterRoleBindingResource.Name)
+					continue
+				}
+				previousSubjects = append(previousSubjects, FormatSubjects(existentClusterRoleBinding.Subjects)...)
+			}
+
+			propagateAnnotations(resource, &clusterRoleBindingResource, r.PropagatedAnnotations)
+
+			if r.StandardLabels {
+				err = setStandardLabels(&clusterRoleBindingResource, resource.Name)
+				if err != nil {
+					return previousSubjects, nextSubjects, generatedObjects, err
+				}
+			}
+
+			err = mutateResource(ctx, r.MutationHook, &clusterRoleBindingResource)
+			if err != nil {
+				return previousSubjects, nextSubjects, generatedObjects, err
+			}
+
+			err = applyResource(ctx, r.Client, clusterRoleBindingResource.DeepCopy())
+			if err != nil {
+				return previousSubjects, nextSubjects, generatedObjects, fmt.Errorf("%w: error applying ClusterRoleBinding: %s", errTargetWriteFailed, err.Error())
+			}
+			logger.V(logLevelDecisions).Info("ClusterRoleBinding applied",
+				"clusterRoleBinding", clusterRoleBindingResource.Name, "subjects", len(clusterRoleBindingResource.Subjects))
+
+			nextSubjects = append(nextSubjects, FormatSubjects(clusterRoleBindingResource.Subjects)...)
+			generatedObjects = append(generatedObjects, clusterRoleBindingResource.DeepCopy())
+			resource.Status.GeneratedBindings = append(resource.Status.GeneratedBindings, clusterRoleBindingResource.Name)
+		}
+	}
+
+	// Remove owned ClusterRoleBindings whose role is not bound anymore
+	for _, clusterRoleBinding := range existentClusterRoleBindingList.Items {
+		if !globals.IsSubset(referenceAnnotations, clusterRoleBinding.Annotations) ||
+			slices.Contains(bindingNames, clusterRoleBinding.Name) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &clusterRoleBinding)
+		if err != nil {
+			return previousSubjects, nextSubjects, generatedObjects, fmt.Errorf("%w: error deleting not needed ClusterRoleBinding: %s", errTargetWriteFailed, err.Error())
+		}
+		logger.V(logLevelChanges).Info("ClusterRoleBinding deleted: its role is not bound anymore",
+			"clusterRoleBinding", clusterRoleBinding.Name)
+	}
+
+	return previousSubjects, nextSubjects, generatedObjects, err
+}
+
+// SyncRoleBindings applies a RoleBinding for each binding target on each targeted namespace, and deletes the owned ones
+// not desired anymore. It returns the subjects of the bindings before and after applying them, to summarize the changes,
+// and the applied bindings
+func (r *DynamicRoleBindingReconciler) SyncRoleBindings(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding,
+	namespaceList *corev1.NamespaceList, namespaceSelector *kuberbacv1alpha1.NamespaceSelectorT, bindingTargets []bindingTargetT,
+	expandedSubjects []rbacv1.Subject, referenceAnnotations map[string]string) (previousSubjects, nextSubjects []string, generatedObjects []client.Object, err error) {
+
+	logger := log.FromContext(ctx)
+
+	// Get Rolebindings
+	existentRoleBindingList := rbacv1.RoleBindingList{}
+	err = r.Client.List(ctx, &existentRoleBindingList)
+	if err != nil {
+		return previousSubjects, nextSubjects, generatedObjects, err
+	}
+
+	// Upgrade already existing RoleBindings tracked only by reference annotations
+	for _, roleBinding := range existentRoleBindingList.Items {
+		if !globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+			continue
+		}
+
+		err = adoptResource(ctx, r.Client, r.OwnershipMode, resource, &roleBinding)
+		if err != nil {
+			return previousSubjects, nextSubjects, generatedObjects, fmt.Errorf("error adopting RoleBinding: %s", err.Error())
+		}
+	}
+
+	targetFilteredNamespaces, err := FilterNamespaceListBySelector(namespaceList, namespaceSelector)
+	if err != nil {
+		return previousSubjects, nextSubjects, generatedObjects, fmt.Errorf("error selecting the namespaces of targets: %w", err)
+	}
+	selectedNamespacesCount := len(targetFilteredNamespaces)
+	targetFilteredNamespaces = RemoveSystemNamespaces(targetFilteredNamespaces,
+		resource.Spec.Targets.ExcludeSystemNamespaces, r.ExcludeSystemNamespaces)
+	excludedSystemNamespacesCount := selectedNamespacesCount - len(targetFilteredNamespaces)
+	selectedNamespacesCount = len(targetFilteredNamespaces)
+	targetFilteredNamespaces = RemoveExcludedNamespaces(targetFilteredNamespaces, namespaceList)
+	optedOutNamespacesCount := selectedNamespacesCount - len(targetFilteredNamespaces)
+	targetFilteredNamespaces = RemoveUnwatchedNamespaces(targetFilteredNamespaces, r.WatchNamespaces)
+	selectedNamespacesCount = len(targetFilteredNamespaces)
+	activeNamespaces := RemoveInactiveNamespaces(targetFilteredNamespaces, namespaceList)
+	inactiveNamespaces := slices.DeleteFunc(slices.Clone(targetFilteredNamespaces), func(namespace string) bool {
+		return slices.Contains(activeNamespaces, namespace)
+	})
+	targetFilteredNamespaces = activeNamespaces
+	selectedNamespacesCount = len(targetFilteredNamespaces)
+	if resource.Spec.Targets.OnlyWhereSubjectsExist {
+		targetFilteredNamespaces = RemoveNamespacesWithoutSubjects(targetFilteredNamespaces, expandedSubjects)
+	}
+	namespacesWithoutSubjectsCount := selectedNamespacesCount - len(targetFilteredNamespaces)
+	logger.V(logLevelDecisions).Info("Target namespaces selected", "namespaces", targetFilteredNamespaces,
+		"excludedSystemNamespaces", excludedSystemNamespacesCount, "optedOutNamespaces", optedOutNamespacesCount,
+		"inactiveNamespaces", inactiveNamespaces, "namespacesWithoutSubjects", namespacesWithoutSubjectsCount)
+
+	resource.Status.TargetNamespacesCount = len(targetFilteredNamespaces)
+
+	// Keep the metadata of each namespace to render the static subjects and the role name
+	namespacesMetadata := map[string]metav1.ObjectMeta{}
+	for _, namespace := range namespaceList.Items {
+		namespacesMetadata[namespace.Name] = namespace.ObjectMeta
+	}
+
+	// Subjects of the owned RoleBindings on each namespace, to keep them on the same shard
+	existentSubjects := map[string]map[string][]rbacv1.Subject{}
+	for _, roleBinding := range existentRoleBindingList.Items {
+		if !globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+			continue
+		}
+		if existentSubjects[roleBinding.Namespace] == nil {
+			existentSubjects[roleBinding.Namespace] = map[string][]rbacv1.Subject{}
+		}
+		existentSubjects[roleBinding.Namespace][roleBinding.Name] = roleBinding.Subjects
+	}
+
+	// Names of the RoleBindings desired on each namespace. Those already existing are kept
+	// on the namespaces that could not be rendered
+	bindingNames := map[string][]string{}
+
+	// Failures writing a RoleBinding do not stop the others, and are reported together
+	var allErrors []error
+
+	// Create the RoleBinding resources on targeted namespaces
+	for _, namespace := range targetFilteredNamespaces {
+
+		var staticSubjects []rbacv1.Subject
+		staticSubjects, err = RenderStaticSubjects(resource, namespacesMetadata[namespace])
+		if err != nil {
+			logger.Error(err, "Failed to render static subjects", "namespace", namespace)
+			bindingNames[namespace] = maps.Keys(existentSubjects[namespace])
+			continue
+		}
+
+		var roleName string
+		if resource.Spec.Targets.RoleNameTemplate != "" {
+			roleName, err = RenderRoleName(resource, namespacesMetadata[namespace])
+			if err != nil {
+				logger.Error(err, "Failed to render role name", "namespace", namespace)
+				bindingNames[namespace] = maps.Keys(existentSubjects[namespace])
+				continue
+			}
+		}
+
+		if resource.Spec.Targets.Bootstrap.CreateServiceAccounts {
+			err = r.CreateMissingServiceAccounts(ctx, resource, namespace, staticSubjects)
+			if err != nil {
+				logger.Error(err, "Failed to create static ServiceAccount subjects", "namespace", namespace)
+			}
+		}
+
+		for _, bindingTarget := range bindingTargets {
+			if roleName != "" {
+				bindingTarget.roleRef.Name = roleName
+			}
+
+			shards := shardSubjects(bindingTarget.name, SortSubjects(appendSubjects(expandedSubjects, staticSubjects...)),
+				resource.Spec.Targets.MaxSubjectsPerBinding, existentSubjects[namespace])
+
+			for _, shard := range shards {
+				bindingNames[namespace] = append(bindingNames[namespace], shard.name)
+
+				roleBindingResource := rbacv1.RoleBinding{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: rbacv1.SchemeGroupVersion.String(),
+						Kind:       "RoleBinding",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        shard.name,
+						Namespace:   namespace,
+						Labels:      resource.Spec.Targets.Labels,
+						Annotations: resource.Spec.Targets.Annotations,
+					},
+					RoleRef:  bindingTarget.roleRef,
+					Subjects: shard.subjects,
+				}
+
+				// Check potential already existing RoleBindings that match the same name and namespace
+				roleBindingFound := false
+				for _, roleBinding := range existentRoleBindingList.Items {
+
+					if roleBinding.Namespace != namespace || roleBinding.Name != roleBindingResource.Name {
+						continue
+					}
+
+					if !globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+						roleBindingFound = true
+						break
+					}
+				}
+
+				if roleBindingFound {
+					logger.V(logLevelDecisions).Info("RoleBinding skipped: it already exists and is not owned by this resource",
+						"namespace", namespace, "roleBinding", roleBindingResource.Name)
+					continue
+				}
+
+				// Finally, apply it!!
+				err = setOwnerReference(r.OwnershipMode, resource, &roleBindingResource, r.Scheme)
+				if err != nil {
+					logger.Error(err, "Failed to set owner reference on RoleBinding", "namespace", namespace, "roleBinding", roleBindingResource.Name)
+					continue
+				}
+
+				propagateAnnotations(resource, &roleBindingResource, r.PropagatedAnnotations)
+
+				if r.StandardLabels {
+					err = setStandardLabels(&roleBindingResource, resource.Name)
+					if err != nil {
+						logger.Error(err, "Failed to set standard labels on RoleBinding", "namespace", namespace, "roleBinding", roleBindingResource.Name)
+						continue
+					}
+				}
+
+				err = mutateResource(ctx, r.MutationHook, &roleBindingResource)
+				if err != nil {
+					allErrors = append(allErrors, err)
+					continue
+				}
+
+				err = applyResource(ctx, r.Client, &roleBindingResource)
+				if err != nil {
+					allErrors = append(allErrors, fmt.Errorf("%w: error applying RoleBinding '%s/%s': %s",
+						errTargetWriteFailed, namespace, roleBindingResource.Name, err.Error()))
+					continue
+				}
+				logger.V(logLevelDecisions).Info("RoleBinding applied",
+					"namespace", namespace, "roleBinding", roleBindingResource.Name, "subjects", len(roleBindingResource.Subjects))
+				nextSubjects = append(nextSubjects, FormatSubjects(roleBindingResource.Subjects)...)
+				generatedObjects = append(generatedObjects, roleBindingResource.DeepCopy())
+				resource.Status.GeneratedBindings = append(resource.Status.GeneratedBindings, namespace+"/"+roleBindingResource.Name)
+			}
+		}
+	}
+	// Summarize the subjects changed on owned RoleBindings
+	for _, roleBinding := range existentRoleBindingList.Items {
+		if globals.IsSubset(referenceAnnotations, roleBinding.Annotations) && !slices.Contains(inactiveNamespaces, roleBinding.Namespace) {
+			previousSubjects = append(previousSubjects, FormatSubjects(roleBinding.Subjects)...)
+		}
+	}
+
+	// Remove owned RoleBidings not defined in manifest: those in namespaces that are not targeted anymore,
+	// and those whose role is not bound anymore. Those in terminating namespaces are deleted along with them
+	for _, roleBinding := range existentRoleBindingList.Items {
+		if !globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+			continue
+		}
+
+		if slices.Contains(inactiveNamespaces, roleBinding.Namespace) {
+			continue
+		}
+
+		namespaceTargeted := slices.Contains(targetFilteredNamespaces, roleBinding.Namespace)
+		if namespaceTargeted && slices.Contains(bindingNames[roleBinding.Namespace], roleBinding.Name) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &roleBinding)
+		if err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting not needed rolebindings: %s", errTargetWriteFailed, err.Error()))
+			continue
+		}
+
+		if !namespaceTargeted {
+			logger.V(logLevelChanges).Info("RoleBinding deleted: its namespace is not targeted anymore",
+				"namespace", roleBinding.Namespace, "roleBinding", roleBinding.Name)
+			continue
+		}
+		logger.V(logLevelChanges).Info("RoleBinding deleted: its role is not bound anymore",
+			"namespace", roleBinding.Namespace, "roleBinding", roleBinding.Name)
+	}
+
+	return previousSubjects, nextSubjects, generatedObjects, errors.Join(allErrors...)
+}
+
+// DeleteTargets deletes all the RoleBindings and ClusterRoleBindings that are owned by the DynamicRoleBinding resource,
+// or orphans them when its deletion policy is 'Orphan'
+func (r *DynamicRoleBindingReconciler) DeleteTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (err error) {
+	return r.releaseTargets(ctx, resource, resource.Spec.DeletionPolicy)
+}
+
+// RevokeTargets deletes all the bindings owned by the DynamicRoleBinding once they expire,
+// or while its schedule does not grant the access. Revoked access must be removed, so the deletion policy is not considered
+func (r *DynamicRoleBindingReconciler) RevokeTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding) (err error) {
+	return r.releaseTargets(ctx, resource, kuberbacv1alpha1.DeletionPolicyDelete)
+}
+
+// GetExpirationTime returns the time when the bindings of the DynamicRoleBinding expire. It is nil when
+// targets do not set 'expiresAfter', or when the bindings were not created yet
+func (r *DynamicRoleBindingReconciler) GetExpirationTime(resource *kuberbacv1alpha1.DynamicRoleBinding) (expirationTime *metav1.Time, err error) {
+
+	if resource.Spec.Targets.ExpiresAfter == "" {
+		return expirationTime, err
+	}
+
+	expiresAfter, err := time.ParseDuration(resource.Spec.Targets.ExpiresAfter)
+	if err != nil || expiresAfter <= 0 {
+		return expirationTime, fmt.Errorf("%w: targets.expiresAfter must be a positive duration: %s", errInvalidSpec,
+			resource.Spec.Targets.ExpiresAfter)
+	}
+
+	if resource.Status.BindingsCreationTime == nil {
+		return expirationTime, err
+	}
+
+	expirationTime = &metav1.Time{Time: resource.Status.BindingsCreationTime.Add(expiresAfter)}
+	return expirationTime, err
+}
+
+// GetScheduleState returns whether the schedule of the DynamicRoleBinding grants the access at the given time,
+// and the next time when it grants or revokes it. The access is always granted when the schedule is not set,
+// and the next change is nil when the schedule does not change anymore
+func (r *DynamicRoleBindingReconciler) GetScheduleState(resource *kuberbacv1alpha1.DynamicRoleBinding, now time.Time) (
+	active bool, nextChangeTime *metav1.Time, err error) {
+
+	schedule := resource.Spec.Schedule
+	if schedule == nil {
+		return true, nextChangeTime, err
+	}
+
+	if schedule.ActiveFrom != nil && schedule.ActiveTo != nil && !schedule.ActiveFrom.Before(schedule.ActiveTo) {
+		return active, nextChangeTime, fmt.Errorf("%w: schedule.activeFrom must be before schedule.activeTo", errInvalidSpec)
+	}
+
+	location, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return active, nextChangeTime, fmt.Errorf("%w: schedule.timeZone is not a valid time zone: %s", errInvalidSpec,
+			schedule.TimeZone)
+	}
+
+	windows := []scheduleWindow{}
+	for index, window := range schedule.Windows {
+		parsedWindow, err := parseScheduleWindow(window)
+		if err != nil {
+			return active, nextChangeTime, fmt.Errorf("%w: schedule.windows[%d]: %s", errInvalidSpec, index, err.Error())
+		}
+		windows = append(windows, parsedWindow)
+	}
+
+	isActive := func(instant time.Time) bool {
+		if schedule.ActiveFrom != nil && instant.Before(schedule.ActiveFrom.Time) {
+			return false
+		}
+		if schedule.ActiveTo != nil && !instant.Before(schedule.ActiveTo.Time) {
+			return false
+		}
+		if len(windows) == 0 {
+			return true
+		}
+		return slices.ContainsFunc(windows, func(window scheduleWindow) bool {
+			return window.contains(instant.In(location))
+		})
+	}
+
+	// The access only changes on the boundaries of the schedule, so they are the only candidates for the next change.
+	// Windows repeat every week, so looking for their boundaries one week after now, and after the start
+	// of the absolute period, is enough
+	candidates := []time.Time{}
+	if schedule.ActiveFrom != nil {
+		candidates = append(candidates, schedule.ActiveFrom.Time)
+	}
+	if schedule.ActiveTo != nil {
+		candidates = append(candidates, schedule.ActiveTo.Time)
+	}
+	for _, window := range windows {
+		candidates = append(candidates, window.boundaries(now.In(location))...)
+		if schedule.ActiveFrom != nil && schedule.ActiveFrom.After(now) {
+			candidates = append(candidates, window.boundaries(schedule.ActiveFrom.In(location))...)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+
+	active = isActive(now)
+	for _, candidate := range candidates {
+		if candidate.After(now) && isActive(candidate) != active {
+			nextChangeTime = &metav1.Time{Time: candidate}
+			break
+		}
+	}
+
+	return active, nextChangeTime, err
+}
+
+// scheduleWindow is a window of a schedule, with its times of the day parsed as the time passed since midnight
+type scheduleWindow struct {
+	days       []time.Weekday
+	start, end time.Duration
+}
+
+// parseScheduleWindow parses the days and times of a window of a schedule
+func parseScheduleWindow(window kuberbacv1alpha1.ScheduleWindowT) (parsedWindow scheduleWindow, err error) {
+
+	weekdays := map[kuberbacv1alpha1.ScheduleDayT]time.Weekday{
+		"Sun": time.Sunday, "Mon": time.Monday, "Tue": time.Tuesday, "Wed": time.Wednesday,
+		"Thu": time.Thursday, "Fri": time.Friday, "Sat": time.Saturday,
+	}
+	for _, day := range window.Days {
+		weekday, found := weekdays[day]
+		if !found {
+			return parsedWindow, fmt.Errorf("'%s' is not a day of the week", day)
+		}
+		parsedWindow.days = append(parsedWindow.days, weekday)
+	}
+	if len(parsedWindow.days) == 0 {
+		parsedWindow.days = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+			time.Thursday, time.Friday, time.Saturday}
+	}
+
+	parsedWindow.start, err = parseTimeOfDay(window.Start)
+	if err != nil {
+		return parsedWindow, err
+	}
+
+	parsedWindow.end, err = parseTimeOfDay(window.End)
+	if err != nil {
+		return parsedWindow, err
+	}
+
+	if parsedWindow.start == parsedWindow.end {
+		return parsedWindow, fmt.Errorf("start and end must be different")
+	}
+
+	return parsedWindow, err
+}
+
+// parseTimeOfDay parses a time of the day as 'HH:MM' into the time passed since midnight
+func parseTimeOfDay(value string) (offset time.Duration, err error) {
+
+	parsedTime, err := time.Parse("15:04", value)
+	if err != nil {
+		return offset, fmt.Errorf("'%s' is not a time of the day as 'HH:MM'", value)
+	}
+
+	offset = time.Duration(parsedTime.Hour())*time.Hour + time.Duration(parsedTime.Minute())*time.Minute
+	return offset, err
+}
+
+// occurrence returns the times when the window opens and closes, if it opens on the day of the given time
+func (w scheduleWindow) occurrence(day time.Time) (opens bool, openTime, closeTime time.Time) {
+
+	if !slices.Contains(w.days, day.Weekday()) {
+		return false, openTime, closeTime
+	}
+
+	// Times are built from the wall clock, so windows keep their hours when daylight saving time changes
+	openTime = time.Date(day.Year(), day.Month(), day.Day(), 0, int(w.start.Minutes()), 0, 0, day.Location())
+	closeTime = time.Date(day.Year(), day.Month(), day.Day(), 0, int(w.end.Minutes()), 0, 0, day.Location())
+
+	// Windows ending before they start close on the next day
+	if w.end < w.start {
+		closeTime = time.Date(day.Year(), day.Month(), day.Day()+1, 0, int(w.end.Minutes()), 0, 0, day.Location())
+	}
+
+	return true, openTime, closeTime
+}
+
+// contains returns whether the window is open at the given time. Windows opened the day before are considered,
+// as they can span midnight
+func (w scheduleWindow) contains(instant time.Time) bool {
+
+	for _, dayOffset := range []int{-1, 0} {
+		day := time.Date(instant.Year(), instant.Month(), instant.Day()+dayOffset, 12, 0, 0, 0, instant.Location())
+		opens, openTime, closeTime := w.occurrence(day)
+		if opens && !instant.Before(openTime) && instant.Before(closeTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// boundaries returns the times when the window opens and closes, from the day before the given time
+// to one week after it
+func (w scheduleWindow) boundaries(from time.Time) (boundaries []time.Time) {
+
+	for dayOffset := -1; dayOffset <= 8; dayOffset++ {
+		day := time.Date(from.Year(), from.Month(), from.Day()+dayOffset, 12, 0, 0, 0, from.Location())
+		opens, openTime, closeTime := w.occurrence(day)
+		if opens {
+			boundaries = append(boundaries, openTime, closeTime)
+		}
+	}
+
+	return boundaries
+}
+
+// releaseTargets deletes or orphans, depending on the given deletion policy,
+// all the bindings owned by the DynamicRoleBinding resource
+func (r *DynamicRoleBindingReconciler) releaseTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding,
+	deletionPolicy string) (err error) {
+
+	var allErrors []error
+
+	// Create a generic RoleBinding structure
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	// Get ClusterRolebindings objects and release those with reference annotations
+	clusterRoleBindingList := rbacv1.ClusterRoleBindingList{}
+	err = r.Client.List(ctx, &clusterRoleBindingList)
+	if err != nil {
+		return err
+	}
+
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+
+		if globals.IsSubset(referenceAnnotations, clusterRoleBinding.Annotations) {
+			err = releaseResource(ctx, r.Client, deletionPolicy, resource, &clusterRoleBinding, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing ClusterRoleBinding: %s", err.Error()))
+			}
+		}
+	}
+
+	// Get Rolebindings objects and release those with reference annotations
+	roleBindingList := rbacv1.RoleBindingList{}
+	err = r.Client.List(ctx, &roleBindingList)
+	if err != nil {
+		return err
+	}
+
+	for _, roleBinding := range roleBindingList.Items {
+
+		if globals.IsSubset(referenceAnnotations, roleBinding.Annotations) {
+			err = releaseResource(ctx, r.Client, deletionPolicy, resource, &roleBinding, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing RoleBinding: %s", err.Error()))
+			}
+		}
 	}
 
 	return errors.Join(allErrors...)
 }
+
+// PropagateTargets mirrors the bindings generated by the DynamicRoleBinding onto the member clusters of the inventory,
+// recording the result of each one on the status. RoleBindings are only propagated to the namespaces existing
+// on each member. Released bindings are removed from the members by passing no generated objects
+func (r *DynamicRoleBindingReconciler) PropagateTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding,
+	generatedObjects []client.Object) (err error) {
+
+	if r.MemberClusters == nil {
+		return err
+	}
+
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	lists := []client.ObjectList{&rbacv1.ClusterRoleBindingList{}, &rbacv1.RoleBindingList{}}
+
+	desiredObjects := func(ctx context.Context, memberCluster multicluster.MemberCluster) ([]client.Object, error) {
+		return generatedObjects, nil
+	}
+
+	resource.Status.MemberClusters, err = propagateTargets(ctx, r.MemberClusters, referenceAnnotations,
+		desiredObjects, lists, resource.Status.MemberClusters)
+	return err
+}
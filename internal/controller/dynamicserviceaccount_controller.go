@@ -0,0 +1,203 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/metrics"
+)
+
+// DynamicServiceAccountReconciler reconciles a DynamicServiceAccount object
+type DynamicServiceAccountReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events about the synchronization of the resources
+	Recorder record.EventRecorder
+
+	// OwnershipMode defines how generated resources are tracked: 'annotations' or 'references'
+	OwnershipMode string
+
+	// PropagatedAnnotations lists the annotations copied from resources onto the ones they generate,
+	// such as the tracking ids of GitOps tools. Items ending with '*' are prefixes
+	PropagatedAnnotations []string
+
+	// ExcludeSystemNamespaces skips system namespaces when selecting target namespaces,
+	// unless resources override it
+	ExcludeSystemNamespaces bool
+
+	// WatchNamespaces restricts the namespaces where resources are generated. All of them are used when empty
+	WatchNamespaces []string
+
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff applied to requeue failed synchronizations
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// SyncSchedule defines when resources are synchronized again after a synchronization
+	SyncSchedule SyncScheduleT
+}
+
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicserviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicserviceaccounts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=dynamicserviceaccounts/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.18.2/pkg/reconcile
+func (r *DynamicServiceAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	//1. Get the content of the Patch
+	dynamicServiceAccountResource := &kuberbacv1alpha1.DynamicServiceAccount{}
+	err = r.Get(ctx, req.NamespacedName, dynamicServiceAccountResource)
+
+	// 2. Check existence on the cluster
+	if err != nil {
+
+		// 2.1 It does NOT exist: manage removal
+		if err = client.IgnoreNotFound(err); err == nil {
+			logger.Info(fmt.Sprintf(resourceNotFoundError, DynamicServiceAccountResourceType, req.NamespacedName))
+			return result, err
+		}
+
+		// 2.2 Failed to get the resource, requeue the request
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, DynamicServiceAccountResourceType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 3. Check if the DynamicServiceAccount instance is marked to be deleted: indicated by the deletion timestamp being set
+	if !dynamicServiceAccountResource.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(dynamicServiceAccountResource, resourceFinalizer) {
+
+			// Delete or orphan all created targets, depending on the deletion policy
+			err = r.DeleteTargets(ctx, dynamicServiceAccountResource)
+			if err != nil {
+				logger.Info(fmt.Sprintf(resourceTargetsDeleteError, DynamicServiceAccountResourceType, req.NamespacedName, err.Error()))
+				return result, err
+			}
+
+			// Remove the finalizers on CR
+			err = updateResource(ctx, r.Client, dynamicServiceAccountResource, func() {
+				controllerutil.RemoveFinalizer(dynamicServiceAccountResource, resourceFinalizer)
+			})
+			if err != nil {
+				logger.Info(fmt.Sprintf(resourceFinalizersUpdateError, DynamicServiceAccountResourceType, req.NamespacedName, err.Error()))
+			}
+
+			// Forget the metrics related to this resource
+			metrics.DeleteResourceMetrics(DynamicServiceAccountResourceType, req.Namespace, req.Name)
+		}
+		result = ctrl.Result{}
+		err = nil
+		return result, err
+	}
+
+	// 4. Add finalizer to the DynamicServiceAccount CR
+	if !controllerutil.ContainsFinalizer(dynamicServiceAccountResource, resourceFinalizer) {
+		err = updateResource(ctx, r.Client, dynamicServiceAccountResource, func() {
+			controllerutil.AddFinalizer(dynamicServiceAccountResource, resourceFinalizer)
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// 5. Update the status before the requeue, along with the time of the next synchronization
+	defer func() {
+		dynamicServiceAccountResource.Status.NextSyncTime = getNextSyncTime(result, err)
+		globals.UpdateReadyCondition(&dynamicServiceAccountResource.Status.Conditions, dynamicServiceAccountResource.Generation)
+		statusErr := updateResourceStatus(ctx, r.Client, dynamicServiceAccountResource)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, DynamicServiceAccountResourceType, req.NamespacedName, statusErr.Error()))
+			result = ctrl.Result{}
+			err = errors.Join(err, statusErr)
+		}
+	}()
+
+	// 6. Schedule periodical request. Invalid times fall back to the default one, so the resource is still synchronized
+	RequeueTime, err := r.SyncSchedule.GetRequeueTime(dynamicServiceAccountResource.Spec.Synchronization)
+	if err != nil {
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, DynamicServiceAccountResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicServiceAccountResource, corev1.EventTypeWarning, globals.ConditionReasonInvalidSpecType, err.Error())
+		err = nil
+	}
+	result = ctrl.Result{
+		RequeueAfter: RequeueTime,
+	}
+
+	// 7. The Patch CR already exist: manage the update
+	syncStartTime := time.Now()
+	err = r.SyncTarget(ctx, dynamicServiceAccountResource)
+	syncDuration := time.Since(syncStartTime)
+	metrics.SyncDuration.WithLabelValues(DynamicServiceAccountResourceType, req.Namespace, req.Name).Observe(syncDuration.Seconds())
+	dynamicServiceAccountResource.Status.LastSyncDuration = getSyncDuration(syncDuration)
+	if err != nil {
+		metrics.SyncErrors.WithLabelValues(DynamicServiceAccountResourceType, req.Namespace, req.Name).Inc()
+		eventReason := r.UpdateConditionSyncFailure(dynamicServiceAccountResource, err)
+		logger.Info(fmt.Sprintf(syncTargetError, DynamicServiceAccountResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(dynamicServiceAccountResource, corev1.EventTypeWarning, eventReason, err.Error())
+
+		// Invalid specs wait for changes, while the rest of failures are retried with backoff
+		result, err = syncErrorResult(err)
+		return result, err
+	}
+
+	// 8. Success, update the status
+	dynamicServiceAccountResource.Status.ObservedGeneration = dynamicServiceAccountResource.Generation
+	r.UpdateConditionSuccess(dynamicServiceAccountResource)
+	r.Recorder.Event(dynamicServiceAccountResource, corev1.EventTypeNormal, eventReasonSynced, "Synced ServiceAccounts on targeted namespaces")
+
+	logger.Info(fmt.Sprintf(scheduleSynchronization, DynamicServiceAccountResourceType, req.NamespacedName, result.RequeueAfter.String()))
+
+	return result, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DynamicServiceAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// Generated ServiceAccounts are watched, so manual changes on them are reverted on the spot
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kuberbacv1alpha1.DynamicServiceAccount{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			propagatedAnnotationsChangedPredicate(r.PropagatedAnnotations),
+		))).
+		Watches(&corev1.ServiceAccount{}, handler.EnqueueRequestsFromMapFunc(ownerAnnotationsMapFunc(DynamicServiceAccountResourceType))).
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.RetryBaseDelay, r.RetryMaxDelay)}).
+		Complete(r)
+}
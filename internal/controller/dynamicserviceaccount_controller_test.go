@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+var _ = Describe("DynamicServiceAccount Controller", func() {
+
+	ctx := context.Background()
+
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": kuberbacv1alpha1.GroupVersion.String(),
+		"kuberbac.prosimcorp.com/owner-kind":       "DynamicServiceAccount",
+		"kuberbac.prosimcorp.com/owner-name":       "deployers",
+		"kuberbac.prosimcorp.com/owner-namespace":  "default",
+	}
+
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		}
+	}
+
+	serviceAccount := func(namespace, name string, annotations map[string]string) *corev1.ServiceAccount {
+		return &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Annotations: annotations},
+		}
+	}
+
+	newResource := func(namespaceSelector kuberbacv1alpha1.NamespaceSelectorT) *kuberbacv1alpha1.DynamicServiceAccount {
+		return &kuberbacv1alpha1.DynamicServiceAccount{
+			TypeMeta: metav1.TypeMeta{APIVersion: kuberbacv1alpha1.GroupVersion.String(), Kind: "DynamicServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deployers",
+				Namespace: "default",
+			},
+			Spec: kuberbacv1alpha1.DynamicServiceAccountSpec{
+				Targets: kuberbacv1alpha1.DynamicServiceAccountTargets{
+					Name:              "{{ .Namespace.Name }}-deployer",
+					NamespaceSelector: namespaceSelector,
+				},
+			},
+		}
+	}
+
+	newReconciler := func(objects ...client.Object) *DynamicServiceAccountReconciler {
+		fakeClient := newFakeApplyClient(append([]client.Object{
+			namespace("payments", map[string]string{"team": "payments"}),
+			namespace("shipping", map[string]string{"team": "shipping"}),
+			namespace("default", nil),
+		}, objects...)...)
+
+		return &DynamicServiceAccountReconciler{
+			Client:   fakeClient,
+			Scheme:   fakeClient.Scheme(),
+			Recorder: &record.FakeRecorder{},
+		}
+	}
+
+	expectNotFound := func(reconciler *DynamicServiceAccountReconciler, namespace, name string) {
+		err := reconciler.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &corev1.ServiceAccount{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue(), "ServiceAccount '%s/%s' should not exist", namespace, name)
+	}
+
+	It("should create the ServiceAccounts only in the selected namespaces", func() {
+		reconciler := newReconciler()
+		resource := newResource(kuberbacv1alpha1.NamespaceSelectorT{
+			MatchLabels: map[string]string{"team": "payments"},
+		})
+
+		Expect(reconciler.SyncTarget(ctx, resource)).To(Succeed())
+
+		generated := &corev1.ServiceAccount{}
+		Expect(reconciler.Client.Get(ctx, client.ObjectKey{Namespace: "payments", Name: "payments-deployer"}, generated)).To(Succeed())
+		Expect(generated.Annotations).To(Equal(referenceAnnotations))
+
+		expectNotFound(reconciler, "shipping", "shipping-deployer")
+		expectNotFound(reconciler, "default", "default-deployer")
+	})
+
+	It("should prune the owned ServiceAccounts of the namespaces not selected anymore", func() {
+		reconciler := newReconciler(
+			serviceAccount("payments", "payments-deployer", referenceAnnotations),
+			serviceAccount("shipping", "shipping-deployer", referenceAnnotations),
+			serviceAccount("shipping", "builder", nil),
+		)
+		resource := newResource(kuberbacv1alpha1.NamespaceSelectorT{MatchList: []string{"payments"}})
+
+		Expect(reconciler.SyncTarget(ctx, resource)).To(Succeed())
+
+		Expect(reconciler.Client.Get(ctx, client.ObjectKey{Namespace: "payments", Name: "payments-deployer"}, &corev1.ServiceAccount{})).To(Succeed())
+		Expect(reconciler.Client.Get(ctx, client.ObjectKey{Namespace: "shipping", Name: "builder"}, &corev1.ServiceAccount{})).To(Succeed())
+		expectNotFound(reconciler, "shipping", "shipping-deployer")
+	})
+
+	It("should leave untouched the existing ServiceAccounts not owned by the resource", func() {
+		unowned := serviceAccount("payments", "payments-deployer", map[string]string{"team": "payments"})
+		reconciler := newReconciler(unowned)
+		resource := newResource(kuberbacv1alpha1.NamespaceSelectorT{
+			MatchRegex: kuberbacv1alpha1.MatchRegexT{Expression: "^(payments|shipping)$"},
+		})
+
+		Expect(reconciler.SyncTarget(ctx, resource)).To(Succeed())
+
+		existing := &corev1.ServiceAccount{}
+		Expect(reconciler.Client.Get(ctx, client.ObjectKey{Namespace: "payments", Name: "payments-deployer"}, existing)).To(Succeed())
+		Expect(existing.Annotations).To(Equal(map[string]string{"team": "payments"}))
+
+		Expect(reconciler.Client.Get(ctx, client.ObjectKey{Namespace: "shipping", Name: "shipping-deployer"}, &corev1.ServiceAccount{})).To(Succeed())
+	})
+})
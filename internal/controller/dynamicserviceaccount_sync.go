@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"golang.org/x/exp/maps"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/globals"
+)
+
+// ServiceAccountTemplateData represents the data injected into the templates of a DynamicServiceAccount
+type ServiceAccountTemplateData struct {
+	// Namespace is the metadata of the namespace where the ServiceAccount is created
+	Namespace metav1.ObjectMeta
+
+	// Owner is the metadata of the DynamicServiceAccount that creates the ServiceAccount
+	Owner metav1.ObjectMeta
+}
+
+// SyncTarget call Kubernetes API to actually perform actions over the resource
+func (r *DynamicServiceAccountReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.DynamicServiceAccount) (err error) {
+
+	// Get all the namespaces and filter them by namespaceSelector later
+	namespaceList := &corev1.NamespaceList{}
+	err = r.Client.List(ctx, namespaceList)
+	if err != nil {
+		return err
+	}
+
+	targetFilteredNamespaces, err := FilterNamespaceListBySelector(namespaceList, &resource.Spec.Targets.NamespaceSelector)
+	if err != nil {
+		return fmt.Errorf("error selecting the namespaces of targets: %w", err)
+	}
+	targetFilteredNamespaces = RemoveSystemNamespaces(targetFilteredNamespaces,
+		resource.Spec.Targets.ExcludeSystemNamespaces, r.ExcludeSystemNamespaces)
+	targetFilteredNamespaces = RemoveExcludedNamespaces(targetFilteredNamespaces, namespaceList)
+	targetFilteredNamespaces = RemoveUnwatchedNamespaces(targetFilteredNamespaces, r.WatchNamespaces)
+
+	// Create a generic ServiceAccount structure
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	// Get ServiceAccounts to check the ownership of the existing ones later
+	existentServiceAccountList := corev1.ServiceAccountList{}
+	err = r.Client.List(ctx, &existentServiceAccountList)
+	if err != nil {
+		return err
+	}
+
+	// Upgrade already existing ServiceAccounts tracked only by reference annotations
+	for _, serviceAccount := range existentServiceAccountList.Items {
+		if !globals.IsSubset(referenceAnnotations, serviceAccount.Annotations) {
+			continue
+		}
+
+		err = adoptResource(ctx, r.Client, r.OwnershipMode, resource, &serviceAccount)
+		if err != nil {
+			return fmt.Errorf("error adopting ServiceAccount: %s", err.Error())
+		}
+	}
+
+	// Create the ServiceAccount resource on targeted namespaces.
+	// Desired ones are stored as 'namespace/name' to clean abandoned resources later
+	desiredServiceAccounts := []string{}
+	for _, namespace := range namespaceList.Items {
+
+		if !slices.Contains(targetFilteredNamespaces, namespace.Name) {
+			continue
+		}
+
+		templateData := ServiceAccountTemplateData{
+			Namespace: namespace.ObjectMeta,
+			Owner:     resource.ObjectMeta,
+		}
+
+		serviceAccountName, err := globals.RenderTemplate(resource.Spec.Targets.Name, templateData)
+		if err != nil {
+			return fmt.Errorf("error rendering name for namespace '%s': %s", namespace.Name, err.Error())
+		}
+
+		serviceAccountLabels, err := globals.RenderTemplateMap(resource.Spec.Targets.Labels, templateData)
+		if err != nil {
+			return fmt.Errorf("error rendering labels for namespace '%s': %s", namespace.Name, err.Error())
+		}
+
+		serviceAccountAnnotations, err := globals.RenderTemplateMap(resource.Spec.Targets.Annotations, templateData)
+		if err != nil {
+			return fmt.Errorf("error rendering annotations for namespace '%s': %s", namespace.Name, err.Error())
+		}
+		maps.Copy(serviceAccountAnnotations, referenceAnnotations)
+
+		desiredServiceAccounts = append(desiredServiceAccounts, namespace.Name+"/"+serviceAccountName)
+
+		// Check potential already existing ServiceAccounts that match the same name and namespace,
+		// but are not owned by this resource. They are never touched
+		serviceAccountFound := false
+		for _, serviceAccount := range existentServiceAccountList.Items {
+
+			if serviceAccount.Namespace != namespace.Name || serviceAccount.Name != serviceAccountName {
+				continue
+			}
+
+			if !globals.IsSubset(referenceAnnotations, serviceAccount.Annotations) {
+				serviceAccountFound = true
+				break
+			}
+		}
+
+		if serviceAccountFound {
+			log.FromContext(ctx).V(logLevelDecisions).Info("Namespace skipped: the ServiceAccount already exists and is not owned by this resource",
+				"namespace", namespace.Name, "serviceAccount", serviceAccountName)
+			continue
+		}
+
+		serviceAccountResource := corev1.ServiceAccount{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: corev1.SchemeGroupVersion.String(),
+				Kind:       "ServiceAccount",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        serviceAccountName,
+				Namespace:   namespace.Name,
+				Labels:      serviceAccountLabels,
+				Annotations: serviceAccountAnnotations,
+			},
+		}
+
+		err = setOwnerReference(r.OwnershipMode, resource, &serviceAccountResource, r.Scheme)
+		if err != nil {
+			return fmt.Errorf("error setting owner reference on ServiceAccount: %s", err.Error())
+		}
+		propagateAnnotations(resource, &serviceAccountResource, r.PropagatedAnnotations)
+
+		err = applyResource(ctx, r.Client, &serviceAccountResource)
+		if err != nil {
+			return fmt.Errorf("%w: error applying ServiceAccount: %s", errTargetWriteFailed, err.Error())
+		}
+	}
+
+	// Remove owned ServiceAccounts not defined in manifest
+	var allErrors []error
+	for _, serviceAccount := range existentServiceAccountList.Items {
+
+		if !globals.IsSubset(referenceAnnotations, serviceAccount.Annotations) ||
+			slices.Contains(desiredServiceAccounts, serviceAccount.Namespace+"/"+serviceAccount.Name) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &serviceAccount)
+		if err = client.IgnoreNotFound(err); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("%w: error deleting not needed ServiceAccount: %s", errTargetWriteFailed, err.Error()))
+			continue
+		}
+		log.FromContext(ctx).V(logLevelChanges).Info("ServiceAccount deleted: it is not targeted anymore",
+			"namespace", serviceAccount.Namespace, "serviceAccount", serviceAccount.Name)
+	}
+
+	return errors.Join(allErrors...)
+}
+
+// DeleteTargets deletes all the ServiceAccounts that are owned by the DynamicServiceAccount resource,
+// or orphans them when its deletion policy is 'Orphan'
+func (r *DynamicServiceAccountReconciler) DeleteTargets(ctx context.Context, resource *kuberbacv1alpha1.DynamicServiceAccount) (err error) {
+
+	var allErrors []error
+
+	// Create a generic ServiceAccount structure
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": resource.APIVersion,
+		"kuberbac.prosimcorp.com/owner-kind":       resource.Kind,
+		"kuberbac.prosimcorp.com/owner-name":       resource.ObjectMeta.Name,
+		"kuberbac.prosimcorp.com/owner-namespace":  resource.ObjectMeta.Namespace,
+	}
+
+	// Get ServiceAccount objects and release those with reference annotations
+	serviceAccountList := corev1.ServiceAccountList{}
+	err = r.Client.List(ctx, &serviceAccountList)
+	if err != nil {
+		return err
+	}
+
+	for _, serviceAccount := range serviceAccountList.Items {
+
+		if globals.IsSubset(referenceAnnotations, serviceAccount.Annotations) {
+			err = releaseResource(ctx, r.Client, resource.Spec.DeletionPolicy, resource, &serviceAccount, referenceAnnotations)
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error releasing ServiceAccount: %s", err.Error()))
+			}
+		}
+	}
+
+	return errors.Join(allErrors...)
+}
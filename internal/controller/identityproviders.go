@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+var (
+	// eksReservedPrefixes can not start the usernames and groups mapped by EKS access entries
+	eksReservedPrefixes = []string{"system:", "eks:", "aws:", "amazon:", "iam:"}
+
+	// iamARNRegex matches the ARNs of IAM users and roles, and of the STS sessions assuming them, on every partition
+	iamARNRegex = regexp.MustCompile(`^arn:aws(-[a-z]+)*:(iam|sts)::[0-9]{12}:[a-z-]+/.+$`)
+
+	// emailRegex matches email addresses, used as names of Google accounts and groups, and as user principal names
+	emailRegex = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+	// objectIDRegex matches the object IDs of Microsoft Entra ID
+	objectIDRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// CheckIdentityProviderName returns an error when the name of a User or Group subject does not follow
+// the identity conventions of a managed Kubernetes provider. Every name is valid when the provider is empty
+func CheckIdentityProviderName(identityProvider, kind, name string) (err error) {
+
+	valid, expected := true, ""
+	switch identityProvider {
+	case kuberbacv1alpha1.IdentityProviderEKS:
+		valid = !slices.ContainsFunc(eksReservedPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) &&
+			(!strings.HasPrefix(name, "arn:") || iamARNRegex.MatchString(name))
+		expected = "an IAM ARN, or a name not starting with " + strings.Join(eksReservedPrefixes, ", ")
+
+	case kuberbacv1alpha1.IdentityProviderGKE:
+		valid = emailRegex.MatchString(name)
+		expected = "the email of a Google account or group"
+
+	case kuberbacv1alpha1.IdentityProviderAKS:
+		valid = objectIDRegex.MatchString(name) || (kind == "User" && emailRegex.MatchString(name))
+		expected = "the object ID of a Microsoft Entra ID group"
+		if kind == "User" {
+			expected = "the object ID or the user principal name of a Microsoft Entra ID user"
+		}
+	}
+
+	if !valid {
+		err = fmt.Errorf("%w: %s '%s' is not valid for the identity provider %s: it must be %s",
+			errInvalidSpec, kind, name, identityProvider, expected)
+	}
+
+	return err
+}
+
+// RenderSubjectName returns the name of a User or Group subject selected by source.subject, prefixed with its namePrefix,
+// once checked against its identityProvider. Names already prefixed are checked without the prefix, and kept as they are
+func RenderSubjectName(subject *kuberbacv1alpha1.DynamicRoleBindingSourceSubject, name string) (result string, err error) {
+
+	name = strings.TrimPrefix(name, subject.NamePrefix)
+
+	err = CheckIdentityProviderName(subject.IdentityProvider, subject.Kind, name)
+	if err != nil {
+		return result, err
+	}
+
+	return subject.NamePrefix + name, err
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+const (
+	// DefaultJanitorInterval is the time between consecutive looks for orphaned objects
+	DefaultJanitorInterval = time.Hour
+)
+
+var (
+	// janitorKinds are the kinds of the objects generated by the controllers, carrying the reference annotations
+	janitorKinds = []janitorKindT{
+		{GVK: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}},
+		{GVK: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"}},
+		{GVK: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}, Namespaced: true},
+		{GVK: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}, Namespaced: true},
+		{GVK: schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ServiceAccount"}, Namespaced: true},
+		{GVK: schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}, Namespaced: true},
+		{GVK: schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingAdmissionPolicy"}},
+		{GVK: schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingAdmissionPolicyBinding"}},
+	}
+
+	// janitorOwnerKinds are the kinds of the resources generating objects. Objects claiming other owners are ignored
+	janitorOwnerKinds = []string{
+		DynamicAccessResourceType,
+		DynamicClusterRoleResourceType,
+		DynamicRoleBindingResourceType,
+		DynamicServiceAccountResourceType,
+	}
+)
+
+// janitorKindT represents a kind of object generated by the controllers
+type janitorKindT struct {
+	GVK        schema.GroupVersionKind
+	Namespaced bool
+}
+
+// OrphanJanitor deletes periodically the objects generated by the controllers whose owner does not exist anymore.
+// Owners release their objects through finalizers, which are skipped when they are removed by hand,
+// or when the operator is down while the owners are deleted. Orphaned objects on purpose, by the 'Orphan'
+// deletion policy, lose their reference annotations, so they are never touched
+type OrphanJanitor struct {
+	Client client.Client
+
+	// Reader lists the objects from the API server, as generated objects are not always cached
+	Reader client.Reader
+
+	Interval time.Duration
+
+	// WatchNamespaces restricts the namespaces where namespaced objects are looked for. All of them are used when empty
+	WatchNamespaces []string
+}
+
+// Start looks for orphaned objects until the context is cancelled. It implements manager.Runnable
+func (j *OrphanJanitor) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("janitor")
+
+	interval := j.Interval
+	if interval <= 0 {
+		interval = DefaultJanitorInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := j.PruneOrphans(log.IntoContext(ctx, logger))
+		if err != nil {
+			logger.Info("Failed to prune orphaned objects", "error", err.Error())
+		}
+		logger.V(logLevelDecisions).Info("Orphaned objects pruned", "deleted", deleted, "nextRun", interval.String())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns true, so only the leader deletes objects
+func (j *OrphanJanitor) NeedLeaderElection() bool {
+	return true
+}
+
+// PruneOrphans deletes the objects whose owner, read from the reference annotations, does not exist.
+// Owners are read from the API server, so recently created ones are never missed by a stale cache
+func (j *OrphanJanitor) PruneOrphans(ctx context.Context) (deleted int, err error) {
+	logger := log.FromContext(ctx)
+
+	// Owners are checked once per run, as they usually generate several objects
+	existentOwners := map[string]bool{}
+	ownerExists := func(kind, namespace, name string) (bool, error) {
+		key := kind + "/" + namespace + "/" + name
+		if exists, checked := existentOwners[key]; checked {
+			return exists, nil
+		}
+
+		owner := &metav1.PartialObjectMetadata{}
+		owner.SetGroupVersionKind(kuberbacv1alpha1.GroupVersion.WithKind(kind))
+		err := j.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, owner)
+		if client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+
+		existentOwners[key] = err == nil
+		return existentOwners[key], nil
+	}
+
+	allErrors := []error{}
+	for _, janitorKind := range janitorKinds {
+		gvk := janitorKind.GVK
+
+		namespaces := []string{""}
+		if janitorKind.Namespaced && len(j.WatchNamespaces) > 0 {
+			namespaces = j.WatchNamespaces
+		}
+
+		for _, namespace := range namespaces {
+			objectList := &metav1.PartialObjectMetadataList{}
+			objectList.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+			listOptions := []client.ListOption{}
+			if namespace != "" {
+				listOptions = append(listOptions, client.InNamespace(namespace))
+			}
+
+			err = listInPages(ctx, j.Reader, objectList, func() error {
+				for _, object := range objectList.Items {
+
+					annotations := object.GetAnnotations()
+					ownerKind := annotations["kuberbac.prosimcorp.com/owner-kind"]
+					ownerName := annotations["kuberbac.prosimcorp.com/owner-name"]
+					if ownerName == "" || !slices.Contains(janitorOwnerKinds, ownerKind) {
+						continue
+					}
+
+					exists, err := ownerExists(ownerKind, annotations["kuberbac.prosimcorp.com/owner-namespace"], ownerName)
+					if err != nil {
+						allErrors = append(allErrors, fmt.Errorf("error getting owner of %s '%s': %s",
+							gvk.Kind, client.ObjectKeyFromObject(&object), err.Error()))
+						continue
+					}
+					if exists {
+						continue
+					}
+
+					object.SetGroupVersionKind(gvk)
+					err = j.Client.Delete(ctx, &object)
+					if err = client.IgnoreNotFound(err); err != nil {
+						allErrors = append(allErrors, fmt.Errorf("error deleting orphaned %s '%s': %s",
+							gvk.Kind, client.ObjectKeyFromObject(&object), err.Error()))
+						continue
+					}
+					deleted++
+					logger.V(logLevelChanges).Info("Orphaned object deleted: its owner does not exist anymore",
+						"kind", gvk.Kind, "object", client.ObjectKeyFromObject(&object).String(),
+						"ownerKind", ownerKind, "owner", annotations["kuberbac.prosimcorp.com/owner-namespace"]+"/"+ownerName)
+				}
+				return nil
+			}, listOptions...)
+
+			// Admission policies are not served by old clusters
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error listing %s: %s", gvk.Kind, err.Error()))
+			}
+		}
+	}
+
+	return deleted, errors.Join(allErrors...)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+var _ = Describe("Orphan janitor", func() {
+	Context("When looking for orphaned objects", func() {
+
+		ctx := context.Background()
+
+		ownerAnnotations := func(ownerName string) map[string]string {
+			return map[string]string{
+				"kuberbac.prosimcorp.com/owner-apiversion": kuberbacv1alpha1.GroupVersion.String(),
+				"kuberbac.prosimcorp.com/owner-kind":       DynamicClusterRoleResourceType,
+				"kuberbac.prosimcorp.com/owner-name":       ownerName,
+				"kuberbac.prosimcorp.com/owner-namespace":  "default",
+			}
+		}
+
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, &kuberbacv1alpha1.DynamicClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "janitor-owner", Namespace: "default"},
+				Spec: kuberbacv1alpha1.DynamicClusterRoleSpec{
+					Target: kuberbacv1alpha1.TargetT{Name: "janitor-owned"},
+					Deny:   []kuberbacv1alpha1.DenyPolicyRuleT{},
+				},
+			})).To(Succeed())
+
+			for _, clusterRole := range []rbacv1.ClusterRole{
+				{ObjectMeta: metav1.ObjectMeta{Name: "janitor-owned", Annotations: ownerAnnotations("janitor-owner")}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "janitor-orphaned", Annotations: ownerAnnotations("janitor-deleted-owner")}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "janitor-unmanaged"}},
+			} {
+				Expect(k8sClient.Create(ctx, &clusterRole)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.DynamicClusterRole{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "janitor-owner", Namespace: "default"}, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			for _, name := range []string{"janitor-owned", "janitor-orphaned", "janitor-unmanaged"} {
+				err := k8sClient.Delete(ctx, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}})
+				Expect(errors.IsNotFound(err) || err == nil).To(BeTrue())
+			}
+		})
+
+		It("should only delete the objects whose owner does not exist", func() {
+			janitor := &OrphanJanitor{Client: k8sClient, Reader: k8sClient}
+
+			_, err := janitor.PruneOrphans(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "janitor-orphaned"}, &rbacv1.ClusterRole{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "janitor-owned"}, &rbacv1.ClusterRole{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "janitor-unmanaged"}, &rbacv1.ClusterRole{})).To(Succeed())
+		})
+	})
+})
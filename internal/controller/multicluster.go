@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+)
+
+// listObjects returns every object of the given list types
+func listObjects(ctx context.Context, c client.Reader, lists ...client.ObjectList) (objects []client.Object, err error) {
+
+	for _, list := range lists {
+		list = list.DeepCopyObject().(client.ObjectList)
+		err = c.List(ctx, list)
+		if err != nil {
+			return objects, err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return objects, err
+		}
+
+		for _, item := range items {
+			objects = append(objects, item.(client.Object))
+		}
+	}
+
+	return objects, err
+}
+
+// desiredObjectsFunc returns the objects desired on a member cluster. They may differ between members,
+// e.g. when rules are expanded against the resources available on each one of them
+type desiredObjectsFunc func(ctx context.Context, memberCluster multicluster.MemberCluster) ([]client.Object, error)
+
+// propagateTargets mirrors the desired objects generated by an owner onto every member cluster of the inventory.
+// They are applied on the members, and those carrying the reference annotations of the owner but not desired anymore
+// are deleted from them, looking for the given list types. The status of each member cluster is returned,
+// keeping the time of its last successful propagation from the previous one. Failures on a member cluster
+// do not stop the propagation onto the rest of them
+func propagateTargets(ctx context.Context, inventory *multicluster.Inventory, referenceAnnotations map[string]string,
+	desiredObjects desiredObjectsFunc, lists []client.ObjectList,
+	previousStatus []kuberbacv1alpha1.MemberClusterStatusT) (status []kuberbacv1alpha1.MemberClusterStatusT, err error) {
+
+	if inventory == nil {
+		return status, err
+	}
+
+	memberClusters, err := inventory.ListMemberClusters(ctx)
+	if err != nil {
+		return previousStatus, err
+	}
+
+	return propagateToMemberClusters(ctx, memberClusters, referenceAnnotations, desiredObjects, lists, previousStatus)
+}
+
+// propagateToMemberClusters mirrors the desired objects onto the given member clusters, returning the status of each one
+func propagateToMemberClusters(ctx context.Context, memberClusters []multicluster.MemberCluster, referenceAnnotations map[string]string,
+	desiredObjects desiredObjectsFunc, lists []client.ObjectList,
+	previousStatus []kuberbacv1alpha1.MemberClusterStatusT) (status []kuberbacv1alpha1.MemberClusterStatusT, err error) {
+
+	var allErrors []error
+	for _, memberCluster := range memberClusters {
+
+		memberClusterStatus := kuberbacv1alpha1.MemberClusterStatusT{Name: memberCluster.Name}
+		previousIndex := slices.IndexFunc(previousStatus, func(previous kuberbacv1alpha1.MemberClusterStatusT) bool {
+			return previous.Name == memberCluster.Name
+		})
+		if previousIndex != -1 {
+			memberClusterStatus.LastSyncTime = previousStatus[previousIndex].LastSyncTime
+		}
+
+		err = memberCluster.Err
+		var memberObjects []client.Object
+		if err == nil {
+			memberObjects, err = desiredObjects(ctx, memberCluster)
+		}
+		if err == nil {
+			err = mirrorObjects(ctx, memberCluster.Client, referenceAnnotations, memberObjects, lists)
+		}
+
+		if err != nil {
+			memberClusterStatus.Message = err.Error()
+			allErrors = append(allErrors, fmt.Errorf("member cluster '%s': %w", memberCluster.Name, err))
+		} else {
+			memberClusterStatus.Synced = true
+			memberClusterStatus.LastSyncTime = &metav1.Time{Time: metav1.Now().Time}
+		}
+
+		status = append(status, memberClusterStatus)
+	}
+
+	return status, errors.Join(allErrors...)
+}
+
+// mirrorObjects applies the desired objects on a member cluster, and deletes from it the objects of the given list types
+// carrying the reference annotations but not desired anymore. Metadata only meaningful on the management cluster,
+// such as owner references, is dropped. Namespaced objects are only applied when their namespace exists on the member.
+// Objects already existing on the member without the reference annotations are never overwritten: they are skipped
+// and reported as ownership conflicts, the same way as on the management cluster
+func mirrorObjects(ctx context.Context, c client.Client, referenceAnnotations map[string]string,
+	desiredObjects []client.Object, lists []client.ObjectList) (err error) {
+
+	objectKey := func(object client.Object) (key string, err error) {
+		gvk, err := apiutil.GVKForObject(object, c.Scheme())
+		if err != nil {
+			return key, err
+		}
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+		return gvk.Kind + "/" + object.GetNamespace() + "/" + object.GetName(), err
+	}
+
+	// Read the objects existing on the member cluster, to know which ones are owned
+	existentObjects, err := listObjects(ctx, c, lists...)
+	if err != nil {
+		return err
+	}
+
+	existentAnnotations := map[string]map[string]string{}
+	for _, existentObject := range existentObjects {
+		key, err := objectKey(existentObject)
+		if err != nil {
+			return err
+		}
+		existentAnnotations[key] = existentObject.GetAnnotations()
+	}
+
+	var allErrors []error
+	conflictingObjects := []string{}
+	existentNamespaces := map[string]bool{}
+	desiredKeys := map[string]struct{}{}
+	for _, desiredObject := range desiredObjects {
+
+		memberObject := desiredObject.DeepCopyObject().(client.Object)
+		memberObject.SetResourceVersion("")
+		memberObject.SetUID("")
+		memberObject.SetGeneration(0)
+		memberObject.SetCreationTimestamp(metav1.Time{})
+		memberObject.SetManagedFields(nil)
+		memberObject.SetOwnerReferences(nil)
+
+		if namespace := memberObject.GetNamespace(); namespace != "" {
+			exists, found := existentNamespaces[namespace]
+			if !found {
+				err = c.Get(ctx, client.ObjectKey{Name: namespace}, &corev1.Namespace{})
+				if client.IgnoreNotFound(err) != nil {
+					return errors.Join(append(allErrors, err)...)
+				}
+				exists = err == nil
+				existentNamespaces[namespace] = exists
+			}
+
+			if !exists {
+				continue
+			}
+		}
+
+		key, err := objectKey(memberObject)
+		if err != nil {
+			return errors.Join(append(allErrors, err)...)
+		}
+		desiredKeys[key] = struct{}{}
+
+		annotations, exists := existentAnnotations[key]
+		if exists && !globals.IsSubset(referenceAnnotations, annotations) {
+			conflictingObject := memberObject.GetName()
+			if memberObject.GetNamespace() != "" {
+				conflictingObject = memberObject.GetNamespace() + "/" + conflictingObject
+			}
+			conflictingObjects = append(conflictingObjects,
+				fmt.Sprintf("%s '%s'", memberObject.GetObjectKind().GroupVersionKind().Kind, conflictingObject))
+			continue
+		}
+
+		err = applyResource(ctx, c, memberObject)
+		if err != nil {
+			allErrors = append(allErrors, fmt.Errorf("error applying %s '%s': %s", memberObject.GetObjectKind().GroupVersionKind().Kind,
+				client.ObjectKeyFromObject(memberObject).String(), err.Error()))
+		}
+	}
+
+	if len(conflictingObjects) > 0 {
+		allErrors = append(allErrors, fmt.Errorf("%w: objects already exist and are not owned by this resource: %s",
+			errTargetOwnershipConflict, strings.Join(conflictingObjects, ", ")))
+	}
+
+	// Delete the objects generated by the owner on the member cluster and not desired anymore
+	for _, existentObject := range existentObjects {
+		if !globals.IsSubset(referenceAnnotations, existentObject.GetAnnotations()) {
+			continue
+		}
+
+		key, err := objectKey(existentObject)
+		if err != nil {
+			return errors.Join(append(allErrors, err)...)
+		}
+
+		if _, desired := desiredKeys[key]; desired {
+			continue
+		}
+
+		err = c.Delete(ctx, existentObject)
+		if client.IgnoreNotFound(err) != nil {
+			allErrors = append(allErrors, fmt.Errorf("error deleting %s '%s': %s", existentObject.GetObjectKind().GroupVersionKind().Kind,
+				client.ObjectKeyFromObject(existentObject).String(), err.Error()))
+		}
+	}
+
+	return errors.Join(allErrors...)
+}
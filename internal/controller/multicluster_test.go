@@ -0,0 +1,220 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/multicluster"
+)
+
+var _ = Describe("Propagation to member clusters", func() {
+
+	ctx := context.Background()
+
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": "kuberbac.prosimcorp.com/v1alpha1",
+		"kuberbac.prosimcorp.com/owner-kind":       DynamicRoleBindingResourceType,
+		"kuberbac.prosimcorp.com/owner-name":       "developers",
+		"kuberbac.prosimcorp.com/owner-namespace":  "default",
+	}
+	lists := []client.ObjectList{&rbacv1.ClusterRoleBindingList{}, &rbacv1.RoleBindingList{}}
+
+	subjects := []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "developers"}}
+
+	clusterRoleBinding := func(name string, annotations map[string]string) *rbacv1.ClusterRoleBinding {
+		return &rbacv1.ClusterRoleBinding{
+			TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: annotations,
+			},
+			RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+			Subjects: subjects,
+		}
+	}
+
+	roleBinding := func(namespace, name string, annotations map[string]string) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: annotations,
+			},
+			RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+			Subjects: subjects,
+		}
+	}
+
+	namespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	desiredObjectsOf := func(objects ...client.Object) desiredObjectsFunc {
+		return func(ctx context.Context, memberCluster multicluster.MemberCluster) ([]client.Object, error) {
+			return objects, nil
+		}
+	}
+
+	It("should apply the desired objects on every member cluster without management metadata", func() {
+		desiredBinding := clusterRoleBinding("developers-view", referenceAnnotations)
+		desiredBinding.UID = "management-uid"
+		desiredBinding.ResourceVersion = "42"
+		desiredBinding.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "kuberbac.prosimcorp.com/v1alpha1", Kind: DynamicRoleBindingResourceType, Name: "developers", UID: "owner-uid",
+		}}
+
+		memberClusters := []multicluster.MemberCluster{
+			{Name: "europe", Client: newFakeApplyClient()},
+			{Name: "america", Client: newFakeApplyClient()},
+		}
+
+		status, err := propagateToMemberClusters(ctx, memberClusters, referenceAnnotations,
+			desiredObjectsOf(desiredBinding), lists, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(HaveLen(2))
+
+		for index, memberCluster := range memberClusters {
+			Expect(status[index].Name).To(Equal(memberCluster.Name))
+			Expect(status[index].Synced).To(BeTrue())
+			Expect(status[index].LastSyncTime).NotTo(BeNil())
+
+			memberBinding := &rbacv1.ClusterRoleBinding{}
+			Expect(memberCluster.Client.Get(ctx, client.ObjectKey{Name: "developers-view"}, memberBinding)).To(Succeed())
+			Expect(memberBinding.OwnerReferences).To(BeEmpty())
+			Expect(memberBinding.UID).NotTo(Equal(types.UID("management-uid")))
+			Expect(memberBinding.Subjects).To(Equal(subjects))
+		}
+	})
+
+	It("should ask for the desired objects of each member cluster", func() {
+		memberClusters := []multicluster.MemberCluster{
+			{Name: "europe", Client: newFakeApplyClient()},
+			{Name: "america", Client: newFakeApplyClient()},
+		}
+
+		desiredObjects := func(ctx context.Context, memberCluster multicluster.MemberCluster) ([]client.Object, error) {
+			return []client.Object{clusterRoleBinding(memberCluster.Name+"-view", referenceAnnotations)}, nil
+		}
+
+		_, err := propagateToMemberClusters(ctx, memberClusters, referenceAnnotations, desiredObjects, lists, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(memberClusters[0].Client.Get(ctx, client.ObjectKey{Name: "europe-view"}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+		Expect(memberClusters[1].Client.Get(ctx, client.ObjectKey{Name: "america-view"}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+
+		err = memberClusters[1].Client.Get(ctx, client.ObjectKey{Name: "europe-view"}, &rbacv1.ClusterRoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should only apply RoleBindings in the namespaces existing on the member cluster", func() {
+		memberClient := newFakeApplyClient(namespace("payments"))
+		memberClusters := []multicluster.MemberCluster{{Name: "europe", Client: memberClient}}
+
+		status, err := propagateToMemberClusters(ctx, memberClusters, referenceAnnotations, desiredObjectsOf(
+			roleBinding("payments", "developers-edit", referenceAnnotations),
+			roleBinding("shipping", "developers-edit", referenceAnnotations),
+		), lists, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status[0].Synced).To(BeTrue())
+
+		Expect(memberClient.Get(ctx, client.ObjectKey{Namespace: "payments", Name: "developers-edit"}, &rbacv1.RoleBinding{})).To(Succeed())
+		err = memberClient.Get(ctx, client.ObjectKey{Namespace: "shipping", Name: "developers-edit"}, &rbacv1.RoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should skip and report the objects existing on the member cluster without being owned", func() {
+		unownedBinding := clusterRoleBinding("developers-view", map[string]string{"team": "platform"})
+		unownedBinding.Subjects = []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "platform"}}
+
+		memberClient := newFakeApplyClient(unownedBinding)
+		memberClusters := []multicluster.MemberCluster{{Name: "europe", Client: memberClient}}
+
+		status, err := propagateToMemberClusters(ctx, memberClusters, referenceAnnotations, desiredObjectsOf(
+			clusterRoleBinding("developers-view", referenceAnnotations),
+			clusterRoleBinding("developers-view-extra", referenceAnnotations),
+		), lists, nil)
+		Expect(err).To(MatchError(errTargetOwnershipConflict))
+		Expect(err.Error()).To(ContainSubstring("ClusterRoleBinding 'developers-view'"))
+		Expect(status[0].Synced).To(BeFalse())
+		Expect(status[0].Message).To(ContainSubstring("developers-view"))
+
+		// The unowned object is neither overwritten nor deleted, while the rest are applied
+		memberBinding := &rbacv1.ClusterRoleBinding{}
+		Expect(memberClient.Get(ctx, client.ObjectKey{Name: "developers-view"}, memberBinding)).To(Succeed())
+		Expect(memberBinding.Annotations).To(Equal(map[string]string{"team": "platform"}))
+		Expect(memberBinding.Subjects[0].Name).To(Equal("platform"))
+
+		Expect(memberClient.Get(ctx, client.ObjectKey{Name: "developers-view-extra"}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+	})
+
+	It("should delete the owned objects not desired anymore from the member cluster", func() {
+		memberClient := newFakeApplyClient(
+			namespace("payments"),
+			clusterRoleBinding("developers-view", referenceAnnotations),
+			clusterRoleBinding("developers-view-stale", referenceAnnotations),
+			clusterRoleBinding("platform-view", map[string]string{"team": "platform"}),
+			roleBinding("payments", "developers-edit", referenceAnnotations),
+		)
+		memberClusters := []multicluster.MemberCluster{{Name: "europe", Client: memberClient}}
+
+		_, err := propagateToMemberClusters(ctx, memberClusters, referenceAnnotations, desiredObjectsOf(
+			clusterRoleBinding("developers-view", referenceAnnotations),
+		), lists, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(memberClient.Get(ctx, client.ObjectKey{Name: "developers-view"}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+		Expect(memberClient.Get(ctx, client.ObjectKey{Name: "platform-view"}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+
+		err = memberClient.Get(ctx, client.ObjectKey{Name: "developers-view-stale"}, &rbacv1.ClusterRoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		err = memberClient.Get(ctx, client.ObjectKey{Namespace: "payments", Name: "developers-edit"}, &rbacv1.RoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should keep propagating when a member cluster is unreachable", func() {
+		lastSyncTime := &metav1.Time{Time: metav1.Now().Add(-time.Hour)}
+		previousStatus := []kuberbacv1alpha1.MemberClusterStatusT{{Name: "europe", Synced: true, LastSyncTime: lastSyncTime}}
+
+		memberClusters := []multicluster.MemberCluster{
+			{Name: "europe", Err: errors.New("kubeconfig is not valid")},
+			{Name: "america", Client: newFakeApplyClient()},
+		}
+
+		status, err := propagateToMemberClusters(ctx, memberClusters, referenceAnnotations,
+			desiredObjectsOf(clusterRoleBinding("developers-view", referenceAnnotations)), lists, previousStatus)
+		Expect(err).To(MatchError(ContainSubstring("member cluster 'europe': kubeconfig is not valid")))
+
+		Expect(status[0].Synced).To(BeFalse())
+		Expect(status[0].LastSyncTime).To(Equal(lastSyncTime))
+		Expect(status[1].Synced).To(BeTrue())
+		Expect(memberClusters[1].Client.Get(ctx, client.ObjectKey{Name: "developers-view"}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+	})
+})
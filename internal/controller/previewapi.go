@@ -0,0 +1,452 @@
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+const (
+	// PreviewAPIRenderPath returns the RBAC a manifest would generate
+	PreviewAPIRenderPath = "/render"
+
+	// PreviewAPIExplainPath returns why a manifest generates it: the sources of each rule for DynamicClusterRoles,
+	// and the decision taken for each evaluated namespace and ServiceAccount for DynamicRoleBindings
+	PreviewAPIExplainPath = "/explain"
+
+	// PreviewAPIUserHeader carries the user of the portal previewing a DynamicRoleBinding. Its permissions are reviewed
+	// before disclosing the namespaces and ServiceAccounts selected, so it must be set by the portal, never by its users
+	PreviewAPIUserHeader = "X-Remote-User"
+
+	// PreviewAPIGroupHeader carries the groups of the user previewing a DynamicRoleBinding. It can be repeated
+	PreviewAPIGroupHeader = "X-Remote-Group"
+
+	// maxPreviewAPIBodyBytes limits the size of the manifests received
+	maxPreviewAPIBodyBytes = 1024 * 1024
+
+	// selfSignedCertificateValidity is the validity of the certificate generated when no CertDir is set.
+	// It is generated again on each start of the operator
+	selfSignedCertificateValidity = 365 * 24 * time.Hour
+)
+
+var (
+	// errPreviewForbidden is returned when the caller is not allowed to see what a preview would disclose
+	errPreviewForbidden = errors.New("preview forbidden")
+)
+
+// PreviewCallerT is the identity of the user previewing a manifest through the portal
+type PreviewCallerT struct {
+	User   string
+	Groups []string
+}
+
+// PreviewResponseT is the body of the successful responses of the preview API.
+// Only the fields related to the kind of the manifest and the path requested are set
+type PreviewResponseT struct {
+	ClusterRoles []rbacv1.ClusterRole `json:"clusterRoles,omitempty"`
+	Rules        []RuleExplanationT   `json:"rules,omitempty"`
+
+	Subjects   []rbacv1.Subject                      `json:"subjects,omitempty"`
+	Namespaces []string                              `json:"namespaces,omitempty"`
+	Decisions  []kuberbacv1alpha1.SelectionDecisionT `json:"decisions,omitempty"`
+}
+
+// PreviewAPIServer serves an HTTP API rendering the manifests of DynamicClusterRoles and DynamicRoleBindings
+// without creating them, so portals can preview their RBAC without giving users access to the cluster.
+// Requests must carry the token as 'Authorization: Bearer <token>', so the API is always served with TLS,
+// reading the files 'tls.crt' and 'tls.key' from CertDir, or generating a self-signed certificate when it is not set.
+// Previews of DynamicRoleBindings must carry the user of the portal too, as they disclose objects of the cluster
+type PreviewAPIServer struct {
+	BindAddress string
+	CertDir     string
+	Token       string
+
+	// Manifests are rendered with the settings of the reconcilers, the same way as synchronizing them
+	DynamicClusterRoleReconciler *DynamicClusterRoleReconciler
+	DynamicRoleBindingReconciler *DynamicRoleBindingReconciler
+}
+
+// Start serves the API until the context is cancelled. It implements manager.Runnable
+func (s *PreviewAPIServer) Start(ctx context.Context) error {
+
+	logger := log.FromContext(ctx).WithName("preview-api")
+
+	if s.Token == "" {
+		return fmt.Errorf("a token is required to serve the preview API")
+	}
+
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return log.IntoContext(ctx, logger)
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Serving preview API", "address", s.BindAddress, "paths", []string{PreviewAPIRenderPath, PreviewAPIExplainPath})
+
+	certFile, keyFile := "", ""
+	if s.CertDir != "" {
+		certFile, keyFile = filepath.Join(s.CertDir, "tls.crt"), filepath.Join(s.CertDir, "tls.key")
+	} else {
+		certificate, err := generateSelfSignedCertificate()
+		if err != nil {
+			return fmt.Errorf("error generating the self-signed certificate of the preview API: %s", err.Error())
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+		logger.Info("No certificate directory set: serving preview API with a self-signed certificate")
+	}
+
+	err := server.ListenAndServeTLS(certFile, keyFile)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// generateSelfSignedCertificate returns a certificate for the hostname of the operator and localhost,
+// signed by its own key. Clients must skip its verification, or trust it explicitly
+func generateSelfSignedCertificate() (certificate tls.Certificate, err error) {
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return certificate, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return certificate, err
+	}
+
+	dnsNames := []string{"localhost"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		dnsNames = append(dnsNames, hostname)
+	}
+
+	notBefore := time.Now().Add(-time.Hour)
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "kuberbac-preview-api"},
+		DNSNames:              dnsNames,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(selfSignedCertificateValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	certificateBytes, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return certificate, err
+	}
+
+	certificate.Certificate = [][]byte{certificateBytes}
+	certificate.PrivateKey = privateKey
+	certificate.Leaf, err = x509.ParseCertificate(certificateBytes)
+	return certificate, err
+}
+
+// NeedLeaderElection returns false, so previews are served on every replica
+func (s *PreviewAPIServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the handler serving the paths of the API
+func (s *PreviewAPIServer) Handler() http.Handler {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PreviewAPIRenderPath, func(response http.ResponseWriter, request *http.Request) {
+		s.servePreview(response, request, false)
+	})
+	mux.HandleFunc(PreviewAPIExplainPath, func(response http.ResponseWriter, request *http.Request) {
+		s.servePreview(response, request, true)
+	})
+
+	return mux
+}
+
+// servePreview renders the manifest posted in the body, as YAML or JSON, and writes the result as JSON
+func (s *PreviewAPIServer) servePreview(response http.ResponseWriter, request *http.Request, explain bool) {
+
+	token, found := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+		writePreviewError(response, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+
+	if request.Method != http.MethodPost {
+		writePreviewError(response, http.StatusMethodNotAllowed, "only POST is allowed")
+		return
+	}
+
+	manifest, err := io.ReadAll(http.MaxBytesReader(response, request.Body, maxPreviewAPIBodyBytes))
+	if err != nil {
+		writePreviewError(response, http.StatusBadRequest, "error reading manifest: "+err.Error())
+		return
+	}
+
+	typeMeta := metav1.TypeMeta{}
+	err = yaml.Unmarshal(manifest, &typeMeta)
+	if err != nil {
+		writePreviewError(response, http.StatusBadRequest, "error decoding manifest: "+err.Error())
+		return
+	}
+
+	if typeMeta.APIVersion != kuberbacv1alpha1.GroupVersion.String() {
+		writePreviewError(response, http.StatusBadRequest,
+			fmt.Sprintf("unsupported apiVersion '%s': only %s is accepted", typeMeta.APIVersion, kuberbacv1alpha1.GroupVersion.String()))
+		return
+	}
+
+	ctx := request.Context()
+	result := PreviewResponseT{}
+	switch typeMeta.Kind {
+	case DynamicClusterRoleResourceType:
+		resource := &kuberbacv1alpha1.DynamicClusterRole{}
+		err = yaml.UnmarshalStrict(manifest, resource)
+		if err != nil {
+			writePreviewError(response, http.StatusBadRequest, "error decoding DynamicClusterRole: "+err.Error())
+			return
+		}
+		result, err = s.PreviewDynamicClusterRole(ctx, resource, explain)
+
+	case DynamicRoleBindingResourceType:
+		resource := &kuberbacv1alpha1.DynamicRoleBinding{}
+		err = yaml.UnmarshalStrict(manifest, resource)
+		if err != nil {
+			writePreviewError(response, http.StatusBadRequest, "error decoding DynamicRoleBinding: "+err.Error())
+			return
+		}
+		caller := PreviewCallerT{
+			User:   request.Header.Get(PreviewAPIUserHeader),
+			Groups: request.Header.Values(PreviewAPIGroupHeader),
+		}
+		result, err = s.PreviewDynamicRoleBinding(ctx, resource, caller, explain)
+
+	default:
+		writePreviewError(response, http.StatusBadRequest,
+			fmt.Sprintf("unsupported kind '%s': only %s and %s are accepted", typeMeta.Kind,
+				DynamicClusterRoleResourceType, DynamicRoleBindingResourceType))
+		return
+	}
+
+	// Mistakes on the manifest are told apart from failures of the operator, so portals can show them to the users
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errPreviewForbidden) {
+			status = http.StatusForbidden
+		}
+		if errors.Is(err, errInvalidSpec) || errors.Is(err, errInvalidSelector) || errors.Is(err, errInvalidRegex) {
+			status = http.StatusUnprocessableEntity
+		}
+		writePreviewError(response, status, err.Error())
+		return
+	}
+
+	log.FromContext(ctx).V(logLevelDecisions).Info("Manifest previewed", "kind", typeMeta.Kind, "explain", explain)
+	writePreviewJSON(response, http.StatusOK, result)
+}
+
+// PreviewDynamicClusterRole renders the ClusterRoles generated by a DynamicClusterRole, or the explanation of their rules.
+// Nothing is read from the cluster apart from the resources it serves and the protection policies, as it would be
+// disclosed through the rendered rules: values are never read from ConfigMaps or Secrets, rules are not imported
+// from existing ClusterRoles, namespaces and ServiceAccounts are not selected, and no rule is expanded into the names
+// of existing objects. Manifests needing any of them are rejected, including those where names denied by protection
+// policies would leave out some objects of a resource allowed as a whole. As when object listing is disabled,
+// self-protection does not deny the objects of the identity of the operator by name
+func (s *PreviewAPIServer) PreviewDynamicClusterRole(ctx context.Context, resource *kuberbacv1alpha1.DynamicClusterRole,
+	explain bool) (result PreviewResponseT, err error) {
+
+	if len(resource.Spec.ValuesFrom) > 0 {
+		return result, fmt.Errorf("%w: valuesFrom is not allowed on previews", errInvalidSpec)
+	}
+
+	if len(resource.Spec.From) > 0 {
+		return result, fmt.Errorf("%w: from is not allowed on previews", errInvalidSpec)
+	}
+
+	if resource.Spec.NamespaceSelector != nil {
+		return result, fmt.Errorf("%w: namespaceSelector is not allowed on previews", errInvalidSpec)
+	}
+
+	for _, denyRule := range resource.Spec.Deny {
+		if len(denyRule.ResourceNames) > 0 || denyRule.ObjectSelector != nil {
+			return result, fmt.Errorf("%w: deny rules by resourceNames or objectSelector are not allowed on previews", errInvalidSpec)
+		}
+	}
+
+	if resource.Spec.AllowImpersonate != nil {
+		for _, serviceAccount := range resource.Spec.AllowImpersonate.ServiceAccounts {
+			if serviceAccount.Selector != nil {
+				return result, fmt.Errorf("%w: allowImpersonate.serviceAccounts selected by selector are not allowed on previews", errInvalidSpec)
+			}
+		}
+	}
+
+	reconciler := s.DynamicClusterRoleReconciler
+	resource.Spec.Explain = explain
+
+	// Objects are never listed, so the special cases expanding allowed resources into the names of their objects
+	// are rejected instead of evaluated
+	objectListing := reconciler.ObjectListing
+	objectListing.Disabled = true
+
+	clusterRoles, _, explanations, _, err := RenderClusterRoles(ctx, reconciler.Client, reconciler.DiscoveryCache,
+		reconciler.WildcardVerbs, objectListing, reconciler.SelfProtection, resource)
+	if err != nil {
+		return result, err
+	}
+
+	if explain {
+		result.Rules = explanations
+		return result, err
+	}
+
+	for _, targetClusterRoles := range clusterRoles {
+		result.ClusterRoles = append(result.ClusterRoles, targetClusterRoles.ClusterRoles...)
+	}
+
+	return result, err
+}
+
+// PreviewDynamicRoleBinding renders the subjects and namespaces selected by a DynamicRoleBinding,
+// or the decision taken for each namespace and ServiceAccount evaluated by its selectors.
+// They disclose the namespaces and ServiceAccounts of the cluster, and their annotations through the decisions,
+// so the caller must be allowed to list them in the whole cluster, as well as ClusterRoles when they are selected
+// by labels. This is checked through SubjectAccessReviews. Group and User subjects can not be selected by
+// regular expression, as they are listed from external providers whose access is not ruled by RBAC
+func (s *PreviewAPIServer) PreviewDynamicRoleBinding(ctx context.Context, resource *kuberbacv1alpha1.DynamicRoleBinding,
+	caller PreviewCallerT, explain bool) (result PreviewResponseT, err error) {
+
+	subject := resource.Spec.Source.Subject
+	if subject != nil && slices.Contains([]string{rbacv1.GroupKind, rbacv1.UserKind}, subject.Kind) &&
+		!reflect.ValueOf(subject.NameSelector.MatchRegex).IsZero() {
+		return result, fmt.Errorf("%w: source.subject.nameSelector.matchRegex is not allowed on previews of Group and User subjects",
+			errInvalidSpec)
+	}
+
+	err = s.ReviewCallerAccess(ctx, caller, GetPreviewedResources(resource))
+	if err != nil {
+		return result, err
+	}
+
+	// Missing roles do not change the selection, so they are not a reason to fail the preview
+	resource.Spec.Source.WaitForRole = false
+	err = s.DynamicRoleBindingReconciler.PreviewTarget(ctx, resource, false)
+	if err != nil && !errors.Is(err, errRoleRefNotFound) {
+		return result, err
+	}
+
+	if explain {
+		result.Decisions, err = s.DynamicRoleBindingReconciler.ExplainSelection(ctx, resource)
+		return result, err
+	}
+
+	result.Subjects = resource.Status.RenderedSubjects
+	result.Namespaces = resource.Status.RenderedNamespaces
+
+	return result, nil
+}
+
+// GetPreviewedResources returns the permissions needed to see the objects disclosed by the preview of a DynamicRoleBinding
+func GetPreviewedResources(resource *kuberbacv1alpha1.DynamicRoleBinding) (result []authorizationv1.ResourceAttributes) {
+
+	// Namespaces are always evaluated for the targets
+	result = append(result, authorizationv1.ResourceAttributes{Verb: "list", Resource: "namespaces"})
+
+	subject := resource.Spec.Source.Subject
+	if subject != nil && slices.Contains([]string{rbacv1.ServiceAccountKind, kuberbacv1alpha1.SubjectKindNamespaceServiceAccounts}, subject.Kind) {
+		result = append(result, authorizationv1.ResourceAttributes{Verb: "list", Resource: "serviceaccounts"})
+	}
+
+	if resource.Spec.Source.ClusterRoleSelector != nil {
+		result = append(result, authorizationv1.ResourceAttributes{Verb: "list", Group: rbacv1.GroupName, Resource: "clusterroles"})
+	}
+
+	return result
+}
+
+// +kubebuilder:rbac:groups="authorization.k8s.io",resources=subjectaccessreviews,verbs=create
+
+// ReviewCallerAccess returns an error when the caller is not allowed to do every one of the actions,
+// according to the SubjectAccessReviews created on its behalf
+func (s *PreviewAPIServer) ReviewCallerAccess(ctx context.Context, caller PreviewCallerT,
+	permissions []authorizationv1.ResourceAttributes) (err error) {
+
+	if caller.User == "" {
+		return fmt.Errorf("%w: the user previewing it must be set in the '%s' header", errPreviewForbidden, PreviewAPIUserHeader)
+	}
+
+	for _, permission := range permissions {
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:               caller.User,
+				Groups:             caller.Groups,
+				ResourceAttributes: permission.DeepCopy(),
+			},
+		}
+
+		err = s.DynamicRoleBindingReconciler.Client.Create(ctx, review)
+		if err != nil {
+			return fmt.Errorf("error reviewing the permissions of user '%s': %s", caller.User, err.Error())
+		}
+
+		if !review.Status.Allowed {
+			resource := permission.Resource
+			if permission.Group != "" {
+				resource += "." + permission.Group
+			}
+			return fmt.Errorf("%w: user '%s' can not %s %s in the whole cluster", errPreviewForbidden,
+				caller.User, permission.Verb, resource)
+		}
+	}
+
+	return err
+}
+
+// writePreviewError writes an error as the JSON body of the response
+func writePreviewError(response http.ResponseWriter, status int, message string) {
+	writePreviewJSON(response, status, map[string]string{"error": message})
+}
+
+// writePreviewJSON writes the body of the response as JSON
+func writePreviewJSON(response http.ResponseWriter, status int, body any) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	_ = json.NewEncoder(response).Encode(body)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/discovery"
+
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+)
+
+var _ = Describe("Preview API", func() {
+	Context("When rendering manifests", func() {
+
+		const manifest = `
+apiVersion: kuberbac.prosimcorp.com/v1alpha1
+kind: DynamicClusterRole
+metadata:
+  name: preview
+spec:
+  target:
+    name: preview
+  allow:
+    - apiGroups: [""]
+      resources: ["configmaps"]
+      verbs: ["get"]
+  deny: []
+`
+
+		newRequest := func(token string) *http.Request {
+			request := httptest.NewRequest(http.MethodPost, PreviewAPIRenderPath, strings.NewReader(manifest))
+			request.Header.Set("Authorization", "Bearer "+token)
+			return request
+		}
+
+		server := &PreviewAPIServer{Token: "preview-token"}
+
+		BeforeEach(func() {
+			server.DynamicClusterRoleReconciler = &DynamicClusterRoleReconciler{
+				Client:         k8sClient,
+				DiscoveryCache: discoverycache.NewDiscoveryCache(discovery.NewDiscoveryClientForConfigOrDie(cfg), time.Minute),
+			}
+			server.DynamicRoleBindingReconciler = &DynamicRoleBindingReconciler{Client: k8sClient}
+		})
+
+		It("should reject requests without the token", func() {
+			response := httptest.NewRecorder()
+			server.Handler().ServeHTTP(response, newRequest("wrong-token"))
+			Expect(response.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should return the ClusterRoles the manifest would generate", func() {
+			response := httptest.NewRecorder()
+			server.Handler().ServeHTTP(response, newRequest("preview-token"))
+			Expect(response.Code).To(Equal(http.StatusOK))
+
+			result := PreviewResponseT{}
+			Expect(json.Unmarshal(response.Body.Bytes(), &result)).To(Succeed())
+			Expect(result.ClusterRoles).To(HaveLen(1))
+			Expect(result.ClusterRoles[0].Rules).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			}))
+		})
+	})
+
+	DescribeTable("When rendering manifests reading data from the cluster",
+		func(spec string) {
+			manifest := `
+apiVersion: kuberbac.prosimcorp.com/v1alpha1
+kind: DynamicClusterRole
+metadata:
+  name: preview
+spec:
+  target:
+    name: preview
+  allow:
+    - apiGroups: [""]
+      resources: ["secrets"]
+      verbs: ["get"]
+` + spec
+
+			request := httptest.NewRequest(http.MethodPost, PreviewAPIRenderPath, strings.NewReader(manifest))
+			request.Header.Set("Authorization", "Bearer preview-token")
+
+			response := httptest.NewRecorder()
+			server := &PreviewAPIServer{Token: "preview-token"}
+			server.Handler().ServeHTTP(response, request)
+			Expect(response.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(response.Body.String()).To(ContainSubstring("not allowed on previews"))
+		},
+		Entry("should reject reading values from ConfigMaps or Secrets", `
+  deny: []
+  valuesFrom:
+    - secretRef:
+        name: values
+`),
+		Entry("should reject importing the rules of existing ClusterRoles", `
+  deny: []
+  from:
+    - name: admin
+`),
+		Entry("should reject deny rules by resource names", `
+  deny:
+    - apiGroups: [""]
+      resources: ["secrets"]
+      resourceNames: ["database-credentials"]
+      verbs: ["get"]
+`),
+		Entry("should reject selecting the namespaces to list objects from", `
+  deny: []
+  namespaceSelector:
+    matchLabels:
+      team: payments
+`),
+		Entry("should reject selecting the ServiceAccounts to impersonate by labels", `
+  deny: []
+  allowImpersonate:
+    serviceAccounts:
+      - selector:
+          matchLabels:
+            team: payments
+`),
+		Entry("should reject deny rules by object selector", `
+  deny:
+    - apiGroups: [""]
+      resources: ["secrets"]
+      verbs: ["get"]
+      objectSelector:
+        matchLabels:
+          sensitive: "true"
+`),
+	)
+
+	Context("When previewing DynamicRoleBindings", func() {
+
+		const manifest = `
+apiVersion: kuberbac.prosimcorp.com/v1alpha1
+kind: DynamicRoleBinding
+metadata:
+  name: preview
+spec:
+  source:
+    clusterRole: view
+    subject:
+      apiGroup: ""
+      kind: ServiceAccount
+      nameSelector:
+        matchList: ["default"]
+  targets:
+    name: preview
+    namespaceSelector:
+      matchList: ["default"]
+`
+
+		newRequest := func(manifest, user string, groups ...string) *http.Request {
+			request := httptest.NewRequest(http.MethodPost, PreviewAPIRenderPath, strings.NewReader(manifest))
+			request.Header.Set("Authorization", "Bearer preview-token")
+			if user != "" {
+				request.Header.Set(PreviewAPIUserHeader, user)
+			}
+			for _, group := range groups {
+				request.Header.Add(PreviewAPIGroupHeader, group)
+			}
+			return request
+		}
+
+		server := &PreviewAPIServer{Token: "preview-token"}
+
+		BeforeEach(func() {
+			server.DynamicRoleBindingReconciler = &DynamicRoleBindingReconciler{Client: k8sClient}
+		})
+
+		It("should reject requests without the user previewing them", func() {
+			response := httptest.NewRecorder()
+			server.Handler().ServeHTTP(response, newRequest(manifest, ""))
+			Expect(response.Code).To(Equal(http.StatusForbidden))
+			Expect(response.Body.String()).To(ContainSubstring(PreviewAPIUserHeader))
+		})
+
+		It("should reject users not allowed to list the objects disclosed", func() {
+			response := httptest.NewRecorder()
+			server.Handler().ServeHTTP(response, newRequest(manifest, "portal-user", "developers"))
+			Expect(response.Code).To(Equal(http.StatusForbidden))
+			Expect(response.Body.String()).To(ContainSubstring("can not list namespaces"))
+		})
+
+		It("should return the selection to users allowed to list the objects disclosed", func() {
+			response := httptest.NewRecorder()
+			server.Handler().ServeHTTP(response, newRequest(manifest, "portal-admin", "system:masters"))
+			Expect(response.Code).To(Equal(http.StatusOK))
+
+			result := PreviewResponseT{}
+			Expect(json.Unmarshal(response.Body.Bytes(), &result)).To(Succeed())
+			Expect(result.Namespaces).To(Equal([]string{"default"}))
+		})
+
+		It("should reject selecting Groups listed from external providers", func() {
+			manifest := strings.NewReplacer(
+				`kind: ServiceAccount`, `kind: Group`,
+				`matchList: ["default"]
+  targets`, `matchRegex:
+          expression: "^team-"
+  targets`,
+			).Replace(manifest)
+
+			response := httptest.NewRecorder()
+			server.Handler().ServeHTTP(response, newRequest(manifest, "portal-admin", "system:masters"))
+			Expect(response.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(response.Body.String()).To(ContainSubstring("not allowed on previews"))
+		})
+	})
+
+	Context("When no certificate directory is set", func() {
+		It("should generate a self-signed certificate for localhost", func() {
+			certificate, err := generateSelfSignedCertificate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificate.PrivateKey).NotTo(BeNil())
+			Expect(certificate.Leaf.VerifyHostname("localhost")).To(Succeed())
+			Expect(certificate.Leaf.VerifyHostname("127.0.0.1")).To(Succeed())
+			Expect(certificate.Leaf.ExtKeyUsage).To(ContainElement(x509.ExtKeyUsageServerAuth))
+			Expect(certificate.Leaf.NotAfter).To(BeTemporally(">", time.Now().Add(30*24*time.Hour)))
+		})
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/metrics"
+)
+
+// RBACReportReconciler reconciles a RBACReport object
+type RBACReportReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events about the synchronization of the resources
+	Recorder record.EventRecorder
+
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff applied to requeue failed synchronizations
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// SyncSchedule defines when resources are synchronized again after a synchronization
+	SyncSchedule SyncScheduleT
+}
+
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=rbacreports,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=rbacreports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.18.2/pkg/reconcile
+func (r *RBACReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	// 1. Get the content of the report
+	rbacReportResource := &kuberbacv1alpha1.RBACReport{}
+	err = r.Get(ctx, req.NamespacedName, rbacReportResource)
+
+	// 2. Check existence on the cluster
+	if err != nil {
+
+		// 2.1 It does NOT exist: nothing was generated, so only forget its metrics
+		if err = client.IgnoreNotFound(err); err == nil {
+			logger.Info(fmt.Sprintf(resourceNotFoundError, RBACReportResourceType, req.NamespacedName))
+			metrics.DeleteResourceMetrics(RBACReportResourceType, req.Namespace, req.Name)
+			return result, err
+		}
+
+		// 2.2 Failed to get the resource, requeue the request
+		logger.Info(fmt.Sprintf(resourceRetrievalError, RBACReportResourceType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 3. Skip the resources being deleted, as reports do not own anything
+	if !rbacReportResource.DeletionTimestamp.IsZero() {
+		return result, err
+	}
+
+	// 4. Update the status before the requeue, along with the time of the next synchronization
+	defer func() {
+		rbacReportResource.Status.NextSyncTime = getNextSyncTime(result, err)
+		statusErr := updateResourceStatus(ctx, r.Client, rbacReportResource)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, RBACReportResourceType, req.NamespacedName, statusErr.Error()))
+			result = ctrl.Result{}
+			err = errors.Join(err, statusErr)
+		}
+	}()
+
+	// 5. Schedule periodical request. Invalid times fall back to the default one, so the resource is still synchronized
+	RequeueTime, err := r.SyncSchedule.GetRequeueTime(rbacReportResource.Spec.Synchronization)
+	if err != nil {
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, RBACReportResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(rbacReportResource, corev1.EventTypeWarning, globals.ConditionReasonInvalidSpecType, err.Error())
+		err = nil
+	}
+	result = ctrl.Result{
+		RequeueAfter: RequeueTime,
+	}
+
+	// 6. Compute the effective access of the selected subjects
+	syncStartTime := time.Now()
+	err = r.SyncTarget(ctx, rbacReportResource)
+	syncDuration := time.Since(syncStartTime)
+	metrics.SyncDuration.WithLabelValues(RBACReportResourceType, req.Namespace, req.Name).Observe(syncDuration.Seconds())
+	rbacReportResource.Status.LastSyncDuration = getSyncDuration(syncDuration)
+	if err != nil {
+		metrics.SyncErrors.WithLabelValues(RBACReportResourceType, req.Namespace, req.Name).Inc()
+		eventReason := r.UpdateConditionSyncFailure(rbacReportResource, err)
+		logger.Info(fmt.Sprintf(syncTargetError, RBACReportResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(rbacReportResource, corev1.EventTypeWarning, eventReason, err.Error())
+
+		// Invalid specs wait for changes, while the rest of failures are retried with backoff
+		result, err = syncErrorResult(err)
+		return result, err
+	}
+
+	// 7. Success, update the status
+	rbacReportResource.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	r.UpdateConditionSuccess(rbacReportResource)
+	r.Recorder.Eventf(rbacReportResource, corev1.EventTypeNormal, eventReasonSynced,
+		"Reported the effective access of %d subjects", rbacReportResource.Status.SubjectsCount)
+
+	logger.Info(fmt.Sprintf(scheduleSynchronization, RBACReportResourceType, req.NamespacedName, result.RequeueAfter.String()))
+
+	return result, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RBACReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// Reports are refreshed on each synchronization, so bindings are not watched
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kuberbacv1alpha1.RBACReport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.RetryBaseDelay, r.RetryMaxDelay)}).
+		Complete(r)
+}
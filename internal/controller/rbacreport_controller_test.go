@@ -0,0 +1,295 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+var _ = Describe("RBACReport Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		reportedSubject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "report-reader", Namespace: "default"}
+		managedAnnotations := map[string]string{"kuberbac.prosimcorp.com/owner-kind": DynamicRoleBindingResourceType}
+
+		readPods := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}}
+		readSecrets := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+		editConfigMaps := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"update"}}
+
+		// Objects granting access to the reported subject. Two managed bindings grant the same ClusterRole,
+		// and an unmanaged one is left out of the report
+		reportObjects := func() []client.Object {
+			return []client.Object{
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "report-read-pods"},
+					Rules:      []rbacv1.PolicyRule{readPods},
+				},
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "report-read-secrets"},
+					Rules:      []rbacv1.PolicyRule{readSecrets},
+				},
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{Name: "report-edit-configmaps", Namespace: "default"},
+					Rules:      []rbacv1.PolicyRule{editConfigMaps, readPods},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "report-read-pods-a", Annotations: managedAnnotations},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "report-read-pods"},
+					Subjects:   []rbacv1.Subject{reportedSubject},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "report-read-pods-b", Annotations: managedAnnotations},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "report-read-pods"},
+					Subjects:   []rbacv1.Subject{reportedSubject},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "report-read-secrets-unmanaged"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "report-read-secrets"},
+					Subjects:   []rbacv1.Subject{reportedSubject},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "report-edit-configmaps", Namespace: "default", Annotations: managedAnnotations},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "report-edit-configmaps"},
+					Subjects:   []rbacv1.Subject{reportedSubject},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			By("creating the roles and bindings of the reported subject")
+			for _, object := range reportObjects() {
+				Expect(k8sClient.Create(ctx, object)).To(Succeed())
+			}
+
+			By("creating the custom resource for the Kind RBACReport")
+			resource := &kuberbacv1alpha1.RBACReport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: kuberbacv1alpha1.RBACReportSpec{
+					Synchronization: kuberbacv1alpha1.SynchronizationT{
+						Time: "10s",
+					},
+					Subject: kuberbacv1alpha1.RBACReportSubjectT{
+						Kind: "ServiceAccount",
+						NameSelector: kuberbacv1alpha1.NameSelectorT{
+							MatchList: []string{reportedSubject.Name},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kuberbacv1alpha1.RBACReport{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance RBACReport")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			for _, object := range reportObjects() {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, object))).To(Succeed())
+			}
+		})
+
+		It("should report the access granted by the managed bindings of the subject", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &RBACReportReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &kuberbacv1alpha1.RBACReport{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.SubjectsCount).To(Equal(1))
+			Expect(resource.Status.Subjects).To(HaveLen(1))
+
+			// Rules granted several times are reported once, and unmanaged bindings are left out
+			report := resource.Status.Subjects[0]
+			Expect(report.Subject).To(Equal(reportedSubject))
+			Expect(report.Bindings).To(Equal([]string{
+				"ClusterRoleBinding:report-read-pods-a",
+				"ClusterRoleBinding:report-read-pods-b",
+				"RoleBinding:default/report-edit-configmaps",
+			}))
+			Expect(report.ClusterRules).To(Equal([]rbacv1.PolicyRule{readPods}))
+			Expect(report.NamespacedRules).To(Equal([]kuberbacv1alpha1.NamespacedRulesT{
+				{Namespace: "default", Rules: []rbacv1.PolicyRule{editConfigMaps, readPods}},
+			}))
+		})
+
+		It("should report the unmanaged bindings when asked to", func() {
+			resource := &kuberbacv1alpha1.RBACReport{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.IncludeUnmanaged = true
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &RBACReportReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Subjects).To(HaveLen(1))
+			Expect(resource.Status.Subjects[0].Bindings).To(ContainElement("ClusterRoleBinding:report-read-secrets-unmanaged"))
+			Expect(resource.Status.Subjects[0].ClusterRules).To(Equal([]rbacv1.PolicyRule{readPods, readSecrets}))
+		})
+	})
+
+	Context("When subjects are bound through implicit groups", func() {
+
+		ctx := context.Background()
+
+		readPods := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}
+		readNodes := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get"}}
+		readSecrets := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+
+		group := func(name string) rbacv1.Subject {
+			return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+		}
+
+		// Objects granting access to every ServiceAccount, to those of the namespace 'payments',
+		// and to every authenticated identity, each one through a different group
+		implicitObjects := func() []client.Object {
+			clusterRole := func(name string, rule rbacv1.PolicyRule) *rbacv1.ClusterRole {
+				return &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}, Rules: []rbacv1.PolicyRule{rule}}
+			}
+
+			return []client.Object{
+				clusterRole("read-pods", readPods),
+				clusterRole("read-nodes", readNodes),
+				clusterRole("read-secrets", readSecrets),
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "payments"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "shipping"}},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "all-service-accounts"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "read-pods"},
+					Subjects:   []rbacv1.Subject{group("system:serviceaccounts")},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "all-authenticated"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "read-nodes"},
+					Subjects:   []rbacv1.Subject{group("system:authenticated")},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "payments-service-accounts", Namespace: "payments"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "read-secrets"},
+					Subjects:   []rbacv1.Subject{group("system:serviceaccounts:payments")},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "alice-pods"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "read-pods"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}},
+				},
+			}
+		}
+
+		syncReport := func(subject kuberbacv1alpha1.RBACReportSubjectT) *kuberbacv1alpha1.RBACReport {
+			resource := &kuberbacv1alpha1.RBACReport{
+				ObjectMeta: metav1.ObjectMeta{Name: "implicit-groups", Namespace: "default"},
+				Spec:       kuberbacv1alpha1.RBACReportSpec{Subject: subject, IncludeUnmanaged: true},
+			}
+			reconciler := &RBACReportReconciler{Client: newFakeClientBuilder().WithObjects(implicitObjects()...).Build()}
+
+			Expect(reconciler.SyncTarget(ctx, resource)).To(Succeed())
+			return resource
+		}
+
+		It("should report the access granted to the groups of the selected ServiceAccounts", func() {
+			resource := syncReport(kuberbacv1alpha1.RBACReportSubjectT{
+				Kind:         rbacv1.ServiceAccountKind,
+				NameSelector: kuberbacv1alpha1.NameSelectorT{MatchList: []string{"deployer"}},
+			})
+			Expect(resource.Status.Subjects).To(HaveLen(2))
+
+			payments := resource.Status.Subjects[0]
+			Expect(payments.Subject).To(Equal(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "payments"}))
+			Expect(payments.Bindings).To(Equal([]string{
+				"ClusterRoleBinding:all-authenticated (system:authenticated)",
+				"ClusterRoleBinding:all-service-accounts (system:serviceaccounts)",
+				"RoleBinding:payments/payments-service-accounts (system:serviceaccounts:payments)",
+			}))
+			Expect(payments.ClusterRules).To(ConsistOf(readPods, readNodes))
+			Expect(payments.NamespacedRules).To(Equal([]kuberbacv1alpha1.NamespacedRulesT{
+				{Namespace: "payments", Rules: []rbacv1.PolicyRule{readSecrets}},
+			}))
+
+			// The ServiceAccounts of other namespaces are not placed in the group of 'payments'
+			shipping := resource.Status.Subjects[1]
+			Expect(shipping.Subject.Namespace).To(Equal("shipping"))
+			Expect(shipping.ClusterRules).To(ConsistOf(readPods, readNodes))
+			Expect(shipping.NamespacedRules).To(BeEmpty())
+		})
+
+		It("should report the access granted to all the authenticated identities for the reported users", func() {
+			resource := syncReport(kuberbacv1alpha1.RBACReportSubjectT{Kind: rbacv1.UserKind})
+			Expect(resource.Status.Subjects).To(HaveLen(1))
+
+			alice := resource.Status.Subjects[0]
+			Expect(alice.Subject.Name).To(Equal("alice"))
+			Expect(alice.Bindings).To(Equal([]string{
+				"ClusterRoleBinding:alice-pods",
+				"ClusterRoleBinding:all-authenticated (system:authenticated)",
+			}))
+			Expect(alice.ClusterRules).To(Equal([]rbacv1.PolicyRule{readPods, readNodes}))
+		})
+
+		It("should report the implicit groups as any other group when groups are selected", func() {
+			resource := syncReport(kuberbacv1alpha1.RBACReportSubjectT{
+				Kind:         rbacv1.GroupKind,
+				NameSelector: kuberbacv1alpha1.NameSelectorT{MatchList: []string{"system:authenticated"}},
+			})
+			Expect(resource.Status.Subjects).To(HaveLen(1))
+			Expect(resource.Status.Subjects[0].Bindings).To(Equal([]string{"ClusterRoleBinding:all-authenticated"}))
+		})
+	})
+})
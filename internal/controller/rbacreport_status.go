@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"prosimcorp.com/kuberbac/internal/globals"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+func (r *RBACReportReconciler) UpdateConditionSuccess(resource *kuberbacv1alpha1.RBACReport) {
+
+	//
+	condition := globals.NewCondition(globals.ConditionTypeResourceSynced, metav1.ConditionTrue,
+		globals.ConditionReasonTargetSynced, globals.ConditionReasonTargetSyncedMessage)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+}
+
+func (r *RBACReportReconciler) UpdateConditionSyncFailure(resource *kuberbacv1alpha1.RBACReport, err error) (reason string) {
+
+	//
+	condition := syncFailureCondition(err)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+	return condition.Reason
+}
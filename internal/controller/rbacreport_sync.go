@@ -0,0 +1,355 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+const (
+	// serviceAccountsGroup is the group Kubernetes places all the ServiceAccounts of the cluster in
+	serviceAccountsGroup = "system:serviceaccounts"
+
+	// authenticatedGroup is the group Kubernetes places all the authenticated users and ServiceAccounts in
+	authenticatedGroup = "system:authenticated"
+)
+
+// subjectAccessBuilderT accumulates the rules granted to a subject while bindings are evaluated
+type subjectAccessBuilderT struct {
+	subject         rbacv1.Subject
+	bindings        []string
+	clusterRules    policyRuleSetT
+	namespacedRules map[string]*policyRuleSetT
+}
+
+// implicitGrantT is the access granted by a binding to a group Kubernetes places subjects in implicitly.
+// Its namespace is empty when it is granted cluster-wide
+type implicitGrantT struct {
+	binding   string
+	namespace string
+	rules     []rbacv1.PolicyRule
+}
+
+// grant adds the rules granted by a binding to the subject. They are granted cluster-wide when namespace is empty
+func (b *subjectAccessBuilderT) grant(binding, namespace string, rules []rbacv1.PolicyRule) {
+
+	b.bindings = append(b.bindings, binding)
+
+	if namespace == "" {
+		b.clusterRules.add(rules...)
+		return
+	}
+
+	if b.namespacedRules[namespace] == nil {
+		b.namespacedRules[namespace] = &policyRuleSetT{}
+	}
+	b.namespacedRules[namespace].add(rules...)
+}
+
+// policyRuleSetT is a list of rules without duplicates, keeping the order in which they were added
+type policyRuleSetT struct {
+	rules []rbacv1.PolicyRule
+	keys  map[string]struct{}
+}
+
+// add appends the rules not already present on the set. They are compared by their compact representation
+func (s *policyRuleSetT) add(rules ...rbacv1.PolicyRule) {
+
+	if s.keys == nil {
+		s.keys = map[string]struct{}{}
+	}
+
+	for _, rule := range rules {
+		key := FormatPolicyRule(rule)
+		if _, found := s.keys[key]; found {
+			continue
+		}
+		s.keys[key] = struct{}{}
+		s.rules = append(s.rules, rule)
+	}
+}
+
+// isManagedBinding returns true when the binding was generated by Kuberbac
+func isManagedBinding(annotations map[string]string) bool {
+	_, managed := annotations["kuberbac.prosimcorp.com/owner-kind"]
+	return managed
+}
+
+// ImplicitSubjectGroups returns the groups Kubernetes places a subject in, without being bound to them explicitly:
+// all the ServiceAccounts, those of its namespace, and all the authenticated identities
+func ImplicitSubjectGroups(subject rbacv1.Subject) []string {
+
+	switch subject.Kind {
+	case rbacv1.ServiceAccountKind:
+		return []string{serviceAccountsGroup, serviceAccountsGroupPrefix + subject.Namespace, authenticatedGroup}
+	case rbacv1.UserKind:
+		return []string{authenticatedGroup}
+	}
+
+	return nil
+}
+
+// isImplicitGroup returns true when the subject is one of the groups Kubernetes places subjects in implicitly
+func isImplicitGroup(subject rbacv1.Subject) bool {
+	return subject.Kind == rbacv1.GroupKind && (subject.Name == serviceAccountsGroup || subject.Name == authenticatedGroup ||
+		strings.HasPrefix(subject.Name, serviceAccountsGroupPrefix))
+}
+
+// MatchReportSubject returns true when the subject of a binding is selected by the RBACReport.
+// Namespaces are only checked for ServiceAccounts, and only when the namespaceSelector is filled
+func MatchReportSubject(selector *kuberbacv1alpha1.RBACReportSubjectT, subject rbacv1.Subject,
+	namespaces []string, matchRegex *regexp.Regexp) bool {
+
+	if subject.Kind != selector.Kind {
+		return false
+	}
+
+	if subject.Kind == "ServiceAccount" && !reflect.ValueOf(selector.NamespaceSelector).IsZero() &&
+		!slices.Contains(namespaces, subject.Namespace) {
+		return false
+	}
+
+	// Matching by fixed list
+	if len(selector.NameSelector.MatchList) > 0 {
+		return slices.Contains(selector.NameSelector.MatchList, subject.Name)
+	}
+
+	// Match by regex
+	if selector.NameSelector.MatchRegex.Expression != "" {
+		return matchRegex.MatchString(subject.Name) != selector.NameSelector.MatchRegex.Negative
+	}
+
+	return true
+}
+
+// SyncTarget computes the effective access of the subjects selected by the RBACReport into its status.
+// It is the union of the rules of the roles referenced by every binding of those subjects,
+// including the bindings of the groups Kubernetes places them in implicitly
+func (r *RBACReportReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.RBACReport) (err error) {
+
+	selector := &resource.Spec.Subject
+
+	// Check namespaceSelector does NOT exist for subjects other than ServiceAccount
+	if selector.Kind != "ServiceAccount" && !reflect.ValueOf(selector.NamespaceSelector).IsZero() {
+		err = fmt.Errorf("%w: subject.namespaceSelector is only allowed for ServiceAccount subjects", errInvalidSelector)
+		return err
+	}
+
+	// Check only one nameSelector is used at once
+	if len(selector.NameSelector.MatchList) > 0 && selector.NameSelector.MatchRegex.Expression != "" {
+		err = fmt.Errorf("%w: only one of the following fields is allowed as subject.nameSelector: matchList, matchRegex", errInvalidSelector)
+		return err
+	}
+
+	// Compile regex expression when filled
+	matchRegex := &regexp.Regexp{}
+	if selector.NameSelector.MatchRegex.Expression != "" {
+		matchRegex, err = CompileMatchRegex("subject.nameSelector.matchRegex", selector.NameSelector.MatchRegex)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Get the namespaces where ServiceAccounts are looked for
+	namespaces := []string{}
+	if !reflect.ValueOf(selector.NamespaceSelector).IsZero() {
+		namespaceList := &corev1.NamespaceList{}
+		err = r.Client.List(ctx, namespaceList)
+		if err != nil {
+			return err
+		}
+
+		namespaces, err = FilterNamespaceListBySelector(namespaceList, &selector.NamespaceSelector)
+		if err != nil {
+			return fmt.Errorf("error selecting the namespaces of subject: %w", err)
+		}
+	}
+
+	// Get the rules of every role, to resolve the references of the bindings later
+	clusterRoleList := rbacv1.ClusterRoleList{}
+	err = r.Client.List(ctx, &clusterRoleList)
+	if err != nil {
+		return err
+	}
+
+	clusterRoleRules := map[string][]rbacv1.PolicyRule{}
+	for _, clusterRole := range clusterRoleList.Items {
+		clusterRoleRules[clusterRole.Name] = clusterRole.Rules
+	}
+
+	roleList := rbacv1.RoleList{}
+	err = r.Client.List(ctx, &roleList)
+	if err != nil {
+		return err
+	}
+
+	roleRules := map[string][]rbacv1.PolicyRule{}
+	for _, role := range roleList.Items {
+		roleRules[role.Namespace+"/"+role.Name] = role.Rules
+	}
+
+	// Accumulate the access of each selected subject, keyed by its compact representation
+	accessBySubject := map[string]*subjectAccessBuilderT{}
+	getSubjectAccess := func(subject rbacv1.Subject) *subjectAccessBuilderT {
+		key := FormatSubjects([]rbacv1.Subject{subject})[0]
+		if _, found := accessBySubject[key]; !found {
+			accessBySubject[key] = &subjectAccessBuilderT{
+				subject:         subject,
+				namespacedRules: map[string]*policyRuleSetT{},
+			}
+		}
+		return accessBySubject[key]
+	}
+
+	// Access bound to the groups Kubernetes places subjects in implicitly, keyed by group.
+	// It is granted once the selected subjects are known
+	implicitGrants := map[string][]implicitGrantT{}
+
+	// Evaluate ClusterRoleBindings. Their rules are granted cluster-wide
+	clusterRoleBindingList := rbacv1.ClusterRoleBindingList{}
+	err = r.Client.List(ctx, &clusterRoleBindingList)
+	if err != nil {
+		return err
+	}
+
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+
+		if !resource.Spec.IncludeUnmanaged && !isManagedBinding(clusterRoleBinding.Annotations) {
+			continue
+		}
+
+		binding := "ClusterRoleBinding:" + clusterRoleBinding.Name
+		rules := clusterRoleRules[clusterRoleBinding.RoleRef.Name]
+
+		for _, subject := range clusterRoleBinding.Subjects {
+			if selector.Kind != rbacv1.GroupKind && isImplicitGroup(subject) {
+				implicitGrants[subject.Name] = append(implicitGrants[subject.Name], implicitGrantT{binding: binding, rules: rules})
+				continue
+			}
+
+			if !MatchReportSubject(selector, subject, namespaces, matchRegex) {
+				continue
+			}
+			getSubjectAccess(subject).grant(binding, "", rules)
+		}
+	}
+
+	// Evaluate RoleBindings. Their rules are granted only inside their namespace
+	roleBindingList := rbacv1.RoleBindingList{}
+	err = r.Client.List(ctx, &roleBindingList)
+	if err != nil {
+		return err
+	}
+
+	for _, roleBinding := range roleBindingList.Items {
+
+		if !resource.Spec.IncludeUnmanaged && !isManagedBinding(roleBinding.Annotations) {
+			continue
+		}
+
+		rules := clusterRoleRules[roleBinding.RoleRef.Name]
+		if roleBinding.RoleRef.Kind == "Role" {
+			rules = roleRules[roleBinding.Namespace+"/"+roleBinding.RoleRef.Name]
+		}
+
+		binding := "RoleBinding:" + roleBinding.Namespace + "/" + roleBinding.Name
+
+		for _, subject := range roleBinding.Subjects {
+			if selector.Kind != rbacv1.GroupKind && isImplicitGroup(subject) {
+				implicitGrants[subject.Name] = append(implicitGrants[subject.Name],
+					implicitGrantT{binding: binding, namespace: roleBinding.Namespace, rules: rules})
+				continue
+			}
+
+			if !MatchReportSubject(selector, subject, namespaces, matchRegex) {
+				continue
+			}
+			getSubjectAccess(subject).grant(binding, roleBinding.Namespace, rules)
+		}
+	}
+
+	// Grant the access bound to the implicit groups to the selected subjects placed in them.
+	// ServiceAccounts are listed, but Kubernetes does not store users, so only the users already reported receive it
+	if len(implicitGrants) > 0 {
+		candidates := []rbacv1.Subject{}
+
+		switch selector.Kind {
+		case rbacv1.ServiceAccountKind:
+			serviceAccountList := corev1.ServiceAccountList{}
+			err = r.Client.List(ctx, &serviceAccountList)
+			if err != nil {
+				return err
+			}
+
+			for _, serviceAccount := range serviceAccountList.Items {
+				candidates = append(candidates, rbacv1.Subject{
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      serviceAccount.Name,
+					Namespace: serviceAccount.Namespace,
+				})
+			}
+		case rbacv1.UserKind:
+			for _, subjectAccess := range accessBySubject {
+				candidates = append(candidates, subjectAccess.subject)
+			}
+		}
+
+		for _, subject := range candidates {
+			if !MatchReportSubject(selector, subject, namespaces, matchRegex) {
+				continue
+			}
+
+			for _, group := range ImplicitSubjectGroups(subject) {
+				for _, implicitGrant := range implicitGrants[group] {
+					getSubjectAccess(subject).grant(implicitGrant.binding+" ("+group+")", implicitGrant.namespace, implicitGrant.rules)
+				}
+			}
+		}
+	}
+
+	// Store the report sorted, so it only changes when the access changes
+	subjectKeys := make([]string, 0, len(accessBySubject))
+	for key := range accessBySubject {
+		subjectKeys = append(subjectKeys, key)
+	}
+	slices.Sort(subjectKeys)
+
+	resource.Status.Subjects = []kuberbacv1alpha1.SubjectAccessT{}
+	for _, key := range subjectKeys {
+		subjectAccess := accessBySubject[key]
+		slices.Sort(subjectAccess.bindings)
+		subjectAccess.bindings = slices.Compact(subjectAccess.bindings)
+
+		report := kuberbacv1alpha1.SubjectAccessT{
+			Subject:      subjectAccess.subject,
+			Bindings:     subjectAccess.bindings,
+			ClusterRules: subjectAccess.clusterRules.rules,
+		}
+
+		ruleNamespaces := make([]string, 0, len(subjectAccess.namespacedRules))
+		for namespace := range subjectAccess.namespacedRules {
+			ruleNamespaces = append(ruleNamespaces, namespace)
+		}
+		slices.Sort(ruleNamespaces)
+
+		for _, namespace := range ruleNamespaces {
+			report.NamespacedRules = append(report.NamespacedRules, kuberbacv1alpha1.NamespacedRulesT{
+				Namespace: namespace,
+				Rules:     subjectAccess.namespacedRules[namespace].rules,
+			})
+		}
+
+		resource.Status.Subjects = append(resource.Status.Subjects, report)
+	}
+	resource.Status.SubjectsCount = len(resource.Status.Subjects)
+
+	return err
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/audit"
+	"prosimcorp.com/kuberbac/internal/globals"
+	"prosimcorp.com/kuberbac/internal/metrics"
+)
+
+// RBACSuggestionReconciler reconciles a RBACSuggestion object
+type RBACSuggestionReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events about the synchronization of the resources
+	Recorder record.EventRecorder
+
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff applied to requeue failed synchronizations
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// SyncSchedule defines when resources are synchronized again after a synchronization
+	SyncSchedule SyncScheduleT
+
+	// AuditStore contains the access observed from the audit events. Suggestions are not computed when it is nil
+	AuditStore *audit.Store
+}
+
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=rbacsuggestions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kuberbac.prosimcorp.com,resources=rbacsuggestions/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;rolebindings,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.18.2/pkg/reconcile
+func (r *RBACSuggestionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	// 1. Get the content of the suggestion
+	rbacSuggestionResource := &kuberbacv1alpha1.RBACSuggestion{}
+	err = r.Get(ctx, req.NamespacedName, rbacSuggestionResource)
+
+	// 2. Check existence on the cluster
+	if err != nil {
+
+		// 2.1 It does NOT exist: nothing was generated, so only forget its metrics
+		if err = client.IgnoreNotFound(err); err == nil {
+			logger.Info(fmt.Sprintf(resourceNotFoundError, RBACSuggestionResourceType, req.NamespacedName))
+			metrics.DeleteResourceMetrics(RBACSuggestionResourceType, req.Namespace, req.Name)
+			return result, err
+		}
+
+		// 2.2 Failed to get the resource, requeue the request
+		logger.Info(fmt.Sprintf(resourceRetrievalError, RBACSuggestionResourceType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 3. Skip the resources being deleted, as suggestions do not own anything
+	if !rbacSuggestionResource.DeletionTimestamp.IsZero() {
+		return result, err
+	}
+
+	// 4. Update the status before the requeue, along with the time of the next synchronization
+	defer func() {
+		rbacSuggestionResource.Status.NextSyncTime = getNextSyncTime(result, err)
+		statusErr := updateResourceStatus(ctx, r.Client, rbacSuggestionResource)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, RBACSuggestionResourceType, req.NamespacedName, statusErr.Error()))
+			result = ctrl.Result{}
+			err = errors.Join(err, statusErr)
+		}
+	}()
+
+	// 5. Schedule periodical request. Invalid times fall back to the default one, so the resource is still synchronized
+	RequeueTime, err := r.SyncSchedule.GetRequeueTime(rbacSuggestionResource.Spec.Synchronization)
+	if err != nil {
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, RBACSuggestionResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(rbacSuggestionResource, corev1.EventTypeWarning, globals.ConditionReasonInvalidSpecType, err.Error())
+		err = nil
+	}
+	result = ctrl.Result{
+		RequeueAfter: RequeueTime,
+	}
+
+	// 6. Suggest the rules used by the bound subjects
+	syncStartTime := time.Now()
+	err = r.SyncTarget(ctx, rbacSuggestionResource)
+	syncDuration := time.Since(syncStartTime)
+	metrics.SyncDuration.WithLabelValues(RBACSuggestionResourceType, req.Namespace, req.Name).Observe(syncDuration.Seconds())
+	rbacSuggestionResource.Status.LastSyncDuration = getSyncDuration(syncDuration)
+	if err != nil {
+		metrics.SyncErrors.WithLabelValues(RBACSuggestionResourceType, req.Namespace, req.Name).Inc()
+		eventReason := r.UpdateConditionSyncFailure(rbacSuggestionResource, err)
+		logger.Info(fmt.Sprintf(syncTargetError, RBACSuggestionResourceType, req.NamespacedName, err.Error()))
+		r.Recorder.Event(rbacSuggestionResource, corev1.EventTypeWarning, eventReason, err.Error())
+
+		// Invalid specs wait for changes, while the rest of failures are retried with backoff
+		result, err = syncErrorResult(err)
+		return result, err
+	}
+
+	// 7. Success, update the status
+	rbacSuggestionResource.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	r.UpdateConditionSuccess(rbacSuggestionResource)
+	r.Recorder.Eventf(rbacSuggestionResource, corev1.EventTypeNormal, eventReasonSynced,
+		"Suggested %d rules for %d subjects", rbacSuggestionResource.Status.RulesCount, rbacSuggestionResource.Status.SubjectsCount)
+
+	logger.Info(fmt.Sprintf(scheduleSynchronization, RBACSuggestionResourceType, req.NamespacedName, result.RequeueAfter.String()))
+
+	return result, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RBACSuggestionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// Suggestions are refreshed on each synchronization, so bindings are not watched
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kuberbacv1alpha1.RBACSuggestion{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.RetryBaseDelay, r.RetryMaxDelay)}).
+		Complete(r)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/audit"
+)
+
+var _ = Describe("RBACSuggestion Controller", func() {
+
+	ctx := context.Background()
+
+	referenceAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-apiversion": kuberbacv1alpha1.GroupVersion.String(),
+		"kuberbac.prosimcorp.com/owner-kind":       DynamicRoleBindingResourceType,
+		"kuberbac.prosimcorp.com/owner-name":       "developers",
+		"kuberbac.prosimcorp.com/owner-namespace":  "default",
+	}
+
+	operator := rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "operator"}
+	deployer := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "payments"}
+
+	newResource := func() *kuberbacv1alpha1.RBACSuggestion {
+		return &kuberbacv1alpha1.RBACSuggestion{
+			ObjectMeta: metav1.ObjectMeta{Name: "developers", Namespace: "default"},
+			Spec:       kuberbacv1alpha1.RBACSuggestionSpec{DynamicRoleBinding: "developers"},
+		}
+	}
+
+	// The operator is bound cluster-wide, and the deployer only inside payments
+	newReconciler := func(auditStore *audit.Store) *RBACSuggestionReconciler {
+		objects := []client.Object{
+			&rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "developers-view", Annotations: referenceAnnotations},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+				Subjects:   []rbacv1.Subject{operator},
+			},
+			&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "developers-edit", Namespace: "payments", Annotations: referenceAnnotations},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+				Subjects:   []rbacv1.Subject{deployer},
+			},
+			&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "platform-edit", Namespace: "shipping"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+				Subjects:   []rbacv1.Subject{deployer},
+			},
+		}
+
+		return &RBACSuggestionReconciler{
+			Client:     newFakeClientBuilder().WithObjects(objects...).Build(),
+			AuditStore: auditStore,
+		}
+	}
+
+	event := func(username, verb string, objectRef *audit.ObjectReferenceT, code int) audit.EventT {
+		return audit.EventT{
+			Stage:          "ResponseComplete",
+			RequestURI:     "/healthz?verbose",
+			Verb:           verb,
+			User:           audit.UserInfoT{Username: username},
+			ObjectRef:      objectRef,
+			ResponseStatus: &audit.ResponseStatusT{Code: code},
+		}
+	}
+
+	It("should suggest the rules used by the bound subjects inside the scope of their bindings", func() {
+		auditStore := audit.NewStore()
+		for _, auditEvent := range []audit.EventT{
+			event("operator", "list", &audit.ObjectReferenceT{Resource: "namespaces"}, 200),
+			event("operator", "get", nil, 200),
+			event("operator", "delete", &audit.ObjectReferenceT{Resource: "secrets", Namespace: "payments"}, 403),
+			event("system:serviceaccount:payments:deployer", "patch",
+				&audit.ObjectReferenceT{APIGroup: "apps", Resource: "deployments", Subresource: "scale", Namespace: "payments"}, 200),
+			event("system:serviceaccount:payments:deployer", "delete",
+				&audit.ObjectReferenceT{Resource: "pods", Namespace: "shipping"}, 200),
+			event("system:serviceaccount:payments:deployer", "list", &audit.ObjectReferenceT{Resource: "nodes"}, 200),
+			event("stranger", "create", &audit.ObjectReferenceT{Resource: "pods", Namespace: "payments"}, 201),
+		} {
+			auditStore.Record(auditEvent)
+		}
+
+		resource := newResource()
+		Expect(newReconciler(auditStore).SyncTarget(ctx, resource)).To(Succeed())
+
+		Expect(resource.Status.SuggestedRules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"list"}},
+			rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale"}, Verbs: []string{"patch"}},
+			rbacv1.PolicyRule{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+		))
+		Expect(resource.Status.RulesCount).To(Equal(3))
+		Expect(resource.Status.SubjectsCount).To(Equal(2))
+		Expect(resource.Status.ObservedNamespaces).To(Equal([]string{"payments"}))
+		Expect(resource.Status.ObservedSince.Time).To(Equal(auditStore.StartTime()))
+	})
+
+	It("should keep the rules suggested before", func() {
+		auditStore := audit.NewStore()
+		auditStore.Record(event("operator", "watch", &audit.ObjectReferenceT{Resource: "namespaces"}, 200))
+
+		resource := newResource()
+		resource.Status.SuggestedRules = []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"namespaces", "configmaps"}, Verbs: []string{"get"}},
+		}
+		Expect(newReconciler(auditStore).SyncTarget(ctx, resource)).To(Succeed())
+
+		Expect(resource.Status.SuggestedRules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "watch"}},
+		))
+	})
+
+	It("should reject the suggestions when audit events are not consumed", func() {
+		err := newReconciler(nil).SyncTarget(ctx, newResource())
+		Expect(err).To(MatchError(errInvalidSpec))
+		Expect(err.Error()).To(ContainSubstring("--audit-webhook-bind-address"))
+	})
+
+	It("should reject the suggestions without a DynamicRoleBinding", func() {
+		resource := newResource()
+		resource.Spec.DynamicRoleBinding = ""
+		Expect(newReconciler(audit.NewStore()).SyncTarget(ctx, resource)).To(MatchError(errInvalidSpec))
+	})
+})
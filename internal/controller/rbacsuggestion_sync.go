@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/internal/audit"
+	"prosimcorp.com/kuberbac/internal/globals"
+)
+
+// boundSubjectT is a subject bound by a DynamicRoleBinding, and where it is bound
+type boundSubjectT struct {
+	subject     rbacv1.Subject
+	clusterWide bool
+	namespaces  []string
+}
+
+// GetBoundSubjects returns the subjects of the bindings generated by a DynamicRoleBinding, sorted.
+// Subjects bound by RoleBindings only use their role inside the namespaces of those bindings
+func (r *RBACSuggestionReconciler) GetBoundSubjects(ctx context.Context, namespace, name string) (result []boundSubjectT, err error) {
+
+	ownerAnnotations := map[string]string{
+		"kuberbac.prosimcorp.com/owner-kind":      DynamicRoleBindingResourceType,
+		"kuberbac.prosimcorp.com/owner-name":      name,
+		"kuberbac.prosimcorp.com/owner-namespace": namespace,
+	}
+
+	boundSubjects := map[string]*boundSubjectT{}
+	getBoundSubject := func(subject rbacv1.Subject) *boundSubjectT {
+		key := FormatSubjects([]rbacv1.Subject{subject})[0]
+		if _, found := boundSubjects[key]; !found {
+			boundSubjects[key] = &boundSubjectT{subject: subject}
+		}
+		return boundSubjects[key]
+	}
+
+	clusterRoleBindingList := rbacv1.ClusterRoleBindingList{}
+	err = r.Client.List(ctx, &clusterRoleBindingList)
+	if err != nil {
+		return result, fmt.Errorf("error listing ClusterRoleBindings: %s", err.Error())
+	}
+
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+		if !globals.IsSubset(ownerAnnotations, clusterRoleBinding.Annotations) {
+			continue
+		}
+
+		for _, subject := range clusterRoleBinding.Subjects {
+			getBoundSubject(subject).clusterWide = true
+		}
+	}
+
+	roleBindingList := rbacv1.RoleBindingList{}
+	err = r.Client.List(ctx, &roleBindingList)
+	if err != nil {
+		return result, fmt.Errorf("error listing RoleBindings: %s", err.Error())
+	}
+
+	for _, roleBinding := range roleBindingList.Items {
+		if !globals.IsSubset(ownerAnnotations, roleBinding.Annotations) {
+			continue
+		}
+
+		for _, subject := range roleBinding.Subjects {
+			boundSubject := getBoundSubject(subject)
+			boundSubject.namespaces = appendSorted(boundSubject.namespaces, roleBinding.Namespace)
+		}
+	}
+
+	for _, boundSubject := range boundSubjects {
+		result = append(result, *boundSubject)
+	}
+	slices.SortFunc(result, func(a, b boundSubjectT) int {
+		return strings.Compare(FormatSubjects([]rbacv1.Subject{a.subject})[0], FormatSubjects([]rbacv1.Subject{b.subject})[0])
+	})
+
+	return result, err
+}
+
+// SuggestPolicyRules returns the minimal rules granting every access, merged with the rules suggested before.
+// Access is stretched to one rule per resource, or non-resource URL, with all its verbs, and then compacted
+func SuggestPolicyRules(previousRules []rbacv1.PolicyRule, accessList []audit.AccessT) (result []rbacv1.PolicyRule) {
+
+	verbsByKey := map[string][]string{}
+
+	// Previous rules are stretched back, as compacted rules grant every combination of their fields
+	for _, rule := range previousRules {
+		for _, nonResourceURL := range rule.NonResourceURLs {
+			key := "#" + nonResourceURL
+			verbsByKey[key] = appendSorted(verbsByKey[key], rule.Verbs...)
+		}
+		for _, apiGroup := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				key := apiGroup + "#" + resource + "#"
+				verbsByKey[key] = appendSorted(verbsByKey[key], rule.Verbs...)
+			}
+		}
+	}
+
+	for _, access := range accessList {
+		key := access.APIGroup + "#" + access.Resource + "#"
+		if access.NonResourceURL != "" {
+			key = "#" + access.NonResourceURL
+		}
+		verbsByKey[key] = appendSorted(verbsByKey[key], access.Verb)
+	}
+
+	keys := make([]string, 0, len(verbsByKey))
+	for key := range verbsByKey {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	stretchedRules := []rbacv1.PolicyRule{}
+	for _, key := range keys {
+		keyParts := strings.Split(key, "#")
+		if len(keyParts) == 2 {
+			stretchedRules = append(stretchedRules, rbacv1.PolicyRule{
+				NonResourceURLs: []string{keyParts[1]},
+				Verbs:           verbsByKey[key],
+			})
+			continue
+		}
+
+		stretchedRules = append(stretchedRules, rbacv1.PolicyRule{
+			APIGroups: []string{keyParts[0]},
+			Resources: []string{keyParts[1]},
+			Verbs:     verbsByKey[key],
+		})
+	}
+
+	return CompactPolicyRules(stretchedRules)
+}
+
+// SyncTarget suggests into the status of the RBACSuggestion the minimal rules covering the requests made by the
+// subjects bound by its DynamicRoleBinding. Requests made outside the scope of the bindings are granted by other roles,
+// so they are ignored: cluster-wide and non-resource requests for subjects only bound by RoleBindings,
+// and requests in namespaces not bound
+func (r *RBACSuggestionReconciler) SyncTarget(ctx context.Context, resource *kuberbacv1alpha1.RBACSuggestion) (err error) {
+
+	if r.AuditStore == nil {
+		return fmt.Errorf("%w: audit events are not consumed by the operator. "+
+			"Set '--audit-webhook-bind-address' or '--audit-log-path' to enable suggestions", errInvalidSpec)
+	}
+
+	if resource.Spec.DynamicRoleBinding == "" {
+		return fmt.Errorf("%w: dynamicRoleBinding must be set", errInvalidSpec)
+	}
+
+	boundSubjects, err := r.GetBoundSubjects(ctx, resource.Namespace, resource.Spec.DynamicRoleBinding)
+	if err != nil {
+		return err
+	}
+
+	accessList := []audit.AccessT{}
+	observedNamespaces := resource.Status.ObservedNamespaces
+	for _, boundSubject := range boundSubjects {
+		subjectAccessList := r.AuditStore.GetAccess(boundSubject.subject.Kind, boundSubject.subject.Namespace, boundSubject.subject.Name)
+
+		for _, access := range subjectAccessList {
+			if !boundSubject.clusterWide &&
+				(access.Namespace == "" || !slices.Contains(boundSubject.namespaces, access.Namespace)) {
+				continue
+			}
+
+			accessList = append(accessList, access)
+			if access.Namespace != "" {
+				observedNamespaces = appendSorted(observedNamespaces, access.Namespace)
+			}
+		}
+	}
+
+	resource.Status.SuggestedRules = SuggestPolicyRules(resource.Status.SuggestedRules, accessList)
+	resource.Status.ObservedNamespaces = observedNamespaces
+	resource.Status.SubjectsCount = len(boundSubjects)
+	resource.Status.RulesCount = len(resource.Status.SuggestedRules)
+
+	if resource.Status.ObservedSince == nil {
+		resource.Status.ObservedSince = &metav1.Time{Time: r.AuditStore.StartTime()}
+	}
+
+	return err
+}
@@ -0,0 +1,379 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// SystemNamespaces are the namespaces used by the control plane.
+// Resources are not generated on them unless explicitly allowed
+var SystemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// ExcludeNamespaceAnnotation opts a namespace out of the results of every namespace selector when set to 'true' on it,
+// so its owners can keep the generated resources away from it
+const ExcludeNamespaceAnnotation = "kuberbac.prosimcorp.com/exclude"
+
+// RemoveSystemNamespaces returns the namespaces of the list that are not system namespaces.
+// The override of the resource takes precedence over the default of the controller
+func RemoveSystemNamespaces(namespaces []string, override *bool, excludeByDefault bool) (result []string) {
+
+	exclude := excludeByDefault
+	if override != nil {
+		exclude = *override
+	}
+
+	if !exclude {
+		return namespaces
+	}
+
+	for _, namespace := range namespaces {
+		if !slices.Contains(SystemNamespaces, namespace) {
+			result = append(result, namespace)
+		}
+	}
+
+	return result
+}
+
+// RemoveExcludedNamespaces returns the namespaces of the list not opted out with the ExcludeNamespaceAnnotation.
+// The annotations are read from the namespaces of the namespace list
+func RemoveExcludedNamespaces(namespaces []string, namespaceList *corev1.NamespaceList) (result []string) {
+
+	excludedNamespaces := []string{}
+	for _, namespace := range namespaceList.Items {
+		if namespace.Annotations[ExcludeNamespaceAnnotation] == "true" {
+			excludedNamespaces = append(excludedNamespaces, namespace.Name)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		if !slices.Contains(excludedNamespaces, namespace) {
+			result = append(result, namespace)
+		}
+	}
+
+	return result
+}
+
+// RemoveInactiveNamespaces returns the namespaces of the list in the 'Active' phase. Objects can not be created
+// inside terminating namespaces, so they are skipped. The phases are read from the namespaces of the namespace list
+func RemoveInactiveNamespaces(namespaces []string, namespaceList *corev1.NamespaceList) (result []string) {
+
+	activeNamespaces := []string{}
+	for _, namespace := range namespaceList.Items {
+		if namespace.Status.Phase == corev1.NamespaceActive {
+			activeNamespaces = append(activeNamespaces, namespace.Name)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		if slices.Contains(activeNamespaces, namespace) {
+			result = append(result, namespace)
+		}
+	}
+
+	return result
+}
+
+// RemoveUnwatchedNamespaces returns the namespaces of the list watched by the operator.
+// All of them are watched when the list of watched namespaces is empty
+func RemoveUnwatchedNamespaces(namespaces []string, watchNamespaces []string) (result []string) {
+
+	if len(watchNamespaces) == 0 {
+		return namespaces
+	}
+
+	for _, namespace := range namespaces {
+		if slices.Contains(watchNamespaces, namespace) {
+			result = append(result, namespace)
+		}
+	}
+
+	return result
+}
+
+// RemoveNamespacesWithoutSubjects returns the namespaces of the list where some of the ServiceAccount subjects live
+func RemoveNamespacesWithoutSubjects(namespaces []string, subjects []rbacv1.Subject) (result []string) {
+
+	subjectNamespaces := map[string]struct{}{}
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind {
+			subjectNamespaces[subject.Namespace] = struct{}{}
+		}
+	}
+
+	for _, namespace := range namespaces {
+		if _, found := subjectNamespaces[namespace]; found {
+			result = append(result, namespace)
+		}
+	}
+
+	return result
+}
+
+// CompileMatchRegex compiles the expression of a matchRegex found in the field of the spec.
+// It is anchored to the whole name when requireFullMatch is set
+func CompileMatchRegex(field string, matchRegex kuberbacv1alpha1.MatchRegexT) (compiledRegex *regexp.Regexp, err error) {
+
+	expression := matchRegex.Expression
+	if matchRegex.RequireFullMatch {
+		expression = "^(?:" + expression + ")$"
+	}
+
+	compiledRegex, err = regexp.Compile(expression)
+	if err != nil {
+		return compiledRegex, fmt.Errorf("%w: %s: %s", errInvalidRegex, field, err.Error())
+	}
+
+	return compiledRegex, err
+}
+
+// NewLabelSelector returns a selector matching both matchLabels and matchExpressions, using LabelSelector semantics.
+// It returns nil when none of them is filled
+func NewLabelSelector(matchLabels map[string]string, matchExpressions []metav1.LabelSelectorRequirement) (selector labels.Selector, err error) {
+
+	if len(matchLabels) == 0 && len(matchExpressions) == 0 {
+		return selector, err
+	}
+
+	selector, err = metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels:      matchLabels,
+		MatchExpressions: matchExpressions,
+	})
+	if err != nil {
+		err = fmt.Errorf("%w: invalid matchLabels or matchExpressions: %s", errInvalidSelector, err.Error())
+	}
+
+	return selector, err
+}
+
+// AnnotationSelectorT matches annotations by exact values and by regular expressions on their values.
+// Annotations missing on the object never match
+type AnnotationSelectorT struct {
+	values      map[string]string
+	expressions map[string]*regexp.Regexp
+}
+
+// NewAnnotationSelector returns a selector matching both matchAnnotations and matchAnnotationsRegex.
+// It returns nil when none of them is filled
+func NewAnnotationSelector(matchAnnotations, matchAnnotationsRegex map[string]string) (selector *AnnotationSelectorT, err error) {
+
+	if len(matchAnnotations) == 0 && len(matchAnnotationsRegex) == 0 {
+		return selector, err
+	}
+
+	selector = &AnnotationSelectorT{
+		values:      matchAnnotations,
+		expressions: map[string]*regexp.Regexp{},
+	}
+
+	for key, expression := range matchAnnotationsRegex {
+		selector.expressions[key], err = regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("%w: matchAnnotationsRegex for '%s': %s", errInvalidRegex, key, err.Error())
+		}
+	}
+
+	return selector, err
+}
+
+// Matches returns whether the annotations match the selector
+func (s *AnnotationSelectorT) Matches(annotations map[string]string) bool {
+	matched, _ := s.Explain(annotations)
+	return matched
+}
+
+// Explain returns whether the annotations match the selector, and the first annotation not matching it
+func (s *AnnotationSelectorT) Explain(annotations map[string]string) (matched bool, reason string) {
+
+	for _, key := range sortedKeys(s.values) {
+		value, found := annotations[key]
+		if !found {
+			return false, fmt.Sprintf("annotation '%s' is missing", key)
+		}
+		if value != s.values[key] {
+			return false, fmt.Sprintf("annotation '%s' is '%s', not '%s'", key, value, s.values[key])
+		}
+	}
+
+	for _, key := range sortedKeys(s.expressions) {
+		value, found := annotations[key]
+		if !found {
+			return false, fmt.Sprintf("annotation '%s' is missing", key)
+		}
+		if !s.expressions[key].MatchString(value) {
+			return false, fmt.Sprintf("annotation '%s' is '%s', not matching '%s'", key, value, s.expressions[key].String())
+		}
+	}
+
+	return true, "annotations match"
+}
+
+// sortedKeys returns the keys of the map sorted, so the explanations are stable
+func sortedKeys[V any](m map[string]V) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// ObjectMatcherT matches objects by the clause filled in a selector, explaining the decision taken.
+// Objects are matched by labels, annotations, a list of names or a regular expression on their names,
+// and every object is matched when none of them is filled
+type ObjectMatcherT struct {
+	LabelSelector      labels.Selector
+	AnnotationSelector *AnnotationSelectorT
+	MatchList          []string
+	MatchRegex         *regexp.Regexp
+	NegativeRegex      bool
+}
+
+// Matches returns whether the object matches the selector
+func (m *ObjectMatcherT) Matches(object metav1.Object) bool {
+	matched, _ := m.Explain(object)
+	return matched
+}
+
+// Explain returns whether the object matches the selector, and the clause matching or rejecting it
+func (m *ObjectMatcherT) Explain(object metav1.Object) (matched bool, reason string) {
+
+	switch {
+
+	// Matching by labels, reporting the first requirement not met
+	case m.LabelSelector != nil:
+		requirements, _ := m.LabelSelector.Requirements()
+		for _, requirement := range requirements {
+			if !requirement.Matches(labels.Set(object.GetLabels())) {
+				return false, fmt.Sprintf("labels do not match '%s'", requirement.String())
+			}
+		}
+		return true, fmt.Sprintf("labels match '%s'", m.LabelSelector.String())
+
+	// Matching by annotations
+	case m.AnnotationSelector != nil:
+		return m.AnnotationSelector.Explain(object.GetAnnotations())
+
+	// Matching by fixed list
+	case len(m.MatchList) > 0:
+		if slices.Contains(m.MatchList, object.GetName()) {
+			return true, "name is in matchList"
+		}
+		return false, "name is not in matchList"
+
+	// Matching by regex, which selects the names not matching it when negative
+	case m.MatchRegex != nil:
+		regexMatched := m.MatchRegex.MatchString(object.GetName())
+		switch {
+		case regexMatched && !m.NegativeRegex:
+			return true, fmt.Sprintf("name matches '%s'", m.MatchRegex.String())
+		case !regexMatched && !m.NegativeRegex:
+			return false, fmt.Sprintf("name does not match '%s'", m.MatchRegex.String())
+		case regexMatched:
+			return false, fmt.Sprintf("name matches negative '%s'", m.MatchRegex.String())
+		default:
+			return true, fmt.Sprintf("name does not match negative '%s'", m.MatchRegex.String())
+		}
+	}
+
+	return true, "selector is empty"
+}
+
+// CheckNamespaceSelector checks if the namespaceSelector has only one field filled
+func CheckNamespaceSelector(namespaceSelector *kuberbacv1alpha1.NamespaceSelectorT) (err error) {
+
+	// Check just only field is filled
+	filledSelectorFields := 0
+
+	// MatchExpressions are combined with MatchLabels, so both count as a single field
+	if len(namespaceSelector.MatchLabels) > 0 || len(namespaceSelector.MatchExpressions) > 0 {
+		filledSelectorFields++
+	}
+
+	// MatchAnnotationsRegex is combined with MatchAnnotations, so both count as a single field
+	if len(namespaceSelector.MatchAnnotations) > 0 || len(namespaceSelector.MatchAnnotationsRegex) > 0 {
+		filledSelectorFields++
+	}
+
+	if len(namespaceSelector.MatchList) > 0 {
+		filledSelectorFields++
+	}
+
+	if namespaceSelector.MatchRegex.Expression != "" {
+		filledSelectorFields++
+	}
+
+	if filledSelectorFields != 1 {
+		err = fmt.Errorf("%w: only one of the following fields is allowed as namespaceSelector: matchLabels (with matchExpressions), matchAnnotations (with matchAnnotationsRegex), matchList, matchRegex", errInvalidSelector)
+	}
+
+	return err
+}
+
+// NewNamespaceMatcher returns a matcher for the clause filled in a namespaceSelector.
+// Every namespace is matched when the namespaceSelector is empty
+func NewNamespaceMatcher(namespaceSelector *kuberbacv1alpha1.NamespaceSelectorT) (matcher *ObjectMatcherT, err error) {
+
+	matcher = &ObjectMatcherT{}
+
+	if reflect.ValueOf(*namespaceSelector).IsZero() {
+		return matcher, err
+	}
+
+	// Check just only field is filled
+	err = CheckNamespaceSelector(namespaceSelector)
+	if err != nil {
+		return matcher, err
+	}
+
+	//
+	if namespaceSelector.MatchRegex.Expression != "" {
+		matcher.MatchRegex, err = CompileMatchRegex("namespaceSelector.matchRegex", namespaceSelector.MatchRegex)
+		if err != nil {
+			return matcher, err
+		}
+		matcher.NegativeRegex = namespaceSelector.MatchRegex.Negative
+	}
+
+	//
+	matcher.LabelSelector, err = NewLabelSelector(namespaceSelector.MatchLabels, namespaceSelector.MatchExpressions)
+	if err != nil {
+		return matcher, err
+	}
+
+	//
+	matcher.AnnotationSelector, err = NewAnnotationSelector(namespaceSelector.MatchAnnotations, namespaceSelector.MatchAnnotationsRegex)
+	if err != nil {
+		return matcher, err
+	}
+
+	matcher.MatchList = namespaceSelector.MatchList
+
+	return matcher, err
+}
+
+// FilterNamespaceListBySelector returns a list of namespaces that match a namespaceSelector field
+func FilterNamespaceListBySelector(namespaceList *corev1.NamespaceList, namespaceSelector *kuberbacv1alpha1.NamespaceSelectorT) (namespaces []string, err error) {
+
+	matcher, err := NewNamespaceMatcher(namespaceSelector)
+	if err != nil {
+		return namespaces, err
+	}
+
+	for _, namespace := range namespaceList.Items {
+		if matcher.Matches(&namespace) {
+			namespaces = append(namespaces, namespace.Name)
+		}
+	}
+
+	return namespaces, err
+}
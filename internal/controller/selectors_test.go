@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// selectorNamespaceList are the namespaces filtered by the namespace selectors
+var selectorNamespaceList = &corev1.NamespaceList{
+	Items: []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "payments",
+			Labels:      map[string]string{"team": "payments"},
+			Annotations: map[string]string{"tenancy.company.com/owner": "payments@company.com", "tenancy.company.com/tier": "gold"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "payments-staging",
+			Labels:      map[string]string{"team": "payments"},
+			Annotations: map[string]string{"tenancy.company.com/owner": "payments@company.com", "tenancy.company.com/tier": "bronze"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "shipping",
+			Annotations: map[string]string{"tenancy.company.com/owner": "shipping@company.com"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	},
+}
+
+var _ = Describe("Namespace selectors", func() {
+
+	DescribeTable("When checking the fields filled in a namespace selector",
+		func(namespaceSelector kuberbacv1alpha1.NamespaceSelectorT, valid bool) {
+			err := CheckNamespaceSelector(&namespaceSelector)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(errInvalidSelector))
+			}
+		},
+		Entry("should accept matchAnnotations alone",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchAnnotations: map[string]string{"tenancy.company.com/owner": "payments@company.com"},
+			}, true),
+		Entry("should accept matchAnnotations together with matchAnnotationsRegex",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchAnnotations:      map[string]string{"tenancy.company.com/owner": "payments@company.com"},
+				MatchAnnotationsRegex: map[string]string{"tenancy.company.com/tier": "^gold$"},
+			}, true),
+		Entry("should reject matchAnnotations together with matchLabels",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchLabels:      map[string]string{"team": "payments"},
+				MatchAnnotations: map[string]string{"tenancy.company.com/owner": "payments@company.com"},
+			}, false),
+		Entry("should reject matchAnnotations together with matchList",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchList:        []string{"payments"},
+				MatchAnnotations: map[string]string{"tenancy.company.com/owner": "payments@company.com"},
+			}, false),
+		Entry("should reject an empty selector",
+			kuberbacv1alpha1.NamespaceSelectorT{}, false),
+	)
+
+	DescribeTable("When filtering namespaces by a namespace selector",
+		func(namespaceSelector kuberbacv1alpha1.NamespaceSelectorT, expected []string) {
+			namespaces, err := FilterNamespaceListBySelector(selectorNamespaceList, &namespaceSelector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaces).To(ConsistOf(expected))
+		},
+		Entry("should select the namespaces containing the annotations",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchAnnotations: map[string]string{"tenancy.company.com/owner": "payments@company.com"},
+			}, []string{"payments", "payments-staging"}),
+		Entry("should require every annotation to match",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchAnnotations: map[string]string{"tenancy.company.com/owner": "payments@company.com", "tenancy.company.com/tier": "gold"},
+			}, []string{"payments"}),
+		Entry("should require matchAnnotations and matchAnnotationsRegex to match",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchAnnotations:      map[string]string{"tenancy.company.com/owner": "payments@company.com"},
+				MatchAnnotationsRegex: map[string]string{"tenancy.company.com/tier": "^bronze$"},
+			}, []string{"payments-staging"}),
+		Entry("should select nothing when no namespace contains the annotations",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchAnnotations: map[string]string{"tenancy.company.com/owner": "billing@company.com"},
+			}, []string{}),
+		Entry("should select by labels as before",
+			kuberbacv1alpha1.NamespaceSelectorT{
+				MatchLabels: map[string]string{"team": "payments"},
+			}, []string{"payments", "payments-staging"}),
+	)
+
+	DescribeTable("When removing the system namespaces",
+		func(override *bool, excludeByDefault bool, expected []string) {
+			namespaces := []string{"default", "kube-system", "payments", "kube-public", "kube-node-lease"}
+			Expect(RemoveSystemNamespaces(namespaces, override, excludeByDefault)).To(Equal(expected))
+		},
+		Entry("should remove them by default when the flag excludes them",
+			nil, true, []string{"default", "payments"}),
+		Entry("should keep them by default when the flag does not exclude them",
+			nil, false, []string{"default", "kube-system", "payments", "kube-public", "kube-node-lease"}),
+		Entry("should keep them when the resource overrides the flag to false",
+			ptr.To(false), true, []string{"default", "kube-system", "payments", "kube-public", "kube-node-lease"}),
+		Entry("should remove them when the resource overrides the flag to true",
+			ptr.To(true), false, []string{"default", "payments"}),
+	)
+})
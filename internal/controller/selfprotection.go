@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+	"prosimcorp.com/kuberbac/pkg/policy"
+)
+
+const (
+	// DefaultOperatorServiceAccount, DefaultOperatorClusterRole and DefaultOperatorClusterRoleBinding are the names
+	// given by the default deployment to the ServiceAccount the operator runs with, its ClusterRole, and the binding between them
+	DefaultOperatorServiceAccount     = "kuberbac-controller-manager"
+	DefaultOperatorClusterRole        = "kuberbac-manager-role"
+	DefaultOperatorClusterRoleBinding = "kuberbac-manager-rolebinding"
+)
+
+var (
+	// selfProtectionVerbs are the verbs giving control over the resources protected by the self-protection
+	selfProtectionVerbs = []string{"create", "update", "patch", "delete", "deletecollection"}
+)
+
+// SelfProtectionT removes from the generated ClusterRoles the rules giving control over the operator, so tenants
+// allowed to create DynamicClusterRoles can not use them to take it over: writing the resources of kuberbac,
+// and touching the ServiceAccount and the ClusterRole the operator runs with, or the binding between them
+type SelfProtectionT struct {
+	// Disabled generates the rules as they are
+	Disabled bool
+
+	// ServiceAccount, ClusterRole and ClusterRoleBinding are the names of the identity of the operator.
+	// As ClusterRoles are not bound to namespaces, the ServiceAccount is protected on all of them
+	ServiceAccount     string
+	ClusterRole        string
+	ClusterRoleBinding string
+
+	// AllowedResources lists the resources whose rules are generated as they are, expressed as 'Kind/namespace/name',
+	// being the kind DynamicClusterRole or DynamicAccess
+	AllowedResources []string
+}
+
+// Validate returns an error when the allowed resources are not expressed as 'Kind/namespace/name'
+func (s *SelfProtectionT) Validate() (err error) {
+
+	for _, allowedResource := range s.AllowedResources {
+		parts := strings.Split(allowedResource, "/")
+		if len(parts) != 3 || parts[1] == "" || parts[2] == "" ||
+			!slices.Contains([]string{DynamicClusterRoleResourceType, DynamicAccessResourceType}, parts[0]) {
+			return fmt.Errorf("invalid allowed resource '%s': expected '%s/<namespace>/<name>' or '%s/<namespace>/<name>'",
+				allowedResource, DynamicClusterRoleResourceType, DynamicAccessResourceType)
+		}
+	}
+
+	return err
+}
+
+// Unprotected returns the objects of the identity of the operator that can not be protected, as they are denied by name
+// and the objects of their API groups can not be listed, or there is no cluster to list them from when rendering offline
+func (s *SelfProtectionT) Unprotected(objectListing ObjectListingT, online bool) (unprotected []string) {
+
+	if s.Disabled {
+		return unprotected
+	}
+
+	if s.ServiceAccount != "" && (!online || !objectListing.Allows("")) {
+		unprotected = append(unprotected, "ServiceAccount/"+s.ServiceAccount)
+	}
+
+	if !online || !objectListing.Allows(rbacv1.GroupName) {
+		if s.ClusterRole != "" {
+			unprotected = append(unprotected, "ClusterRole/"+s.ClusterRole)
+		}
+		if s.ClusterRoleBinding != "" {
+			unprotected = append(unprotected, "ClusterRoleBinding/"+s.ClusterRoleBinding)
+		}
+	}
+
+	return unprotected
+}
+
+// GetPolicyRules returns the rules denied by the self-protection to a DynamicClusterRole.
+// Names can only be denied when the objects of their resource can be listed, so the identity of the operator
+// is left out when rendering without a cluster, or when the controller is not allowed to list them. See Unprotected
+func (s *SelfProtectionT) GetPolicyRules(resource *kuberbacv1alpha1.DynamicClusterRole, objectListing ObjectListingT,
+	online bool) (result []rbacv1.PolicyRule) {
+
+	// DynamicAccesses are rendered as DynamicClusterRoles keeping their kind, so they are told apart from them
+	if s.Disabled || slices.Contains(s.AllowedResources, resource.Kind+"/"+client.ObjectKeyFromObject(resource).String()) {
+		return result
+	}
+
+	result = append(result, rbacv1.PolicyRule{
+		APIGroups: []string{kuberbacv1alpha1.GroupVersion.Group},
+		Resources: []string{"*"},
+		Verbs:     selfProtectionVerbs,
+	})
+
+	if !online {
+		return result
+	}
+
+	if s.ServiceAccount != "" && objectListing.Allows("") {
+		result = append(result, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"serviceaccounts", "serviceaccounts/token"},
+			ResourceNames: []string{s.ServiceAccount},
+			Verbs:         append(slices.Clone(selfProtectionVerbs), policy.ImpersonateVerb),
+		})
+	}
+
+	if !objectListing.Allows(rbacv1.GroupName) {
+		return result
+	}
+
+	if s.ClusterRole != "" {
+		result = append(result, rbacv1.PolicyRule{
+			APIGroups:     []string{rbacv1.GroupName},
+			Resources:     []string{"clusterroles"},
+			ResourceNames: []string{s.ClusterRole},
+			Verbs:         append(slices.Clone(selfProtectionVerbs), "bind", "escalate"),
+		})
+	}
+
+	if s.ClusterRoleBinding != "" {
+		result = append(result, rbacv1.PolicyRule{
+			APIGroups:     []string{rbacv1.GroupName},
+			Resources:     []string{"clusterrolebindings"},
+			ResourceNames: []string{s.ClusterRoleBinding},
+			Verbs:         selfProtectionVerbs,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// referenceAnnotationPrefix is the prefix of the annotations pointing generated objects to their owner
+	referenceAnnotationPrefix = "kuberbac.prosimcorp.com/owner-"
+)
+
+var (
+	// snapshotRemovedFields are the fields filled by the API server, or bound to the objects of a single cluster,
+	// which are removed from the objects of a snapshot, so they can be restored on any cluster
+	snapshotRemovedFields = [][]string{
+		{"metadata", "uid"},
+		{"metadata", "resourceVersion"},
+		{"metadata", "generation"},
+		{"metadata", "creationTimestamp"},
+		{"metadata", "managedFields"},
+		{"metadata", "selfLink"},
+		{"metadata", "ownerReferences"},
+		{"status"},
+	}
+)
+
+// TakeSnapshot returns the objects generated by the controllers, those carrying the reference annotations
+// of a known owner, as a single List ready to be restored by RestoreSnapshot on the same or another cluster.
+// Owner references are removed, as they point to the UIDs of the owners in this cluster. Namespaced objects
+// are only looked for in the given namespaces, or in all of them when empty
+func TakeSnapshot(ctx context.Context, reader client.Reader, namespaces []string) (snapshot *unstructured.UnstructuredList, err error) {
+
+	snapshot = &unstructured.UnstructuredList{}
+	snapshot.SetAPIVersion("v1")
+	snapshot.SetKind("List")
+
+	allErrors := []error{}
+	for _, janitorKind := range janitorKinds {
+		gvk := janitorKind.GVK
+
+		listNamespaces := []string{""}
+		if janitorKind.Namespaced && len(namespaces) > 0 {
+			listNamespaces = namespaces
+		}
+
+		for _, namespace := range listNamespaces {
+			objectList := &unstructured.UnstructuredList{}
+			objectList.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+			listOptions := []client.ListOption{}
+			if namespace != "" {
+				listOptions = append(listOptions, client.InNamespace(namespace))
+			}
+
+			err = listInPages(ctx, reader, objectList, func() error {
+				for _, object := range objectList.Items {
+
+					annotations := object.GetAnnotations()
+					if annotations["kuberbac.prosimcorp.com/owner-name"] == "" ||
+						!slices.Contains(janitorOwnerKinds, annotations["kuberbac.prosimcorp.com/owner-kind"]) {
+						continue
+					}
+
+					for _, field := range snapshotRemovedFields {
+						unstructured.RemoveNestedField(object.Object, field...)
+					}
+					object.SetGroupVersionKind(gvk)
+					snapshot.Items = append(snapshot.Items, object)
+				}
+				return nil
+			}, listOptions...)
+
+			// Admission policies are not served by old clusters
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			if err != nil {
+				allErrors = append(allErrors, fmt.Errorf("error listing %s: %s", gvk.Kind, err.Error()))
+			}
+		}
+	}
+
+	return snapshot, errors.Join(allErrors...)
+}
+
+// RestoreSnapshot applies the objects of a snapshot taken by TakeSnapshot, using Server-Side Apply with the field manager
+// of the operator, so it keeps owning them. Owners adopt restored objects through their reference annotations, while
+// the janitor deletes those whose owner does not exist. Setting orphan removes the reference annotations instead,
+// keeping restored objects untracked, as the 'Orphan' deletion policy does. Objects failing to be applied are skipped
+func RestoreSnapshot(ctx context.Context, c client.Client, snapshot *unstructured.UnstructuredList, orphan, dryRun bool) (
+	restored int, err error) {
+
+	applyOptions := []client.PatchOption{client.FieldOwner(fieldManager), client.ForceOwnership}
+	if dryRun {
+		applyOptions = append(applyOptions, client.DryRunAll)
+	}
+
+	allErrors := []error{}
+	for _, item := range snapshot.Items {
+		object := item.DeepCopy()
+
+		if orphan {
+			annotations := maps.Clone(object.GetAnnotations())
+			maps.DeleteFunc(annotations, func(key, _ string) bool {
+				return strings.HasPrefix(key, referenceAnnotationPrefix)
+			})
+			object.SetAnnotations(annotations)
+		}
+
+		err = c.Patch(ctx, object, client.Apply, applyOptions...)
+		if err != nil {
+			allErrors = append(allErrors, fmt.Errorf("error restoring %s '%s': %s",
+				object.GetKind(), client.ObjectKeyFromObject(object), err.Error()))
+			continue
+		}
+		restored++
+	}
+
+	return restored, errors.Join(allErrors...)
+}
@@ -0,0 +1,68 @@
+package discoverycache
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+
+	"prosimcorp.com/kuberbac/internal/metrics"
+)
+
+// DiscoveryCache stores the resources available in the cluster for a while,
+// so they are not requested to the API server on every synchronization.
+// It is safe to be shared between several reconcilers
+type DiscoveryCache struct {
+	discoveryClient discovery.DiscoveryInterface
+	ttl             time.Duration
+
+	//
+	mutex            sync.Mutex
+	apiGroups        []*metav1.APIGroup
+	apiResourceLists []*metav1.APIResourceList
+	expiration       time.Time
+}
+
+// NewDiscoveryCache returns a DiscoveryCache that refreshes its content after the TTL expires
+func NewDiscoveryCache(discoveryClient discovery.DiscoveryInterface, ttl time.Duration) *DiscoveryCache {
+	return &DiscoveryCache{
+		discoveryClient: discoveryClient,
+		ttl:             ttl,
+	}
+}
+
+// ServerGroupsAndResources returns the groups and resources available in the cluster.
+// They are retrieved from the API server only when the cache is empty, expired or invalidated.
+// Failed retrievals are never cached
+func (c *DiscoveryCache) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.apiResourceLists != nil && time.Now().Before(c.expiration) {
+		return c.apiGroups, c.apiResourceLists, nil
+	}
+
+	discoveryStartTime := time.Now()
+	apiGroups, apiResourceLists, err := c.discoveryClient.ServerGroupsAndResources()
+	metrics.DiscoveryRefreshDuration.Observe(time.Since(discoveryStartTime).Seconds())
+	if err != nil {
+		return apiGroups, apiResourceLists, err
+	}
+
+	c.apiGroups = apiGroups
+	c.apiResourceLists = apiResourceLists
+	c.expiration = time.Now().Add(c.ttl)
+
+	return c.apiGroups, c.apiResourceLists, nil
+}
+
+// Invalidate forces the next call to retrieve the resources from the API server.
+// It is intended to be called when the resources available in the cluster change, e.g. on CRD events
+func (c *DiscoveryCache) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.apiGroups = nil
+	c.apiResourceLists = nil
+}
@@ -11,11 +11,93 @@ const (
 
 	// Kubernetes error type
 	ConditionReasonKubernetesApiCallErrorType    = "KubernetesApiCallError"
-	ConditionReasonKubernetesApiCallErrorMessage = "Call to Kubernetes API failed. More info in logs."
+	ConditionReasonKubernetesApiCallErrorMessage = "Call to Kubernetes API failed, so it will be retried"
+
+	// Generated resources can not be written into the cluster
+	ConditionReasonTargetWriteFailedType    = "TargetWriteFailed"
+	ConditionReasonTargetWriteFailedMessage = "Generated resources can not be written, so it will be retried"
+
+	// Resources available in the cluster can not be discovered
+	ConditionReasonDiscoveryFailedType    = "DiscoveryFailed"
+	ConditionReasonDiscoveryFailedMessage = "Resources available in the cluster can not be discovered, so it will be retried"
+
+	// The mutation hook failed or rejected some generated resource
+	ConditionReasonMutationHookFailedType    = "MutationHookFailed"
+	ConditionReasonMutationHookFailedMessage = "Generated resources can not be mutated by the hook, so it will be retried"
+
+	// Generated rules exceed the ceiling configured in the operator
+	ConditionReasonEscalationRejectedType    = "EscalationRejected"
+	ConditionReasonEscalationRejectedMessage = "Generated rules contain privileged verbs or resources not allowed by the operator. More info in logs."
+
+	// The spec can not be synchronized until it is fixed
+	ConditionReasonInvalidSpecType    = "InvalidSpec"
+	ConditionReasonInvalidSpecMessage = "Spec is not valid, so it will not be retried until it changes"
+
+	// Some selector of the spec can not be evaluated until it is fixed
+	ConditionReasonSelectorErrorType    = "SelectorError"
+	ConditionReasonSelectorErrorMessage = "Some selector of the spec is not valid, so it will not be retried until it changes"
+
+	// Some regular expression of the selectors does not compile until it is fixed
+	ConditionReasonInvalidRegexType    = "InvalidRegex"
+	ConditionReasonInvalidRegexMessage = "Some regular expression of the selectors does not compile, so it will not be retried until it changes"
+
+	// The role referenced by a binding does not exist
+	ConditionReasonRoleRefNotFoundType    = "RoleRefNotFound"
+	ConditionReasonRoleRefNotFoundMessage = "Referenced role does not exist, so it will be retried until it appears. More info in logs."
+
+	// Several roles match the selector of a binding expecting only one of them
+	ConditionReasonAmbiguousRoleRefType    = "AmbiguousRoleRef"
+	ConditionReasonAmbiguousRoleRefMessage = "Several roles match a selector expecting only one of them, so it will be retried until only one matches"
+
+	// The name of some target is taken by an object not owned by the resource
+	ConditionReasonTargetOwnershipConflictType    = "TargetOwnershipConflict"
+	ConditionReasonTargetOwnershipConflictMessage = "Some target name is taken by an object not owned by this resource, so it is not overwritten. More info in logs."
 
 	// Success
 	ConditionReasonTargetSynced        = "TargetSynced"
 	ConditionReasonTargetSyncedMessage = "Target was successfully synced"
+
+	// Bindings were deleted as they expired
+	ConditionReasonBindingsExpiredType    = "BindingsExpired"
+	ConditionReasonBindingsExpiredMessage = "Bindings expired and were deleted. Extend 'expiresAfter' or recreate the resource to grant access again"
+
+	// Bindings were deleted as the schedule does not grant the access
+	ConditionReasonOutsideScheduleType    = "OutsideSchedule"
+	ConditionReasonOutsideScheduleMessage = "Bindings were deleted as the schedule does not grant the access now. They will be created again once it does"
+
+	// Success on dry-run mode
+	ConditionReasonTargetRendered        = "TargetRendered"
+	ConditionReasonTargetRenderedMessage = "Target was successfully rendered in dry-run mode. Nothing was changed in the cluster"
+
+	// ConditionTypeReady summarizes whether the resource is fully rendered for its current generation.
+	// It mirrors the ResourceSynced condition, and records the generation it refers to, so GitOps tools can rely on it
+	ConditionTypeReady = "Ready"
+
+	// The resource was not synchronized yet
+	ConditionReasonReconcilingType    = "Reconciling"
+	ConditionReasonReconcilingMessage = "Resource was not synchronized yet"
+
+	// ConditionTypeSizePressure indicates that some generated object is approaching the size limits of etcd
+	ConditionTypeSizePressure = "SizePressure"
+
+	// Generated objects are far from the size limits
+	ConditionReasonWithinSizeLimitType    = "WithinSizeLimit"
+	ConditionReasonWithinSizeLimitMessage = "Generated ClusterRoles are far from the object size limits"
+
+	// Some generated object is approaching the size limits
+	ConditionReasonApproachingSizeLimitType    = "ApproachingSizeLimit"
+	ConditionReasonApproachingSizeLimitMessage = "Some generated ClusterRole is approaching the object size limits. Consider compacting or sharding its rules. More info in logs."
+
+	// ConditionTypeTargetConflict indicates that some target name is declared by other resources too
+	ConditionTypeTargetConflict = "TargetConflict"
+
+	// No other resource declares the same target names
+	ConditionReasonNoTargetConflictType    = "NoTargetConflict"
+	ConditionReasonNoTargetConflictMessage = "No other resource declares the same target names"
+
+	// Some target name is declared by other resources too
+	ConditionReasonTargetNameSharedType    = "TargetNameShared"
+	ConditionReasonTargetNameSharedMessage = "Some target name is declared by other resources too, so only the owner of the generated object, or the oldest resource when it does not exist, writes it"
 )
 
 // NewCondition a set of default options for creating a Condition.
@@ -51,6 +133,23 @@ func UpdateCondition(conditions *[]metav1.Condition, condition metav1.Condition)
 		currentCondition.Status = condition.Status
 		currentCondition.Reason = condition.Reason
 		currentCondition.Message = condition.Message
+		currentCondition.ObservedGeneration = condition.ObservedGeneration
 		currentCondition.LastTransitionTime = metav1.Now()
 	}
 }
+
+// UpdateReadyCondition sets the Ready condition from the ResourceSynced one, for the generation just reconciled.
+// Resources not synchronized yet are not ready
+func UpdateReadyCondition(conditions *[]metav1.Condition, generation int64) {
+
+	//
+	condition := NewCondition(ConditionTypeReady, metav1.ConditionFalse,
+		ConditionReasonReconcilingType, ConditionReasonReconcilingMessage)
+
+	if syncedCondition := getCondition(conditions, ConditionTypeResourceSynced); syncedCondition != nil {
+		condition = NewCondition(ConditionTypeReady, syncedCondition.Status, syncedCondition.Reason, syncedCondition.Message)
+	}
+	condition.ObservedGeneration = generation
+
+	UpdateCondition(conditions, condition)
+}
@@ -1,5 +1,11 @@
 package globals
 
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
 func IsSubset(smaller, larger map[string]string) bool {
 	for key, value := range smaller {
 		if largerValue, ok := larger[key]; !ok || largerValue != value {
@@ -8,3 +14,34 @@ func IsSubset(smaller, larger map[string]string) bool {
 	}
 	return true
 }
+
+// RenderTemplate parses a Golang template and executes it with the given data
+func RenderTemplate(text string, data any) (result string, err error) {
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return result, err
+	}
+
+	buffer := bytes.Buffer{}
+	err = tmpl.Execute(&buffer, data)
+	if err != nil {
+		return result, err
+	}
+
+	return buffer.String(), err
+}
+
+// RenderTemplateMap executes the templates present on the values of a map with the given data
+func RenderTemplateMap(templates map[string]string, data any) (result map[string]string, err error) {
+
+	result = make(map[string]string, len(templates))
+	for key, value := range templates {
+		result[key], err = RenderTemplate(value, data)
+		if err != nil {
+			return result, fmt.Errorf("error rendering template for key '%s': %s", key, err.Error())
+		}
+	}
+
+	return result, err
+}
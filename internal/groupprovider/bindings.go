@@ -0,0 +1,76 @@
+package groupprovider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// bindingsOwnerKindAnnotation is set on the bindings generated by kuberbac. Their subjects are ignored,
+	// as they were already selected from this provider, and would be kept bound forever otherwise
+	bindingsOwnerKindAnnotation = "kuberbac.prosimcorp.com/owner-kind"
+)
+
+// BindingsProvider reads the groups and users from the subjects of the RoleBindings and ClusterRoleBindings
+// already present in the cluster. Only subjects bound by someone else are known, so it is a good fit
+// for clusters where identities are granted permissions by other tools
+type BindingsProvider struct {
+	Client client.Reader
+}
+
+// ListGroups returns the Group subjects of the bindings present in the cluster
+func (p *BindingsProvider) ListGroups(ctx context.Context) (groups []string, err error) {
+	return p.listSubjects(ctx, rbacv1.GroupKind)
+}
+
+// ListUsers returns the User subjects of the bindings present in the cluster
+func (p *BindingsProvider) ListUsers(ctx context.Context) (users []string, err error) {
+	return p.listSubjects(ctx, rbacv1.UserKind)
+}
+
+// listSubjects returns the sorted names of the subjects of a kind, ignoring the bindings generated by kuberbac
+func (p *BindingsProvider) listSubjects(ctx context.Context, kind string) (result []string, err error) {
+
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	err = p.Client.List(ctx, clusterRoleBindingList)
+	if err != nil {
+		return result, fmt.Errorf("error listing ClusterRoleBindings: %s", err.Error())
+	}
+
+	roleBindingList := &rbacv1.RoleBindingList{}
+	err = p.Client.List(ctx, roleBindingList)
+	if err != nil {
+		return result, fmt.Errorf("error listing RoleBindings: %s", err.Error())
+	}
+
+	subjectLists := [][]rbacv1.Subject{}
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+		if _, managed := clusterRoleBinding.Annotations[bindingsOwnerKindAnnotation]; managed {
+			continue
+		}
+		subjectLists = append(subjectLists, clusterRoleBinding.Subjects)
+	}
+	for _, roleBinding := range roleBindingList.Items {
+		if _, managed := roleBinding.Annotations[bindingsOwnerKindAnnotation]; managed {
+			continue
+		}
+		subjectLists = append(subjectLists, roleBinding.Subjects)
+	}
+
+	for _, subjects := range subjectLists {
+		for _, subject := range subjects {
+			if subject.Kind == kind && subject.Name != "" {
+				result = append(result, subject.Name)
+			}
+		}
+	}
+
+	slices.Sort(result)
+	result = slices.Compact(result)
+
+	return result, err
+}
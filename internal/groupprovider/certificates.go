@@ -0,0 +1,191 @@
+package groupprovider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"slices"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CertificatesInventoryKey is the key of the inventory ConfigMap containing the issued certificates, as JSON
+	CertificatesInventoryKey = "certificates.json"
+)
+
+// certificateRecordT is the identity of an issued certificate, remembered in the inventory until it expires
+type certificateRecordT struct {
+	User     string    `json:"user,omitempty"`
+	Groups   []string  `json:"groups,omitempty"`
+	NotAfter time.Time `json:"notAfter"`
+}
+
+// CertificatesProvider reads the groups and users from the client certificates issued by the cluster
+// through CertificateSigningRequests. The common name of each certificate is its user, and its organizations
+// are its groups. Only approved requests whose certificate is issued and not expired are read,
+// so the listed identities follow the certificates handed out by the platform.
+// Requests are garbage-collected about one hour after being approved, while certificates last much longer,
+// so issued certificates are remembered in the inventory ConfigMap, written through Writer, until they expire
+type CertificatesProvider struct {
+	Client client.Reader
+	Writer client.Writer
+
+	InventoryNamespace string
+	InventoryName      string
+}
+
+// ListGroups returns the organizations of the valid client certificates issued by the cluster
+func (p *CertificatesProvider) ListGroups(ctx context.Context) (groups []string, err error) {
+
+	certificates, err := p.listCertificates(ctx)
+	if err != nil {
+		return groups, err
+	}
+
+	for _, certificate := range certificates {
+		groups = append(groups, certificate.Groups...)
+	}
+
+	slices.Sort(groups)
+	groups = slices.Compact(groups)
+
+	return groups, err
+}
+
+// ListUsers returns the common names of the valid client certificates issued by the cluster
+func (p *CertificatesProvider) ListUsers(ctx context.Context) (users []string, err error) {
+
+	certificates, err := p.listCertificates(ctx)
+	if err != nil {
+		return users, err
+	}
+
+	for _, certificate := range certificates {
+		if certificate.User != "" {
+			users = append(users, certificate.User)
+		}
+	}
+
+	slices.Sort(users)
+	users = slices.Compact(users)
+
+	return users, err
+}
+
+// listCertificates returns the certificates of the inventory merged with the ones issued for the approved
+// CertificateSigningRequests of the kube-apiserver-client signer, keyed by the UID of their request.
+// Expired certificates are dropped, and the inventory is written back when it changes
+func (p *CertificatesProvider) listCertificates(ctx context.Context) (result map[string]certificateRecordT, err error) {
+
+	inventory := &corev1.ConfigMap{}
+	err = p.Client.Get(ctx, types.NamespacedName{Namespace: p.InventoryNamespace, Name: p.InventoryName}, inventory)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return result, fmt.Errorf("error getting certificates inventory ConfigMap '%s/%s': %s", p.InventoryNamespace, p.InventoryName, err.Error())
+	}
+	inventoryFound := err == nil
+
+	result = map[string]certificateRecordT{}
+	if inventoryData := inventory.Data[CertificatesInventoryKey]; inventoryData != "" {
+		err = json.Unmarshal([]byte(inventoryData), &result)
+		if err != nil {
+			return result, fmt.Errorf("error parsing certificates inventory ConfigMap '%s/%s': %s", p.InventoryNamespace, p.InventoryName, err.Error())
+		}
+	}
+
+	csrList := &certificatesv1.CertificateSigningRequestList{}
+	err = p.Client.List(ctx, csrList)
+	if err != nil {
+		return result, fmt.Errorf("error listing CertificateSigningRequests: %s", err.Error())
+	}
+
+	for _, csr := range csrList.Items {
+		if csr.Spec.SignerName != certificatesv1.KubeAPIServerClientSignerName || !isApproved(&csr) {
+			continue
+		}
+
+		// Requests are approved before being signed, so the certificate may not be issued yet
+		block, _ := pem.Decode(csr.Status.Certificate)
+		if block == nil {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		result[string(csr.UID)] = certificateRecordT{
+			User:     certificate.Subject.CommonName,
+			Groups:   certificate.Subject.Organization,
+			NotAfter: certificate.NotAfter.UTC(),
+		}
+	}
+
+	now := time.Now()
+	for uid, certificate := range result {
+		if now.After(certificate.NotAfter) {
+			delete(result, uid)
+		}
+	}
+
+	err = p.writeInventory(ctx, inventory, inventoryFound, result)
+	return result, err
+}
+
+// writeInventory creates or updates the inventory ConfigMap when the certificates changed.
+// Conflicts are ignored, as another writer already stored it, and the requests are merged again on the next list
+func (p *CertificatesProvider) writeInventory(ctx context.Context, inventory *corev1.ConfigMap, inventoryFound bool,
+	certificates map[string]certificateRecordT) (err error) {
+
+	inventoryData, err := json.Marshal(certificates)
+	if err != nil {
+		return fmt.Errorf("error encoding certificates inventory: %s", err.Error())
+	}
+	if inventoryFound && inventory.Data[CertificatesInventoryKey] == string(inventoryData) {
+		return nil
+	}
+
+	inventory.Namespace = p.InventoryNamespace
+	inventory.Name = p.InventoryName
+	if inventory.Data == nil {
+		inventory.Data = map[string]string{}
+	}
+	inventory.Data[CertificatesInventoryKey] = string(inventoryData)
+
+	if inventoryFound {
+		err = p.Writer.Update(ctx, inventory)
+	} else {
+		err = p.Writer.Create(ctx, inventory)
+	}
+	if err != nil && !apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error writing certificates inventory ConfigMap '%s/%s': %s", p.InventoryNamespace, p.InventoryName, err.Error())
+	}
+
+	return nil
+}
+
+// isApproved returns whether the CertificateSigningRequest is approved, and neither denied nor failed
+func isApproved(csr *certificatesv1.CertificateSigningRequest) (approved bool) {
+
+	for _, condition := range csr.Status.Conditions {
+		if condition.Status == corev1.ConditionFalse {
+			continue
+		}
+
+		switch condition.Type {
+		case certificatesv1.CertificateApproved:
+			approved = true
+		case certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+			return false
+		}
+	}
+
+	return approved
+}
@@ -0,0 +1,57 @@
+package groupprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigMapGroupsKey is the key of the ConfigMap containing the groups, one per line
+	ConfigMapGroupsKey = "groups"
+
+	// ConfigMapUsersKey is the key of the ConfigMap containing the users, one per line
+	ConfigMapUsersKey = "users"
+)
+
+// ConfigMapProvider reads the groups and users from a ConfigMap. They are expressed one per line
+// under the keys 'groups' and 'users'. Empty lines and lines starting with '#' are ignored
+type ConfigMapProvider struct {
+	Client    client.Reader
+	Namespace string
+	Name      string
+}
+
+// ListGroups returns the groups defined in the ConfigMap
+func (p *ConfigMapProvider) ListGroups(ctx context.Context) (groups []string, err error) {
+	return p.listKey(ctx, ConfigMapGroupsKey)
+}
+
+// ListUsers returns the users defined in the ConfigMap
+func (p *ConfigMapProvider) ListUsers(ctx context.Context) (users []string, err error) {
+	return p.listKey(ctx, ConfigMapUsersKey)
+}
+
+// listKey returns the lines of a key of the ConfigMap
+func (p *ConfigMapProvider) listKey(ctx context.Context, key string) (result []string, err error) {
+
+	configMap := &corev1.ConfigMap{}
+	err = p.Client.Get(ctx, types.NamespacedName{Namespace: p.Namespace, Name: p.Name}, configMap)
+	if err != nil {
+		return result, fmt.Errorf("error getting %s ConfigMap '%s/%s': %s", key, p.Namespace, p.Name, err.Error())
+	}
+
+	for _, line := range strings.Split(configMap.Data[key], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return result, err
+}
@@ -0,0 +1,117 @@
+package groupprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// ProviderTypeConfigMap reads the groups from a ConfigMap in the cluster
+	ProviderTypeConfigMap = "configmap"
+
+	// ProviderTypeSCIM reads the groups from the '/Groups' endpoint of a SCIM 2.0 server,
+	// usually exposed by identity providers next to their OIDC endpoints
+	ProviderTypeSCIM = "scim"
+
+	// ProviderTypeBindings reads the groups from the subjects of the RoleBindings and ClusterRoleBindings
+	// already present in the cluster
+	ProviderTypeBindings = "bindings"
+
+	// ProviderTypeCertificates reads the groups and users from the client certificates issued by the cluster
+	// through CertificateSigningRequests
+	ProviderTypeCertificates = "certificates"
+)
+
+// Provider lists the groups available in an external directory. Kubernetes does not store groups,
+// so a provider is needed to select Group subjects by regular expression
+type Provider interface {
+	ListGroups(ctx context.Context) ([]string, error)
+}
+
+// UserProvider lists the users available in an external directory. As for groups,
+// Kubernetes does not store users, so a provider is needed to select User subjects by regular expression
+type UserProvider interface {
+	ListUsers(ctx context.Context) ([]string, error)
+}
+
+// listCache stores a list of names until it expires
+type listCache struct {
+	mutex      sync.Mutex
+	items      []string
+	expiration time.Time
+}
+
+// get returns the cached items, calling the list function only when the cache is empty or expired.
+// Failed calls are never cached
+func (c *listCache) get(ctx context.Context, ttl time.Duration, list func(ctx context.Context) ([]string, error)) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.items != nil && time.Now().Before(c.expiration) {
+		return c.items, nil
+	}
+
+	items, err := list(ctx)
+	if err != nil {
+		return items, err
+	}
+
+	c.items = items
+	if c.items == nil {
+		c.items = []string{}
+	}
+	c.expiration = time.Now().Add(ttl)
+
+	return c.items, nil
+}
+
+// CachedProvider stores the groups listed by another provider for a while,
+// so the directory is not requested on every synchronization.
+// It is safe to be shared between several reconcilers
+type CachedProvider struct {
+	provider Provider
+	ttl      time.Duration
+
+	//
+	cache listCache
+}
+
+// NewCachedProvider returns a CachedProvider that refreshes its content after the TTL expires
+func NewCachedProvider(provider Provider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		provider: provider,
+		ttl:      ttl,
+	}
+}
+
+// ListGroups returns the groups of the directory. They are requested to the underlying provider
+// only when the cache is empty or expired. Failed requests are never cached
+func (c *CachedProvider) ListGroups(ctx context.Context) ([]string, error) {
+	return c.cache.get(ctx, c.ttl, c.provider.ListGroups)
+}
+
+// CachedUserProvider stores the users listed by another provider for a while,
+// so the directory is not requested on every synchronization.
+// It is safe to be shared between several reconcilers
+type CachedUserProvider struct {
+	provider UserProvider
+	ttl      time.Duration
+
+	//
+	cache listCache
+}
+
+// NewCachedUserProvider returns a CachedUserProvider that refreshes its content after the TTL expires
+func NewCachedUserProvider(provider UserProvider, ttl time.Duration) *CachedUserProvider {
+	return &CachedUserProvider{
+		provider: provider,
+		ttl:      ttl,
+	}
+}
+
+// ListUsers returns the users of the directory. They are requested to the underlying provider
+// only when the cache is empty or expired. Failed requests are never cached
+func (c *CachedUserProvider) ListUsers(ctx context.Context) ([]string, error) {
+	return c.cache.get(ctx, c.ttl, c.provider.ListUsers)
+}
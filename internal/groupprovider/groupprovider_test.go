@@ -0,0 +1,322 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groupprovider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// countingProvider returns its groups and counts the calls, failing while err is set
+type countingProvider struct {
+	groups []string
+	err    error
+	calls  int
+}
+
+func (p *countingProvider) ListGroups(ctx context.Context) ([]string, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.groups, nil
+}
+
+var _ = Describe("SCIM provider", func() {
+
+	ctx := context.Background()
+
+	// newSCIMServer serves the groups in pages of the given size, ignoring the requested count
+	newSCIMServer := func(groups []string, pageSize int, requests *[]*http.Request) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests = append(*requests, r)
+
+			startIndex, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+			if err != nil || r.URL.Path != "/scim/v2/Groups" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			page := scimListResponse{TotalResults: len(groups), StartIndex: startIndex}
+			for index := startIndex - 1; index < len(groups) && index < startIndex-1+pageSize; index++ {
+				page.Resources = append(page.Resources, struct {
+					DisplayName string `json:"displayName"`
+				}{DisplayName: groups[index]})
+			}
+			page.ItemsPerPage = len(page.Resources)
+
+			w.Header().Set("Content-Type", "application/scim+json")
+			_ = json.NewEncoder(w).Encode(page)
+		}))
+	}
+
+	It("should request every page of groups", func() {
+		requests := []*http.Request{}
+		server := newSCIMServer([]string{"admins", "developers", "operators", "viewers", "auditors"}, 2, &requests)
+		defer server.Close()
+
+		provider := &SCIMProvider{URL: server.URL + "/scim/v2/", HTTPClient: server.Client()}
+		groups, err := provider.ListGroups(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal([]string{"admins", "developers", "operators", "viewers", "auditors"}))
+
+		Expect(requests).To(HaveLen(3))
+		for index, startIndex := range []string{"1", "3", "5"} {
+			Expect(requests[index].URL.Query().Get("startIndex")).To(Equal(startIndex))
+			Expect(requests[index].URL.Query().Get("count")).To(Equal(strconv.Itoa(scimPageSize)))
+		}
+	})
+
+	It("should send the token read from the file as a bearer token", func() {
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("secret-token\n"), 0o600)).To(Succeed())
+
+		requests := []*http.Request{}
+		server := newSCIMServer([]string{"admins"}, 2, &requests)
+		defer server.Close()
+
+		provider := &SCIMProvider{URL: server.URL + "/scim/v2", TokenFile: tokenFile, HTTPClient: server.Client()}
+		_, err := provider.ListGroups(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer secret-token"))
+	})
+
+	It("should stop on an empty page even when more groups are announced", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"totalResults": 10, "Resources": []}`))
+		}))
+		defer server.Close()
+
+		provider := &SCIMProvider{URL: server.URL, HTTPClient: server.Client()}
+		groups, err := provider.ListGroups(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(BeEmpty())
+	})
+
+	It("should fail on unexpected status codes", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		provider := &SCIMProvider{URL: server.URL, HTTPClient: server.Client()}
+		_, err := provider.ListGroups(ctx)
+		Expect(err).To(MatchError(ContainSubstring("unexpected status 401")))
+	})
+})
+
+var _ = Describe("ConfigMap provider", func() {
+
+	ctx := context.Background()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "identities", Namespace: "kuberbac"},
+		Data: map[string]string{
+			ConfigMapGroupsKey: "# Teams of the platform\nadmins\n\n  developers  \n#operators\n",
+			ConfigMapUsersKey:  "alice\nbob",
+		},
+	}
+
+	newProvider := func(name string) *ConfigMapProvider {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(configMap.DeepCopy()).Build()
+		return &ConfigMapProvider{Client: fakeClient, Namespace: "kuberbac", Name: name}
+	}
+
+	It("should read one entry per line, ignoring empty lines and comments", func() {
+		groups, err := newProvider("identities").ListGroups(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal([]string{"admins", "developers"}))
+
+		users, err := newProvider("identities").ListUsers(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(users).To(Equal([]string{"alice", "bob"}))
+	})
+
+	It("should fail when the ConfigMap does not exist", func() {
+		_, err := newProvider("missing").ListGroups(ctx)
+		Expect(err).To(MatchError(ContainSubstring("ConfigMap 'kuberbac/missing'")))
+	})
+})
+
+var _ = Describe("Cached provider", func() {
+
+	ctx := context.Background()
+
+	It("should serve the cached groups until they expire", func() {
+		provider := &countingProvider{groups: []string{"admins"}}
+		cachedProvider := NewCachedProvider(provider, 50*time.Millisecond)
+
+		for range 3 {
+			groups, err := cachedProvider.ListGroups(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(Equal([]string{"admins"}))
+		}
+		Expect(provider.calls).To(Equal(1))
+
+		provider.groups = []string{"admins", "developers"}
+		Eventually(func() ([]string, error) {
+			return cachedProvider.ListGroups(ctx)
+		}).WithTimeout(time.Second).WithPolling(10 * time.Millisecond).Should(Equal([]string{"admins", "developers"}))
+		Expect(provider.calls).To(BeNumerically(">=", 2))
+	})
+
+	It("should not cache the failed requests", func() {
+		provider := &countingProvider{err: errors.New("directory unavailable")}
+		cachedProvider := NewCachedProvider(provider, time.Hour)
+
+		_, err := cachedProvider.ListGroups(ctx)
+		Expect(err).To(HaveOccurred())
+
+		provider.err = nil
+		provider.groups = []string{"admins"}
+		groups, err := cachedProvider.ListGroups(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal([]string{"admins"}))
+		Expect(provider.calls).To(Equal(2))
+	})
+
+	It("should cache empty results too", func() {
+		provider := &countingProvider{}
+		cachedProvider := NewCachedProvider(provider, time.Hour)
+
+		for range 2 {
+			groups, err := cachedProvider.ListGroups(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(BeEmpty())
+		}
+		Expect(provider.calls).To(Equal(1))
+	})
+})
+
+var _ = Describe("Certificates provider", func() {
+
+	ctx := context.Background()
+
+	// issuedCertificate returns a PEM certificate for a user and its groups, valid until notAfter
+	issuedCertificate := func(user string, groups []string, notAfter time.Time) []byte {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: user, Organization: groups},
+			NotBefore:    notAfter.Add(-48 * time.Hour),
+			NotAfter:     notAfter,
+		}
+		certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+	}
+
+	csr := func(name string, certificate []byte, conditionType certificatesv1.RequestConditionType) *certificatesv1.CertificateSigningRequest {
+		return &certificatesv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid")},
+			Spec:       certificatesv1.CertificateSigningRequestSpec{SignerName: certificatesv1.KubeAPIServerClientSignerName},
+			Status: certificatesv1.CertificateSigningRequestStatus{
+				Conditions:  []certificatesv1.CertificateSigningRequestCondition{{Type: conditionType, Status: corev1.ConditionTrue}},
+				Certificate: certificate,
+			},
+		}
+	}
+
+	newProvider := func(objects ...client.Object) *CertificatesProvider {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).Build()
+		return &CertificatesProvider{Client: fakeClient, Writer: fakeClient, InventoryNamespace: "kuberbac", InventoryName: "certificates"}
+	}
+
+	validUntil := time.Now().Add(24 * time.Hour)
+
+	It("should read the identities of the approved and valid certificates", func() {
+		provider := newProvider(
+			csr("alice", issuedCertificate("alice", []string{"developers", "oncall"}, validUntil), certificatesv1.CertificateApproved),
+			csr("bob", issuedCertificate("bob", []string{"admins"}, validUntil), certificatesv1.CertificateDenied),
+			csr("carol", issuedCertificate("carol", []string{"auditors"}, time.Now().Add(-time.Hour)), certificatesv1.CertificateApproved),
+			csr("dave", nil, certificatesv1.CertificateApproved),
+		)
+
+		users, err := provider.ListUsers(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(users).To(Equal([]string{"alice"}))
+
+		groups, err := provider.ListGroups(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal([]string{"developers", "oncall"}))
+	})
+
+	It("should remember the certificates whose requests were garbage-collected until they expire", func() {
+		aliceCSR := csr("alice", issuedCertificate("alice", []string{"developers"}, validUntil), certificatesv1.CertificateApproved)
+		provider := newProvider(aliceCSR)
+
+		users, err := provider.ListUsers(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(users).To(Equal([]string{"alice"}))
+
+		By("garbage-collecting the request")
+		Expect(provider.Writer.Delete(ctx, aliceCSR)).To(Succeed())
+
+		users, err = provider.ListUsers(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(users).To(Equal([]string{"alice"}))
+
+		By("expiring the remembered certificate")
+		inventory := &corev1.ConfigMap{}
+		Expect(provider.Client.Get(ctx, types.NamespacedName{Namespace: "kuberbac", Name: "certificates"}, inventory)).To(Succeed())
+		inventory.Data[CertificatesInventoryKey] = `{"alice-uid":{"user":"alice","groups":["developers"],"notAfter":"2020-01-01T00:00:00Z"}}`
+		Expect(provider.Writer.Update(ctx, inventory)).To(Succeed())
+
+		users, err = provider.ListUsers(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(users).To(BeEmpty())
+
+		Expect(provider.Client.Get(ctx, types.NamespacedName{Namespace: "kuberbac", Name: "certificates"}, inventory)).To(Succeed())
+		Expect(inventory.Data[CertificatesInventoryKey]).To(Equal("{}"))
+	})
+
+	It("should fail on inventories that can not be parsed", func() {
+		provider := newProvider(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kuberbac", Name: "certificates"},
+			Data:       map[string]string{CertificatesInventoryKey: "{"},
+		})
+
+		_, err := provider.ListUsers(ctx)
+		Expect(err).To(MatchError(ContainSubstring("error parsing certificates inventory")))
+	})
+})
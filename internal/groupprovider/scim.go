@@ -0,0 +1,117 @@
+package groupprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// scimPageSize is the number of groups requested on each page
+	scimPageSize = 100
+)
+
+// scimListResponse represents the subset of a SCIM 2.0 ListResponse needed to list groups
+// Ref: https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+type scimListResponse struct {
+	TotalResults int `json:"totalResults"`
+	ItemsPerPage int `json:"itemsPerPage"`
+	StartIndex   int `json:"startIndex"`
+	Resources    []struct {
+		DisplayName string `json:"displayName"`
+	} `json:"Resources"`
+}
+
+// SCIMProvider reads the groups from the '/Groups' endpoint of a SCIM 2.0 server.
+// Groups are identified by their 'displayName', which is expected to match the group names in the OIDC tokens
+type SCIMProvider struct {
+	// URL is the base URL of the SCIM server, e.g. 'https://idp.example.com/scim/v2'
+	URL string
+
+	// TokenFile is the path to a file containing a bearer token. It is read on each request,
+	// so rotated tokens are picked up. Optional
+	TokenFile string
+
+	HTTPClient *http.Client
+}
+
+// ListGroups returns the display names of all the groups, requesting as many pages as needed
+func (p *SCIMProvider) ListGroups(ctx context.Context) (groups []string, err error) {
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	token := ""
+	if p.TokenFile != "" {
+		tokenBytes, err := os.ReadFile(p.TokenFile)
+		if err != nil {
+			return groups, fmt.Errorf("error reading SCIM token: %s", err.Error())
+		}
+		token = strings.TrimSpace(string(tokenBytes))
+	}
+
+	// SCIM indexes are 1-based
+	for startIndex := 1; ; {
+		page, err := p.getPage(ctx, httpClient, token, startIndex)
+		if err != nil {
+			return groups, err
+		}
+
+		for _, resource := range page.Resources {
+			if resource.DisplayName != "" {
+				groups = append(groups, resource.DisplayName)
+			}
+		}
+
+		startIndex += len(page.Resources)
+		if len(page.Resources) == 0 || startIndex > page.TotalResults {
+			break
+		}
+	}
+
+	return groups, err
+}
+
+// getPage requests a page of groups starting at the given index
+func (p *SCIMProvider) getPage(ctx context.Context, httpClient *http.Client, token string, startIndex int) (page *scimListResponse, err error) {
+
+	query := url.Values{}
+	query.Set("attributes", "displayName")
+	query.Set("startIndex", strconv.Itoa(startIndex))
+	query.Set("count", strconv.Itoa(scimPageSize))
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.URL, "/")+"/Groups?"+query.Encode(), nil)
+	if err != nil {
+		return page, err
+	}
+
+	request.Header.Set("Accept", "application/scim+json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return page, fmt.Errorf("error requesting SCIM groups: %s", err.Error())
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return page, fmt.Errorf("error requesting SCIM groups: unexpected status %s", response.Status)
+	}
+
+	page = &scimListResponse{}
+	err = json.NewDecoder(response.Body).Decode(page)
+	if err != nil {
+		return page, fmt.Errorf("error decoding SCIM groups: %s", err.Error())
+	}
+
+	return page, err
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "kuberbac"
+
+	// Labels used to identify the custom resource that produced the metric
+	labelKind      = "kind"
+	labelNamespace = "namespace"
+	labelName      = "name"
+)
+
+var (
+	// SyncDuration measures how long each synchronization of a custom resource takes
+	SyncDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "sync_duration_seconds",
+			Help:      "Duration of the synchronization of the targets of a resource",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{labelKind, labelNamespace, labelName},
+	)
+
+	// SyncErrors counts the synchronizations of a custom resource that failed
+	SyncErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "sync_errors_total",
+			Help:      "Number of failed synchronizations of the targets of a resource",
+		},
+		[]string{labelKind, labelNamespace, labelName},
+	)
+
+	// GeneratedRules represents the number of PolicyRules rendered into the ClusterRoles of a DynamicClusterRole
+	GeneratedRules = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "generated_rules",
+			Help:      "Number of PolicyRules generated for a resource on its last successful synchronization",
+		},
+		[]string{labelKind, labelNamespace, labelName},
+	)
+
+	// GeneratedBindings represents the number of RoleBindings or ClusterRoleBindings generated by a DynamicRoleBinding
+	GeneratedBindings = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "generated_bindings",
+			Help:      "Number of bindings generated for a resource on its last successful synchronization",
+		},
+		[]string{labelKind, labelNamespace, labelName},
+	)
+
+	// StretchedRules represents the number of rules the allow rules of a DynamicClusterRole are expanded into
+	// before evaluating the deny ones. Large values usually point to wildcards matching too many resources
+	StretchedRules = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "stretched_rules",
+			Help:      "Number of rules the allow rules of a resource are stretched into on its last synchronization",
+		},
+		[]string{labelKind, labelNamespace, labelName},
+	)
+
+	// GeneratedObjectBytes represents the size of the largest object generated for a resource,
+	// to be compared with the object size limit of etcd
+	GeneratedObjectBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "generated_object_bytes",
+			Help:      "Size in bytes of the largest object generated for a resource on its last synchronization",
+		},
+		[]string{labelKind, labelNamespace, labelName},
+	)
+
+	// DiscoveryRefreshDuration measures how long it takes to retrieve the resources available in the cluster
+	DiscoveryRefreshDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "discovery_refresh_duration_seconds",
+			Help:      "Duration of the retrieval of API resources from the discovery endpoint",
+			Buckets:   prometheus.DefBuckets,
+		},
+	)
+)
+
+func init() {
+	// Register custom metrics into the global controller-runtime registry,
+	// so they are served together with the rest of the manager metrics
+	metrics.Registry.MustRegister(
+		SyncDuration,
+		SyncErrors,
+		GeneratedRules,
+		GeneratedBindings,
+		StretchedRules,
+		GeneratedObjectBytes,
+		DiscoveryRefreshDuration,
+	)
+}
+
+// DeleteResourceMetrics removes all the series related to a custom resource.
+// It is intended to be called when the resource is deleted from the cluster
+func DeleteResourceMetrics(kind, namespace, name string) {
+	labels := prometheus.Labels{
+		labelKind:      kind,
+		labelNamespace: namespace,
+		labelName:      name,
+	}
+
+	SyncDuration.Delete(labels)
+	SyncErrors.Delete(labels)
+	GeneratedRules.Delete(labels)
+	GeneratedBindings.Delete(labels)
+	StretchedRules.Delete(labels)
+	GeneratedObjectBytes.Delete(labels)
+}
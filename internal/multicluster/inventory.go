@@ -0,0 +1,145 @@
+package multicluster
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+)
+
+const (
+	// DefaultKubeconfigKey is the key of the inventory Secrets containing the kubeconfig of the member cluster
+	DefaultKubeconfigKey = "kubeconfig"
+
+	// DefaultSelector selects the Secrets of the inventory among the ones living in its namespace
+	DefaultSelector = "kuberbac.prosimcorp.com/member-cluster=true"
+)
+
+// MemberCluster is a cluster of the inventory, reachable through the client built from its kubeconfig.
+// Err is set instead of the client when the kubeconfig can not be loaded
+type MemberCluster struct {
+	// Name is the name of the Secret containing the kubeconfig of the cluster
+	Name   string
+	Client client.Client
+	Err    error
+
+	// Discovery serves the resources available in the member cluster, so rules are expanded against them
+	Discovery *discoverycache.DiscoveryCache
+}
+
+// memberClient is a client built from the content of an inventory Secret
+type memberClient struct {
+	resourceVersion string
+	client          client.Client
+	discovery       *discoverycache.DiscoveryCache
+}
+
+// Inventory reads the member clusters of a fleet from Secrets containing their kubeconfig, so a management cluster
+// can propagate the generated resources onto them. Clients are built again only when their Secret changes.
+// It is safe to be shared between several reconcilers
+type Inventory struct {
+	// Reader reads the inventory Secrets from the management cluster. It should not be served by the cache,
+	// so the operator does not need to watch every Secret
+	Reader client.Reader
+
+	// Namespace and Selector define the Secrets of the inventory. KubeconfigKey is the key of the kubeconfig inside them
+	Namespace     string
+	Selector      labels.Selector
+	KubeconfigKey string
+
+	// Scheme is used by the clients of the member clusters
+	Scheme *runtime.Scheme
+
+	// DiscoveryCacheTTL is the time the resources available in each member cluster are cached for
+	DiscoveryCacheTTL time.Duration
+
+	// clients caches the client of each member cluster, along with the version of the Secret it was built from
+	mutex   sync.Mutex
+	clients map[string]memberClient
+}
+
+// ListMemberClusters returns the member clusters of the inventory, sorted by name.
+// Clusters whose kubeconfig can not be loaded are returned too, carrying the error
+func (i *Inventory) ListMemberClusters(ctx context.Context) (clusters []MemberCluster, err error) {
+
+	secretList := &corev1.SecretList{}
+	err = i.Reader.List(ctx, secretList, client.InNamespace(i.Namespace), client.MatchingLabelsSelector{Selector: i.Selector})
+	if err != nil {
+		return clusters, fmt.Errorf("error listing the Secrets of the cluster inventory: %s", err.Error())
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	desiredClients := map[string]memberClient{}
+	for _, secret := range secretList.Items {
+
+		cachedClient, found := i.clients[secret.Name]
+		if !found || cachedClient.resourceVersion != secret.ResourceVersion {
+			memberClusterClient, memberClusterDiscovery, err := i.newClient(&secret)
+			if err != nil {
+				clusters = append(clusters, MemberCluster{Name: secret.Name, Err: err})
+				continue
+			}
+			cachedClient = memberClient{resourceVersion: secret.ResourceVersion, client: memberClusterClient,
+				discovery: memberClusterDiscovery}
+		}
+
+		desiredClients[secret.Name] = cachedClient
+		clusters = append(clusters, MemberCluster{Name: secret.Name, Client: cachedClient.client,
+			Discovery: cachedClient.discovery})
+	}
+
+	// Clients of removed Secrets are forgotten
+	i.clients = desiredClients
+
+	slices.SortFunc(clusters, func(a, b MemberCluster) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return clusters, nil
+}
+
+// newClient returns a client and a discovery cache for the member cluster whose kubeconfig is contained in the Secret
+func (i *Inventory) newClient(secret *corev1.Secret) (memberClusterClient client.Client,
+	memberClusterDiscovery *discoverycache.DiscoveryCache, err error) {
+
+	kubeconfigKey := i.KubeconfigKey
+	if kubeconfigKey == "" {
+		kubeconfigKey = DefaultKubeconfigKey
+	}
+
+	kubeconfig, found := secret.Data[kubeconfigKey]
+	if !found {
+		return memberClusterClient, memberClusterDiscovery, fmt.Errorf("Secret '%s' does not contain the key '%s'", secret.Name, kubeconfigKey)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return memberClusterClient, memberClusterDiscovery, fmt.Errorf("invalid kubeconfig in Secret '%s': %s", secret.Name, err.Error())
+	}
+
+	memberClusterClient, err = client.New(config, client.Options{Scheme: i.Scheme})
+	if err != nil {
+		return memberClusterClient, memberClusterDiscovery, fmt.Errorf("error creating client from Secret '%s': %s", secret.Name, err.Error())
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return memberClusterClient, memberClusterDiscovery, fmt.Errorf("error creating discovery client from Secret '%s': %s", secret.Name, err.Error())
+	}
+	memberClusterDiscovery = discoverycache.NewDiscoveryCache(discoveryClient, i.DiscoveryCacheTTL)
+
+	return memberClusterClient, memberClusterDiscovery, err
+}
@@ -0,0 +1,159 @@
+package mutationhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// protectedAnnotationPrefix is the prefix of the annotations used by the controllers to track the objects
+	// they generate. The hook can not change them, as the objects would not be recognized as owned anymore
+	protectedAnnotationPrefix = "kuberbac.prosimcorp.com/"
+
+	// maxResponseBytes limits the size of the objects returned by the hook
+	maxResponseBytes = 4 * 1024 * 1024
+)
+
+var (
+	// ErrRejected is returned when the hook refuses an object, answering with an unexpected status
+	ErrRejected = errors.New("rejected by the mutation hook")
+)
+
+// Hook sends the objects rendered by the controllers to an external webhook right before they are applied,
+// so organizations can enforce naming conventions or inject metadata centrally.
+// The object is sent as JSON in a POST request, and the webhook answers with '200 OK' and the object to apply,
+// '204 No Content' to apply it as it is, or any other status to reject it, using the body as the reason
+type Hook struct {
+	// URL is the endpoint of the webhook, e.g. 'https://policies.example.com/kuberbac/mutate'
+	URL string
+
+	// TokenFile is the path to a file containing a bearer token. It is read on each request,
+	// so rotated tokens are picked up. Optional
+	TokenFile string
+
+	HTTPClient *http.Client
+}
+
+// Mutate sends the object to the webhook, and replaces it by the one returned. Only the labels and annotations
+// of the object can be changed by the webhook, except the tracking annotations, so other responses are rejected
+func (h *Hook) Mutate(ctx context.Context, object client.Object) (err error) {
+
+	httpClient := h.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	token := ""
+	if h.TokenFile != "" {
+		tokenBytes, err := os.ReadFile(h.TokenFile)
+		if err != nil {
+			return fmt.Errorf("error reading mutation hook token: %s", err.Error())
+		}
+		token = strings.TrimSpace(string(tokenBytes))
+	}
+
+	body, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("error encoding object for the mutation hook: %s", err.Error())
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("error requesting mutation hook: %s", err.Error())
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("error reading mutation hook response: %s", err.Error())
+	}
+
+	switch response.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusOK:
+	default:
+		reason := strings.TrimSpace(string(responseBody))
+		if reason == "" {
+			reason = response.Status
+		}
+		return fmt.Errorf("%w: %s", ErrRejected, reason)
+	}
+
+	// Decode into an empty object of the same type, so fields removed by the webhook are removed too
+	mutatedObject := reflect.New(reflect.TypeOf(object).Elem()).Interface().(client.Object)
+	err = json.Unmarshal(responseBody, mutatedObject)
+	if err != nil {
+		return fmt.Errorf("error decoding mutation hook response: %s", err.Error())
+	}
+	mutatedObject.GetObjectKind().SetGroupVersionKind(object.GetObjectKind().GroupVersionKind())
+
+	err = checkIdentity(object, mutatedObject)
+	if err != nil {
+		return err
+	}
+
+	reflect.ValueOf(object).Elem().Set(reflect.ValueOf(mutatedObject).Elem())
+	return nil
+}
+
+// checkIdentity returns an error when the mutated object is not recognizable as the original one,
+// or anything else than its labels and annotations was changed
+func checkIdentity(object, mutatedObject client.Object) error {
+
+	if mutatedObject.GetName() != object.GetName() || mutatedObject.GetNamespace() != object.GetNamespace() {
+		return fmt.Errorf("%w: the name or namespace of the object was changed", ErrRejected)
+	}
+
+	if !reflect.DeepEqual(mutatedObject.GetOwnerReferences(), object.GetOwnerReferences()) {
+		return fmt.Errorf("%w: the owner references of the object were changed", ErrRejected)
+	}
+
+	protectedAnnotations := func(annotations map[string]string) map[string]string {
+		result := maps.Clone(annotations)
+		maps.DeleteFunc(result, func(key, _ string) bool {
+			return !strings.HasPrefix(key, protectedAnnotationPrefix)
+		})
+		return result
+	}
+	if !maps.Equal(protectedAnnotations(mutatedObject.GetAnnotations()), protectedAnnotations(object.GetAnnotations())) {
+		return fmt.Errorf("%w: the annotations with prefix '%s' were changed", ErrRejected, protectedAnnotationPrefix)
+	}
+
+	// Rules, subjects and role references were already checked against the escalation protection
+	// and the self-protection, so the webhook can not change them after that
+	withoutMetadata := func(object client.Object) client.Object {
+		result := object.DeepCopyObject().(client.Object)
+		result.SetLabels(nil)
+		result.SetAnnotations(nil)
+		return result
+	}
+	if !equality.Semantic.DeepEqual(withoutMetadata(mutatedObject), withoutMetadata(object)) {
+		return fmt.Errorf("%w: only the labels and annotations of the object can be changed", ErrRejected)
+	}
+
+	return nil
+}
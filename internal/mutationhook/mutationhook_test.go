@@ -0,0 +1,158 @@
+package mutationhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newClusterRole returns a ClusterRole as rendered by the controllers, tracked by its annotations and owner
+func newClusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "developers",
+			Labels: map[string]string{"team": "platform"},
+			Annotations: map[string]string{
+				"kuberbac.prosimcorp.com/owner-name": "developers",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "kuberbac.prosimcorp.com/v1alpha1", Kind: "DynamicClusterRole", Name: "developers", UID: "1234"},
+			},
+		},
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+		},
+	}
+}
+
+// newMutatingServer returns a webhook answering with the received ClusterRole once mutated by the given function
+func newMutatingServer(mutate func(clusterRole *rbacv1.ClusterRole)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clusterRole := &rbacv1.ClusterRole{}
+		err := json.NewDecoder(r.Body).Decode(clusterRole)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mutate(clusterRole)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(clusterRole)
+	}))
+}
+
+var _ = Describe("Mutation hook", func() {
+
+	ctx := context.Background()
+
+	It("should replace the object by the one returned with '200 OK'", func() {
+		server := newMutatingServer(func(clusterRole *rbacv1.ClusterRole) {
+			clusterRole.Labels["cost-center"] = "1234"
+			clusterRole.Annotations["example.com/reviewed"] = "true"
+		})
+		defer server.Close()
+
+		clusterRole := newClusterRole()
+		err := (&Hook{URL: server.URL}).Mutate(ctx, clusterRole)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterRole.Labels).To(HaveKeyWithValue("cost-center", "1234"))
+		Expect(clusterRole.Annotations).To(HaveKeyWithValue("example.com/reviewed", "true"))
+		Expect(clusterRole.Rules).To(Equal(newClusterRole().Rules))
+		Expect(clusterRole.GroupVersionKind()).To(Equal(rbacv1.SchemeGroupVersion.WithKind("ClusterRole")))
+	})
+
+	It("should keep the object as it is with '204 No Content'", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		clusterRole := newClusterRole()
+		err := (&Hook{URL: server.URL}).Mutate(ctx, clusterRole)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusterRole).To(Equal(newClusterRole()))
+	})
+
+	It("should reject the object with any other status, using the body as the reason", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "names must start with 'team-'", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		clusterRole := newClusterRole()
+		err := (&Hook{URL: server.URL}).Mutate(ctx, clusterRole)
+		Expect(err).To(MatchError(ErrRejected))
+		Expect(err).To(MatchError(ContainSubstring("names must start with 'team-'")))
+		Expect(clusterRole).To(Equal(newClusterRole()))
+	})
+
+	It("should send the token read from the token file", func() {
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("secret\n"), 0o600)).To(Succeed())
+
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := (&Hook{URL: server.URL, TokenFile: tokenFile}).Mutate(ctx, newClusterRole())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authorization).To(Equal("Bearer secret"))
+	})
+
+	It("should not read responses larger than the limit", func() {
+		server := newMutatingServer(func(clusterRole *rbacv1.ClusterRole) {
+			clusterRole.Annotations["example.com/padding"] = strings.Repeat("a", maxResponseBytes)
+		})
+		defer server.Close()
+
+		clusterRole := newClusterRole()
+		err := (&Hook{URL: server.URL}).Mutate(ctx, clusterRole)
+		Expect(err).To(MatchError(ContainSubstring("error decoding mutation hook response")))
+		Expect(clusterRole).To(Equal(newClusterRole()))
+	})
+
+	DescribeTable("should reject the responses changing the identity or the content of the object",
+		func(mutate func(clusterRole *rbacv1.ClusterRole), reason string) {
+			server := newMutatingServer(mutate)
+			defer server.Close()
+
+			clusterRole := newClusterRole()
+			err := (&Hook{URL: server.URL}).Mutate(ctx, clusterRole)
+			Expect(err).To(MatchError(ErrRejected))
+			Expect(err).To(MatchError(ContainSubstring(reason)))
+			Expect(clusterRole).To(Equal(newClusterRole()))
+		},
+		Entry("changed name", func(clusterRole *rbacv1.ClusterRole) {
+			clusterRole.Name = "team-developers"
+		}, "name or namespace"),
+		Entry("changed owner references", func(clusterRole *rbacv1.ClusterRole) {
+			clusterRole.OwnerReferences = nil
+		}, "owner references"),
+		Entry("changed tracking annotation", func(clusterRole *rbacv1.ClusterRole) {
+			clusterRole.Annotations["kuberbac.prosimcorp.com/owner-name"] = "admins"
+		}, "kuberbac.prosimcorp.com/"),
+		Entry("removed tracking annotation", func(clusterRole *rbacv1.ClusterRole) {
+			delete(clusterRole.Annotations, "kuberbac.prosimcorp.com/owner-name")
+		}, "kuberbac.prosimcorp.com/"),
+		Entry("added tracking annotation", func(clusterRole *rbacv1.ClusterRole) {
+			clusterRole.Annotations["kuberbac.prosimcorp.com/owner-namespace"] = "default"
+		}, "kuberbac.prosimcorp.com/"),
+		Entry("changed rules", func(clusterRole *rbacv1.ClusterRole) {
+			clusterRole.Rules[0].Verbs = append(clusterRole.Rules[0].Verbs, "delete")
+		}, "only the labels and annotations"),
+	)
+})
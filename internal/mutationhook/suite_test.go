@@ -0,0 +1,14 @@
+package mutationhook
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMutationHook(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Mutation Hook Suite")
+}
@@ -0,0 +1,237 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"prosimcorp.com/kuberbac/internal/discoverycache"
+)
+
+const (
+	// DefaultInterval is the time between consecutive checks
+	DefaultInterval = time.Minute
+)
+
+var (
+	// errNotChecked is reported until the checks run for the first time
+	errNotChecked = errors.New("readiness checks did not run yet")
+
+	// RequiredPermissions are the permissions the operator can not work without. They are a subset of its ClusterRole,
+	// focused on generating resources, as missing them makes every synchronization fail
+	RequiredPermissions = []authorizationv1.ResourceAttributes{
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterroles", Verb: "list"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterroles", Verb: "watch"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterroles", Verb: "create"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterroles", Verb: "patch"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterroles", Verb: "delete"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterroles", Verb: "escalate"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", Verb: "list"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", Verb: "create"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", Verb: "patch"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", Verb: "delete"},
+		{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", Verb: "bind"},
+		{Group: "rbac.authorization.k8s.io", Resource: "roles", Verb: "list"},
+		{Group: "rbac.authorization.k8s.io", Resource: "roles", Verb: "create"},
+		{Group: "rbac.authorization.k8s.io", Resource: "roles", Verb: "patch"},
+		{Group: "rbac.authorization.k8s.io", Resource: "roles", Verb: "delete"},
+		{Group: "rbac.authorization.k8s.io", Resource: "roles", Verb: "escalate"},
+		{Group: "rbac.authorization.k8s.io", Resource: "rolebindings", Verb: "list"},
+		{Group: "rbac.authorization.k8s.io", Resource: "rolebindings", Verb: "create"},
+		{Group: "rbac.authorization.k8s.io", Resource: "rolebindings", Verb: "patch"},
+		{Group: "rbac.authorization.k8s.io", Resource: "rolebindings", Verb: "delete"},
+		{Group: "rbac.authorization.k8s.io", Resource: "rolebindings", Verb: "bind"},
+		{Group: "", Resource: "namespaces", Verb: "list"},
+		{Group: "", Resource: "serviceaccounts", Verb: "list"},
+		{Group: "", Resource: "serviceaccounts", Verb: "create"},
+		{Group: "", Resource: "serviceaccounts", Verb: "patch"},
+		{Group: "", Resource: "serviceaccounts", Verb: "delete"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicclusterroles", Verb: "update"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicclusterroles", Subresource: "status", Verb: "update"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicrolebindings", Verb: "update"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicrolebindings", Subresource: "status", Verb: "update"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicserviceaccounts", Verb: "update"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicserviceaccounts", Subresource: "status", Verb: "update"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicaccesses", Verb: "update"},
+		{Group: "kuberbac.prosimcorp.com", Resource: "dynamicaccesses", Subresource: "status", Verb: "update"},
+		{Group: "*", Resource: "*", Verb: "list"},
+	}
+
+	// namespacedResources are the resources of RequiredPermissions living inside namespaces
+	namespacedResources = []string{
+		"roles", "rolebindings", "serviceaccounts",
+		"dynamicclusterroles", "dynamicrolebindings", "dynamicserviceaccounts", "dynamicaccesses",
+	}
+)
+
+// RestrictedPermissions returns the RequiredPermissions for operators restricted to list the objects of some API groups,
+// replacing the permission to list every resource by the permission to list the resources of those groups
+func RestrictedPermissions(groups []string) (result []authorizationv1.ResourceAttributes) {
+
+	for _, permission := range RequiredPermissions {
+		if permission.Group == "*" && permission.Resource == "*" {
+			continue
+		}
+		result = append(result, permission)
+	}
+
+	for _, group := range groups {
+		result = append(result, authorizationv1.ResourceAttributes{Group: group, Resource: "*", Verb: "list"})
+	}
+
+	return result
+}
+
+// NamespacedPermissions returns the permissions on namespaced resources once for each of the namespaces,
+// as operators restricted to some namespaces may only be granted them inside those ones.
+// Permissions on cluster-scoped resources are kept as they are
+func NamespacedPermissions(permissions []authorizationv1.ResourceAttributes, namespaces []string) (result []authorizationv1.ResourceAttributes) {
+
+	for _, permission := range permissions {
+		if len(namespaces) == 0 || !slices.Contains(namespacedResources, permission.Resource) {
+			result = append(result, permission)
+			continue
+		}
+
+		for _, namespace := range namespaces {
+			namespacedPermission := permission
+			namespacedPermission.Namespace = namespace
+			result = append(result, namespacedPermission)
+		}
+	}
+
+	return result
+}
+
+// Gate checks periodically that the operator is able to work: the resources of the cluster can be discovered,
+// and its own permissions are enough. Results are served to the readiness probe, so broken deployments are caught
+// by rollout checks instead of failing on every synchronization.
+// Checks run in the background, as the probe would time out waiting for them
+type Gate struct {
+	DiscoveryCache *discoverycache.DiscoveryCache
+	Client         client.Client
+
+	// Permissions are checked by SelfSubjectAccessReviews. RequiredPermissions are used when empty
+	Permissions []authorizationv1.ResourceAttributes
+	Interval    time.Duration
+
+	// Namespaces restrict the permissions on namespaced resources to the watched namespaces. All of them when empty
+	Namespaces []string
+
+	//
+	mutex   sync.RWMutex
+	lastErr error
+	checked bool
+}
+
+// Start runs the checks until the context is cancelled. It implements manager.Runnable
+func (g *Gate) Start(ctx context.Context) error {
+
+	interval := g.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := g.runChecks(ctx)
+		if err != nil {
+			log.FromContext(ctx).Info("Readiness checks failed", "error", err.Error())
+		}
+
+		g.mutex.Lock()
+		g.lastErr = err
+		g.checked = true
+		g.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns false, so replicas not leading are also checked and reported as ready
+func (g *Gate) NeedLeaderElection() bool {
+	return false
+}
+
+// Check returns the result of the last checks. It implements healthz.Checker
+func (g *Gate) Check(_ *http.Request) error {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	if !g.checked {
+		return errNotChecked
+	}
+	return g.lastErr
+}
+
+// runChecks discovers the resources of the cluster and reviews the permissions of the operator
+func (g *Gate) runChecks(ctx context.Context) (err error) {
+
+	if g.DiscoveryCache != nil {
+		_, _, err = g.DiscoveryCache.ServerGroupsAndResources()
+		if err != nil {
+			return fmt.Errorf("error discovering the resources of the cluster: %s", err.Error())
+		}
+	}
+
+	permissions := g.Permissions
+	if len(permissions) == 0 {
+		permissions = RequiredPermissions
+	}
+	permissions = NamespacedPermissions(permissions, g.Namespaces)
+
+	deniedPermissions := []string{}
+	for _, permission := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: permission.DeepCopy(),
+			},
+		}
+
+		err = g.Client.Create(ctx, review)
+		if err != nil {
+			return fmt.Errorf("error reviewing the permissions of the operator: %s", err.Error())
+		}
+
+		if !review.Status.Allowed {
+			deniedPermissions = append(deniedPermissions, formatPermission(permission))
+		}
+	}
+
+	if len(deniedPermissions) > 0 {
+		return fmt.Errorf("missing permissions: %s", strings.Join(deniedPermissions, ", "))
+	}
+
+	return err
+}
+
+// formatPermission returns a compact representation of a permission, e.g. 'patch clusterroles.rbac.authorization.k8s.io'
+// or 'patch roles.rbac.authorization.k8s.io in namespace payments'
+func formatPermission(permission authorizationv1.ResourceAttributes) string {
+	resource := permission.Resource
+	if permission.Group != "" {
+		resource += "." + permission.Group
+	}
+	if permission.Subresource != "" {
+		resource += "/" + permission.Subresource
+	}
+	if permission.Namespace != "" {
+		resource += " in namespace " + permission.Namespace
+	}
+	return permission.Verb + " " + resource
+}
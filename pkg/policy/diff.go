@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"cmp"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// PermissionT represents the smallest unit of access granted by a PolicyRule:
+// a single verb over a resource, optionally restricted to an object name, or over a NonResourceURL
+type PermissionT struct {
+	APIGroup       string `json:"apiGroup,omitempty"`
+	Resource       string `json:"resource,omitempty"`
+	ResourceName   string `json:"resourceName,omitempty"`
+	NonResourceURL string `json:"nonResourceURL,omitempty"`
+	Verb           string `json:"verb"`
+}
+
+// String returns the permission in the form of 'verb resource.group [name]', or 'verb url' for NonResourceURLs
+func (p PermissionT) String() string {
+
+	if p.NonResourceURL != "" {
+		return p.Verb + " " + p.NonResourceURL
+	}
+
+	result := p.Verb + " " + p.Resource
+	if p.APIGroup != "" {
+		result += "." + p.APIGroup
+	}
+	if p.ResourceName != "" {
+		result += " " + p.ResourceName
+	}
+
+	return result
+}
+
+// comparePermissions sorts permissions by what they are granted on, and then by verb,
+// so the permissions over the same resource are listed together
+func comparePermissions(a, b PermissionT) int {
+	return cmp.Or(
+		cmp.Compare(a.NonResourceURL, b.NonResourceURL),
+		cmp.Compare(a.APIGroup, b.APIGroup),
+		cmp.Compare(a.Resource, b.Resource),
+		cmp.Compare(a.ResourceName, b.ResourceName),
+		cmp.Compare(a.Verb, b.Verb),
+	)
+}
+
+// GetPermissions flattens a list of PolicyRules into the sorted list of permissions they grant.
+// Permissions restricted to an object name are omitted when the same verb is granted over the whole resource
+func GetPermissions(policyRules []rbacv1.PolicyRule) (result []PermissionT) {
+
+	for _, policyRule := range policyRules {
+
+		if len(policyRule.NonResourceURLs) > 0 {
+			for _, url := range policyRule.NonResourceURLs {
+				for _, verb := range policyRule.Verbs {
+					result = append(result, PermissionT{NonResourceURL: url, Verb: verb})
+				}
+			}
+			continue
+		}
+
+		resourceNames := policyRule.ResourceNames
+		if len(resourceNames) == 0 {
+			resourceNames = []string{""}
+		}
+
+		for _, group := range policyRule.APIGroups {
+			for _, resource := range policyRule.Resources {
+				for _, resourceName := range resourceNames {
+					for _, verb := range policyRule.Verbs {
+						result = append(result, PermissionT{
+							APIGroup:     group,
+							Resource:     resource,
+							ResourceName: resourceName,
+							Verb:         verb,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(result, comparePermissions)
+	result = slices.Compact(result)
+
+	// Deletion happens in place, so the whole resources are looked up on a copy
+	allPermissions := slices.Clone(result)
+	return slices.DeleteFunc(result, func(permission PermissionT) bool {
+		if permission.ResourceName == "" {
+			return false
+		}
+
+		permission.ResourceName = ""
+		_, found := slices.BinarySearchFunc(allPermissions, permission, comparePermissions)
+		return found
+	})
+}
+
+// DiffPolicyRules compares the permissions granted by two lists of PolicyRules, returning those only
+// granted by the next list, and those only granted by the previous one. Both results are sorted
+func DiffPolicyRules(previousRules, nextRules []rbacv1.PolicyRule) (added, removed []PermissionT) {
+
+	previousPermissions := GetPermissions(previousRules)
+	nextPermissions := GetPermissions(nextRules)
+
+	for _, permission := range nextPermissions {
+		if _, found := slices.BinarySearchFunc(previousPermissions, permission, comparePermissions); !found {
+			added = append(added, permission)
+		}
+	}
+
+	for _, permission := range previousPermissions {
+		if _, found := slices.BinarySearchFunc(nextPermissions, permission, comparePermissions); !found {
+			removed = append(removed, permission)
+		}
+	}
+
+	return added, removed
+}
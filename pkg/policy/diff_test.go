@@ -0,0 +1,87 @@
+package policy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+var _ = Describe("PolicyRules permissions diff", func() {
+
+	podsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}, Verbs: []string{"get", "list"}}
+	deploymentsRule := rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}}
+	secretRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"db"}, Verbs: []string{"get"}}
+	healthzRule := rbacv1.PolicyRule{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}}
+
+	Context("When flattening rules into permissions", func() {
+
+		It("should return a sorted permission per verb, resource and name", func() {
+			Expect(GetPermissions([]rbacv1.PolicyRule{deploymentsRule, secretRule, healthzRule, podsRule})).To(Equal([]PermissionT{
+				{Resource: "pods", Verb: "get"},
+				{Resource: "pods", Verb: "list"},
+				{Resource: "pods/log", Verb: "get"},
+				{Resource: "pods/log", Verb: "list"},
+				{Resource: "secrets", ResourceName: "db", Verb: "get"},
+				{APIGroup: "apps", Resource: "deployments", Verb: "get"},
+				{NonResourceURL: "/healthz", Verb: "get"},
+			}))
+		})
+
+		It("should merge duplicated permissions", func() {
+			Expect(GetPermissions([]rbacv1.PolicyRule{deploymentsRule, deploymentsRule})).To(HaveLen(1))
+		})
+
+		It("should omit named permissions covered by the whole resource", func() {
+			secretsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+			Expect(GetPermissions([]rbacv1.PolicyRule{secretRule, secretsRule})).To(Equal([]PermissionT{
+				{Resource: "secrets", Verb: "get"},
+			}))
+		})
+
+		It("should format permissions like kubectl", func() {
+			Expect(PermissionT{APIGroup: "apps", Resource: "deployments", Verb: "get"}.String()).To(Equal("get deployments.apps"))
+			Expect(PermissionT{Resource: "secrets", ResourceName: "db", Verb: "get"}.String()).To(Equal("get secrets db"))
+			Expect(PermissionT{NonResourceURL: "/healthz", Verb: "get"}.String()).To(Equal("get /healthz"))
+		})
+	})
+
+	Context("When comparing two lists of rules", func() {
+
+		It("should return nothing when they grant the same permissions", func() {
+			splitPodsRules := []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "get"}},
+				{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get", "list"}},
+			}
+
+			added, removed := DiffPolicyRules([]rbacv1.PolicyRule{podsRule}, splitPodsRules)
+			Expect(added).To(BeEmpty())
+			Expect(removed).To(BeEmpty())
+		})
+
+		It("should return the permissions added and removed", func() {
+			nextPodsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "watch"}}
+
+			added, removed := DiffPolicyRules(
+				[]rbacv1.PolicyRule{podsRule, healthzRule},
+				[]rbacv1.PolicyRule{nextPodsRule, deploymentsRule})
+			Expect(added).To(Equal([]PermissionT{
+				{Resource: "pods", Verb: "watch"},
+				{APIGroup: "apps", Resource: "deployments", Verb: "get"},
+			}))
+			Expect(removed).To(Equal([]PermissionT{
+				{Resource: "pods", Verb: "list"},
+				{Resource: "pods/log", Verb: "get"},
+				{Resource: "pods/log", Verb: "list"},
+				{NonResourceURL: "/healthz", Verb: "get"},
+			}))
+		})
+
+		It("should report narrowing a resource down to some names", func() {
+			secretsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+
+			added, removed := DiffPolicyRules([]rbacv1.PolicyRule{secretsRule}, []rbacv1.PolicyRule{secretRule})
+			Expect(added).To(Equal([]PermissionT{{Resource: "secrets", ResourceName: "db", Verb: "get"}}))
+			Expect(removed).To(Equal([]PermissionT{{Resource: "secrets", Verb: "get"}}))
+		})
+	})
+})
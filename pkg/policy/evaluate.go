@@ -0,0 +1,342 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// EvaluateSpecialCases checks for special cases in the PolicyRules maps
+// and returns the resulting map with them evaluated
+func (p *ProcessorT) EvaluateSpecialCases(ctx context.Context, allowMap, denyMap map[string]rbacv1.PolicyRule) (
+	result map[string]rbacv1.PolicyRule, err error) {
+
+	for denyMapkey, policyRule := range denyMap {
+		if strings.HasPrefix(denyMapkey, "nonresourceurl") || IsGroupKey(denyMapkey) {
+			continue
+		}
+
+		// Generic resource found, ignore it
+		parts := strings.Split(denyMapkey, "#")
+		if parts[2] == "" {
+			continue
+		}
+
+		// We found a deny rule acting on a Resource with ResourceName,
+		// Find the Resources without ResourceName in the allow map
+		// and add all the resource names minus the ones in the deny rule
+		key := strings.Join(parts[:2], "#") + "#"
+		if _, ok := allowMap[key]; ok {
+
+			// Find the GVKR for the resource allocated in deny
+			tmpGvkr := GVKR{}
+			coreResourceType := strings.Split(policyRule.Resources[0], "/")[0]
+			for _, gvkr := range p.ResourcesByGroup[policyRule.APIGroups[0]] {
+				if gvkr.Resource == coreResourceType {
+					tmpGvkr = gvkr
+				}
+			}
+
+			// Offline rendering has no access to the objects of the cluster
+			if p.ObjectLister == nil {
+				return result, fmt.Errorf("listing objects from the cluster is required to evaluate deny rules with resourceNames")
+			}
+
+			// Get a list of all the resources of the same type
+			objectNames, err := p.listObjectNames(ctx, tmpGvkr, nil)
+			if err != nil {
+				return result, err
+			}
+
+			for _, objectName := range objectNames {
+				allowMap[key+objectName] = rbacv1.PolicyRule{
+					APIGroups:     allowMap[key].APIGroups,
+					Resources:     allowMap[key].Resources,
+					ResourceNames: []string{objectName},
+					Verbs:         allowMap[key].Verbs,
+				}
+			}
+
+			delete(allowMap, key)
+		}
+	}
+
+	result = allowMap
+	return result, err
+}
+
+// getListNamespaces returns the namespaces to list the objects of a resource when evaluating deny rules.
+// Namespaced resources are only listed inside the namespace of the processor, when it is set,
+// or inside its list of namespaces otherwise. An empty namespace lists the objects of the whole cluster
+func (p *ProcessorT) getListNamespaces(gvkr GVKR) []string {
+
+	if !gvkr.Namespaced {
+		return []string{""}
+	}
+
+	if p.Namespace != "" {
+		return []string{p.Namespace}
+	}
+
+	if p.Namespaces != nil {
+		return p.Namespaces
+	}
+
+	return []string{""}
+}
+
+// listObjectNames returns the names of the objects of a resource living in the namespaces considered by the processor.
+// Objects sharing the same name in several namespaces are only returned once, as rules can not tell them apart
+func (p *ProcessorT) listObjectNames(ctx context.Context, gvkr GVKR, selector labels.Selector) (result []string, err error) {
+
+	listedNames := map[string]struct{}{}
+	for _, namespace := range p.getListNamespaces(gvkr) {
+		objectNames, err := p.ObjectLister.ListObjectNames(ctx, gvkr.GVK, namespace, selector)
+		if err != nil {
+			return result, err
+		}
+
+		for _, objectName := range objectNames {
+			if _, listed := listedNames[objectName]; !listed {
+				listedNames[objectName] = struct{}{}
+				result = append(result, objectName)
+			}
+		}
+	}
+
+	return result, err
+}
+
+// EvaluatePolicyRulesInNamespaces evaluates the allow and deny PolicyRule maps once per namespace, for renderings
+// of namespaced roles. Resources allowed but denied by name are expanded to the names of the objects living
+// in each namespace, so the denied names are only excluded from the objects of that namespace.
+// The maps are not modified, as each namespace evaluates its own copy
+func (p *ProcessorT) EvaluatePolicyRulesInNamespaces(ctx context.Context, allowMap, denyMap map[string]rbacv1.PolicyRule,
+	namespaces []string) (result map[string]map[string]rbacv1.PolicyRule, err error) {
+
+	result = make(map[string]map[string]rbacv1.PolicyRule, len(namespaces))
+	for _, namespace := range namespaces {
+
+		namespacedProcessor := *p
+		namespacedProcessor.Namespace = namespace
+
+		namespaceAllowMap, err := namespacedProcessor.EvaluateSpecialCases(ctx, maps.Clone(allowMap), denyMap)
+		if err != nil {
+			return result, fmt.Errorf("error evaluating deny rules in namespace '%s': %w", namespace, err)
+		}
+
+		namespaceAllowMap, err = namespacedProcessor.EvaluatePolicyRules(namespaceAllowMap, denyMap)
+		if err != nil {
+			return result, fmt.Errorf("error evaluating deny rules in namespace '%s': %w", namespace, err)
+		}
+
+		result[namespace] = namespaceAllowMap
+	}
+
+	return result, err
+}
+
+// ResolveObjectSelector translates a deny rule with an object selector into rules with the names of the objects
+// matching it, so they can be evaluated as usual. There is a rule per resource, and those matching no objects are dropped
+func (p *ProcessorT) ResolveObjectSelector(ctx context.Context, denyRule rbacv1.PolicyRule, selector labels.Selector) (
+	result []rbacv1.PolicyRule, err error) {
+
+	// Only objects of resources can be selected by their labels
+	if len(denyRule.Resources) == 0 || len(denyRule.NonResourceURLs) > 0 {
+		return result, fmt.Errorf("objectSelector requires resources, and is not allowed with nonResourceURLs")
+	}
+
+	// Offline rendering has no access to the objects of the cluster
+	if p.ObjectLister == nil {
+		return result, fmt.Errorf("listing objects from the cluster is required to evaluate deny rules with objectSelector")
+	}
+
+	// Look for the objects of each resource type covered by the rule
+	stretchedRules := p.StretchDenyPolicyRules(p.ExpandPolicyRules([]rbacv1.PolicyRule{denyRule}))
+	for _, stretchedRule := range stretchedRules {
+
+		// Subresources are authorized by the name of their parent object
+		coreResourceType := strings.Split(stretchedRule.Resources[0], "/")[0]
+
+		tmpGvkr := GVKR{}
+		for _, gvkr := range p.ResourcesByGroup[stretchedRule.APIGroups[0]] {
+			if gvkr.Resource == coreResourceType && gvkr.Subresource == "" {
+				tmpGvkr = gvkr
+			}
+		}
+
+		objectNames, err := p.listObjectNames(ctx, tmpGvkr, selector)
+		if err != nil {
+			return result, err
+		}
+
+		var resourceNames []string
+		for _, objectName := range objectNames {
+			if len(stretchedRule.ResourceNames) != 0 && !slices.Contains(stretchedRule.ResourceNames, objectName) {
+				continue
+			}
+
+			if !slices.Contains(resourceNames, objectName) {
+				resourceNames = append(resourceNames, objectName)
+			}
+		}
+
+		if len(resourceNames) == 0 {
+			continue
+		}
+
+		result = append(result, rbacv1.PolicyRule{
+			APIGroups:     stretchedRule.APIGroups,
+			Resources:     stretchedRule.Resources,
+			ResourceNames: resourceNames,
+			Verbs:         stretchedRule.Verbs,
+		})
+	}
+
+	return result, err
+}
+
+// EvaluatePolicyRules compares the allow and deny PolicyRule maps and returns the resulting map
+func (p *ProcessorT) EvaluatePolicyRules(allowMap, denyMap map[string]rbacv1.PolicyRule) (result map[string]rbacv1.PolicyRule, err error) {
+
+	for denyMapKey, policyRule := range denyMap {
+
+		// NonResourceURLs rules
+		// Treat verbs for all allow rules matching some path in common. Allowed wildcards can not be narrowed
+		// to the paths not denied, so they lose the denied verbs as a whole, even when denied partially
+		if strings.HasPrefix(denyMapKey, "nonresourceurl") {
+			for allowMapKey := range allowMap {
+				if !MatchDenyKey(denyMapKey, allowMapKey) {
+					continue
+				}
+
+				tmpPolicyRule := allowMap[allowMapKey]
+				tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[allowMapKey].Verbs, policyRule.Verbs)
+				allowMap[allowMapKey] = tmpPolicyRule
+
+				if len(allowMap[allowMapKey].Verbs) == 0 {
+					delete(allowMap, allowMapKey)
+				}
+			}
+			continue
+		}
+
+		// Deny rule found for a whole API group,
+		// Treat verbs for all allow rules of the group, whatever their resources, subresources or names
+		if IsGroupKey(denyMapKey) {
+			for allowMapKey := range allowMap {
+				if !MatchDenyKey(denyMapKey, allowMapKey) {
+					continue
+				}
+
+				tmpPolicyRule := allowMap[allowMapKey]
+				tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[allowMapKey].Verbs, policyRule.Verbs)
+				allowMap[allowMapKey] = tmpPolicyRule
+
+				if len(allowMap[allowMapKey].Verbs) == 0 {
+					delete(allowMap, allowMapKey)
+				}
+			}
+			continue
+		}
+
+		denyMapKeyParts := strings.Split(denyMapKey, "#")
+
+		// Deny rule found for a Resouce NOT defining a ResourceName,
+		// Treat verbs for all allow rules that match the prefix
+		if denyMapKeyParts[2] == "" {
+			for allowMapKey, _ := range allowMap {
+				if strings.HasPrefix(allowMapKey, denyMapKey) {
+					tmpPolicyRule := allowMap[allowMapKey]
+					tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[allowMapKey].Verbs, policyRule.Verbs)
+					allowMap[allowMapKey] = tmpPolicyRule
+				}
+
+				if len(allowMap[allowMapKey].Verbs) == 0 {
+					delete(allowMap, allowMapKey)
+				}
+			}
+			continue
+		}
+
+		// Deny rule found for a Resouce DO defining a ResourceName pattern,
+		// Treat verbs for all allow rules of the same resource whose names match it
+		if IsResourceNamePattern(denyMapKeyParts[2]) {
+			for allowMapKey := range allowMap {
+				if !MatchDenyKey(denyMapKey, allowMapKey) {
+					continue
+				}
+
+				tmpPolicyRule := allowMap[allowMapKey]
+				tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[allowMapKey].Verbs, policyRule.Verbs)
+				allowMap[allowMapKey] = tmpPolicyRule
+
+				if len(allowMap[allowMapKey].Verbs) == 0 {
+					delete(allowMap, allowMapKey)
+				}
+			}
+			continue
+		}
+
+		// Deny rule found for a Resouce DO defining a ResourceName,
+		// Treat verbs for all allow rules that match the prefix
+		if denyMapKeyParts[2] != "" {
+			if _, ok := allowMap[denyMapKey]; ok {
+				tmpPolicyRule := allowMap[denyMapKey]
+				tmpPolicyRule.Verbs = p.GetSurvivingVerbs(allowMap[denyMapKey].Verbs, policyRule.Verbs)
+				allowMap[denyMapKey] = tmpPolicyRule
+
+				if len(allowMap[denyMapKey].Verbs) == 0 {
+					delete(allowMap, denyMapKey)
+				}
+			}
+		}
+	}
+
+	result = allowMap
+
+	return result, err
+}
+
+// MatchDenyKey returns whether a deny rule acts on an allowed one, both keyed as in the evaluated maps.
+// It follows the same criteria as EvaluatePolicyRules
+func MatchDenyKey(denyKey, allowKey string) bool {
+
+	// Deny rules with NonResourceURLs act on the allowed ones matching some path in common
+	if denyURL, found := strings.CutPrefix(denyKey, "nonresourceurl#"); found {
+		allowURL, found := strings.CutPrefix(allowKey, "nonresourceurl#")
+		return found && OverlapNonResourceURLs(denyURL, allowURL)
+	}
+
+	// Deny rules without resourceNames act on every name of the resource,
+	// and those acting on whole API groups on every resource of the group
+	if strings.HasSuffix(denyKey, "#") {
+		return strings.HasPrefix(allowKey, denyKey)
+	}
+
+	// Deny rules with a resourceName pattern act on the names of the resource matching it
+	denyResourceKey, denyName := denyKey[:strings.LastIndex(denyKey, "#")+1], denyKey[strings.LastIndex(denyKey, "#")+1:]
+	if IsResourceNamePattern(denyName) {
+		allowName, found := strings.CutPrefix(allowKey, denyResourceKey)
+		if !found || allowName == "" {
+			return false
+		}
+
+		matched, err := path.Match(denyName, allowName)
+		return err == nil && matched
+	}
+
+	return denyKey == allowKey
+}
+
+// IsResourceNamePattern returns whether a resourceName of a deny rule is a pattern, like 'prod-*',
+// matching the names of several objects instead of a single one
+func IsResourceNamePattern(resourceName string) bool {
+	return strings.Contains(resourceName, "*")
+}
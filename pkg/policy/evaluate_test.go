@@ -0,0 +1,475 @@
+package policy
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// evaluationResources is the discovery snapshot used to evaluate the rules
+var evaluationResources = []*metav1.APIResourceList{
+	{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+			{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+			{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: []string{"create", "delete", "get", "list"}},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list"}},
+		},
+	},
+	{
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list", "update"}},
+		},
+	},
+}
+
+// evaluationObjects are the names of the objects of each kind, read to evaluate deny rules by name
+var evaluationObjects = map[string][]string{
+	"ConfigMap": {"app-config", "db-credentials"},
+	"Secret":    {"dev-db", "prod-db"},
+}
+
+// newEvaluationProcessor returns a processor for the evaluation snapshot, listing the evaluation objects
+func newEvaluationProcessor() ProcessorT {
+	return NewProcessorFromResources(evaluationResources, ObjectListerFunc(
+		func(_ context.Context, gvk schema.GroupVersionKind, _ string, _ labels.Selector) ([]string, error) {
+			return evaluationObjects[gvk.Kind], nil
+		}))
+}
+
+// evaluate returns the verbs surviving for each key once the deny rules are evaluated on the allow ones.
+// Verbs are sorted, as their order is not relevant
+func evaluate(allowRules, denyRules []rbacv1.PolicyRule) map[string][]string {
+	processor := newEvaluationProcessor()
+
+	allowMap, denyMap, err := processor.GetPolicyRuleMaps(context.Background(), allowRules, denyRules)
+	Expect(err).NotTo(HaveOccurred())
+
+	resultMap, err := processor.EvaluatePolicyRules(allowMap, denyMap)
+	Expect(err).NotTo(HaveOccurred())
+
+	result := map[string][]string{}
+	for key, policyRule := range resultMap {
+		result[key] = slices.Clone(policyRule.Verbs)
+		slices.Sort(result[key])
+	}
+	return result
+}
+
+var _ = Describe("PolicyRules surviving verbs", func() {
+	processor := ProcessorT{}
+
+	DescribeTable("When removing the denied verbs from the allowed ones",
+		func(allowVerbs, denyVerbs, expectedVerbs []string) {
+			Expect(processor.GetSurvivingVerbs(allowVerbs, denyVerbs)).To(ConsistOf(expectedVerbs))
+		},
+		Entry("should keep every verb without deny verbs",
+			[]string{"get", "list"}, nil, []string{"get", "list"}),
+		Entry("should keep every verb when deny verbs are not allowed",
+			[]string{"get", "list"}, []string{"delete", "update"}, []string{"get", "list"}),
+		Entry("should remove the verbs allowed and denied",
+			[]string{"get", "list", "watch"}, []string{"list", "delete"}, []string{"get", "watch"}),
+		Entry("should remove every verb when all of them are denied",
+			[]string{"get", "list"}, []string{"list", "get"}, []string{}),
+		Entry("should remove the verbs denied several times",
+			[]string{"get", "list"}, []string{"get", "get"}, []string{"list"}),
+		Entry("should return the verbs allowed several times once",
+			[]string{"get", "get", "list"}, []string{"list"}, []string{"get"}),
+		Entry("should return nothing without allowed verbs",
+			nil, []string{"get"}, []string{}),
+		Entry("should remove every verb, including special ones, when the wildcard is denied",
+			[]string{"bind", "escalate", "get"}, []string{"*"}, []string{}),
+	)
+})
+
+var _ = Describe("PolicyRules evaluation", func() {
+
+	DescribeTable("When evaluating matrices of allow and deny rules",
+		func(allowRules, denyRules []rbacv1.PolicyRule, expected map[string][]string) {
+			Expect(evaluate(allowRules, denyRules)).To(Equal(expected))
+		},
+
+		// Wildcards
+		Entry("should expand wildcard groups, resources and verbs to the discovered ones",
+			[]rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+			nil,
+			map[string][]string{
+				"#pods#":            {"create", "delete", "get", "list", "patch", "update", "watch"},
+				"#pods/log#":        {"get"},
+				"#secrets#":         {"create", "delete", "get", "list"},
+				"#configmaps#":      {"get", "list"},
+				"apps#deployments#": {"get", "list", "update"},
+			}),
+		Entry("should remove the resources whose verbs are all denied through wildcards",
+			[]rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets", "pods/log"}, Verbs: []string{"*"}}},
+			map[string][]string{
+				"#pods#":            {"get"},
+				"#configmaps#":      {"get"},
+				"apps#deployments#": {"get"},
+			}),
+		Entry("should remove the special verbs allowed explicitly when denied through wildcards",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods", "secrets"}, Verbs: []string{"get", "bind", "escalate"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}}},
+			map[string][]string{
+				"#secrets#": {"bind", "escalate", "get"},
+			}),
+		Entry("should remove the denied verbs on resources denied through wildcard groups",
+			[]rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "update"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"deployments"}, Verbs: []string{"update"}}},
+			map[string][]string{
+				"apps#deployments#": {"get", "list"},
+			}),
+		Entry("should keep the subresources of denied resources",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods", "pods/*"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"#pods/log#": {"get"},
+			}),
+
+		// ResourceNames
+		Entry("should expand allowed resources to the names of their objects when some of them are denied",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"db-credentials"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"#configmaps#app-config":     {"get", "list"},
+				"#configmaps#db-credentials": {"list"},
+			}),
+		Entry("should remove the objects whose names match a denied pattern",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"prod-*"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"#secrets#dev-db": {"get"},
+			}),
+		Entry("should remove the allowed names when the whole resource is denied",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"app-config"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}},
+			map[string][]string{}),
+		Entry("should keep the allowed names not denied",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"app-config", "unknown"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"app-config"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"#configmaps#unknown": {"get"},
+			}),
+
+		// NonResourceURLs
+		Entry("should remove the denied NonResourceURLs",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/healthz", "/metrics"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"nonresourceurl#/healthz": {"get"},
+			}),
+		Entry("should remove the NonResourceURLs matching a denied prefix",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/api/v1", "/apis", "/healthz"}, Verbs: []string{"get", "post"}}},
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/api*"}, Verbs: []string{"post"}}},
+			map[string][]string{
+				"nonresourceurl#/api/v1":  {"get"},
+				"nonresourceurl#/apis":    {"get"},
+				"nonresourceurl#/healthz": {"get", "post"},
+			}),
+		Entry("should remove the allowed wildcard NonResourceURLs covered by a denied wildcard",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics/*", "/healthz"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics*"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"nonresourceurl#/healthz": {"get"},
+			}),
+		Entry("should remove the denied verbs from the allowed wildcard NonResourceURLs matching a denied path",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics/*"}, Verbs: []string{"get", "post"}}},
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics/cadvisor"}, Verbs: []string{"post"}}},
+			map[string][]string{
+				"nonresourceurl#/metrics/*": {"get"},
+			}),
+		Entry("should remove the denied verbs from the allowed wildcard NonResourceURLs wider than a denied wildcard",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics*"}, Verbs: []string{"get", "post"}}},
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics/*"}, Verbs: []string{"post"}}},
+			map[string][]string{
+				"nonresourceurl#/metrics*": {"get"},
+			}),
+		Entry("should keep the NonResourceURLs not matching the denied wildcards",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metricsz", "/metrics"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/metrics/*"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"nonresourceurl#/metricsz": {"get"},
+				"nonresourceurl#/metrics":  {"get"},
+			}),
+		Entry("should collapse the repeated trailing wildcards of NonResourceURLs",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/logs/**", "/logs/*"}, Verbs: []string{"get"}}},
+			nil,
+			map[string][]string{
+				"nonresourceurl#/logs/*": {"get"},
+			}),
+		Entry("should expand wildcard verbs of NonResourceURLs to the default verbs",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"*"}}},
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"create", "delete", "deletecollection", "patch", "update"}}},
+			map[string][]string{
+				"nonresourceurl#/healthz": {"get", "list", "watch"},
+			}),
+
+		// Missing groups
+		Entry("should drop the rules of groups not present in the cluster",
+			[]rbacv1.PolicyRule{{APIGroups: []string{"example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get"}}},
+			nil,
+			map[string][]string{}),
+		Entry("should drop the rules of resources not present in their group",
+			[]rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			nil,
+			map[string][]string{}),
+		Entry("should ignore deny rules of groups not present in the cluster",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{"example.com"}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			map[string][]string{
+				"#pods#": {"get"},
+			}),
+	)
+})
+
+var _ = Describe("NonResourceURLs validation", func() {
+
+	DescribeTable("When validating the NonResourceURLs of PolicyRules",
+		func(url string, valid bool) {
+			err := ValidateNonResourceURLs([]rbacv1.PolicyRule{{NonResourceURLs: []string{url}, Verbs: []string{"get"}}})
+			Expect(err == nil).To(Equal(valid))
+		},
+		Entry("should accept exact paths", "/healthz", true),
+		Entry("should accept paths ending with a wildcard", "/metrics/*", true),
+		Entry("should accept the whole wildcard", "*", true),
+		Entry("should reject paths not starting with a slash", "metrics", false),
+		Entry("should reject wildcards not placed at the end", "/logs/*/tail", false),
+	)
+})
+
+var _ = Describe("Impersonation rules", func() {
+	Context("When generating the rules allowing to impersonate identities", func() {
+
+		It("should never generate rules without names, as they allow impersonating anybody", func() {
+			Expect(GetImpersonationRule(ImpersonatedUsers, []string{"bob", "alice", "bob", ""})).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"users"}, ResourceNames: []string{"alice", "bob"}, Verbs: []string{"impersonate"}},
+			}))
+			Expect(GetImpersonationRule(ImpersonatedGroups, []string{""})).To(BeEmpty())
+		})
+
+		It("should exclude the identities denied by name, by pattern or as a whole", func() {
+			impersonationRules := append(GetImpersonationRule(ImpersonatedUsers, []string{"alice", "admin-bob", "carol"}),
+				GetImpersonationRule(ImpersonatedGroups, []string{"system:masters"})...)
+
+			Expect(ExcludeDeniedImpersonations(impersonationRules, []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"users"}, ResourceNames: []string{"admin-*"}, Verbs: []string{"impersonate"}},
+				{APIGroups: []string{"*"}, Resources: []string{"users"}, ResourceNames: []string{"carol"}, Verbs: []string{"*"}},
+				{APIGroups: []string{""}, Resources: []string{"users"}, ResourceNames: []string{"alice"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"impersonate"}, ResourceNames: []string{"system:*"}},
+			})).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"users"}, ResourceNames: []string{"alice"}, Verbs: []string{"impersonate"}},
+			}))
+		})
+
+		It("should keep impersonated users and groups as cluster-scoped rules", func() {
+			processor := newEvaluationProcessor()
+
+			clusterScopedRules, namespaceScopedRules := processor.SplitPolicyRules(GetImpersonationRule(ImpersonatedGroups, []string{"developers"}))
+			Expect(clusterScopedRules).To(HaveLen(1))
+			Expect(namespaceScopedRules).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Object selector resolution", func() {
+
+	ctx := context.Background()
+
+	// Objects are listed by their labels, keyed by kind
+	objects := map[string]map[string]labels.Set{
+		"ConfigMap": {"app-config": {"tier": "frontend"}, "db-credentials": {"tier": "backend"}},
+		"Secret":    {"dev-db": {"tier": "backend", "env": "dev"}, "prod-db": {"tier": "backend", "env": "prod"}},
+	}
+
+	processor := NewProcessorFromResources(evaluationResources, ObjectListerFunc(
+		func(_ context.Context, gvk schema.GroupVersionKind, _ string, selector labels.Selector) (result []string, err error) {
+			for name, objectLabels := range objects[gvk.Kind] {
+				if selector.Matches(objectLabels) {
+					result = append(result, name)
+				}
+			}
+			slices.Sort(result)
+			return result, err
+		}))
+
+	DescribeTable("should translate the selector into the names of the matching objects",
+		func(denyRule rbacv1.PolicyRule, selector labels.Set, expected []rbacv1.PolicyRule) {
+			result, err := processor.ResolveObjectSelector(ctx, denyRule, labels.SelectorFromSet(selector))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		Entry("on a single resource",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+			labels.Set{"env": "prod"},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"prod-db"}, Verbs: []string{"get"}},
+			}),
+		Entry("keeping only the objects also named by the rule",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"dev-db"}, Verbs: []string{"get"}},
+			labels.Set{"tier": "backend"},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"dev-db"}, Verbs: []string{"get"}},
+			}),
+		Entry("dropping the resources with no matching objects",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"get"}},
+			labels.Set{"tier": "frontend"},
+			[]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"app-config"}, Verbs: []string{"get"}},
+			}),
+		Entry("dropping the whole rule when the selector matches nothing",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"get"}},
+			labels.Set{"tier": "database"},
+			nil),
+	)
+
+	DescribeTable("should reject the rules with no objects to select",
+		func(denyRule rbacv1.PolicyRule) {
+			_, err := processor.ResolveObjectSelector(ctx, denyRule, labels.SelectorFromSet(labels.Set{"tier": "backend"}))
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("with nonResourceURLs only",
+			rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}}),
+		Entry("with nonResourceURLs along resources",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}}),
+		Entry("with no resources",
+			rbacv1.PolicyRule{APIGroups: []string{""}, Verbs: []string{"get"}}),
+	)
+
+	It("should require listing objects from the cluster", func() {
+		offlineProcessor := NewProcessorFromResources(evaluationResources, nil)
+
+		_, err := offlineProcessor.ResolveObjectSelector(ctx,
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+			labels.SelectorFromSet(labels.Set{"tier": "backend"}))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("PolicyRules evaluation properties", func() {
+	Context("When evaluating random allow and deny rules", func() {
+
+		groups := [][]string{{""}, {"apps"}, {"*"}, {"example.com"}}
+		resources := [][]string{{"pods"}, {"pods/log"}, {"secrets"}, {"configmaps"}, {"deployments"}, {"*"}, {"pods/*"}}
+		resourceNames := [][]string{nil, nil, {"app-config"}, {"db-credentials"}, {"prod-*"}, {"dev-db", "prod-db"}}
+		nonResourceURLs := [][]string{{"/healthz"}, {"/metrics"}, {"/api*"}, {"/api/v1"}}
+		verbs := [][]string{{"*"}, {"get"}, {"get", "list"}, {"create", "update", "delete"}, {"watch", "patch"},
+			{"bind", "escalate", "impersonate"}, {"*", "bind"}}
+
+		random := rand.New(rand.NewPCG(uint64(GinkgoRandomSeed()), 0))
+		pick := func(options [][]string) []string {
+			return options[random.IntN(len(options))]
+		}
+
+		randomRules := func() (result []rbacv1.PolicyRule) {
+			for range random.IntN(4) + 1 {
+				if random.IntN(4) == 0 {
+					result = append(result, rbacv1.PolicyRule{NonResourceURLs: pick(nonResourceURLs), Verbs: pick(verbs)})
+					continue
+				}
+				result = append(result, rbacv1.PolicyRule{
+					APIGroups:     pick(groups),
+					Resources:     pick(resources),
+					ResourceNames: pick(resourceNames),
+					Verbs:         pick(verbs),
+				})
+			}
+			return result
+		}
+
+		// genericKey returns the key of a rule without its resource name, granting every object of the resource
+		genericKey := func(key string) string {
+			if strings.HasPrefix(key, "nonresourceurl#") {
+				return key
+			}
+			return key[:strings.LastIndex(key, "#")+1]
+		}
+
+		It("should never widen the access granted by the allow rules", func() {
+			for range 500 {
+				allowRules, denyRules := randomRules(), randomRules()
+
+				allowed := evaluate(allowRules, nil)
+				for key, survivingVerbs := range evaluate(allowRules, denyRules) {
+					// Names expanded from a generic key are granted the verbs allowed on both of them
+					namedVerbs, namedFound := allowed[key]
+					genericVerbs, genericFound := allowed[genericKey(key)]
+					allowedVerbs, found := slices.Concat(namedVerbs, genericVerbs), namedFound || genericFound
+
+					Expect(found).To(BeTrue(), "key %q not allowed by %v", key, allowRules)
+					Expect(allowedVerbs).To(ContainElements(survivingVerbs), "key %q widened by %v", key, denyRules)
+				}
+			}
+		})
+
+		It("should evaluate the same as deny rules stretched over the whole cluster", func() {
+			for range 500 {
+				allowRules, denyRules := randomRules(), randomRules()
+
+				processor := newEvaluationProcessor()
+				allowMap := processor.GetMapFromStretchedPolicyRules(processor.StretchPolicyRules(processor.ExpandPolicyRules(allowRules)))
+				denyMap := processor.GetMapFromStretchedPolicyRules(processor.StretchDenyPolicyRules(processor.ExpandPolicyRules(denyRules)))
+
+				allowMap, err := processor.EvaluateSpecialCases(context.Background(), allowMap, denyMap)
+				Expect(err).NotTo(HaveOccurred())
+				resultMap, err := processor.EvaluatePolicyRules(allowMap, denyMap)
+				Expect(err).NotTo(HaveOccurred())
+
+				expected := map[string][]string{}
+				for key, policyRule := range resultMap {
+					expected[key] = slices.Clone(policyRule.Verbs)
+					slices.Sort(expected[key])
+				}
+				Expect(evaluate(allowRules, denyRules)).To(Equal(expected), "allow %v, deny %v", allowRules, denyRules)
+			}
+		})
+
+		It("should never keep the verbs denied on an object", func() {
+			for range 500 {
+				allowRules, denyRules := randomRules(), randomRules()
+
+				processor := newEvaluationProcessor()
+				denyMap := processor.GetMapFromStretchedPolicyRules(processor.StretchDenyPolicyRules(processor.ExpandPolicyRules(denyRules)))
+
+				for key, survivingVerbs := range evaluate(allowRules, denyRules) {
+					for denyKey, denyRule := range denyMap {
+						if !MatchDenyKey(denyKey, key) {
+							continue
+						}
+						for _, verb := range denyRule.Verbs {
+							Expect(survivingVerbs).NotTo(ContainElement(verb), "key %q keeps verb %q denied by %v", key, verb, denyRules)
+						}
+					}
+				}
+			}
+		})
+
+		It("should never keep any verb on the keys denied with wildcard verbs", func() {
+			for range 500 {
+				allowRules, denyRules := randomRules(), randomRules()
+
+				processor := newEvaluationProcessor()
+				denyMap := processor.GetMapFromStretchedPolicyRules(processor.StretchDenyPolicyRules(processor.ExpandPolicyRules(denyRules)))
+
+				for key, survivingVerbs := range evaluate(allowRules, denyRules) {
+					for denyKey, denyRule := range denyMap {
+						if !MatchDenyKey(denyKey, key) || !slices.Contains(denyRule.Verbs, "*") {
+							continue
+						}
+						Expect(survivingVerbs).To(BeEmpty(), "key %q keeps verbs denied with wildcards by %v", key, denyRules)
+					}
+				}
+			}
+		})
+	})
+})
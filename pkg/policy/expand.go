@@ -0,0 +1,664 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// GetSurvivingVerbs returns allowed verbs that are not in the deny list
+func (p *ProcessorT) GetSurvivingVerbs(allowVerbs []string, denyVerbs []string) (result []string) {
+	tmpMap := map[string]int{}
+
+	for _, allowVerbsVal := range allowVerbs { // list
+		tmpMap[allowVerbsVal] = 1
+	}
+
+	// Wildcard deny verbs cover every allowed verb, including special ones like 'bind' or 'impersonate',
+	// which are never part of the verbs a wildcard expands to
+	if slices.Contains(denyVerbs, "*") {
+		return result
+	}
+
+	for _, denyVerbsVal := range denyVerbs { // get
+		if _, ok := tmpMap[denyVerbsVal]; !ok {
+			continue
+		}
+
+		tmpMap[denyVerbsVal] = tmpMap[denyVerbsVal] + 1
+	}
+
+	for tmpMapKey, tmpMapVal := range tmpMap {
+		if tmpMapVal == 1 {
+			result = append(result, tmpMapKey)
+		}
+	}
+
+	return result
+}
+
+// MatchResourceExpression returns whether a resource, expressed as 'resource' or 'resource/subresource',
+// matches an expression. Supported expressions are:
+//   - Regular expressions prefixed by 'regex:', matching the whole resource. Example: 'regex:(pods|services)/.*'
+//   - Globs, where '*' does not match the '/' separator. Example: '*/status'
+//   - Several of the previous ones separated by '|'. Example: 'secrets|configmaps'
+//
+// Invalid expressions never match
+func MatchResourceExpression(expression, resource string) bool {
+
+	if regex, found := strings.CutPrefix(expression, resourceRegexPrefix); found {
+		compiledRegex, err := regexp.Compile("^(?:" + regex + ")$")
+		if err != nil {
+			return false
+		}
+
+		return compiledRegex.MatchString(resource)
+	}
+
+	for _, glob := range strings.Split(expression, "|") {
+		matched, err := path.Match(glob, resource)
+		if err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CoversGroup returns whether some of the PolicyRules apply to an API group, naming it or through a wildcard,
+// so their expansion changes when the resources of that group are added to or removed from the cluster
+func CoversGroup(policyRules []rbacv1.PolicyRule, group string) bool {
+	return slices.ContainsFunc(policyRules, func(policyRule rbacv1.PolicyRule) bool {
+		return slices.Contains(policyRule.APIGroups, "*") || slices.Contains(policyRule.APIGroups, group)
+	})
+}
+
+// ExpandVerbs replaces wildcard verbs with the usable verbs of a resource, or the default ones when they are unknown.
+// Configured override and extra verbs are applied
+func (p *ProcessorT) ExpandVerbs(verbs []string, usableVerbs []string) (result []string) {
+
+	if !slices.Contains(verbs, "*") {
+		return verbs
+	}
+
+	wildcardVerbs := usableVerbs
+	if len(p.WildcardVerbs.Override) > 0 {
+		wildcardVerbs = p.WildcardVerbs.Override
+	}
+	if len(wildcardVerbs) == 0 {
+		wildcardVerbs = DefaultVerbs
+	}
+
+	for _, verb := range slices.Concat(verbs, wildcardVerbs, p.WildcardVerbs.Extra) {
+		if verb != "*" && !slices.Contains(result, verb) {
+			result = append(result, verb)
+		}
+	}
+	slices.Sort(result)
+
+	return result
+}
+
+// ExpandDenyVerbs replaces wildcard verbs of deny rules as ExpandVerbs does, but keeps the wildcard itself.
+// Wildcard deny verbs must cover every allowed verb, and allow rules can grant verbs a wildcard never expands to
+func (p *ProcessorT) ExpandDenyVerbs(verbs []string, usableVerbs []string) (result []string) {
+
+	result = p.ExpandVerbs(verbs, usableVerbs)
+	if slices.Contains(verbs, "*") {
+		result = append([]string{"*"}, result...)
+	}
+
+	return result
+}
+
+// ExpandVerbAliases replaces the aliases of groups of verbs, such as 'read', with the verbs they stand for.
+// The rest of verbs are kept as they are, and duplicated ones are removed
+func ExpandVerbAliases(verbs []string) (result []string) {
+
+	for _, verb := range verbs {
+		aliasedVerbs, isAlias := VerbAliases[verb]
+		if !isAlias {
+			aliasedVerbs = []string{verb}
+		}
+
+		for _, aliasedVerb := range aliasedVerbs {
+			if !slices.Contains(result, aliasedVerb) {
+				result = append(result, aliasedVerb)
+			}
+		}
+	}
+
+	return result
+}
+
+// FilterUnsupportedVerbs removes the default verbs not supported by a resource, according to its usable verbs.
+// Special verbs, such as 'bind', 'escalate' or 'use', are never reported by discovery, so they are always kept.
+// Nothing is filtered when usable verbs are unknown
+func (p *ProcessorT) FilterUnsupportedVerbs(verbs []string, usableVerbs []string) (result []string) {
+
+	if len(usableVerbs) == 0 {
+		return verbs
+	}
+
+	for _, verb := range verbs {
+		if slices.Contains(DefaultVerbs, verb) && !slices.Contains(usableVerbs, verb) {
+			continue
+		}
+		result = append(result, verb)
+	}
+
+	return result
+}
+
+// NormalizeNonResourceURL collapses the trailing wildcards of a NonResourceURL into a single one.
+// Kubernetes only checks whether a NonResourceURL ends with '*' to match it as a prefix, so they are equivalent
+func NormalizeNonResourceURL(url string) string {
+
+	if !strings.HasSuffix(url, "*") {
+		return url
+	}
+
+	return strings.TrimRight(url, "*") + "*"
+}
+
+// ValidateNonResourceURLs returns an error for each NonResourceURL of the PolicyRules never matching a request.
+// Requested paths always start with '/', and Kubernetes only understands '*' as the last character, as a prefix
+func ValidateNonResourceURLs(policyRules []rbacv1.PolicyRule) error {
+
+	var allErrors []error
+	for _, policyRule := range policyRules {
+		for _, url := range policyRule.NonResourceURLs {
+			if url == "*" {
+				continue
+			}
+
+			if !strings.HasPrefix(url, "/") {
+				allErrors = append(allErrors, fmt.Errorf("nonResourceURL '%s' must start with '/'", url))
+				continue
+			}
+
+			if strings.Contains(strings.TrimRight(url, "*"), "*") {
+				allErrors = append(allErrors, fmt.Errorf("nonResourceURL '%s' can only contain '*' at the end", url))
+			}
+		}
+	}
+
+	return errors.Join(allErrors...)
+}
+
+// OverlapNonResourceURLs returns whether two NonResourceURLs match some requested path in common.
+// As Kubernetes does, those ending with '*' match every path starting with what precedes it, and the rest match exactly
+func OverlapNonResourceURLs(url, otherURL string) bool {
+
+	prefix, wildcard := strings.CutSuffix(NormalizeNonResourceURL(url), "*")
+	otherPrefix, otherWildcard := strings.CutSuffix(NormalizeNonResourceURL(otherURL), "*")
+
+	switch {
+	case wildcard && otherWildcard:
+		return strings.HasPrefix(prefix, otherPrefix) || strings.HasPrefix(otherPrefix, prefix)
+	case wildcard:
+		return strings.HasPrefix(otherURL, prefix)
+	case otherWildcard:
+		return strings.HasPrefix(url, otherPrefix)
+	}
+
+	return url == otherURL
+}
+
+// ExpandPolicyRules gets a list of PolicyRules and expands wildcard items to specific ones
+func (p *ProcessorT) ExpandPolicyRules(policyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
+
+	for _, policyRule := range policyRules {
+
+		// No verbs? Kubernets will ignore you, so we will too
+		if len(policyRule.Verbs) == 0 {
+			continue
+		}
+
+		// Rules with NonResourceUrls can NOT come with APIGroups or Resources or ResourceNames
+		if len(policyRule.NonResourceURLs) != 0 &&
+			(len(policyRule.APIGroups) != 0 || len(policyRule.Resources) != 0 || len(policyRule.ResourceNames) != 0) {
+			continue
+		}
+
+		// Rules without NonResourceUrls MUST come with APIgroups and Resources defined
+		if len(policyRule.NonResourceURLs) == 0 &&
+			(len(policyRule.APIGroups) == 0 || len(policyRule.Resources) == 0) {
+			continue
+		}
+
+		// Rules with ResourceNames MUST come with Resources and APIGroups defined
+		if len(policyRule.ResourceNames) != 0 &&
+			(len(policyRule.APIGroups) == 0 || len(policyRule.Resources) == 0) {
+			continue
+		}
+
+		//
+		newPolicyRule := rbacv1.PolicyRule{}
+
+		// 1. Expand groups in the PolicyRule.
+		// Add all of them or user-specified ones.
+		if slices.Contains(policyRule.APIGroups, "*") {
+			for group := range p.ResourcesByGroup {
+				newPolicyRule.APIGroups = append(newPolicyRule.APIGroups, group)
+			}
+		} else {
+			for _, group := range policyRule.APIGroups {
+				if _, ok := p.ResourcesByGroup[group]; ok {
+					newPolicyRule.APIGroups = append(newPolicyRule.APIGroups, group)
+				}
+			}
+		}
+
+		// 2. Expand resources in the PolicyRule.
+		// Add all of them or user-specified ones.
+		if slices.Contains(policyRule.Resources, "*") {
+
+			// Replace '*' with all resources owned by groups defined in the PolicyRule
+			// Loop over defined groups, probe their existence, and get their probed resources
+			for _, group := range newPolicyRule.APIGroups {
+
+				if _, ok := p.ResourcesByGroup[group]; ok {
+
+					for _, gvkr := range p.ResourcesByGroup[group] {
+
+						if gvkr.Subresource != "" {
+							newPolicyRule.Resources = append(newPolicyRule.Resources, gvkr.Resource+"/"+gvkr.Subresource)
+							continue
+						}
+
+						newPolicyRule.Resources = append(newPolicyRule.Resources, gvkr.Resource)
+					}
+				}
+			}
+		} else {
+
+			for _, resource := range policyRule.Resources {
+
+				// Add only resources that exists
+				if slices.Contains(p.ResourceList, resource) {
+					if !slices.Contains(newPolicyRule.Resources, resource) {
+						newPolicyRule.Resources = append(newPolicyRule.Resources, resource)
+					}
+					continue
+				}
+
+				// Not an exact name, so try to expand it as an expression against existing resources
+				for _, existingResource := range p.ResourceList {
+					if MatchResourceExpression(resource, existingResource) &&
+						!slices.Contains(newPolicyRule.Resources, existingResource) {
+						newPolicyRule.Resources = append(newPolicyRule.Resources, existingResource)
+					}
+				}
+			}
+		}
+
+		// 2.1. This is a middle cleanup step after previous expansions
+		// Delete groups that should NOT be there for the resources present in the PolicyRule
+		// When the resource type is not found, delete it too
+		newGroupList := []string{}
+		for _, resource := range newPolicyRule.Resources {
+			for _, group := range newPolicyRule.APIGroups {
+
+				// Add group to marked-groups only when a resource type is found for that group in the huge map
+				for _, gvkr := range p.ResourcesByGroup[group] {
+					resourceType := strings.Split(resource, "/")[0]
+					if strings.Compare(gvkr.Resource, resourceType) == 0 && !slices.Contains(newGroupList, group) {
+						newGroupList = append(newGroupList, group)
+						break
+					}
+				}
+			}
+		}
+		newPolicyRule.APIGroups = newGroupList
+
+		// 3. Add some fields as it
+		newPolicyRule.ResourceNames = policyRule.ResourceNames
+		newPolicyRule.NonResourceURLs = policyRule.NonResourceURLs
+
+		// 4. Verbs are kept as they are, replacing aliases. Wildcards are expanded later, per resource, when stretching
+		newPolicyRule.Verbs = ExpandVerbAliases(policyRule.Verbs)
+
+		result = append(result, newPolicyRule)
+	}
+
+	return result
+}
+
+// IntersectPolicyRules narrows down expanded PolicyRules to the groups and resources present in a map keyed as
+// explained in GetMapFromStretchedPolicyRules. Deny rules can only act on allowed resources, so stretching deny
+// wildcards against the whole cluster only produces entries that are discarded later.
+// Rules with NonResourceURLs are kept as they are, and rules left without resources are dropped
+func IntersectPolicyRules(policyRules []rbacv1.PolicyRule, policyRulesMap map[string]rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
+
+	// Keys look like 'group#resource#resourceName', so the resource part is everything up to the last separator
+	presentResources := map[string]struct{}{}
+	for key := range policyRulesMap {
+		if strings.HasPrefix(key, "nonresourceurl#") {
+			continue
+		}
+		presentResources[key[:strings.LastIndex(key, "#")]] = struct{}{}
+	}
+
+	for _, policyRule := range policyRules {
+
+		if len(policyRule.NonResourceURLs) > 0 {
+			result = append(result, policyRule)
+			continue
+		}
+
+		newPolicyRule := policyRule
+		newPolicyRule.APIGroups = nil
+		newPolicyRule.Resources = nil
+		for _, group := range policyRule.APIGroups {
+			for _, resource := range policyRule.Resources {
+				if _, found := presentResources[group+"#"+resource]; !found {
+					continue
+				}
+
+				if !slices.Contains(newPolicyRule.APIGroups, group) {
+					newPolicyRule.APIGroups = append(newPolicyRule.APIGroups, group)
+				}
+				if !slices.Contains(newPolicyRule.Resources, resource) {
+					newPolicyRule.Resources = append(newPolicyRule.Resources, resource)
+				}
+			}
+		}
+
+		if len(newPolicyRule.Resources) == 0 {
+			continue
+		}
+		result = append(result, newPolicyRule)
+	}
+
+	return result
+}
+
+// ExcludeVerbs removes some verbs from every PolicyRule, unless the rule names them explicitly in its verbs, or through an alias.
+// Wildcards are expanded and the rules stretched first, so verbs granted through '*' are removed too.
+// Rules left without verbs are dropped
+func (p *ProcessorT) ExcludeVerbs(policyRules []rbacv1.PolicyRule, excludedVerbs []string) (result []rbacv1.PolicyRule) {
+
+	for _, policyRule := range policyRules {
+
+		// Verbs named by the rule are kept, so each rule is able to opt out of the exclusion
+		ruleVerbs := ExpandVerbAliases(policyRule.Verbs)
+		ruleExcludedVerbs := slices.DeleteFunc(ExpandVerbAliases(excludedVerbs), func(verb string) bool {
+			return slices.Contains(ruleVerbs, verb)
+		})
+		if len(ruleExcludedVerbs) == 0 {
+			result = append(result, policyRule)
+			continue
+		}
+
+		for _, stretchedRule := range p.StretchPolicyRules(p.ExpandPolicyRules([]rbacv1.PolicyRule{policyRule})) {
+			stretchedRule.Verbs = slices.DeleteFunc(slices.Clone(stretchedRule.Verbs), func(verb string) bool {
+				return slices.Contains(ruleExcludedVerbs, verb)
+			})
+			if len(stretchedRule.Verbs) == 0 {
+				continue
+			}
+			result = append(result, stretchedRule)
+		}
+	}
+
+	return result
+}
+
+// StretchPolicyRules gets a list of complex PolicyRules and returns a new list with single resource per item
+func (p *ProcessorT) StretchPolicyRules(policyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
+	return p.stretchPolicyRules(policyRules, p.ExpandVerbs)
+}
+
+// StretchDenyPolicyRules stretches deny PolicyRules as StretchPolicyRules does,
+// keeping their wildcard verbs as explained in ExpandDenyVerbs
+func (p *ProcessorT) StretchDenyPolicyRules(policyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
+	return p.stretchPolicyRules(policyRules, p.ExpandDenyVerbs)
+}
+
+// stretchPolicyRules stretches the PolicyRules, expanding their wildcard verbs with the given function
+func (p *ProcessorT) stretchPolicyRules(policyRules []rbacv1.PolicyRule,
+	expandVerbs func(verbs []string, usableVerbs []string) []string) (result []rbacv1.PolicyRule) {
+
+	for _, policyRule := range policyRules {
+
+		// Append rules with NonResourceURLs without expansion, only normalizing their wildcards
+		if len(policyRule.NonResourceURLs) > 0 {
+			for _, url := range policyRule.NonResourceURLs {
+				result = append(result, rbacv1.PolicyRule{
+					NonResourceURLs: []string{NormalizeNonResourceURL(url)},
+					Verbs:           expandVerbs(policyRule.Verbs, nil),
+				})
+			}
+			continue
+		}
+
+		// Append the rest of the rules expanding them
+		// We are checking that resource exists in a group
+		for _, resource := range policyRule.Resources {
+
+			for _, group := range policyRule.APIGroups {
+
+				//
+				resourceFound := false
+				var usableVerbs []string
+				for _, gvkr := range p.ResourcesByGroup[group] {
+
+					tmpResourceName := gvkr.Resource
+					if gvkr.Subresource != "" {
+						tmpResourceName += "/" + gvkr.Subresource
+					}
+
+					if strings.Compare(tmpResourceName, resource) == 0 {
+						resourceFound = true
+						usableVerbs = gvkr.UsableVerbs
+					}
+				}
+
+				if !resourceFound {
+					continue
+				}
+
+				// Wildcard verbs are expanded using the verbs supported by this resource,
+				// and those not supported are removed. Rules left without verbs are useless
+				verbs := p.FilterUnsupportedVerbs(expandVerbs(policyRule.Verbs, usableVerbs), usableVerbs)
+				if len(verbs) == 0 {
+					continue
+				}
+
+				//
+				if len(policyRule.ResourceNames) != 0 {
+					for _, name := range policyRule.ResourceNames {
+						result = append(result, rbacv1.PolicyRule{
+							APIGroups:     []string{group},
+							Resources:     []string{resource},
+							ResourceNames: []string{name},
+							Verbs:         verbs,
+						})
+					}
+					continue
+				}
+
+				//
+				result = append(result, rbacv1.PolicyRule{
+					APIGroups: []string{group},
+					Resources: []string{resource},
+					Verbs:     verbs,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// GetMapFromStretchedPolicyRules return a map with the keys in the form of
+// "group#resource#resourceName" or "nonresourceurl#url", and the value as PolicyRule
+func (p *ProcessorT) GetMapFromStretchedPolicyRules(policyRules []rbacv1.PolicyRule) (result map[string]rbacv1.PolicyRule) {
+
+	result = make(map[string]rbacv1.PolicyRule)
+
+	for _, policyRule := range policyRules {
+
+		// For NonResourceURLs rules
+		if len(policyRule.NonResourceURLs) != 0 {
+
+			nonResourceUrlMapKey := "nonresourceurl#" + policyRule.NonResourceURLs[0]
+
+			if _, nonResourceUrlKeyFound := result[nonResourceUrlMapKey]; nonResourceUrlKeyFound {
+				tmp := append(result[nonResourceUrlMapKey].Verbs, policyRule.Verbs...)
+				slices.Sort(tmp)
+				tmp = slices.Compact(tmp)
+
+				result[nonResourceUrlMapKey] = rbacv1.PolicyRule{
+					NonResourceURLs: policyRule.NonResourceURLs,
+					Verbs:           tmp,
+				}
+				continue
+			}
+
+			result[nonResourceUrlMapKey] = policyRule
+
+			continue
+		}
+
+		// For ResourceNames rules
+		resourceKey := policyRule.APIGroups[0] + "#" + policyRule.Resources[0] + "#"
+		if len(policyRule.ResourceNames) != 0 {
+			resourceKey += policyRule.ResourceNames[0]
+		}
+
+		if _, resourceKeyFound := result[resourceKey]; resourceKeyFound {
+
+			tmp := append(result[resourceKey].Verbs, policyRule.Verbs...)
+			slices.Sort(tmp)
+			tmp = slices.Compact(tmp)
+
+			result[resourceKey] = rbacv1.PolicyRule{
+				APIGroups:     policyRule.APIGroups,
+				Resources:     policyRule.Resources,
+				ResourceNames: policyRule.ResourceNames,
+				Verbs:         tmp,
+			}
+			continue
+		}
+
+		result[resourceKey] = policyRule
+	}
+	return result
+}
+
+// IsGroupWidePolicyRule returns whether a PolicyRule acts on every resource and subresource of its API groups,
+// this is, it has the '*' resource and neither resourceNames nor NonResourceURLs
+func IsGroupWidePolicyRule(policyRule rbacv1.PolicyRule) bool {
+	return len(policyRule.NonResourceURLs) == 0 && len(policyRule.ResourceNames) == 0 &&
+		len(policyRule.Verbs) != 0 && slices.Contains(policyRule.Resources, "*")
+}
+
+// GetMapFromGroupPolicyRules returns a map with the keys in the form of "group#", and the value as PolicyRule,
+// for PolicyRules acting on whole API groups. Wildcard groups are expanded to the discovered ones,
+// and only the groups present in the given map, keyed as in GetMapFromStretchedPolicyRules, are kept.
+// Wildcard verbs are kept as explained in ExpandDenyVerbs, so they cover every verb allowed on the groups
+func (p *ProcessorT) GetMapFromGroupPolicyRules(policyRules []rbacv1.PolicyRule,
+	policyRulesMap map[string]rbacv1.PolicyRule) (result map[string]rbacv1.PolicyRule) {
+
+	result = make(map[string]rbacv1.PolicyRule)
+
+	presentGroups := map[string]struct{}{}
+	for key := range policyRulesMap {
+		if strings.HasPrefix(key, "nonresourceurl#") {
+			continue
+		}
+		presentGroups[key[:strings.Index(key, "#")]] = struct{}{}
+	}
+
+	for _, policyRule := range policyRules {
+
+		groups := policyRule.APIGroups
+		if slices.Contains(groups, "*") {
+			groups = nil
+			for group := range presentGroups {
+				groups = append(groups, group)
+			}
+		}
+
+		verbs := p.ExpandDenyVerbs(ExpandVerbAliases(policyRule.Verbs), nil)
+		for _, group := range groups {
+			if _, found := presentGroups[group]; !found {
+				continue
+			}
+
+			groupKey := group + "#"
+			tmp := slices.Concat(result[groupKey].Verbs, verbs)
+			slices.Sort(tmp)
+			result[groupKey] = rbacv1.PolicyRule{
+				APIGroups: []string{group},
+				Resources: []string{"*"},
+				Verbs:     slices.Compact(tmp),
+			}
+		}
+	}
+
+	return result
+}
+
+// IsGroupKey returns whether a key of the PolicyRule maps acts on a whole API group, in the form of "group#"
+func IsGroupKey(key string) bool {
+	return !strings.HasPrefix(key, "nonresourceurl#") && strings.Count(key, "#") == 1
+}
+
+// SplitPolicyRules separates PolicyRules into two lists: clusterScopedRules and namespaceScopedRules.
+// Rules with NonResourceURLs are not bound to any namespace, so they are always considered cluster-scoped
+func (p *ProcessorT) SplitPolicyRules(policyRules []rbacv1.PolicyRule) (clusterScopedRules, namespaceScopedRules []rbacv1.PolicyRule) {
+
+	for _, policyRule := range policyRules {
+
+		//
+		if len(policyRule.NonResourceURLs) > 0 {
+			clusterScopedRules = append(clusterScopedRules, policyRule)
+			continue
+		}
+
+		// Rules without resources can not be matched against the discovered ones
+		if len(policyRule.APIGroups) == 0 || len(policyRule.Resources) == 0 {
+			continue
+		}
+
+		// Impersonated users and groups are never discovered, and they are not bound to any namespace
+		if policyRule.APIGroups[0] == "" && slices.Contains(impersonatedIdentities, policyRule.Resources[0]) {
+			clusterScopedRules = append(clusterScopedRules, policyRule)
+			continue
+		}
+
+		// Look for current PolicyRule in the resourcesByGroup map
+		for _, resource := range p.ResourcesByGroup[policyRule.APIGroups[0]] {
+
+			//
+			resourceName := resource.Resource
+			if resource.Subresource != "" {
+				resourceName += "/" + resource.Subresource
+			}
+
+			// Ignore when it is not the correct resource
+			if policyRule.Resources[0] != resourceName {
+				continue
+			}
+
+			// Add to the corresponding list
+			if resource.Namespaced {
+				namespaceScopedRules = append(namespaceScopedRules, policyRule)
+			} else {
+				clusterScopedRules = append(clusterScopedRules, policyRule)
+			}
+
+			break
+		}
+	}
+
+	return clusterScopedRules, namespaceScopedRules
+}
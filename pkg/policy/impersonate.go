@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+const (
+	// ImpersonateVerb is the verb checked by the API server on the identities impersonated by a request
+	ImpersonateVerb = "impersonate"
+
+	// Identities that can be impersonated, as resources of the core group. Users and groups are not served
+	// by the API, so they are never discovered and can not be expanded as the rest of the resources
+	ImpersonatedUsers           = "users"
+	ImpersonatedGroups          = "groups"
+	ImpersonatedServiceAccounts = "serviceaccounts"
+)
+
+// impersonatedIdentities are the impersonated resources not discovered from the cluster.
+// They are not bound to any namespace
+var impersonatedIdentities = []string{ImpersonatedUsers, ImpersonatedGroups}
+
+// GetImpersonationRule returns the rule allowing to impersonate some identities of a kind.
+// Nothing is returned without names, as a rule without resourceNames allows impersonating anybody
+func GetImpersonationRule(resource string, names []string) (result []rbacv1.PolicyRule) {
+
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+	names = slices.DeleteFunc(names, func(name string) bool { return name == "" })
+
+	if len(names) == 0 {
+		return result
+	}
+
+	return append(result, rbacv1.PolicyRule{
+		APIGroups:     []string{""},
+		Resources:     []string{resource},
+		ResourceNames: names,
+		Verbs:         []string{ImpersonateVerb},
+	})
+}
+
+// ExcludeDeniedImpersonations removes from the impersonation rules the identities denied by the deny rules acting
+// on the 'impersonate' verb, with the same criteria as EvaluatePolicyRules. Rules left without names are dropped,
+// instead of allowing to impersonate anybody
+func ExcludeDeniedImpersonations(impersonationRules, denyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
+
+	for _, impersonationRule := range impersonationRules {
+		resource := impersonationRule.Resources[0]
+		allowKey := "#" + resource + "#"
+
+		// Deny rules acting on the impersonation of this kind of identity
+		denyKeys := []string{}
+		for _, denyRule := range denyRules {
+			if !slices.Contains(denyRule.Verbs, ImpersonateVerb) && !slices.Contains(denyRule.Verbs, "*") {
+				continue
+			}
+
+			if !slices.Contains(denyRule.APIGroups, "") && !slices.Contains(denyRule.APIGroups, "*") {
+				continue
+			}
+
+			if !slices.ContainsFunc(denyRule.Resources, func(expression string) bool {
+				return MatchResourceExpression(expression, resource)
+			}) {
+				continue
+			}
+
+			if len(denyRule.ResourceNames) == 0 {
+				denyKeys = append(denyKeys, allowKey)
+			}
+			for _, resourceName := range denyRule.ResourceNames {
+				denyKeys = append(denyKeys, allowKey+resourceName)
+			}
+		}
+
+		impersonationRule.ResourceNames = slices.DeleteFunc(slices.Clone(impersonationRule.ResourceNames), func(name string) bool {
+			return slices.ContainsFunc(denyKeys, func(denyKey string) bool { return MatchDenyKey(denyKey, allowKey+name) })
+		})
+
+		if len(impersonationRule.ResourceNames) > 0 {
+			result = append(result, impersonationRule)
+		}
+	}
+
+	return result
+}
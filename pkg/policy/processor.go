@@ -0,0 +1,252 @@
+// Package policy implements the processing of RBAC PolicyRules done by Kuberbac: allow rules are expanded
+// against the resources available in a cluster, stretched to a single resource per rule, and the deny rules
+// are evaluated on them. It does not depend on a cluster connection, so the same semantics of the operator
+// can be reused by other tools, such as CLIs, linters or tests
+package policy
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// resourceRegexPrefix marks the resources of a PolicyRule that must be evaluated as regular expressions
+	resourceRegexPrefix = "regex:"
+)
+
+var (
+	// DefaultVerbs are used to expand wildcard verbs when discovery does not report verbs, e.g. for NonResourceURLs
+	DefaultVerbs = []string{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}
+
+	// VerbAliases are shorthands for groups of verbs, accepted in the verbs of allow and deny rules.
+	// Special verbs, such as 'bind', 'escalate' or 'impersonate', are never part of them, so they must be named
+	VerbAliases = map[string][]string{
+		"read":  {"get", "list", "watch"},
+		"write": {"create", "update", "patch", "delete", "deletecollection"},
+		"admin": DefaultVerbs,
+	}
+)
+
+// GVKR represents a resource type inside Kubernetes
+type GVKR struct {
+	GVK         schema.GroupVersionKind
+	Resource    string
+	Subresource string
+
+	//
+	Namespaced  bool
+	UsableVerbs []string // Used to expand wildcard verbs and filter unsupported ones for this resource
+}
+
+// WildcardVerbsT defines how wildcard verbs are expanded.
+// By default, they are expanded to the verbs reported by discovery for each resource
+type WildcardVerbsT struct {
+	// Override replaces the verbs reported by discovery for all the resources when defined
+	Override []string
+
+	// Extra verbs are always added, e.g. those never reported by discovery such as 'bind' or 'impersonate'
+	Extra []string
+}
+
+// ResourceDiscoverer represents anything able to retrieve the resources available in the cluster,
+// such as a discovery client or a recorded discovery snapshot
+type ResourceDiscoverer interface {
+	ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error)
+}
+
+// ObjectLister represents anything able to list the names of the objects of a resource, used to evaluate
+// deny rules by name or by object selector. Objects are listed in every namespace when the namespace is empty,
+// and regardless of their labels when the selector is nil
+type ObjectLister interface {
+	ListObjectNames(ctx context.Context, gvk schema.GroupVersionKind, namespace string, selector labels.Selector) ([]string, error)
+}
+
+// ObjectListerFunc allows using ordinary functions as ObjectLister
+type ObjectListerFunc func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, selector labels.Selector) ([]string, error)
+
+// ListObjectNames calls the function itself
+func (f ObjectListerFunc) ListObjectNames(ctx context.Context, gvk schema.GroupVersionKind, namespace string,
+	selector labels.Selector) ([]string, error) {
+	return f(ctx, gvk, namespace, selector)
+}
+
+// ProcessorT represents the things done
+// in the backstage to process PolicyRules
+type ProcessorT struct {
+	// ObjectLister reads the objects of the cluster when deny rules need them.
+	// It can be nil when they are not available, e.g. when rendering offline
+	ObjectLister ObjectLister
+
+	//
+	ResourcesByGroup map[string][]GVKR
+	ResourceList     []string
+
+	//
+	WildcardVerbs WildcardVerbsT
+
+	// Namespace restricts the objects read to evaluate deny rules by name, or by object selector, to a single
+	// namespace, as namespaced roles only grant access inside it. Objects are read cluster-wide when empty
+	Namespace string
+
+	// Namespaces restricts the objects read to evaluate deny rules to several namespaces, when Namespace is empty.
+	// Objects are read cluster-wide when nil, while an empty list reads no namespaced object at all
+	Namespaces []string
+}
+
+// NewProcessor returns a ProcessorT for the resources retrieved from the discoverer
+func NewProcessor(discoverer ResourceDiscoverer, objectLister ObjectLister) (p ProcessorT, err error) {
+
+	// Retrieve all types of resources available in the cluster
+	_, apiResourceLists, err := discoverer.ServerGroupsAndResources()
+	if err != nil {
+		return p, err
+	}
+
+	return NewProcessorFromResources(apiResourceLists, objectLister), err
+}
+
+// NewProcessorFromResources returns a ProcessorT for a snapshot of the resources available in a cluster,
+// as returned by discovery
+func NewProcessorFromResources(apiResourceLists []*metav1.APIResourceList, objectLister ObjectLister) (p ProcessorT) {
+	p.ObjectLister = objectLister
+
+	p.SetResourcesByGroup(apiResourceLists)
+	p.SetResourceList()
+
+	return p
+}
+
+// SetResourcesByGroup stores a map of groups with their resources inside
+// into the ProcessorT struct, from the resources available in the cluster
+func (p *ProcessorT) SetResourcesByGroup(apiResourceLists []*metav1.APIResourceList) {
+
+	p.ResourcesByGroup = make(map[string][]GVKR)
+
+	// Process the resources and group them by API group
+	for _, resourcesLists := range apiResourceLists {
+
+		//
+		groupVersion := strings.Split(resourcesLists.GroupVersion, "/")
+
+		//
+		group := ""
+		version := groupVersion[0]
+
+		if len(groupVersion) == 2 {
+			group = groupVersion[0]
+			version = groupVersion[1]
+		}
+
+		p.ResourcesByGroup[group] = []GVKR{}
+
+		for _, apiResource := range resourcesLists.APIResources {
+
+			resourceSubResource := strings.Split(apiResource.Name, "/")
+			resource := resourceSubResource[0]
+			subresource := ""
+			if len(resourceSubResource) > 1 {
+				subresource = strings.Join(resourceSubResource[1:], "/")
+			}
+			p.ResourcesByGroup[group] = append(p.ResourcesByGroup[group], GVKR{
+				Resource:    resource,
+				Subresource: subresource,
+				GVK: schema.GroupVersionKind{
+					Group:   group,
+					Version: version,
+					Kind:    apiResource.Kind,
+				},
+				Namespaced:  apiResource.Namespaced,
+				UsableVerbs: apiResource.Verbs,
+			})
+		}
+	}
+}
+
+// SetResourceList constructs a simple list of resources available in the cluster
+// and store it into the ProcessorT struct
+func (p *ProcessorT) SetResourceList() {
+	p.ResourceList = []string{}
+	for _, resList := range p.ResourcesByGroup {
+		for _, res := range resList {
+			if res.Subresource != "" {
+				p.ResourceList = append(p.ResourceList, res.Resource+"/"+res.Subresource)
+				continue
+			}
+
+			p.ResourceList = append(p.ResourceList, res.Resource)
+		}
+	}
+}
+
+// GetPolicyRuleMaps expands and stretches the allow and deny PolicyRules, and returns them as maps keyed
+// as explained in GetMapFromStretchedPolicyRules, ready to be evaluated by EvaluatePolicyRules.
+// Deny rules acting on whole API groups are not stretched, but keyed as explained in GetMapFromGroupPolicyRules,
+// so they trim every allowed resource, subresource and name of their groups, whatever the shape of the allow rules.
+// The deny map only contains the resources present in the allow map, as the rest can not be denied.
+// Resources allowed but denied by name are already expanded to the names of their objects
+func (p *ProcessorT) GetPolicyRuleMaps(ctx context.Context, allowRules, denyRules []rbacv1.PolicyRule) (
+	allowMap, denyMap map[string]rbacv1.PolicyRule, err error) {
+
+	// Transform '*' symbols with actual things, and stretch the rules to a single resource per item
+	stretchAllowList := p.StretchPolicyRules(p.ExpandPolicyRules(allowRules))
+
+	// Craft a map with stretched policy rules. Its keys are created as unique identifiers.
+	// This is done to increase performance when evaluating the rules.
+	allowMap = p.GetMapFromStretchedPolicyRules(stretchAllowList)
+
+	// Deny rules are only stretched over the allowed resources. Wildcards would produce an entry
+	// for every resource of the cluster otherwise, which is expensive on big clusters and never matches
+	groupDenyRules := slices.DeleteFunc(slices.Clone(denyRules), func(policyRule rbacv1.PolicyRule) bool {
+		return !IsGroupWidePolicyRule(policyRule)
+	})
+	resourceDenyRules := slices.DeleteFunc(slices.Clone(denyRules), IsGroupWidePolicyRule)
+
+	stretchDenyList := p.StretchDenyPolicyRules(IntersectPolicyRules(p.ExpandPolicyRules(resourceDenyRules), allowMap))
+	denyMap = p.GetMapFromStretchedPolicyRules(stretchDenyList)
+	maps.Copy(denyMap, p.GetMapFromGroupPolicyRules(groupDenyRules, allowMap))
+
+	allowMap, err = p.EvaluateSpecialCases(ctx, allowMap, denyMap)
+	return allowMap, denyMap, err
+}
+
+// Process returns the PolicyRules granted by the allow rules once the deny rules are evaluated on them.
+// There is a rule per resource, object name or NonResourceURL, sorted so the output is stable between calls
+func (p *ProcessorT) Process(ctx context.Context, allowRules, denyRules []rbacv1.PolicyRule) (result []rbacv1.PolicyRule, err error) {
+
+	allowMap, denyMap, err := p.GetPolicyRuleMaps(ctx, allowRules, denyRules)
+	if err != nil {
+		return result, err
+	}
+
+	resultMap, err := p.EvaluatePolicyRules(allowMap, denyMap)
+	if err != nil {
+		return result, err
+	}
+
+	return GetSortedPolicyRules(resultMap), err
+}
+
+// GetSortedPolicyRules returns the PolicyRules of a map sorted by their keys.
+// Verbs are sorted and deduplicated too, so the same policy always renders the same rules,
+// whatever the order of the allow rules or the verbs reported by discovery
+func GetSortedPolicyRules(policyRulesMap map[string]rbacv1.PolicyRule) (result []rbacv1.PolicyRule) {
+
+	keys := maps.Keys(policyRulesMap)
+	slices.Sort(keys)
+	for _, key := range keys {
+		policyRule := policyRulesMap[key]
+		policyRule.Verbs = slices.Clone(policyRule.Verbs)
+		slices.Sort(policyRule.Verbs)
+		policyRule.Verbs = slices.Compact(policyRule.Verbs)
+		result = append(result, policyRule)
+	}
+
+	return result
+}
@@ -0,0 +1,386 @@
+package policy
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("PolicyRules scopes splitting", func() {
+	Context("When separating the scopes of mixed rules", func() {
+
+		policyRulesProcessor := ProcessorT{
+			ResourcesByGroup: map[string][]GVKR{
+				"": {
+					{Resource: "pods", Namespaced: true},
+					{Resource: "pods", Subresource: "log", Namespaced: true},
+					{Resource: "nodes", Namespaced: false},
+				},
+				"rbac.authorization.k8s.io": {
+					{Resource: "clusterroles", Namespaced: false},
+					{Resource: "roles", Namespaced: true},
+				},
+			},
+		}
+
+		podsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}}
+		podLogsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}}
+		nodesRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get"}}
+		rolesRule := rbacv1.PolicyRule{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles"}, Verbs: []string{"list"}}
+		clusterRolesRule := rbacv1.PolicyRule{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Verbs: []string{"list"}}
+		healthzRule := rbacv1.PolicyRule{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}}
+		metricsRule := rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}}
+
+		It("should place NonResourceURLs rules on the cluster-scoped list", func() {
+			clusterScopedRules, namespaceScopedRules := policyRulesProcessor.SplitPolicyRules([]rbacv1.PolicyRule{
+				healthzRule, podsRule, nodesRule, metricsRule, podLogsRule, rolesRule, clusterRolesRule,
+			})
+
+			Expect(clusterScopedRules).To(Equal([]rbacv1.PolicyRule{healthzRule, nodesRule, metricsRule, clusterRolesRule}))
+			Expect(namespaceScopedRules).To(Equal([]rbacv1.PolicyRule{podsRule, podLogsRule, rolesRule}))
+		})
+
+		It("should only fill the cluster-scoped list when all the rules are NonResourceURLs", func() {
+			clusterScopedRules, namespaceScopedRules := policyRulesProcessor.SplitPolicyRules([]rbacv1.PolicyRule{
+				healthzRule, metricsRule,
+			})
+
+			Expect(clusterScopedRules).To(Equal([]rbacv1.PolicyRule{healthzRule, metricsRule}))
+			Expect(namespaceScopedRules).To(BeEmpty())
+		})
+
+		It("should drop the rules for resources not present in the cluster", func() {
+			unknownRule := rbacv1.PolicyRule{APIGroups: []string{"example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get"}}
+
+			clusterScopedRules, namespaceScopedRules := policyRulesProcessor.SplitPolicyRules([]rbacv1.PolicyRule{
+				unknownRule, podsRule, healthzRule,
+			})
+
+			Expect(clusterScopedRules).To(Equal([]rbacv1.PolicyRule{healthzRule}))
+			Expect(namespaceScopedRules).To(Equal([]rbacv1.PolicyRule{podsRule}))
+		})
+	})
+})
+
+var _ = Describe("PolicyRules processing", func() {
+	Context("When processing rules against a discovery snapshot", func() {
+
+		ctx := context.Background()
+
+		apiResourceLists := []*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list", "watch"}},
+					{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: []string{"get", "list", "watch"}},
+				},
+			},
+		}
+
+		// Objects are served from memory, keyed by kind, keeping the labels of each one
+		objects := map[string]map[string]labels.Set{
+			"ConfigMap": {
+				"app-config":     {"tier": "frontend"},
+				"db-credentials": {"tier": "backend"},
+			},
+		}
+
+		var listedNamespaces []string
+		objectLister := ObjectListerFunc(func(ctx context.Context, gvk schema.GroupVersionKind, namespace string,
+			selector labels.Selector) (result []string, err error) {
+
+			listedNamespaces = append(listedNamespaces, namespace)
+			for name, objectLabels := range objects[gvk.Kind] {
+				if selector == nil || selector.Matches(objectLabels) {
+					result = append(result, name)
+				}
+			}
+			return result, err
+		})
+
+		BeforeEach(func() {
+			listedNamespaces = nil
+		})
+
+		allowRules := []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"get", "list"}},
+			{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+		}
+
+		It("should return the allowed rules once the deny ones are evaluated", func() {
+			processor := NewProcessorFromResources(apiResourceLists, objectLister)
+
+			result, err := processor.Process(ctx, allowRules, []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}},
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"db-credentials"}, Verbs: []string{"get"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"app-config"}, Verbs: []string{"get", "list"}},
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"db-credentials"}, Verbs: []string{"list"}},
+				{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+			}))
+			Expect(listedNamespaces).To(Equal([]string{""}))
+		})
+
+		It("should list the objects inside the namespace of the processor", func() {
+			processor := NewProcessorFromResources(apiResourceLists, objectLister)
+			processor.Namespace = "default"
+
+			resolvedRules, err := processor.ResolveObjectSelector(ctx,
+				rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+				labels.SelectorFromSet(labels.Set{"tier": "backend"}))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resolvedRules).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"db-credentials"}, Verbs: []string{"get"}},
+			}))
+			Expect(listedNamespaces).To(Equal([]string{"default"}))
+		})
+
+		It("should list the objects only inside the namespaces of the processor, once per name", func() {
+			processor := NewProcessorFromResources(apiResourceLists, objectLister)
+			processor.Namespaces = []string{"team-a", "team-b"}
+
+			result, err := processor.Process(ctx, allowRules, []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}},
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"db-credentials"}, Verbs: []string{"get"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"app-config"}, Verbs: []string{"get", "list"}},
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"db-credentials"}, Verbs: []string{"list"}},
+				{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+			}))
+			Expect(listedNamespaces).To(Equal([]string{"team-a", "team-b"}))
+		})
+
+		It("should fail to evaluate deny rules by name without an object lister", func() {
+			processor := NewProcessorFromResources(apiResourceLists, nil)
+
+			_, err := processor.Process(ctx, allowRules, []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"db-credentials"}, Verbs: []string{"get"}},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("PolicyRules groups coverage", func() {
+	Context("When checking whether rules depend on the resources of a group", func() {
+
+		It("should cover the groups named by the rules or through a wildcard", func() {
+			namedRules := []rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}},
+				{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+			}
+			Expect(CoversGroup(namedRules, "apps")).To(BeTrue())
+			Expect(CoversGroup(namedRules, "example.com")).To(BeFalse())
+
+			wildcardRules := append(namedRules, rbacv1.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"get"}})
+			Expect(CoversGroup(wildcardRules, "example.com")).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("PolicyRules intersection", func() {
+	Context("When narrowing down rules to the resources of a map", func() {
+
+		allowMap := map[string]rbacv1.PolicyRule{
+			"#pods#":                  {APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			"#secrets#db":             {APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"db"}, Verbs: []string{"get"}},
+			"nonresourceurl#/healthz": {NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+		}
+
+		It("should only keep the groups and resources present in the map", func() {
+			Expect(IntersectPolicyRules([]rbacv1.PolicyRule{
+				{APIGroups: []string{"", "apps"}, Resources: []string{"pods", "secrets", "deployments"}, Verbs: []string{"delete"}},
+			}, allowMap)).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods", "secrets"}, Verbs: []string{"delete"}},
+			}))
+		})
+
+		It("should drop the rules left without resources, and keep those with NonResourceURLs", func() {
+			Expect(IntersectPolicyRules([]rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"delete"}},
+				{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+			}, allowMap)).To(Equal([]rbacv1.PolicyRule{
+				{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+			}))
+		})
+	})
+})
+
+var _ = Describe("PolicyRules verbs exclusion", func() {
+	Context("When excluding verbs from every rule", func() {
+
+		policyRulesProcessor := NewProcessorFromResources([]*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"delete", "get", "list"}},
+					{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: []string{"delete", "get"}},
+				},
+			},
+		}, nil)
+
+		It("should remove the verbs, including those granted through wildcards", func() {
+			Expect(policyRulesProcessor.ExcludeVerbs([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			}, []string{"delete"})).To(ConsistOf(
+				rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+				rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+			))
+		})
+
+		It("should keep the verbs named explicitly by a rule", func() {
+			podsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "delete"}}
+			Expect(policyRulesProcessor.ExcludeVerbs([]rbacv1.PolicyRule{podsRule}, []string{"delete"})).To(Equal([]rbacv1.PolicyRule{podsRule}))
+		})
+
+		It("should drop the rules left without verbs", func() {
+			Expect(policyRulesProcessor.ExcludeVerbs([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}},
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			}, []string{"delete", "get"})).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			}))
+		})
+	})
+})
+
+var _ = Describe("PolicyRules verb aliases", func() {
+	Context("When rules use aliases of groups of verbs", func() {
+
+		policyRulesProcessor := NewProcessorFromResources([]*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: DefaultVerbs},
+				},
+			},
+		}, nil)
+
+		It("should replace the aliases with their verbs, keeping the rest of them once", func() {
+			Expect(ExpandVerbAliases([]string{"read", "get", "bind"})).To(Equal([]string{"get", "list", "watch", "bind"}))
+			Expect(ExpandVerbAliases([]string{"admin"})).To(ConsistOf(DefaultVerbs))
+		})
+
+		It("should expand the aliases of allow and deny rules before evaluating them", func() {
+			allowedRules, err := policyRulesProcessor.Process(context.Background(),
+				[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"admin"}}},
+				[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"write"}}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowedRules).To(HaveLen(1))
+			Expect(allowedRules[0].Verbs).To(ConsistOf("get", "list", "watch"))
+		})
+
+		It("should exclude the verbs of an alias, unless the rule names them through another alias", func() {
+			Expect(policyRulesProcessor.ExcludeVerbs([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}},
+			}, []string{"write"})).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+			}))
+
+			podsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"admin"}}
+			Expect(policyRulesProcessor.ExcludeVerbs([]rbacv1.PolicyRule{podsRule}, []string{"delete"})).To(Equal([]rbacv1.PolicyRule{podsRule}))
+		})
+	})
+})
+
+var _ = Describe("PolicyRules group-wide deny", func() {
+	Context("When denying whole API groups", func() {
+
+		ctx := context.Background()
+
+		policyRulesProcessor := NewProcessorFromResources([]*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"get", "list", "update"}},
+				},
+			},
+			{
+				GroupVersion: "apps/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list", "update"}},
+					{Name: "deployments/scale", Kind: "Scale", Namespaced: true, Verbs: []string{"get", "update"}},
+					{Name: "statefulsets", Kind: "StatefulSet", Namespaced: true, Verbs: []string{"get", "list", "update"}},
+				},
+			},
+		}, nil)
+
+		// Allow rules written resource by resource, with subresources and names
+		allowRules := []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "update"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments", "deployments/scale"}, Verbs: []string{"get", "update"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"statefulsets"}, ResourceNames: []string{"db"}, Verbs: []string{"get"}},
+		}
+
+		It("should key the deny rules acting on whole groups by their group", func() {
+			_, denyMap, err := policyRulesProcessor.GetPolicyRuleMaps(ctx, allowRules, []rbacv1.PolicyRule{
+				{APIGroups: []string{"apps", "batch"}, Resources: []string{"*"}, Verbs: []string{"update"}},
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"update"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(denyMap).To(Equal(map[string]rbacv1.PolicyRule{
+				"apps#":  {APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"update"}},
+				"#pods#": {APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"update"}},
+			}))
+		})
+
+		It("should trim every resource, subresource and name of the denied groups", func() {
+			result, err := policyRulesProcessor.Process(ctx, allowRules, []rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "update"}},
+			}))
+		})
+
+		It("should trim the special verbs allowed explicitly when denying every verb", func() {
+			result, err := policyRulesProcessor.Process(ctx, []rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "bind", "escalate", "impersonate"}},
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"bind"}},
+			}, []rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"bind"}},
+			}))
+
+			result, err = policyRulesProcessor.Process(ctx, []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*", "bind", "escalate", "impersonate"}},
+			}, []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeEmpty())
+		})
+
+		It("should only trim the denied verbs when the groups are selected through a wildcard", func() {
+			result, err := policyRulesProcessor.Process(ctx, allowRules, []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"write"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale"}, Verbs: []string{"get"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"statefulsets"}, ResourceNames: []string{"db"}, Verbs: []string{"get"}},
+			}))
+		})
+	})
+})
@@ -0,0 +1,234 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kuberbacv1alpha1 "prosimcorp.com/kuberbac/api/v1alpha1"
+)
+
+// allVerbs are the verbs reported by discovery for most of the core resources, like pods or configmaps
+var allVerbs = []string{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}
+
+// createDynamicClusterRole creates a DynamicClusterRole in the default namespace, and removes it after the spec
+func createDynamicClusterRole(ctx context.Context, name string, spec kuberbacv1alpha1.DynamicClusterRoleSpec) {
+	spec.Synchronization.Time = "10s"
+	if spec.Deny == nil {
+		spec.Deny = []kuberbacv1alpha1.DenyPolicyRuleT{}
+	}
+
+	resource := &kuberbacv1alpha1.DynamicClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       spec,
+	}
+	Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+	DeferCleanup(func(ctx context.Context) {
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+}
+
+// getClusterRoleRules returns a function polling the rules of a ClusterRole, to be used with Eventually
+func getClusterRoleRules(ctx context.Context, name string) func(g Gomega) []rbacv1.PolicyRule {
+	return func(g Gomega) []rbacv1.PolicyRule {
+		clusterRole := &rbacv1.ClusterRole{}
+		g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name}, clusterRole)).To(Succeed())
+		return clusterRole.Rules
+	}
+}
+
+var _ = Describe("Policy pipeline", func() {
+
+	Context("When a DynamicClusterRole combines allow and deny rules", func() {
+
+		It("should expand wildcard verbs and drop the denied resources", func(ctx SpecContext) {
+			createDynamicClusterRole(ctx, "pipeline-wildcards", kuberbacv1alpha1.DynamicClusterRoleSpec{
+				Target: kuberbacv1alpha1.TargetT{Name: "pipeline-wildcards"},
+				Allow: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"pods", "configmaps", "secrets"}, Verbs: []string{"*"}},
+				},
+				Deny: []kuberbacv1alpha1.DenyPolicyRuleT{
+					{PolicyRule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}}},
+				},
+			})
+
+			Eventually(getClusterRoleRules(ctx, "pipeline-wildcards")).WithContext(ctx).Should(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: allVerbs},
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: allVerbs},
+			}))
+		})
+
+		It("should only remove the denied verbs", func(ctx SpecContext) {
+			createDynamicClusterRole(ctx, "pipeline-verbs", kuberbacv1alpha1.DynamicClusterRoleSpec{
+				Target: kuberbacv1alpha1.TargetT{Name: "pipeline-verbs"},
+				Allow: []rbacv1.PolicyRule{
+					{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "watch", "update"}},
+				},
+				Deny: []kuberbacv1alpha1.DenyPolicyRuleT{
+					{PolicyRule: rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"update"}}},
+				},
+			})
+
+			Eventually(getClusterRoleRules(ctx, "pipeline-verbs")).WithContext(ctx).Should(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "watch"}},
+			}))
+		})
+
+		It("should narrow the allowed resources to the names not denied", func(ctx SpecContext) {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pipeline-resource-names"}}
+			Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+			for _, name := range []string{"admin-token", "app-token"} {
+				secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace.Name}}
+				Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			}
+
+			createDynamicClusterRole(ctx, "pipeline-resource-names", kuberbacv1alpha1.DynamicClusterRoleSpec{
+				Target: kuberbacv1alpha1.TargetT{Name: "pipeline-resource-names"},
+				Allow: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}},
+				},
+				Deny: []kuberbacv1alpha1.DenyPolicyRuleT{
+					{PolicyRule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"},
+						ResourceNames: []string{"admin-token"}}},
+				},
+			})
+
+			Eventually(getClusterRoleRules(ctx, "pipeline-resource-names")).WithContext(ctx).Should(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"admin-token"}, Verbs: []string{"list"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"app-token"}, Verbs: []string{"get", "list"}},
+			}))
+		})
+
+		It("should split the rules by scope when asked", func(ctx SpecContext) {
+			createDynamicClusterRole(ctx, "pipeline-scopes", kuberbacv1alpha1.DynamicClusterRoleSpec{
+				Target: kuberbacv1alpha1.TargetT{Name: "pipeline-scopes", SeparateScopes: true},
+				Allow: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"pods", "nodes"}, Verbs: []string{"get", "list"}},
+					{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+				},
+			})
+
+			Eventually(getClusterRoleRules(ctx, "pipeline-scopes-cluster")).WithContext(ctx).Should(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get", "list"}},
+				{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+			}))
+			Eventually(getClusterRoleRules(ctx, "pipeline-scopes-namespace")).WithContext(ctx).Should(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+			}))
+		})
+
+		It("should remove the generated ClusterRoles when deleted", func(ctx SpecContext) {
+			resource := &kuberbacv1alpha1.DynamicClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "pipeline-deletion", Namespace: "default"},
+				Spec: kuberbacv1alpha1.DynamicClusterRoleSpec{
+					Synchronization: kuberbacv1alpha1.SynchronizationT{Time: "10s"},
+					Target:          kuberbacv1alpha1.TargetT{Name: "pipeline-deletion"},
+					Allow:           []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+					Deny:            []kuberbacv1alpha1.DenyPolicyRuleT{},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			Eventually(getClusterRoleRules(ctx, "pipeline-deletion")).WithContext(ctx).Should(HaveLen(1))
+
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "pipeline-deletion"}, &rbacv1.ClusterRole{})
+				return apierrors.IsNotFound(err)
+			}).WithContext(ctx).Should(BeTrue())
+		})
+	})
+
+	Context("When a DynamicRoleBinding binds a generated ClusterRole", func() {
+
+		It("should bind the selected ServiceAccounts on the selected namespaces", func(ctx SpecContext) {
+			for _, name := range []string{"pipeline-team-a", "pipeline-team-b", "pipeline-other"} {
+				labels := map[string]string{"team": "pipeline"}
+				if name == "pipeline-other" {
+					labels = nil
+				}
+				namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+				Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+
+				serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "ci", Namespace: name}}
+				Expect(k8sClient.Create(ctx, serviceAccount)).To(Succeed())
+			}
+
+			createDynamicClusterRole(ctx, "pipeline-ci", kuberbacv1alpha1.DynamicClusterRoleSpec{
+				Target: kuberbacv1alpha1.TargetT{Name: "pipeline-ci"},
+				Allow: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+				},
+			})
+
+			namespaceSelector := kuberbacv1alpha1.NamespaceSelectorT{MatchLabels: map[string]string{"team": "pipeline"}}
+			resource := &kuberbacv1alpha1.DynamicRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "pipeline-ci", Namespace: "default"},
+				Spec: kuberbacv1alpha1.DynamicRoleBindingSpec{
+					Synchronization: kuberbacv1alpha1.SynchronizationT{Time: "10s"},
+					Source: kuberbacv1alpha1.DynamicRoleBindingSource{
+						DynamicClusterRole: "pipeline-ci",
+						Subject: &kuberbacv1alpha1.DynamicRoleBindingSourceSubject{
+							ApiGroup:          "",
+							Kind:              "ServiceAccount",
+							NameSelector:      kuberbacv1alpha1.NameSelectorT{MatchList: []string{"ci"}},
+							NamespaceSelector: namespaceSelector,
+						},
+					},
+					Targets: kuberbacv1alpha1.DynamicRoleBindingTargets{
+						Name:              "pipeline-ci",
+						NamespaceSelector: namespaceSelector,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func(ctx context.Context) {
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+
+			expectedSubjects := []rbacv1.Subject{
+				{Kind: "ServiceAccount", Name: "ci", Namespace: "pipeline-team-a"},
+				{Kind: "ServiceAccount", Name: "ci", Namespace: "pipeline-team-b"},
+			}
+
+			for _, namespace := range []string{"pipeline-team-a", "pipeline-team-b"} {
+				Eventually(func(g Gomega) {
+					roleBinding := &rbacv1.RoleBinding{}
+					g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "pipeline-ci-pipeline-ci"}, roleBinding)).To(Succeed())
+					g.Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "pipeline-ci"}))
+					g.Expect(roleBinding.Subjects).To(ConsistOf(expectedSubjects))
+				}).WithContext(ctx).Should(Succeed())
+			}
+
+			Consistently(func(g Gomega) []rbacv1.RoleBinding {
+				roleBindingList := &rbacv1.RoleBindingList{}
+				g.Expect(k8sClient.List(ctx, roleBindingList, client.InNamespace("pipeline-other"))).To(Succeed())
+				return roleBindingList.Items
+			}).WithContext(ctx).WithTimeout(2 * time.Second).Should(BeEmpty())
+		})
+	})
+})